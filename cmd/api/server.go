@@ -7,17 +7,20 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
-
-	// "time"
+	"strconv"
+	"time"
 
 	mw "github.com/aayushxrj/go-rest-api-school-mgmt/internal/api/middlewares"
 	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/api/router"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/jobs"
 	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/repository/sqlconnect"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/tracing"
 	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
 	"github.com/joho/godotenv"
 	"golang.org/x/net/http2"
@@ -30,6 +33,20 @@ func main() {
 		panic(err)
 	}
 
+	shutdownTracing, err := tracing.Init(context.Background())
+	if err != nil {
+		utils.ErrorHandler(err, "Error initializing tracing")
+		return
+	}
+	defer shutdownTracing(context.Background())
+
+	// the sensitive fields of students can't be read or written without the key
+	err = utils.InitEncryption()
+	if err != nil {
+		utils.ErrorHandler(err, "Error reading configuration")
+		return
+	}
+
 	_, err = sqlconnect.ConnectDB()
 	if err != nil {
 		// log.Fatal("Error connecting to database:", err)
@@ -37,6 +54,20 @@ func main() {
 		return
 	}
 
+	// soft-deleted records are purged after SOFT_DELETE_RETENTION_DAYS, 0 keeps them forever
+	retentionDays := 30
+	if days := os.Getenv("SOFT_DELETE_RETENTION_DAYS"); days != "" {
+		retentionDays, err = strconv.Atoi(days)
+		if err != nil || retentionDays < 0 {
+			utils.ErrorHandler(fmt.Errorf("invalid SOFT_DELETE_RETENTION_DAYS %q", days), "Error reading configuration")
+			return
+		}
+	}
+	if retentionDays > 0 {
+		go jobs.PurgeDeleted(context.Background(), time.Duration(retentionDays)*24*time.Hour, time.Hour)
+	}
+	go jobs.PurgeIdempotencyKeys(context.Background(), time.Hour)
+
 	port := fmt.Sprintf(":%s", os.Getenv("API_PORT"))
 
 	cert := "cmd/api/cert.pem"
@@ -60,14 +91,77 @@ func main() {
 
 	jwtMiddleware := mw.MiddlewaresExcludePaths(mw.JWTMiddleware,
 		"/swagger",
+		"/metrics",
 		"/execs/login",
 		"/execs/forgotpassword",
 		"/execs/resetpassword/reset")
 
+	// opt-in validation against the swagger spec, OPENAPI_VALIDATION=requests|strict
+	openAPIValidation, err := mw.OpenAPIValidation(mw.OpenAPIValidationOptions{
+		SpecPath: "docs/swagger.json",
+		Mode:     os.Getenv("OPENAPI_VALIDATION"),
+	})
+	if err != nil {
+		utils.ErrorHandler(err, "Error loading OpenAPI spec")
+		return
+	}
+
+	// Cache-Control per route, "no-cache" lets clients keep a copy but revalidate it with ETag or Last-Modified
+	cacheOptions := mw.CacheOptions{
+		Default: "no-store",
+		Policies: map[string]string{
+			"/students":                   "private, no-cache",
+			"/students/{id}":              "private, no-cache",
+			"/students/{id}/history":      "private, no-cache",
+			"/students/{id}/attendance":   "private, no-cache",
+			"/students/{id}/grades":       "private, no-cache",
+			"/students/{id}/reportcard":   "private, no-cache",
+			"/students/{id}/comments":     "private, no-cache",
+			"/students/{id}/enrollments":  "private, no-cache",
+			"/students/{id}/guardians":    "private, no-cache",
+			"/teachers":                   "private, no-cache",
+			"/teachers/{id}":              "private, no-cache",
+			"/teachers/{id}/history":      "private, no-cache",
+			"/teachers/{id}/students":     "private, no-cache",
+			"/teachers/{id}/studentcount": "private, no-cache",
+			"/teachers/{id}/assignments":  "private, no-cache",
+			"/teachers/{id}/timetable":    "private, no-cache",
+			"/classes":                    "private, no-cache",
+			"/classes/{id}":               "private, no-cache",
+			"/classes/{id}/students":      "private, no-cache",
+			"/classes/{id}/attendance":    "private, no-cache",
+			"/classes/{id}/assessments":   "private, no-cache",
+			"/classes/{id}/grades":        "private, no-cache",
+			"/classes/{id}/timetable":     "private, no-cache",
+			"/gradebands":                 "private, no-cache",
+			"/reportcards/{id}":           "private, no-cache",
+			"/academicyears":              "private, no-cache",
+			"/academicyears/{id}":         "private, no-cache",
+			"/terms":                      "private, no-cache",
+			"/terms/current":              "private, no-cache",
+			"/terms/{id}":                 "private, no-cache",
+			"/timetable":                  "private, no-cache",
+			"/timetable/{id}":             "private, no-cache",
+			"/timetable/periods":          "private, no-cache",
+			"/timetable/rooms":            "private, no-cache",
+			"/guardians":                  "private, no-cache",
+			"/guardians/{id}":             "private, no-cache",
+			"/guardians/{id}/students":    "private, no-cache",
+			"/execs":                      "private, no-cache",
+			"/execs/{id}":                 "private, no-cache",
+			"/swagger/":                   "public, max-age=3600",
+		},
+	}
+
 	// proper ordering of middlewares
 	// example: Cors -> Rate Limiter -> Response Time -> Security Headers -> Compression -> HPP -> Actual Handler
 	// secureMux := utils.ApplyMiddlewares(router, mw.SecurityHeaders, mw.Compression, mw.Hpp(hppOptions), mw.XSSMiddleware, jwtMiddleware, mw.ResponseTimeMiddleware, rl.Middleware, mw.Cors)
 	secureMux := utils.ApplyMiddlewares(router, 
+		openAPIValidation,
+		// inside the JWT middleware, keys are scoped to the logged in exec
+		mw.Idempotency(24*time.Hour),
+		mw.ConditionalGet,
+		mw.CacheControl(router, cacheOptions),
 		mw.SecurityHeaders, 
 		mw.Compression, 
 		// mw.Hpp(hppOptions), 
@@ -75,12 +169,17 @@ func main() {
 		jwtMiddleware, 
 		mw.ResponseTimeMiddleware, 
 		// mw.Cors
+		mw.Metrics(router),
+		mw.RequestID,
 	)
 
+	// the server span wraps the whole chain so middleware, handler and SQL spans nest under it
+	tracedMux := mw.Tracing(router)(secureMux)
+
 	// Create custom server
 	server := &http.Server{
 		Addr:      port,
-		Handler:   secureMux,
+		Handler:   tracedMux,
 		TLSConfig: tlsConfig,
 	}
 
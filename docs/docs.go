@@ -15,54 +15,20 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/execs": {
+        "/academicyears": {
             "get": {
-                "description": "Get a list of execs with optional filtering and sorting.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the academic years of the school, latest first, with their terms",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
                 ],
                 "tags": [
-                    "execs"
-                ],
-                "summary": "Retrieve all execs",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Filter by first name (optional)",
-                        "name": "first_name",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by last name (optional)",
-                        "name": "last_name",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by email (optional)",
-                        "name": "email",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by role (optional)",
-                        "name": "role",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Sorting (e.g., first_name:asc, role:desc) (optional)",
-                        "name": "sortby",
-                        "in": "query"
-                    }
+                    "terms"
                 ],
+                "summary": "List the academic years",
                 "responses": {
                     "200": {
-                        "description": "List of execs with metadata",
+                        "description": "Academic years",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -71,33 +37,34 @@ const docTemplate = `{
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
             },
             "post": {
-                "description": "Add one or more execs",
+                "description": "Add one or more academic years. They must not overlap, classes can only be added to an existing year. Admins only.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
                 ],
                 "tags": [
-                    "execs"
+                    "terms"
                 ],
-                "summary": "Add new execs",
+                "summary": "Add academic years",
                 "parameters": [
                     {
-                        "description": "List of execs",
-                        "name": "execs",
+                        "description": "List of academic years",
+                        "name": "years",
                         "in": "body",
                         "required": true,
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Exec"
+                                "$ref": "#/definitions/models.AcademicYear"
                             }
                         }
                     }
@@ -113,523 +80,545 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid request payload",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "403": {
+                        "description": "Only admins may change academic years",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
-                    }
-                }
-            },
-            "patch": {
-                "description": "Apply partial updates to multiple execs",
-                "consumes": [
-                    "application/json"
-                ],
-                "tags": [
-                    "execs"
-                ],
-                "summary": "Partially update multiple execs",
-                "parameters": [
-                    {
-                        "description": "List of updates with exec IDs",
-                        "name": "updates",
-                        "in": "body",
-                        "required": true,
+                    },
+                    "409": {
+                        "description": "An academic year with this name already exists",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "object",
-                                "additionalProperties": true
-                            }
+                            "$ref": "#/definitions/utils.Problem"
                         }
-                    }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No Content"
                     },
-                    "400": {
-                        "description": "Invalid request payload",
+                    "422": {
+                        "description": "Validation failed",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
             }
         },
-        "/execs/forgotpassword": {
-            "post": {
-                "description": "Sends a password reset link to the exec's email.",
-                "consumes": [
-                    "application/json"
-                ],
+        "/academicyears/{id}": {
+            "get": {
+                "description": "Get an academic year by ID with its terms",
                 "produces": [
-                    "text/plain"
+                    "application/json",
+                    "application/problem+json"
                 ],
                 "tags": [
-                    "auth"
+                    "terms"
                 ],
-                "summary": "Request password reset",
+                "summary": "Get an academic year",
                 "parameters": [
                     {
-                        "description": "Exec email",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object",
-                            "properties": {
-                                "email": {
-                                    "type": "string"
-                                }
-                            }
-                        }
+                        "type": "integer",
+                        "description": "Academic year ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Password reset link sent",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.AcademicYear"
                         }
                     },
                     "400": {
-                        "description": "Invalid request or user not found",
+                        "description": "Invalid Academic Year ID",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Academic year not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
-            }
-        },
-        "/execs/login": {
-            "post": {
-                "description": "Authenticates an exec user using username and password and returns a JWT token.",
+            },
+            "put": {
+                "description": "Move the dates of an academic year, its terms must stay inside them. The name can't be changed. Admins only.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
                 ],
                 "tags": [
-                    "auth"
+                    "terms"
                 ],
-                "summary": "User Login",
+                "summary": "Update an academic year",
                 "parameters": [
                     {
-                        "description": "Login Credentials (username and password required)",
-                        "name": "credentials",
+                        "type": "integer",
+                        "description": "Academic year ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated academic year",
+                        "name": "year",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Exec"
+                            "$ref": "#/definitions/models.AcademicYear"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "JWT token in response body and also set as HttpOnly cookie",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.AcademicYear"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body or missing username/password",
+                        "description": "Invalid request payload or ID",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
                     "403": {
-                        "description": "Account inactive or password incorrect",
+                        "description": "Only admins may change academic years",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Academic year not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
                     "500": {
-                        "description": "Could not create login token",
+                        "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
-            }
-        },
-        "/execs/logout": {
-            "post": {
-                "description": "Logs out the currently authenticated user by clearing the JWT cookie.",
+            },
+            "delete": {
+                "description": "Delete an academic year with its terms, as long as no class belongs to it. Admins only.",
                 "produces": [
-                    "application/json"
+                    "application/problem+json"
                 ],
                 "tags": [
-                    "auth"
+                    "terms"
+                ],
+                "summary": "Delete an academic year",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Academic year ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Log out a user",
                 "responses": {
-                    "200": {
-                        "description": "Logged out successfully",
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid Academic Year ID",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may delete academic years",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Academic year not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Classes still belong to the academic year",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
             }
         },
-        "/execs/resetpassword/reset/{resetcode}": {
+        "/academicyears/{id}/rollover": {
             "post": {
-                "description": "Resets the exec's password using a reset token sent via email.",
+                "description": "Move the students of an academic year on to the classes of the next one. Every class moves up a grade keeping its section and grade 12 graduates, unless mapping says otherwise for a class, e.g. {\"10A\": \"11B\"}, or a whole grade, e.g. {\"12\": \"graduated\"} or {\"9\": \"10\"}. Held back students stay in a class of the same name, or the one given, in the next year. The enrollments of the year are ended with their outcome and graduates keep their last class. Missing classes of the next year are added with create_classes. Everything happens in one transaction, with dry_run=true it is rolled back and only the outcome reported. Admins only.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "text/plain"
+                    "application/json",
+                    "application/problem+json"
                 ],
                 "tags": [
-                    "auth"
+                    "terms"
                 ],
-                "summary": "Reset password using reset token",
+                "summary": "Roll an academic year over",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Password reset token",
-                        "name": "resetcode",
+                        "type": "integer",
+                        "description": "ID of the academic year ending",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "New password request",
-                        "name": "body",
+                        "type": "boolean",
+                        "description": "Report what the rollover would do without changing anything (optional)",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "description": "The next academic year, mapping and exceptions",
+                        "name": "rollover",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "object",
-                            "properties": {
-                                "confirm_password": {
-                                    "type": "string"
-                                },
-                                "new_password": {
-                                    "type": "string"
-                                }
-                            }
+                            "$ref": "#/definitions/models.Rollover"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Password reset successfully",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.RolloverResult"
                         }
                     },
                     "400": {
-                        "description": "Invalid request or password mismatch",
+                        "description": "Invalid request payload or ID",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may roll over academic years",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Academic year not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "A class of the next year would be over its capacity",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed, e.g. a missing class of the next year",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
             }
         },
-        "/execs/{id}": {
+        "/audit": {
             "get": {
-                "description": "Retrieve details of an exec by ID",
+                "description": "Get audit entries, newest first, with optional filters. Admins only.",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
                 ],
                 "tags": [
-                    "execs"
+                    "audit"
                 ],
-                "summary": "Get one exec",
+                "summary": "List the audit log",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Exec ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.Exec"
-                        }
+                        "description": "Filter by the exec who made the change (optional)",
+                        "name": "actor_id",
+                        "in": "query"
                     },
-                    "400": {
-                        "description": "Invalid Exec ID",
-                        "schema": {
-                            "type": "string"
-                        }
+                    {
+                        "type": "string",
+                        "description": "Filter by username of the actor (optional)",
+                        "name": "actor_username",
+                        "in": "query"
                     },
-                    "500": {
-                        "description": "Internal server error",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
-                }
-            },
-            "delete": {
-                "description": "Delete an exec by ID",
-                "tags": [
-                    "execs"
-                ],
-                "summary": "Delete one exec",
-                "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Exec ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No Content"
+                        "type": "string",
+                        "description": "Filter by action, e.g. create, update, delete, login (optional)",
+                        "name": "action",
+                        "in": "query"
                     },
-                    "400": {
-                        "description": "Invalid Exec ID",
-                        "schema": {
-                            "type": "string"
-                        }
+                    {
+                        "type": "string",
+                        "description": "Filter by resource, e.g. students, teachers, execs (optional)",
+                        "name": "resource",
+                        "in": "query"
                     },
-                    "500": {
-                        "description": "Internal server error",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
-                }
-            },
-            "patch": {
-                "description": "Apply partial updates to a single exec by ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "execs"
-                ],
-                "summary": "Partially update one exec",
-                "parameters": [
                     {
                         "type": "integer",
-                        "description": "Exec ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Filter by ID of the changed record (optional)",
+                        "name": "resource_id",
+                        "in": "query"
                     },
                     {
-                        "description": "Partial updates",
-                        "name": "updates",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.Exec"
-                        }
+                        "type": "string",
+                        "description": "Filter by client IP (optional)",
+                        "name": "ip",
+                        "in": "query"
                     },
-                    "400": {
-                        "description": "Invalid request payload or ID",
-                        "schema": {
-                            "type": "string"
-                        }
+                    {
+                        "type": "string",
+                        "description": "Filter by request ID (optional)",
+                        "name": "request_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries at or after this RFC 3339 time or date (optional)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries before this RFC 3339 time or date (optional)",
+                        "name": "to",
+                        "in": "query"
                     },
-                    "500": {
-                        "description": "Internal server error",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
-                }
-            }
-        },
-        "/execs/{id}/updatepassword": {
-            "patch": {
-                "description": "Allows an exec to update their password after providing the current password. A new JWT token is generated upon success.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "auth"
-                ],
-                "summary": "Update an exec's password",
-                "parameters": [
                     {
                         "type": "integer",
-                        "description": "Exec ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Page number, starting at 1 (optional)",
+                        "name": "page",
+                        "in": "query"
                     },
                     {
-                        "description": "Password update request",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UpdatePasswordRequest"
-                        }
+                        "type": "integer",
+                        "description": "Page size, defaults to 10 (optional)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Password updated successfully",
+                        "description": "List of audit entries with metadata",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "Invalid input or password update failed",
+                    "403": {
+                        "description": "Only admins may read the audit log",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid time range",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
             }
         },
-        "/students": {
+        "/classes": {
             "get": {
-                "description": "Get a list of students with optional filtering and sorting.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get a list of classes with optional filtering and sorting.",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
                 ],
                 "tags": [
-                    "students"
+                    "classes"
                 ],
-                "summary": "Retrieve all students",
+                "summary": "Retrieve all classes",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Filter by first name (optional)",
-                        "name": "first_name",
+                        "description": "Filter by name, e.g. 10A (optional)",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by grade (optional)",
+                        "name": "grade",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Filter by last name (optional)",
-                        "name": "last_name",
+                        "description": "Filter by section (optional)",
+                        "name": "section",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by homeroom teacher (optional)",
+                        "name": "homeroom_teacher_id",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Filter by email (optional)",
-                        "name": "email",
+                        "description": "Filter by academic year, e.g. 2025-2026 (optional)",
+                        "name": "academic_year",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Filter by class (optional)",
-                        "name": "class",
+                        "description": "Only classes of the academic year of this term, its ID or current (optional)",
+                        "name": "term",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Sorting (e.g., first_name:asc, class:desc) (optional)",
+                        "description": "Sorting (e.g., grade:asc, section:asc) (optional)",
                         "name": "sortby",
                         "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number, starting at 1 (optional)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, defaults to 10 (optional)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response, answered with 304 if unchanged",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified from a previous response",
+                        "name": "If-Modified-Since",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of students with metadata",
+                        "description": "List of classes with metadata",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Hash of the response body"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Latest updated_at of the listed records"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "422": {
+                        "description": "Unknown term",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
             },
             "post": {
-                "description": "Add one or more students",
+                "description": "Add one or more classes, their names are made of the grade and section. Admins only.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
                 ],
                 "tags": [
-                    "students"
+                    "classes"
                 ],
-                "summary": "Add new students",
+                "summary": "Add new classes",
                 "parameters": [
                     {
-                        "description": "List of students",
-                        "name": "students",
+                        "description": "List of classes",
+                        "name": "classes",
                         "in": "body",
                         "required": true,
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Student"
+                                "$ref": "#/definitions/models.Class"
                             }
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for this request, retries with the same key get the first response replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -643,175 +632,143 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid request payload",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may change classes",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "A class with this name already exists in the academic year",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete multiple students by their IDs",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/classes/{id}": {
+            "get": {
+                "description": "Retrieve details of a class by ID",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
                 ],
                 "tags": [
-                    "students"
+                    "classes"
                 ],
-                "summary": "Delete multiple students",
+                "summary": "Get one class",
                 "parameters": [
                     {
-                        "description": "List of student IDs",
-                        "name": "ids",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "integer"
-                            }
-                        }
+                        "type": "integer",
+                        "description": "Class ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response, answered with 304 if unchanged",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified from a previous response",
+                        "name": "If-Modified-Since",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.Class"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the class, send it back in If-Match"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "When the record was last updated"
+                            }
                         }
                     },
+                    "304": {
+                        "description": "Not Modified"
+                    },
                     "400": {
-                        "description": "Invalid request payload",
+                        "description": "Invalid Class ID",
                         "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal server error",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
-                }
-            },
-            "patch": {
-                "description": "Apply partial updates to multiple students",
-                "consumes": [
-                    "application/json"
-                ],
-                "tags": [
-                    "students"
-                ],
-                "summary": "Partially update multiple students",
-                "parameters": [
-                    {
-                        "description": "List of updates",
-                        "name": "updates",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "object",
-                                "additionalProperties": true
-                            }
-                        }
-                    }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "400": {
-                        "description": "Invalid request payload",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal server error",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
-                }
-            }
-        },
-        "/students/{id}": {
-            "get": {
-                "description": "Retrieve details of a student by ID",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "students"
-                ],
-                "summary": "Get one student",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Student ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.Student"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
-                    "400": {
-                        "description": "Invalid Student ID",
+                    "404": {
+                        "description": "Class not found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
             },
             "put": {
-                "description": "Update an existing student by ID",
+                "description": "Replace a class by ID. Changing the grade or section renames the class of its students too. Admins only.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
                 ],
                 "tags": [
-                    "students"
+                    "classes"
                 ],
-                "summary": "Update a student",
+                "summary": "Update a class",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Student ID",
+                        "description": "Class ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated student",
-                        "name": "student",
+                        "type": "string",
+                        "description": "ETag of the class from GET /classes/{id}, or *",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated class",
+                        "name": "class",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Student"
+                            "$ref": "#/definitions/models.Class"
                         }
                     }
                 ],
@@ -819,36 +776,88 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Student"
+                            "$ref": "#/definitions/models.Class"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the class, send it back in If-Match"
+                            }
                         }
                     },
                     "400": {
                         "description": "Invalid request payload or ID",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may change classes",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Class not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "A class with this name already exists in the academic year",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Class was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Missing If-Match header",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
             },
             "delete": {
-                "description": "Delete a student by ID",
+                "description": "Delete a class by ID for good, it must have no students, deleted ones included. Admins only.",
+                "produces": [
+                    "application/problem+json"
+                ],
                 "tags": [
-                    "students"
+                    "classes"
                 ],
-                "summary": "Delete one student",
+                "summary": "Delete a class",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Student ID",
+                        "description": "Class ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the class from GET /classes/{id}, or *",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -856,41 +865,79 @@ const docTemplate = `{
                         "description": "No Content"
                     },
                     "400": {
-                        "description": "Invalid Student ID",
+                        "description": "Invalid Class ID",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may change classes",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Class not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Class still has students",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Class was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Missing If-Match header",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
             },
             "patch": {
-                "description": "Apply partial updates to a single student by ID",
+                "description": "Update some fields of a class by ID, a null homeroom_teacher_id removes the homeroom teacher. Admins only.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
                 ],
                 "tags": [
-                    "students"
+                    "classes"
                 ],
-                "summary": "Partially update one student",
+                "summary": "Partially update a class",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Student ID",
+                        "description": "Class ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Partial updates",
+                        "type": "string",
+                        "description": "ETag of the class from GET /classes/{id}, or *",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update",
                         "name": "updates",
                         "in": "body",
                         "required": true,
@@ -904,61 +951,84 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Student"
+                            "$ref": "#/definitions/models.Class"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the class, send it back in If-Match"
+                            }
                         }
                     },
                     "400": {
                         "description": "Invalid request payload or ID",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may change classes",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Class not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "A class with this name already exists in the academic year",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Class was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Missing If-Match header",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
             }
         },
-        "/teachers": {
+        "/classes/{id}/assessments": {
             "get": {
-                "description": "Get a list of teachers with optional filtering and sorting.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the assessments of a class by date",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
                 ],
                 "tags": [
-                    "teachers"
+                    "grades"
                 ],
-                "summary": "Retrieve all teachers",
+                "summary": "List the assessments of a class",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Filter by first name (optional)",
-                        "name": "first_name",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by last name (optional)",
-                        "name": "last_name",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by email (optional)",
-                        "name": "email",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by class (optional)",
-                        "name": "class",
-                        "in": "query"
+                        "type": "integer",
+                        "description": "Class ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
@@ -968,51 +1038,77 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Sorting (e.g., first_name:asc, class:desc) (optional)",
-                        "name": "sortby",
+                        "description": "Filter by term (optional)",
+                        "name": "term",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of teachers with metadata",
+                        "description": "Assessments of the class",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
+                    "400": {
+                        "description": "Invalid Class ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Class not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
             },
             "post": {
-                "description": "Add one or more teachers",
+                "description": "Add one or more assessments to a class, a missing weight counts as 1. The batch is all or nothing.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
                 ],
                 "tags": [
-                    "teachers"
+                    "grades"
                 ],
-                "summary": "Add new teachers",
+                "summary": "Add assessments to a class",
                 "parameters": [
                     {
-                        "description": "List of teachers",
-                        "name": "teachers",
+                        "type": "integer",
+                        "description": "Class ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "List of assessments",
+                        "name": "assessments",
                         "in": "body",
                         "required": true,
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Teacher"
+                                "$ref": "#/definitions/models.Assessment"
                             }
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for this request, retries with the same key get the first response replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -1024,457 +1120,7460 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid request payload",
+                        "description": "Invalid request payload or ID",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Class not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete multiple teachers by their IDs",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/classes/{id}/assessments/{assessmentId}": {
+            "get": {
+                "description": "Get an assessment of a class with its marks",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
                 ],
                 "tags": [
-                    "teachers"
+                    "grades"
                 ],
-                "summary": "Delete multiple teachers",
+                "summary": "Get an assessment",
                 "parameters": [
                     {
-                        "description": "List of teacher IDs",
-                        "name": "ids",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "integer"
-                            }
-                        }
+                        "type": "integer",
+                        "description": "Class ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Assessment ID",
+                        "name": "assessmentId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.Assessment"
                         }
                     },
                     "400": {
-                        "description": "Invalid request payload",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal server error",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
-                }
-            },
-            "patch": {
-                "description": "Apply partial updates to multiple teachers",
-                "consumes": [
-                    "application/json"
-                ],
-                "tags": [
-                    "teachers"
-                ],
-                "summary": "Partially update multiple teachers",
-                "parameters": [
-                    {
-                        "description": "List of updates",
-                        "name": "updates",
-                        "in": "body",
-                        "required": true,
+                        "description": "Invalid Class or Assessment ID",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "object",
-                                "additionalProperties": true
-                            }
+                            "$ref": "#/definitions/utils.Problem"
                         }
-                    }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No Content"
                     },
-                    "400": {
-                        "description": "Invalid request payload",
+                    "404": {
+                        "description": "Assessment not found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
-            }
-        },
-        "/teachers/{id}": {
-            "get": {
-                "description": "Retrieve details of a teacher by ID",
+            },
+            "delete": {
+                "description": "Delete an assessment of a class with all of its marks. Admins only.",
                 "produces": [
-                    "application/json"
+                    "application/problem+json"
                 ],
                 "tags": [
-                    "teachers"
+                    "grades"
                 ],
-                "summary": "Get one teacher",
+                "summary": "Delete an assessment",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Teacher ID",
+                        "description": "Class ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Assessment ID",
+                        "name": "assessmentId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid Class or Assessment ID",
                         "schema": {
-                            "$ref": "#/definitions/models.Teacher"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
-                    "400": {
-                        "description": "Invalid Teacher ID",
+                    "403": {
+                        "description": "Only admins may delete assessments",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Assessment not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/classes/{id}/assessments/{assessmentId}/marks": {
             "put": {
-                "description": "Update an existing teacher by ID",
+                "description": "Enter the marks of students of the class in an assessment, replacing marks they already had in it. A null score, e.g. for an excused absence, doesn't count towards averages. The batch is all or nothing.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
                 ],
                 "tags": [
-                    "teachers"
+                    "grades"
                 ],
-                "summary": "Update a teacher",
+                "summary": "Enter marks",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Teacher ID",
+                        "description": "Class ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated teacher",
-                        "name": "teacher",
+                        "type": "integer",
+                        "description": "Assessment ID",
+                        "name": "assessmentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "A mark per student",
+                        "name": "marks",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Teacher"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Mark"
+                            }
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for this request, retries with the same key get the first response replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Created, updated and unchanged counts with the marks",
                         "schema": {
-                            "$ref": "#/definitions/models.Teacher"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
                         "description": "Invalid request payload or ID",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Assessment not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed, e.g. a score over the max score",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a teacher by ID",
+            }
+        },
+        "/classes/{id}/attendance": {
+            "get": {
+                "description": "Count the attendance of a class by status for every day between from and to, with the attendance rate. Only whole-day records are counted unless period is given.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
                 "tags": [
-                    "teachers"
+                    "attendance"
                 ],
-                "summary": "Delete one teacher",
+                "summary": "Daily attendance summaries of a class",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Teacher ID",
+                        "description": "Class ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day, e.g. 2026-01-01, defaults to today (optional)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day, defaults to today (optional)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Count the records of this period instead, 0 is the whole day (optional)",
+                        "name": "period",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "Summary of every day with records, and of the whole range",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     },
                     "400": {
-                        "description": "Invalid Teacher ID",
+                        "description": "Invalid Class ID",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Class not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid from, to or period",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
             },
-            "patch": {
-                "description": "Apply partial updates to a single teacher by ID",
+            "post": {
+                "description": "Record the attendance of the students of a class for a day, period 0, or one of its periods. Submitting again for the same day and period corrects the earlier records. The batch is all or nothing.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
                 ],
                 "tags": [
-                    "teachers"
+                    "attendance"
                 ],
-                "summary": "Partially update one teacher",
+                "summary": "Take the attendance of a class",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Teacher ID",
+                        "description": "Class ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Partial updates",
-                        "name": "updates",
+                        "description": "Day, period, teacher and a record per student",
+                        "name": "sheet",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.AttendanceSheet"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for this request, retries with the same key get the first response replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Created, updated and unchanged counts with the records",
                         "schema": {
-                            "$ref": "#/definitions/models.Teacher"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
                         "description": "Invalid request payload or ID",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Class not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed, e.g. a student of another class",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
             }
         },
-        "/teachers/{id}/studentcount": {
+        "/classes/{id}/grades": {
             "get": {
-                "description": "Get the total number of students assigned to a specific teacher.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the weighted averages of every student of a class by subject and overall, with their grades, and the class averages",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
                 ],
                 "tags": [
-                    "teachers"
+                    "grades"
                 ],
-                "summary": "Retrieve student count by teacher ID",
+                "summary": "Averages of a class",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Teacher ID",
+                        "description": "Class ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only this subject (optional)",
+                        "name": "subject",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only this term (optional)",
+                        "name": "term",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Student count",
+                        "description": "Averages of the students and of the class",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Invalid Teacher ID",
+                        "description": "Invalid Class ID",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Class not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
             }
         },
-        "/teachers/{id}/students": {
-            "get": {
-                "description": "Get all students assigned to a specific teacher.",
-                "consumes": [
-                    "application/json"
-                ],
+        "/classes/{id}/reportcards": {
+            "post": {
+                "description": "Start a job that generates the report card of every student of a class for a term and emails it to the student as a PDF, or stores it to be fetched from /reportcards/{id}. Storing a report card again replaces the student's earlier one for the term. Poll the returned job for its outcome. Admins only.",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
                 ],
                 "tags": [
-                    "teachers"
+                    "reportcards"
                 ],
-                "summary": "Retrieve students by teacher ID",
+                "summary": "Generate the report cards of a class",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Teacher ID",
+                        "description": "Class ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Term, e.g. Term 1, defaults to the current term (optional)",
+                        "name": "term",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "email or store, defaults to store (optional)",
+                        "name": "delivery",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "List of students with metadata",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.ReportCardJob"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the job to poll"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Invalid Teacher ID",
+                        "description": "Invalid Class ID",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may generate report cards",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Class not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Missing term or invalid delivery",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.Exec": {
-            "type": "object",
+        },
+        "/classes/{id}/students": {
+            "get": {
+                "description": "List the students of a class, by last name",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "classes"
+                ],
+                "summary": "Get the students of a class",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Class ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Students of the class",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Class ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Class not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/classes/{id}/timetable": {
+            "get": {
+                "description": "Get the lessons of a class in a term by weekday and period. With ?format=ics or Accept: text/calendar it is an iCalendar file with a weekly event per lesson until the end of the term.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json",
+                    "text/calendar"
+                ],
+                "tags": [
+                    "timetable"
+                ],
+                "summary": "Weekly timetable of a class",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Class ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of a term or current, defaults to current (optional)",
+                        "name": "term",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json or ics, overrides the Accept header (optional)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The term and the lessons of the class, or the calendar",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Class ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Class not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unknown term, no current term or invalid format",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/execs": {
+            "get": {
+                "description": "Get a list of execs with optional filtering and sorting.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "execs"
+                ],
+                "summary": "Retrieve all execs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by first name (optional)",
+                        "name": "first_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by last name (optional)",
+                        "name": "last_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by email (optional)",
+                        "name": "email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by role (optional)",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sorting (e.g., first_name:asc, role:desc) (optional)",
+                        "name": "sortby",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include soft-deleted records, admins only (optional)",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response, answered with 304 if unchanged",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified from a previous response",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of execs with metadata",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Hash of the response body"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Latest updated_at of the listed records"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "403": {
+                        "description": "include_deleted requires the admin role",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add one or more execs",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "execs"
+                ],
+                "summary": "Add new execs",
+                "parameters": [
+                    {
+                        "description": "List of execs",
+                        "name": "execs",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Exec"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for this request, retries with the same key get the first response replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "enum": [
+                            "atomic",
+                            "partial"
+                        ],
+                        "type": "string",
+                        "description": "atomic (default) applies all items or none, partial applies each item on its own",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "207": {
+                        "description": "Result of each item, in partial mode",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Duplicate email or username, or a request with this Idempotency-Key still in progress",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Apply partial updates to multiple execs, each item must include the version it was based on",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "execs"
+                ],
+                "summary": "Partially update multiple execs",
+                "parameters": [
+                    {
+                        "description": "List of updates with exec IDs",
+                        "name": "updates",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "object",
+                                "additionalProperties": true
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for this request, retries with the same key get the first response replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "enum": [
+                            "atomic",
+                            "partial"
+                        ],
+                        "type": "string",
+                        "description": "atomic (default) applies all items or none, partial applies each item on its own",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "207": {
+                        "description": "Result of each item, in partial mode",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Exec not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Duplicate email or username, or a request with this Idempotency-Key still in progress",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Exec was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/execs/forgotpassword": {
+            "post": {
+                "description": "Sends a password reset link to the exec's email.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Request password reset",
+                "parameters": [
+                    {
+                        "description": "Exec email",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "email": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password reset link sent",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or user not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Duplicate email or username",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/execs/login": {
+            "post": {
+                "description": "Authenticates an exec user using username and password and returns a JWT token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "User Login",
+                "parameters": [
+                    {
+                        "description": "Login Credentials (username and password required)",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Exec"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "JWT token in response body and also set as HttpOnly cookie",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or missing username/password",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Account inactive or password incorrect",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Duplicate email or username",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not create login token",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/execs/logout": {
+            "post": {
+                "description": "Logs out the currently authenticated user by clearing the JWT cookie.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Log out a user",
+                "responses": {
+                    "200": {
+                        "description": "Logged out successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/execs/resetpassword/reset/{resetcode}": {
+            "post": {
+                "description": "Resets the exec's password using a reset token sent via email.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Reset password using reset token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Password reset token",
+                        "name": "resetcode",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New password request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "confirm_password": {
+                                    "type": "string"
+                                },
+                                "new_password": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password reset successfully",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or password mismatch",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid or expired reset code",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/execs/{id}": {
+            "get": {
+                "description": "Retrieve details of an exec by ID",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "execs"
+                ],
+                "summary": "Get one exec",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Exec ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include soft-deleted records, admins only (optional)",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response, answered with 304 if unchanged",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified from a previous response",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Exec"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the exec, send it back in If-Match"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "When the record was last updated"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "400": {
+                        "description": "Invalid Exec ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "include_deleted requires the admin role",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Exec not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Soft-delete an exec by ID, it can be restored until it is purged",
+                "produces": [
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "execs"
+                ],
+                "summary": "Delete one exec",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Exec ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the exec from GET /execs/{id}, or *",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid Exec ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Exec not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Exec was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Missing If-Match header",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Apply partial updates to a single exec by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "execs"
+                ],
+                "summary": "Partially update one exec",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Exec ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the exec from GET /execs/{id}, or *",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Partial updates",
+                        "name": "updates",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Exec"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the exec, send it back in If-Match"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload or ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Exec not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Duplicate email or username",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Exec was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Missing If-Match header",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/execs/{id}/restore": {
+            "post": {
+                "description": "Undo the soft delete of a exec, admins only",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "execs"
+                ],
+                "summary": "Restore a deleted exec",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Exec ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Exec"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the exec, send it back in If-Match"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Exec ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may restore records",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Exec not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Exec is not deleted",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/execs/{id}/updatepassword": {
+            "post": {
+                "description": "Allows an exec to update their password after providing the current password. A new JWT token is generated upon success.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Update an exec's password",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Exec ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Password update request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdatePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password updated successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input or password update failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Exec not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Current password does not match",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/gradebands": {
+            "get": {
+                "description": "Get the letter grades of the school and the percentage each starts at, highest first",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "grades"
+                ],
+                "summary": "List the grade bands",
+                "responses": {
+                    "200": {
+                        "description": "Grade bands",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the letter grades of the school. An average gets the grade of the highest band it reaches, one band should start at 0. Admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "grades"
+                ],
+                "summary": "Replace the grade bands",
+                "parameters": [
+                    {
+                        "description": "Grade bands",
+                        "name": "bands",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.GradeBand"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Grade bands",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may change grade bands",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/guardians": {
+            "get": {
+                "description": "Get a list of guardians with optional filtering and sorting.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "guardians"
+                ],
+                "summary": "Retrieve all guardians",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by first name (optional)",
+                        "name": "first_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by last name (optional)",
+                        "name": "last_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by relationship to their students (optional)",
+                        "name": "relationship",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by phone number (optional)",
+                        "name": "phone",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by email (optional)",
+                        "name": "email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sorting (e.g., last_name:asc, first_name:asc) (optional)",
+                        "name": "sortby",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number, starting at 1 (optional)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, defaults to 10 (optional)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response, answered with 304 if unchanged",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified from a previous response",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of guardians with metadata",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Hash of the response body"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Latest updated_at of the listed records"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add one or more guardians, link them to their students with PUT /students/{id}/guardians/{guardianId}. Admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "guardians"
+                ],
+                "summary": "Add new guardians",
+                "parameters": [
+                    {
+                        "description": "List of guardians",
+                        "name": "guardians",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Guardian"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for this request, retries with the same key get the first response replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may change guardians",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/guardians/{id}": {
+            "get": {
+                "description": "Retrieve details of a guardian by ID",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "guardians"
+                ],
+                "summary": "Get one guardian",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Guardian ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response, answered with 304 if unchanged",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified from a previous response",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Guardian"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the guardian, send it back in If-Match"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "When the record was last updated"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "400": {
+                        "description": "Invalid Guardian ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Guardian not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace a guardian by ID. Admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "guardians"
+                ],
+                "summary": "Update a guardian",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Guardian ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the guardian from GET /guardians/{id}, or *",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated guardian",
+                        "name": "guardian",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Guardian"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Guardian"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the guardian, send it back in If-Match"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload or ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may change guardians",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Guardian not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Guardian was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Missing If-Match header",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a guardian by ID for good, unlinking them from their students. Admins only.",
+                "produces": [
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "guardians"
+                ],
+                "summary": "Delete a guardian",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Guardian ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the guardian from GET /guardians/{id}, or *",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid Guardian ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may change guardians",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Guardian not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Guardian was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Missing If-Match header",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update some fields of a guardian by ID, an empty email or address removes it. Admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "guardians"
+                ],
+                "summary": "Partially update a guardian",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Guardian ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the guardian from GET /guardians/{id}, or *",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "updates",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Guardian"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the guardian, send it back in If-Match"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload or ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may change guardians",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Guardian not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Guardian was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Missing If-Match header",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/guardians/{id}/students": {
+            "get": {
+                "description": "List the students a guardian is responsible for, by last name, with whether the guardian is their primary contact and may pick them up",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "guardians"
+                ],
+                "summary": "Get the students of a guardian",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Guardian ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Students of the guardian",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Guardian ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Guardian not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/imports/{id}": {
+            "get": {
+                "description": "Get the status of an import and, once it has finished, what was done with every row. Admins only.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "imports"
+                ],
+                "summary": "Get an import job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Import job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportJob"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid import job ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may read imports",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Import job not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/reportcards/jobs/{id}": {
+            "get": {
+                "description": "Get the status of a report card job and, once it has finished, the outcome for every student. Admins only.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "reportcards"
+                ],
+                "summary": "Get a report card job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Report card job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReportCardJob"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid report card job ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may read report card jobs",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Report card job not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/reportcards/{id}": {
+            "get": {
+                "description": "Get a report card stored by a report card job, as it was when it was generated. It is the stored PDF with ?format=pdf or Accept: application/pdf.",
+                "produces": [
+                    "application/json",
+                    "application/pdf",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "reportcards"
+                ],
+                "summary": "Get a stored report card",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Report card ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json or pdf, overrides the Accept header (optional)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReportCard"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid report card ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Report card not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid format",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/students": {
+            "get": {
+                "description": "Get a list of students with optional filtering and sorting. Address and medical notes are only shown to admins and managers.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json",
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "application/pdf"
+                ],
+                "tags": [
+                    "students"
+                ],
+                "summary": "Retrieve all students",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by first name (optional)",
+                        "name": "first_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by last name (optional)",
+                        "name": "last_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by email (optional)",
+                        "name": "email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by class (optional)",
+                        "name": "class",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by student number (optional)",
+                        "name": "student_number",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "female",
+                            "male",
+                            "other"
+                        ],
+                        "type": "string",
+                        "description": "Filter by gender (optional)",
+                        "name": "gender",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by date of birth, YYYY-MM-DD (optional)",
+                        "name": "date_of_birth",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only students enrolled during this term, its ID or current (optional)",
+                        "name": "term",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sorting (e.g., first_name:asc, class:desc) (optional)",
+                        "name": "sortby",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number, starting at 1 (optional)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, defaults to 10 (optional)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include soft-deleted records, admins only (optional)",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xlsx",
+                            "pdf"
+                        ],
+                        "type": "string",
+                        "description": "Export the listing as a file instead of JSON, also chosen with the Accept header (optional)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response, answered with 304 if unchanged",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified from a previous response",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of students with metadata, or the exported file",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Hash of the response body"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Latest updated_at of the listed records"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "403": {
+                        "description": "include_deleted requires the admin role",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid format or unknown term",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Insert the students that don't exist yet and update the ones that do, matching them by email or by the columns in key. The batch is all or nothing.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "students"
+                ],
+                "summary": "Insert or update students",
+                "parameters": [
+                    {
+                        "description": "List of students",
+                        "name": "students",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Student"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated columns that identify a student, defaults to email (optional)",
+                        "name": "key",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for this request, retries with the same key get the first response replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Created, updated and unchanged counts with the stored students",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins and managers may change the address and medical notes",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Duplicate email, or a request with this Idempotency-Key still in progress",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add one or more students, enrolled today unless enrollment_date is given. Only admins and managers may set the address and medical notes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "students"
+                ],
+                "summary": "Add new students",
+                "parameters": [
+                    {
+                        "description": "List of students",
+                        "name": "students",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Student"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for this request, retries with the same key get the first response replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "enum": [
+                            "atomic",
+                            "partial"
+                        ],
+                        "type": "string",
+                        "description": "atomic (default) applies all items or none, partial applies each item on its own",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "207": {
+                        "description": "Result of each item, in partial mode",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins and managers may change the address and medical notes",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Duplicate email, or a request with this Idempotency-Key still in progress",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Soft-delete multiple students by their IDs, they can be restored until they are purged",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "students"
+                ],
+                "summary": "Delete multiple students",
+                "parameters": [
+                    {
+                        "description": "List of student IDs",
+                        "name": "ids",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for this request, retries with the same key get the first response replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "enum": [
+                            "atomic",
+                            "partial"
+                        ],
+                        "type": "string",
+                        "description": "atomic (default) applies all items or none, partial applies each item on its own",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "207": {
+                        "description": "Result of each item, in partial mode",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Student not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "A request with this Idempotency-Key is still in progress",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key was already used for a different request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Apply partial updates to multiple students, each item must include the version it was based on",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "students"
+                ],
+                "summary": "Partially update multiple students",
+                "parameters": [
+                    {
+                        "description": "List of updates",
+                        "name": "updates",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "object",
+                                "additionalProperties": true
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for this request, retries with the same key get the first response replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "enum": [
+                            "atomic",
+                            "partial"
+                        ],
+                        "type": "string",
+                        "description": "atomic (default) applies all items or none, partial applies each item on its own",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "207": {
+                        "description": "Result of each item, in partial mode",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins and managers may change the address and medical notes",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Student not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Duplicate email, or a request with this Idempotency-Key still in progress",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Student was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/students/import": {
+            "post": {
+                "description": "Insert or update the students in a CSV or .xlsx file, matched by email or by the columns in key. The first row names the columns, headers like \"First Name\" match first_name and others can be mapped with columns. Every row is validated first and the file is imported all or nothing. Files of more than 500 rows are imported in the background, poll the returned job for its status. Admins only.",
+                "consumes": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "students"
+                ],
+                "summary": "Import students from a CSV or Excel file",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV or .xlsx file, when sent as a multipart form",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated Header:column pairs, e.g. Given Name:first_name,Notes:- to skip a header (optional)",
+                        "name": "columns",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated columns that identify a student, defaults to email (optional)",
+                        "name": "key",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Report what the import would change without changing anything (optional)",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for this request, retries with the same key get the first response replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The finished import",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportJob"
+                        }
+                    },
+                    "202": {
+                        "description": "The import is running in the background",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportJob"
+                        }
+                    },
+                    "400": {
+                        "description": "Unreadable file",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may import",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Duplicate email, or a request with this Idempotency-Key still in progress",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Not a CSV or Excel file",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid header or rows, with the row of each error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/students/{id}": {
+            "get": {
+                "description": "Retrieve details of a student by ID. Address and medical notes are only shown to admins and managers.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "students"
+                ],
+                "summary": "Get one student",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Student ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include soft-deleted records, admins only (optional)",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Show the student as it was at this RFC 3339 time or date, e.g. 2026-01-01 (optional)",
+                        "name": "as_of",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response, answered with 304 if unchanged",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified from a previous response",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Student"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the student, send it back in If-Match"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "When the record was last updated"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "400": {
+                        "description": "Invalid Student ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "include_deleted requires the admin role",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Student not found, or did not exist at as_of",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid as_of",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update an existing student by ID. Other roles than admins and managers leave out the address and medical notes, which are kept.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "students"
+                ],
+                "summary": "Update a student",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Student ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the student from GET /students/{id}, or *",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated student",
+                        "name": "student",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Student"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Student"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the student, send it back in If-Match"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload or ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins and managers may change the address and medical notes",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Student not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Duplicate email",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Student was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Missing If-Match header",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Soft-delete a student by ID, it can be restored until it is purged",
+                "produces": [
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "students"
+                ],
+                "summary": "Delete one student",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Student ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the student from GET /students/{id}, or *",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid Student ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Student not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Student was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Missing If-Match header",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Apply partial updates to a single student by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "students"
+                ],
+                "summary": "Partially update one student",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Student ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the student from GET /students/{id}, or *",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Partial updates",
+                        "name": "updates",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Student"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the student, send it back in If-Match"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload or ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins and managers may change the address and medical notes",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Student not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Duplicate email",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Student was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Missing If-Match header",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/students/{id}/attendance": {
+            "get": {
+                "description": "List the attendance records of a student, newest first, with counts by status and the attendance rate",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "attendance"
+                ],
+                "summary": "Attendance of a student",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Student ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day, e.g. 2026-01-01 (optional)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day (optional)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only the records of this period, 0 is the whole day (optional)",
+                        "name": "period",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Records of the student and their summary",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Student ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Student not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid from, to or period",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/students/{id}/comments": {
+            "get": {
+                "description": "List what teachers wrote about a student for their report cards, by term and subject",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "reportcards"
+                ],
+                "summary": "List the comments on a student",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Student ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only the comments of this term (optional)",
+                        "name": "term",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Comments on the student",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Student ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Student not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Write the comments of teachers on a student for their report card, replacing the comment of the same term and subject. A subject is commented on by a teacher assigned to it in the student's class for the term, the general comment without a subject by the homeroom teacher. The batch is all or nothing.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "reportcards"
+                ],
+                "summary": "Comment on a student",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Student ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Comments by term and subject",
+                        "name": "comments",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ReportComment"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for this request, retries with the same key get the first response replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Created, updated and unchanged counts with the comments",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload or ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Student not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed, e.g. a teacher who doesn't teach the subject",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/students/{id}/enrollments": {
+            "get": {
+                "description": "List the classes a student has been in with when they joined and left them, latest first",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "students"
+                ],
+                "summary": "Class history of a student",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Student ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Enrollments of the student",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Student ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Student not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/students/{id}/grades": {
+            "get": {
+                "description": "Get the marks of a student with their weighted averages by subject and overall, and the grades of those",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "grades"
+                ],
+                "summary": "Grades of a student",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Student ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only this subject (optional)",
+                        "name": "subject",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only this term (optional)",
+                        "name": "term",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StudentGrades"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Student ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Student not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/students/{id}/guardians": {
+            "get": {
+                "description": "List the guardians of a student with their contact details, the primary contact first",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "students"
+                ],
+                "summary": "Get the guardians of a student",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Student ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Guardians of the student",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Student ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Student not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/students/{id}/guardians/{guardianId}": {
+            "put": {
+                "description": "Make a guardian a guardian of a student, or change whether they are the student's primary contact and may pick them up. A new primary contact replaces the previous one. Admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "students"
+                ],
+                "summary": "Link a guardian to a student",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Student ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Guardian ID",
+                        "name": "guardianId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "How the guardian stands to the student",
+                        "name": "link",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.GuardianLink"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Link changed",
+                        "schema": {
+                            "$ref": "#/definitions/models.StudentGuardian"
+                        }
+                    },
+                    "201": {
+                        "description": "Guardian linked",
+                        "schema": {
+                            "$ref": "#/definitions/models.StudentGuardian"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload or ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may change guardians",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Student or guardian not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a guardian from the guardians of a student, the guardian is kept. Admins only.",
+                "produces": [
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "students"
+                ],
+                "summary": "Unlink a guardian from a student",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Student ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Guardian ID",
+                        "name": "guardianId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid Student or Guardian ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may change guardians",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Guardian is not a guardian of the student",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/students/{id}/history": {
+            "get": {
+                "description": "List every recorded revision of a student, newest first",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "students"
+                ],
+                "summary": "Get the history of a student",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Student ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Revisions of the student",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Student ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "No history found for student",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/students/{id}/reportcard": {
+            "get": {
+                "description": "Put together the report card of a student for a term: their averages and grades by subject with who taught it, their whole-day attendance in their class during the term and the comments of their teachers. It is rendered as a PDF with ?format=pdf or Accept: application/pdf.",
+                "produces": [
+                    "application/json",
+                    "application/pdf",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "reportcards"
+                ],
+                "summary": "Get the report card of a student",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Student ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Term, e.g. Term 1, defaults to the current term (optional)",
+                        "name": "term",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json or pdf, overrides the Accept header (optional)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReportCard"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Student ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Student not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Missing or unknown term or invalid format",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/students/{id}/restore": {
+            "post": {
+                "description": "Undo the soft delete of a student, admins only",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "students"
+                ],
+                "summary": "Restore a deleted student",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Student ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Student"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the student, send it back in If-Match"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Student ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may restore records",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Student not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Student is not deleted",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/teachers": {
+            "get": {
+                "description": "Get a list of teachers with optional filtering and sorting.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json",
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "application/pdf"
+                ],
+                "tags": [
+                    "teachers"
+                ],
+                "summary": "Retrieve all teachers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by first name (optional)",
+                        "name": "first_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by last name (optional)",
+                        "name": "last_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by email (optional)",
+                        "name": "email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by class (optional)",
+                        "name": "class",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by subject (optional)",
+                        "name": "subject",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only teachers assigned to a class in this term, its ID or current (optional)",
+                        "name": "term",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sorting (e.g., first_name:asc, class:desc) (optional)",
+                        "name": "sortby",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include soft-deleted records, admins only (optional)",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xlsx",
+                            "pdf"
+                        ],
+                        "type": "string",
+                        "description": "Export the listing as a file instead of JSON, also chosen with the Accept header (optional)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response, answered with 304 if unchanged",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified from a previous response",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of teachers with metadata, or the exported file",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Hash of the response body"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Latest updated_at of the listed records"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "403": {
+                        "description": "include_deleted requires the admin role",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid format or unknown term",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Insert the teachers that don't exist yet and update the ones that do, matching them by email or by the columns in key. The batch is all or nothing.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "teachers"
+                ],
+                "summary": "Insert or update teachers",
+                "parameters": [
+                    {
+                        "description": "List of teachers",
+                        "name": "teachers",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Teacher"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated columns that identify a teacher, defaults to email (optional)",
+                        "name": "key",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for this request, retries with the same key get the first response replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Created, updated and unchanged counts with the stored teachers",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Duplicate email, or a request with this Idempotency-Key still in progress",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add one or more teachers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "teachers"
+                ],
+                "summary": "Add new teachers",
+                "parameters": [
+                    {
+                        "description": "List of teachers",
+                        "name": "teachers",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Teacher"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for this request, retries with the same key get the first response replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "enum": [
+                            "atomic",
+                            "partial"
+                        ],
+                        "type": "string",
+                        "description": "atomic (default) applies all items or none, partial applies each item on its own",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "207": {
+                        "description": "Result of each item, in partial mode",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Duplicate email, or a request with this Idempotency-Key still in progress",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Soft-delete multiple teachers by their IDs, they can be restored until they are purged",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "teachers"
+                ],
+                "summary": "Delete multiple teachers",
+                "parameters": [
+                    {
+                        "description": "List of teacher IDs",
+                        "name": "ids",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for this request, retries with the same key get the first response replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "enum": [
+                            "atomic",
+                            "partial"
+                        ],
+                        "type": "string",
+                        "description": "atomic (default) applies all items or none, partial applies each item on its own",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "207": {
+                        "description": "Result of each item, in partial mode",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Teacher not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "A request with this Idempotency-Key is still in progress",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key was already used for a different request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Apply partial updates to multiple teachers, each item must include the version it was based on",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "teachers"
+                ],
+                "summary": "Partially update multiple teachers",
+                "parameters": [
+                    {
+                        "description": "List of updates",
+                        "name": "updates",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "object",
+                                "additionalProperties": true
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for this request, retries with the same key get the first response replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "enum": [
+                            "atomic",
+                            "partial"
+                        ],
+                        "type": "string",
+                        "description": "atomic (default) applies all items or none, partial applies each item on its own",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "207": {
+                        "description": "Result of each item, in partial mode",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Teacher not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Duplicate email, or a request with this Idempotency-Key still in progress",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Teacher was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/teachers/import": {
+            "post": {
+                "description": "Insert or update the teachers in a CSV or .xlsx file, matched by email or by the columns in key. The first row names the columns, headers like \"First Name\" match first_name and others can be mapped with columns. Every row is validated first and the file is imported all or nothing. Files of more than 500 rows are imported in the background, poll the returned job for its status. Admins only.",
+                "consumes": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "teachers"
+                ],
+                "summary": "Import teachers from a CSV or Excel file",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV or .xlsx file, when sent as a multipart form",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated Header:column pairs, e.g. Given Name:first_name,Notes:- to skip a header (optional)",
+                        "name": "columns",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated columns that identify a teacher, defaults to email (optional)",
+                        "name": "key",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Report what the import would change without changing anything (optional)",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for this request, retries with the same key get the first response replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The finished import",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportJob"
+                        }
+                    },
+                    "202": {
+                        "description": "The import is running in the background",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportJob"
+                        }
+                    },
+                    "400": {
+                        "description": "Unreadable file",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may import",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Duplicate email, or a request with this Idempotency-Key still in progress",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Not a CSV or Excel file",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid header or rows, with the row of each error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/teachers/{id}": {
+            "get": {
+                "description": "Retrieve details of a teacher by ID",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "teachers"
+                ],
+                "summary": "Get one teacher",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Teacher ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include soft-deleted records, admins only (optional)",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Show the teacher as it was at this RFC 3339 time or date, e.g. 2026-01-01 (optional)",
+                        "name": "as_of",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response, answered with 304 if unchanged",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified from a previous response",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Teacher"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the teacher, send it back in If-Match"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "When the record was last updated"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "400": {
+                        "description": "Invalid Teacher ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "include_deleted requires the admin role",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Teacher not found, or did not exist at as_of",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid as_of",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update an existing teacher by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "teachers"
+                ],
+                "summary": "Update a teacher",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Teacher ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the teacher from GET /teachers/{id}, or *",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated teacher",
+                        "name": "teacher",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Teacher"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Teacher"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the teacher, send it back in If-Match"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload or ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Teacher not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Duplicate email",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Teacher was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Missing If-Match header",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Soft-delete a teacher by ID, it can be restored until it is purged",
+                "produces": [
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "teachers"
+                ],
+                "summary": "Delete one teacher",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Teacher ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the teacher from GET /teachers/{id}, or *",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid Teacher ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Teacher not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Teacher was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Missing If-Match header",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Apply partial updates to a single teacher by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "teachers"
+                ],
+                "summary": "Partially update one teacher",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Teacher ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the teacher from GET /teachers/{id}, or *",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Partial updates",
+                        "name": "updates",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Teacher"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the teacher, send it back in If-Match"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload or ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Teacher not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Duplicate email",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Teacher was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Missing If-Match header",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/teachers/{id}/assignments": {
+            "get": {
+                "description": "Get the classes a teacher teaches and what, by term",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "teachers"
+                ],
+                "summary": "List the assignments of a teacher",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Teacher ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by subject (optional)",
+                        "name": "subject",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by term (optional)",
+                        "name": "term",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Assignments of the teacher",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Teacher ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Teacher not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Assign a teacher to teach subjects to classes in a term. The batch is all or nothing. Admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "teachers"
+                ],
+                "summary": "Assign a teacher to classes",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Teacher ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Classes, subjects and terms",
+                        "name": "assignments",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TeachingAssignment"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for this request, retries with the same key get the first response replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload or ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may assign teachers",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Teacher not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The teacher already has this assignment",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/teachers/{id}/assignments/{assignmentId}": {
+            "delete": {
+                "description": "Remove an assignment of a teacher to a class. Admins only.",
+                "produces": [
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "teachers"
+                ],
+                "summary": "Unassign a teacher",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Teacher ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Assignment ID",
+                        "name": "assignmentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid Teacher or Assignment ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may unassign teachers",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Teaching assignment not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The timetable still has lessons of the assignment",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/teachers/{id}/history": {
+            "get": {
+                "description": "List every recorded revision of a teacher, newest first",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "teachers"
+                ],
+                "summary": "Get the history of a teacher",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Teacher ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Revisions of the teacher",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Teacher ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "No history found for teacher",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/teachers/{id}/restore": {
+            "post": {
+                "description": "Undo the soft delete of a teacher, admins only",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "teachers"
+                ],
+                "summary": "Restore a deleted teacher",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Teacher ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Teacher"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the teacher, send it back in If-Match"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Teacher ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may restore records",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Teacher not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Teacher is not deleted",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/teachers/{id}/studentcount": {
+            "get": {
+                "description": "Get the number of students in the classes a teacher is assigned to.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "teachers"
+                ],
+                "summary": "Retrieve student count by teacher ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Teacher ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only the classes the teacher teaches this subject to (optional)",
+                        "name": "subject",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Student count",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Teacher ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/teachers/{id}/students": {
+            "get": {
+                "description": "Get the students of every class a teacher is assigned to.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json",
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "application/pdf"
+                ],
+                "tags": [
+                    "teachers"
+                ],
+                "summary": "Retrieve students by teacher ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Teacher ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only the classes the teacher teaches this subject to (optional)",
+                        "name": "subject",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xlsx",
+                            "pdf"
+                        ],
+                        "type": "string",
+                        "description": "Export the listing as a file instead of JSON, also chosen with the Accept header (optional)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sorting of exports (e.g., first_name:asc) (optional)",
+                        "name": "sortby",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response, answered with 304 if unchanged",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified from a previous response",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of students with metadata, or the exported file",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Hash of the response body"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Latest updated_at of the listed records"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "400": {
+                        "description": "Invalid Teacher ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid format",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/teachers/{id}/timetable": {
+            "get": {
+                "description": "Get the lessons a teacher gives in a term by weekday and period. With ?format=ics or Accept: text/calendar it is an iCalendar file with a weekly event per lesson until the end of the term.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json",
+                    "text/calendar"
+                ],
+                "tags": [
+                    "timetable"
+                ],
+                "summary": "Weekly timetable of a teacher",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Teacher ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of a term or current, defaults to current (optional)",
+                        "name": "term",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json or ics, overrides the Accept header (optional)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The term and the lessons of the teacher, or the calendar",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Teacher ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Teacher not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unknown term, no current term or invalid format",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/terms": {
+            "get": {
+                "description": "Get the terms of the school in the order they take place, optionally only those of an academic year",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "terms"
+                ],
+                "summary": "List the terms",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only the terms of this academic year, e.g. 2025-2026 (optional)",
+                        "name": "academic_year",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Terms",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add one or more terms to academic years. A term lies within its year and doesn't overlap the other terms of the year, its name is unique in the year. Admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "terms"
+                ],
+                "summary": "Add terms",
+                "parameters": [
+                    {
+                        "description": "List of terms",
+                        "name": "terms",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Term"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may change terms",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "A term with this name already exists in the academic year",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/terms/current": {
+            "get": {
+                "description": "Get the term the school is in: the one set under PUT /terms/current or else the one today falls in",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "terms"
+                ],
+                "summary": "Get the current term",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Term"
+                        }
+                    },
+                    "404": {
+                        "description": "There is no current term",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Make a term the current one whatever the date, e.g. to prepare the next term early. Admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "terms"
+                ],
+                "summary": "Set the current term",
+                "parameters": [
+                    {
+                        "description": "The current term",
+                        "name": "current",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CurrentTerm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Term"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may set the current term",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unknown term",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/terms/{id}": {
+            "get": {
+                "description": "Get a term by ID",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "terms"
+                ],
+                "summary": "Get a term",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Term ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Term"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Term ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Term not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Move the dates of a term within its academic year. Its name and year can't be changed. Admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "terms"
+                ],
+                "summary": "Update a term",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Term ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated term",
+                        "name": "term",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Term"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Term"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload or ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may change terms",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Term not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a term no teaching assignment or assessment refers to. Admins only.",
+                "produces": [
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "terms"
+                ],
+                "summary": "Delete a term",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Term ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid Term ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may delete terms",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Term not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The term is in use",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/timetable": {
+            "get": {
+                "description": "Get the lessons of the timetable of a term by weekday and period, optionally only those of a class, teacher or room",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "timetable"
+                ],
+                "summary": "List the timetable",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of a term or current, defaults to current (optional)",
+                        "name": "term",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only the lessons of this class (optional)",
+                        "name": "class_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only the lessons of this teacher (optional)",
+                        "name": "teacher_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only the lessons in this room (optional)",
+                        "name": "room_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The term and its lessons",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid class, teacher or room ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unknown term or no current term",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add weekly lessons of a term. The teacher must be assigned to teach the subject to the class in the term, which must be one of the academic year of the class. A teacher, class or room can't have two lessons at the same period of a weekday. The batch is all or nothing. Admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "timetable"
+                ],
+                "summary": "Add lessons to the timetable",
+                "parameters": [
+                    {
+                        "description": "List of lessons",
+                        "name": "slots",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Slot"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may change the timetable",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "A teacher, class or room is already booked at that time",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/timetable/periods": {
+            "get": {
+                "description": "Get the periods of the school day with their times, the same on every weekday",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "timetable"
+                ],
+                "summary": "List the periods",
+                "responses": {
+                    "200": {
+                        "description": "Periods",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the periods of the school day. Periods have distinct numbers and don't overlap, a period the timetable uses can't be left out. Admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "timetable"
+                ],
+                "summary": "Replace the periods",
+                "parameters": [
+                    {
+                        "description": "Periods",
+                        "name": "periods",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Period"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Periods",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may change periods",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "A period left out is used by the timetable",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/timetable/rooms": {
+            "get": {
+                "description": "Get the rooms lessons take place in, by name",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "timetable"
+                ],
+                "summary": "List the rooms",
+                "responses": {
+                    "200": {
+                        "description": "Rooms",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add one or more rooms, their names are unique. Admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "timetable"
+                ],
+                "summary": "Add rooms",
+                "parameters": [
+                    {
+                        "description": "List of rooms",
+                        "name": "rooms",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Room"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may change rooms",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "A room with this name already exists",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/timetable/rooms/{id}": {
+            "delete": {
+                "description": "Delete a room no lesson of the timetable takes place in. Admins only.",
+                "produces": [
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "timetable"
+                ],
+                "summary": "Delete a room",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid Room ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may change rooms",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The room is used by the timetable",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/timetable/{id}": {
+            "get": {
+                "description": "Get a slot of the timetable by ID",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "timetable"
+                ],
+                "summary": "Get a lesson of the timetable",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Slot ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Slot"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Slot ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Slot not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace a slot of the timetable, with the same checks as adding one. Admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "timetable"
+                ],
+                "summary": "Move a lesson of the timetable",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Slot ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated lesson",
+                        "name": "slot",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Slot"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Slot"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload or ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may change the timetable",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Slot not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "A teacher, class or room is already booked at that time",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a slot of the timetable. Admins only.",
+                "produces": [
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "timetable"
+                ],
+                "summary": "Remove a lesson from the timetable",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Slot ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid Slot ID",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins may change the timetable",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Slot not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.AcademicYear": {
+            "type": "object",
+            "required": [
+                "end_date",
+                "name",
+                "start_date"
+            ],
+            "properties": {
+                "end_date": {
+                    "type": "string",
+                    "format": "date"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "type": "string",
+                    "format": "date"
+                },
+                "terms": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Term"
+                    },
+                    "readOnly": true
+                }
+            }
+        },
+        "models.Assessment": {
+            "type": "object",
+            "required": [
+                "date",
+                "max_score",
+                "name",
+                "subject",
+                "term"
+            ],
+            "properties": {
+                "class_id": {
+                    "type": "integer",
+                    "readOnly": true
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "readOnly": true
+                },
+                "date": {
+                    "type": "string",
+                    "format": "date"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "marks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Mark"
+                    },
+                    "readOnly": true
+                },
+                "max_score": {
+                    "type": "number",
+                    "maximum": 10000,
+                    "minimum": 0.01
+                },
+                "name": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "term": {
+                    "type": "string"
+                },
+                "weight": {
+                    "type": "number",
+                    "maximum": 100,
+                    "minimum": 0
+                }
+            }
+        },
+        "models.Attendance": {
+            "type": "object",
+            "required": [
+                "status",
+                "student_id"
+            ],
+            "properties": {
+                "class_id": {
+                    "type": "integer",
+                    "readOnly": true
+                },
+                "date": {
+                    "type": "string",
+                    "format": "date",
+                    "readOnly": true
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "period": {
+                    "type": "integer",
+                    "readOnly": true
+                },
+                "recorded_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "readOnly": true
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "present",
+                        "absent",
+                        "late",
+                        "excused"
+                    ]
+                },
+                "student_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "teacher_id": {
+                    "type": "integer",
+                    "readOnly": true
+                }
+            }
+        },
+        "models.AttendanceSheet": {
+            "type": "object",
+            "required": [
+                "date",
+                "teacher_id"
+            ],
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "format": "date"
+                },
+                "period": {
+                    "type": "integer",
+                    "maximum": 12,
+                    "minimum": 0
+                },
+                "records": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Attendance"
+                    }
+                },
+                "teacher_id": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "models.AttendanceSummary": {
+            "type": "object",
+            "properties": {
+                "absent": {
+                    "type": "integer"
+                },
+                "date": {
+                    "type": "string",
+                    "format": "date"
+                },
+                "excused": {
+                    "type": "integer"
+                },
+                "late": {
+                    "type": "integer"
+                },
+                "present": {
+                    "type": "integer"
+                },
+                "rate": {
+                    "type": "number"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Class": {
+            "type": "object",
+            "required": [
+                "academic_year",
+                "grade",
+                "section"
+            ],
+            "properties": {
+                "academic_year": {
+                    "type": "string"
+                },
+                "capacity": {
+                    "type": "integer",
+                    "maximum": 200,
+                    "minimum": 0
+                },
+                "grade": {
+                    "type": "integer",
+                    "maximum": 12,
+                    "minimum": 1
+                },
+                "homeroom_teacher_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "readOnly": true
+                },
+                "section": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "readOnly": true
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CurrentTerm": {
+            "type": "object",
+            "required": [
+                "term_id"
+            ],
+            "properties": {
+                "term_id": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "models.Exec": {
+            "type": "object",
+            "required": [
+                "email",
+                "first_name",
+                "last_name",
+                "password",
+                "role",
+                "username"
+            ],
+            "properties": {
+                "deleted_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "readOnly": true
+                },
+                "email": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "inactive_status": {
+                    "type": "boolean"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "password_changed_at": {
+                    "$ref": "#/definitions/models.NullString"
+                },
+                "password_reset_token": {
+                    "$ref": "#/definitions/models.NullString"
+                },
+                "password_token_expires": {
+                    "$ref": "#/definitions/models.NullString"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "readOnly": true
+                },
+                "user_created_at": {
+                    "$ref": "#/definitions/models.NullString"
+                },
+                "username": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GradeBand": {
+            "type": "object",
+            "required": [
+                "grade"
+            ],
+            "properties": {
+                "grade": {
+                    "type": "string"
+                },
+                "min_percent": {
+                    "type": "number",
+                    "maximum": 100,
+                    "minimum": 0
+                }
+            }
+        },
+        "models.GradedMark": {
+            "type": "object",
+            "properties": {
+                "assessment": {
+                    "type": "string"
+                },
+                "assessment_id": {
+                    "type": "integer"
+                },
+                "date": {
+                    "type": "string",
+                    "format": "date"
+                },
+                "max_score": {
+                    "type": "number"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "number"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "term": {
+                    "type": "string"
+                },
+                "weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.Guardian": {
+            "type": "object",
+            "required": [
+                "first_name",
+                "last_name",
+                "phone",
+                "relationship"
+            ],
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "relationship": {
+                    "type": "string",
+                    "enum": [
+                        "mother",
+                        "father",
+                        "parent",
+                        "grandparent",
+                        "sibling",
+                        "relative",
+                        "guardian",
+                        "other"
+                    ]
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "readOnly": true
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GuardianLink": {
+            "type": "object",
+            "properties": {
+                "can_pick_up": {
+                    "type": "boolean"
+                },
+                "primary_contact": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.HeldBack": {
+            "type": "object",
+            "required": [
+                "student_id"
+            ],
+            "properties": {
+                "class": {
+                    "type": "string"
+                },
+                "student_id": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "models.ImportJob": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "resource": {
+                    "type": "string"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportRow"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "running",
+                        "completed",
+                        "failed"
+                    ]
+                },
+                "total_rows": {
+                    "type": "integer"
+                },
+                "unchanged": {
+                    "type": "integer"
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ImportRow": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "created",
+                        "updated",
+                        "unchanged"
+                    ]
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "row": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Mark": {
+            "type": "object",
+            "required": [
+                "student_id"
+            ],
+            "properties": {
+                "note": {
+                    "type": "string"
+                },
+                "recorded_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "readOnly": true
+                },
+                "score": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "student_id": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "models.NullString": {
+            "type": "object",
+            "properties": {
+                "string": {
+                    "type": "string"
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.Period": {
+            "type": "object",
+            "required": [
+                "end_time",
+                "number",
+                "start_time"
+            ],
+            "properties": {
+                "end_time": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "integer",
+                    "maximum": 12,
+                    "minimum": 1
+                },
+                "start_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ReportCard": {
+            "type": "object",
             "properties": {
+                "academic_year": {
+                    "type": "string"
+                },
+                "attendance": {
+                    "$ref": "#/definitions/models.AttendanceSummary"
+                },
+                "average": {
+                    "type": "number"
+                },
+                "class": {
+                    "type": "string"
+                },
+                "class_id": {
+                    "type": "integer"
+                },
+                "comments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReportComment"
+                    }
+                },
                 "email": {
                     "type": "string"
                 },
-                "first_name": {
+                "first_name": {
+                    "type": "string"
+                },
+                "generated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "grade": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "student_id": {
+                    "type": "integer"
+                },
+                "subjects": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReportCardSubject"
+                    }
+                },
+                "term": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ReportCardJob": {
+            "type": "object",
+            "properties": {
+                "class_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "delivered": {
+                    "type": "integer"
+                },
+                "delivery": {
+                    "type": "string",
+                    "enum": [
+                        "email",
+                        "store"
+                    ]
+                },
+                "error": {
                     "type": "string"
                 },
+                "failed": {
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "inactive_status": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReportCardResult"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "running",
+                        "completed",
+                        "failed"
+                    ]
+                },
+                "term": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ReportCardResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "report_card_id": {
+                    "type": "integer"
+                },
+                "student_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ReportCardSubject": {
+            "type": "object",
+            "properties": {
+                "assessments": {
+                    "type": "integer"
+                },
+                "average": {
+                    "type": "number"
+                },
+                "grade": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "teachers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.ReportComment": {
+            "type": "object",
+            "required": [
+                "comment",
+                "teacher_id",
+                "term"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "teacher_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "teacher_name": {
+                    "type": "string",
+                    "readOnly": true
+                },
+                "term": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "readOnly": true
+                }
+            }
+        },
+        "models.Rollover": {
+            "type": "object",
+            "required": [
+                "to_year"
+            ],
+            "properties": {
+                "create_classes": {
                     "type": "boolean"
                 },
+                "held_back": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.HeldBack"
+                    }
+                },
+                "mapping": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "to_year": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RolloverMove": {
+            "type": "object",
+            "properties": {
+                "first_name": {
+                    "type": "string"
+                },
+                "from_class": {
+                    "type": "string"
+                },
                 "last_name": {
                     "type": "string"
                 },
-                "password": {
+                "outcome": {
+                    "type": "string",
+                    "enum": [
+                        "promoted",
+                        "held_back",
+                        "graduated"
+                    ]
+                },
+                "student_id": {
+                    "type": "integer"
+                },
+                "to_class": {
                     "type": "string"
+                }
+            }
+        },
+        "models.RolloverResult": {
+            "type": "object",
+            "properties": {
+                "created_classes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "password_changed_at": {
-                    "$ref": "#/definitions/models.NullString"
+                "dry_run": {
+                    "type": "boolean"
                 },
-                "password_reset_token": {
-                    "$ref": "#/definitions/models.NullString"
+                "from_year": {
+                    "type": "string"
                 },
-                "password_token_expires": {
-                    "$ref": "#/definitions/models.NullString"
+                "graduated": {
+                    "type": "integer"
                 },
-                "role": {
+                "held_back": {
+                    "type": "integer"
+                },
+                "promoted": {
+                    "type": "integer"
+                },
+                "students": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RolloverMove"
+                    }
+                },
+                "to_year": {
                     "type": "string"
+                }
+            }
+        },
+        "models.Room": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "capacity": {
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 0
                 },
-                "user_created_at": {
-                    "$ref": "#/definitions/models.NullString"
+                "id": {
+                    "type": "integer"
                 },
-                "username": {
+                "name": {
                     "type": "string"
                 }
             }
         },
-        "models.NullString": {
+        "models.Slot": {
             "type": "object",
+            "required": [
+                "class_id",
+                "period",
+                "subject",
+                "teacher_id",
+                "term_id",
+                "weekday"
+            ],
             "properties": {
-                "string": {
+                "class": {
+                    "type": "string",
+                    "readOnly": true
+                },
+                "class_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "end_time": {
+                    "type": "string",
+                    "readOnly": true
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "period": {
+                    "type": "integer",
+                    "maximum": 12,
+                    "minimum": 1
+                },
+                "room": {
+                    "type": "string",
+                    "readOnly": true
+                },
+                "room_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "start_time": {
+                    "type": "string",
+                    "readOnly": true
+                },
+                "subject": {
                     "type": "string"
                 },
-                "valid": {
-                    "type": "boolean"
+                "teacher_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "teacher_name": {
+                    "type": "string",
+                    "readOnly": true
+                },
+                "term": {
+                    "type": "string",
+                    "readOnly": true
+                },
+                "term_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "weekday": {
+                    "type": "integer",
+                    "maximum": 7,
+                    "minimum": 1,
+                    "enum": [
+                        1,
+                        2,
+                        3,
+                        4,
+                        5,
+                        6,
+                        7
+                    ]
                 }
             }
         },
         "models.Student": {
             "type": "object",
+            "required": [
+                "class",
+                "email",
+                "first_name",
+                "last_name"
+            ],
             "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "allergies": {
+                    "type": "string"
+                },
                 "class": {
                     "type": "string"
                 },
+                "class_id": {
+                    "type": "integer",
+                    "readOnly": true
+                },
+                "date_of_birth": {
+                    "type": "string",
+                    "format": "date"
+                },
+                "deleted_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "readOnly": true
+                },
                 "email": {
                     "type": "string"
                 },
+                "enrollment_date": {
+                    "type": "string",
+                    "format": "date"
+                },
                 "first_name": {
                     "type": "string"
                 },
+                "gender": {
+                    "type": "string",
+                    "enum": [
+                        "female",
+                        "male",
+                        "other"
+                    ]
+                },
                 "id": {
                     "type": "integer"
                 },
                 "last_name": {
                     "type": "string"
+                },
+                "medical_notes": {
+                    "type": "string"
+                },
+                "student_number": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "readOnly": true
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.StudentGrades": {
+            "type": "object",
+            "properties": {
+                "average": {
+                    "type": "number"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "grade": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "student_id": {
+                    "type": "integer"
+                },
+                "subjects": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SubjectGrade"
+                    }
+                }
+            }
+        },
+        "models.StudentGuardian": {
+            "type": "object",
+            "properties": {
+                "can_pick_up": {
+                    "type": "boolean"
+                },
+                "guardian_id": {
+                    "type": "integer"
+                },
+                "primary_contact": {
+                    "type": "boolean"
+                },
+                "student_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SubjectGrade": {
+            "type": "object",
+            "properties": {
+                "average": {
+                    "type": "number"
+                },
+                "grade": {
+                    "type": "string"
+                },
+                "marks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GradedMark"
+                    }
+                },
+                "subject": {
+                    "type": "string"
                 }
             }
         },
         "models.Teacher": {
             "type": "object",
+            "required": [
+                "class",
+                "email",
+                "first_name",
+                "last_name",
+                "subject"
+            ],
             "properties": {
                 "class": {
                     "type": "string"
                 },
+                "deleted_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "readOnly": true
+                },
                 "email": {
                     "type": "string"
                 },
@@ -1489,6 +8588,87 @@ const docTemplate = `{
                 },
                 "subject": {
                     "type": "string"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "readOnly": true
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TeachingAssignment": {
+            "type": "object",
+            "required": [
+                "class_id",
+                "subject",
+                "term"
+            ],
+            "properties": {
+                "class_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "class_name": {
+                    "type": "string",
+                    "readOnly": true
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "readOnly": true
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "teacher_id": {
+                    "type": "integer",
+                    "readOnly": true
+                },
+                "term": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Term": {
+            "type": "object",
+            "required": [
+                "academic_year_id",
+                "end_date",
+                "name",
+                "start_date"
+            ],
+            "properties": {
+                "academic_year": {
+                    "type": "string",
+                    "readOnly": true
+                },
+                "academic_year_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "current": {
+                    "type": "boolean",
+                    "readOnly": true
+                },
+                "end_date": {
+                    "type": "string",
+                    "format": "date"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "type": "string",
+                    "format": "date"
                 }
             }
         },
@@ -1502,6 +8682,49 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "utils.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "row": {
+                    "type": "integer"
+                }
+            }
+        },
+        "utils.Problem": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/utils.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
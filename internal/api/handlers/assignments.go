@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/repository/sqlconnect"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+// GetTeachingAssignmentsHandler godoc
+// @Summary List the assignments of a teacher
+// @Description Get the classes a teacher teaches and what, by term
+// @Tags teachers
+// @Produce json,application/problem+json
+// @Param id path int true "Teacher ID"
+// @Param subject query string false "Filter by subject (optional)"
+// @Param term query string false "Filter by term (optional)"
+// @Success 200 {object} map[string]interface{} "Assignments of the teacher"
+// @Failure 400 {object} utils.Problem "Invalid Teacher ID"
+// @Failure 404 {object} utils.Problem "Teacher not found"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /teachers/{id}/assignments [get]
+func GetTeachingAssignmentsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Teacher ID")
+		return
+	}
+
+	assignments, err := sqlconnect.GetTeachingAssignmentsDBHandler(r.Context(), id, r.URL.Query().Get("subject"), r.URL.Query().Get("term"))
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status string                      `json:"status"`
+		Count  int                         `json:"count"`
+		Data   []models.TeachingAssignment `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(assignments),
+		Data:   assignments,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// AddTeachingAssignmentsHandler godoc
+// @Summary Assign a teacher to classes
+// @Description Assign a teacher to teach subjects to classes in a term. The batch is all or nothing. Admins only.
+// @Tags teachers
+// @Accept json
+// @Produce json,application/problem+json
+// @Param id path int true "Teacher ID"
+// @Param assignments body []models.TeachingAssignment true "Classes, subjects and terms"
+// @Param Idempotency-Key header string false "Unique key for this request, retries with the same key get the first response replayed"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} utils.Problem "Invalid request payload or ID"
+// @Failure 403 {object} utils.Problem "Only admins may assign teachers"
+// @Failure 404 {object} utils.Problem "Teacher not found"
+// @Failure 409 {object} utils.Problem "The teacher already has this assignment"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /teachers/{id}/assignments [post]
+func AddTeachingAssignmentsHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Teacher ID")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusInternalServerError, "Error reading request body.")
+		return
+	}
+
+	var rawAssignments []map[string]interface{}
+	err = json.Unmarshal(body, &rawAssignments)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	var newAssignments []models.TeachingAssignment
+	err = json.Unmarshal(body, &newAssignments)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	allowedFields := map[string]struct{}{"class_id": {}, "subject": {}, "term": {}}
+	var validationErrs []utils.FieldError
+	for i, assignment := range newAssignments {
+		err := unknownFieldsError(rawAssignments[i], allowedFields)
+		if err != nil {
+			utils.WriteError(w, r, err)
+			return
+		}
+		validationErrs = append(validationErrs, utils.WithIndex(utils.ValidateStruct(assignment), i)...)
+	}
+	if err := validationError(validationErrs); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	added, err := sqlconnect.AddTeachingAssignmentsDBHandler(r.Context(), id, newAssignments)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	response := struct {
+		Status string                      `json:"status"`
+		Count  int                         `json:"count"`
+		Data   []models.TeachingAssignment `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(added),
+		Data:   added,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// DeleteTeachingAssignmentHandler godoc
+// @Summary Unassign a teacher
+// @Description Remove an assignment of a teacher to a class. Admins only.
+// @Tags teachers
+// @Produce application/problem+json
+// @Param id path int true "Teacher ID"
+// @Param assignmentId path int true "Assignment ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} utils.Problem "Invalid Teacher or Assignment ID"
+// @Failure 403 {object} utils.Problem "Only admins may unassign teachers"
+// @Failure 404 {object} utils.Problem "Teaching assignment not found"
+// @Failure 409 {object} utils.Problem "The timetable still has lessons of the assignment"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /teachers/{id}/assignments/{assignmentId} [delete]
+func DeleteTeachingAssignmentHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Teacher ID")
+		return
+	}
+	assignmentID, err := strconv.Atoi(r.PathValue("assignmentId"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Assignment ID")
+		return
+	}
+
+	err = sqlconnect.DeleteTeachingAssignmentDBHandler(r.Context(), id, assignmentID)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/repository/sqlconnect"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+// SubmitAttendanceHandler godoc
+// @Summary Take the attendance of a class
+// @Description Record the attendance of the students of a class for a day, period 0, or one of its periods. Submitting again for the same day and period corrects the earlier records. The batch is all or nothing.
+// @Tags attendance
+// @Accept json
+// @Produce json,application/problem+json
+// @Param id path int true "Class ID"
+// @Param sheet body models.AttendanceSheet true "Day, period, teacher and a record per student"
+// @Param Idempotency-Key header string false "Unique key for this request, retries with the same key get the first response replayed"
+// @Success 200 {object} map[string]interface{} "Created, updated and unchanged counts with the records"
+// @Failure 400 {object} utils.Problem "Invalid request payload or ID"
+// @Failure 404 {object} utils.Problem "Class not found"
+// @Failure 422 {object} utils.Problem "Validation failed, e.g. a student of another class"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /classes/{id}/attendance [post]
+func SubmitAttendanceHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Class ID")
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var sheet models.AttendanceSheet
+	err = decoder.Decode(&sheet)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	validationErrs := utils.ValidateStruct(sheet)
+	if _, err := time.Parse(time.DateOnly, sheet.Date); sheet.Date != "" && err != nil {
+		validationErrs = append(validationErrs, utils.FieldError{Field: "date", Message: "must be a date like 2026-01-01"})
+	}
+	if len(sheet.Records) == 0 {
+		validationErrs = append(validationErrs, utils.FieldError{Field: "records", Message: "must have a record for at least one student"})
+	}
+	for i, record := range sheet.Records {
+		validationErrs = append(validationErrs, utils.WithIndex(utils.ValidateStruct(record), i)...)
+	}
+	if err := validationError(validationErrs); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	records, result, err := sqlconnect.SubmitAttendanceDBHandler(r.Context(), id, sheet)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status string `json:"status"`
+		models.UpsertResult
+		Data []models.Attendance `json:"data"`
+	}{
+		Status:       "success",
+		UpsertResult: result,
+		Data:         records,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetClassAttendanceHandler godoc
+// @Summary Daily attendance summaries of a class
+// @Description Count the attendance of a class by status for every day between from and to, with the attendance rate. Only whole-day records are counted unless period is given.
+// @Tags attendance
+// @Produce json,application/problem+json
+// @Param id path int true "Class ID"
+// @Param from query string false "First day, e.g. 2026-01-01, defaults to today (optional)"
+// @Param to query string false "Last day, defaults to today (optional)"
+// @Param period query int false "Count the records of this period instead, 0 is the whole day (optional)"
+// @Success 200 {object} map[string]interface{} "Summary of every day with records, and of the whole range"
+// @Failure 400 {object} utils.Problem "Invalid Class ID"
+// @Failure 404 {object} utils.Problem "Class not found"
+// @Failure 422 {object} utils.Problem "Invalid from, to or period"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /classes/{id}/attendance [get]
+func GetClassAttendanceHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Class ID")
+		return
+	}
+
+	from, to, period, err := attendanceParams(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	today := time.Now().Format(time.DateOnly)
+	if from == "" {
+		from = today
+	}
+	if to == "" {
+		to = today
+	}
+	if period == nil {
+		period = new(int)
+	}
+
+	days, err := sqlconnect.GetClassAttendanceSummaryDBHandler(r.Context(), id, from, to, period)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	var total models.AttendanceSummary
+	for _, day := range days {
+		total.Add(models.AttendancePresent, day.Present)
+		total.Add(models.AttendanceAbsent, day.Absent)
+		total.Add(models.AttendanceLate, day.Late)
+		total.Add(models.AttendanceExcused, day.Excused)
+	}
+
+	response := struct {
+		Status  string                     `json:"status"`
+		From    string                     `json:"from"`
+		To      string                     `json:"to"`
+		Period  int                        `json:"period"`
+		Summary models.AttendanceSummary   `json:"summary"`
+		Data    []models.AttendanceSummary `json:"data"`
+	}{
+		Status:  "success",
+		From:    from,
+		To:      to,
+		Period:  *period,
+		Summary: total,
+		Data:    days,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetStudentAttendanceHandler godoc
+// @Summary Attendance of a student
+// @Description List the attendance records of a student, newest first, with counts by status and the attendance rate
+// @Tags attendance
+// @Produce json,application/problem+json
+// @Param id path int true "Student ID"
+// @Param from query string false "First day, e.g. 2026-01-01 (optional)"
+// @Param to query string false "Last day (optional)"
+// @Param period query int false "Only the records of this period, 0 is the whole day (optional)"
+// @Success 200 {object} map[string]interface{} "Records of the student and their summary"
+// @Failure 400 {object} utils.Problem "Invalid Student ID"
+// @Failure 404 {object} utils.Problem "Student not found"
+// @Failure 422 {object} utils.Problem "Invalid from, to or period"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /students/{id}/attendance [get]
+func GetStudentAttendanceHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Student ID")
+		return
+	}
+
+	from, to, period, err := attendanceParams(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	records, err := sqlconnect.GetStudentAttendanceDBHandler(r.Context(), id, from, to, period)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	var summary models.AttendanceSummary
+	for _, record := range records {
+		summary.Add(record.Status, 1)
+	}
+
+	response := struct {
+		Status  string                   `json:"status"`
+		Count   int                      `json:"count"`
+		Summary models.AttendanceSummary `json:"summary"`
+		Data    []models.Attendance      `json:"data"`
+	}{
+		Status:  "success",
+		Count:   len(records),
+		Summary: summary,
+		Data:    records,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// attendanceParams reads the from, to and period query parameters, period is nil when it wasn't given
+func attendanceParams(r *http.Request) (from, to string, period *int, err error) {
+	from, err = parseDateParam(r, "from")
+	if err != nil {
+		return "", "", nil, err
+	}
+	to, err = parseDateParam(r, "to")
+	if err != nil {
+		return "", "", nil, err
+	}
+	if from != "" && to != "" && from > to {
+		return "", "", nil, utils.ValidationError("Invalid date range", utils.FieldError{Field: "to", Message: "must not be before from"})
+	}
+
+	if value := r.URL.Query().Get("period"); value != "" {
+		p, err := strconv.Atoi(value)
+		if err != nil || p < 0 || p > 12 {
+			return "", "", nil, utils.ValidationError("Invalid period", utils.FieldError{Field: "period", Message: "must be a whole number from 0 to 12"})
+		}
+		period = &p
+	}
+	return from, to, period, nil
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/repository/sqlconnect"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+// GetAuditEntriesHandler godoc
+// @Summary List the audit log
+// @Description Get audit entries, newest first, with optional filters. Admins only.
+// @Tags audit
+// @Produce json,application/problem+json
+// @Param actor_id query int false "Filter by the exec who made the change (optional)"
+// @Param actor_username query string false "Filter by username of the actor (optional)"
+// @Param action query string false "Filter by action, e.g. create, update, delete, login (optional)"
+// @Param resource query string false "Filter by resource, e.g. students, teachers, execs (optional)"
+// @Param resource_id query int false "Filter by ID of the changed record (optional)"
+// @Param ip query string false "Filter by client IP (optional)"
+// @Param request_id query string false "Filter by request ID (optional)"
+// @Param from query string false "Only entries at or after this RFC 3339 time or date (optional)"
+// @Param to query string false "Only entries before this RFC 3339 time or date (optional)"
+// @Param page query int false "Page number, starting at 1 (optional)"
+// @Param limit query int false "Page size, defaults to 10 (optional)"
+// @Success 200 {object} map[string]interface{} "List of audit entries with metadata"
+// @Failure 403 {object} utils.Problem "Only admins may read the audit log"
+// @Failure 422 {object} utils.Problem "Invalid time range"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /audit [get]
+func GetAuditEntriesHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	var fieldErrs []utils.FieldError
+	from, err := parseTimeParam(r, "from")
+	if err != nil {
+		fieldErrs = append(fieldErrs, utils.FieldError{Field: "from", Message: timeParamMessage})
+	}
+	to, err := parseTimeParam(r, "to")
+	if err != nil {
+		fieldErrs = append(fieldErrs, utils.FieldError{Field: "to", Message: timeParamMessage})
+	}
+	if err := validationError(fieldErrs); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	page, limit := getPaginationParams(r)
+
+	var entries []models.AuditEntry
+	entries, total, err := sqlconnect.GetAuditEntriesDBHandler(entries, r, limit, page, from, to)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status   string              `json:"status"`
+		Count    int                 `json:"count"`
+		Page     int                 `json:"page"`
+		PageSize int                 `json:"page_size"`
+		Data     []models.AuditEntry `json:"data"`
+	}{
+		Status:   "success",
+		Count:    total,
+		Page:     page,
+		PageSize: limit,
+		Data:     entries,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,399 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/repository/sqlconnect"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+// GetClassesHandler godoc
+// @Summary Retrieve all classes
+// @Description Get a list of classes with optional filtering and sorting.
+// @Tags classes
+// @Produce json,application/problem+json
+// @Param name query string false "Filter by name, e.g. 10A (optional)"
+// @Param grade query int false "Filter by grade (optional)"
+// @Param section query string false "Filter by section (optional)"
+// @Param homeroom_teacher_id query int false "Filter by homeroom teacher (optional)"
+// @Param academic_year query string false "Filter by academic year, e.g. 2025-2026 (optional)"
+// @Param term query string false "Only classes of the academic year of this term, its ID or current (optional)"
+// @Param sortby query string false "Sorting (e.g., grade:asc, section:asc) (optional)"
+// @Param page query int false "Page number, starting at 1 (optional)"
+// @Param limit query int false "Page size, defaults to 10 (optional)"
+// @Param If-None-Match header string false "ETag from a previous response, answered with 304 if unchanged"
+// @Param If-Modified-Since header string false "Last-Modified from a previous response"
+// @Success 200 {object} map[string]interface{} "List of classes with metadata"
+// @Header 200 {string} ETag "Hash of the response body"
+// @Header 200 {string} Last-Modified "Latest updated_at of the listed records"
+// @Success 304 "Not Modified"
+// @Failure 422 {object} utils.Problem "Unknown term"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /classes [get]
+func GetClassesHandler(w http.ResponseWriter, r *http.Request) {
+	var classes []models.Class
+	page, limit := getPaginationParams(r)
+
+	classes, totalClasses, err := sqlconnect.GetClassesDBHandler(classes, r, limit, page)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status   string         `json:"status"`
+		Count    int            `json:"count"`
+		Page     int            `json:"page"`
+		PageSize int            `json:"page_size"`
+		Data     []models.Class `json:"data"`
+	}{
+		Status:   "success",
+		Count:    totalClasses,
+		Page:     page,
+		PageSize: limit,
+		Data:     classes,
+	}
+
+	utils.SetLastModified(w, newestUpdate(classes))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetOneClassHandler godoc
+// @Summary Get one class
+// @Description Retrieve details of a class by ID
+// @Tags classes
+// @Produce json,application/problem+json
+// @Param id path int true "Class ID"
+// @Param If-None-Match header string false "ETag from a previous response, answered with 304 if unchanged"
+// @Param If-Modified-Since header string false "Last-Modified from a previous response"
+// @Success 200 {object} models.Class
+// @Header 200 {string} ETag "Version of the class, send it back in If-Match"
+// @Header 200 {string} Last-Modified "When the record was last updated"
+// @Success 304 "Not Modified"
+// @Failure 400 {object} utils.Problem "Invalid Class ID"
+// @Failure 404 {object} utils.Problem "Class not found"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /classes/{id} [get]
+func GetOneClassHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Class ID")
+		return
+	}
+
+	class, err := sqlconnect.GetOneClassDBHandler(r.Context(), id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	utils.SetVersionETag(w, class.Version)
+	if class.UpdatedAt != nil {
+		utils.SetLastModified(w, class.UpdatedAt.Time)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(class)
+}
+
+// GetClassStudentsHandler godoc
+// @Summary Get the students of a class
+// @Description List the students of a class, by last name
+// @Tags classes
+// @Produce json,application/problem+json
+// @Param id path int true "Class ID"
+// @Success 200 {object} map[string]interface{} "Students of the class"
+// @Failure 400 {object} utils.Problem "Invalid Class ID"
+// @Failure 404 {object} utils.Problem "Class not found"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /classes/{id}/students [get]
+func GetClassStudentsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Class ID")
+		return
+	}
+
+	students, err := sqlconnect.GetClassStudentsDBHandler(r.Context(), id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status string           `json:"status"`
+		Count  int              `json:"count"`
+		Data   []models.Student `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(students),
+		Data:   students,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// AddClassesHandler godoc
+// @Summary Add new classes
+// @Description Add one or more classes, their names are made of the grade and section. Admins only.
+// @Tags classes
+// @Accept json
+// @Produce json,application/problem+json
+// @Param classes body []models.Class true "List of classes"
+// @Param Idempotency-Key header string false "Unique key for this request, retries with the same key get the first response replayed"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} utils.Problem "Invalid request payload"
+// @Failure 403 {object} utils.Problem "Only admins may change classes"
+// @Failure 409 {object} utils.Problem "A class with this name already exists in the academic year"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /classes [post]
+func AddClassesHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusInternalServerError, "Error reading request body.")
+		return
+	}
+
+	var rawClasses []map[string]interface{}
+	err = json.Unmarshal(body, &rawClasses)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	var newClasses []models.Class
+	err = json.Unmarshal(body, &newClasses)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	allowedFields := make(map[string]struct{})
+	for _, field := range GetFieldNames(models.Class{}) {
+		allowedFields[field] = struct{}{}
+	}
+	for _, class := range rawClasses {
+		err := unknownFieldsError(class, allowedFields)
+		if err != nil {
+			utils.WriteError(w, r, err)
+			return
+		}
+	}
+
+	var validationErrs []utils.FieldError
+	for i, class := range newClasses {
+		validationErrs = append(validationErrs, utils.WithIndex(utils.ValidateStruct(class), i)...)
+	}
+	if err := validationError(validationErrs); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	addedClasses, err := sqlconnect.AddClassesDBHandler(r.Context(), newClasses)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	response := struct {
+		Status string         `json:"status"`
+		Count  int            `json:"count"`
+		Data   []models.Class `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(addedClasses),
+		Data:   addedClasses,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// UpdateClassHandler godoc
+// @Summary Update a class
+// @Description Replace a class by ID. Changing the grade or section renames the class of its students too. Admins only.
+// @Tags classes
+// @Accept json
+// @Produce json,application/problem+json
+// @Param id path int true "Class ID"
+// @Param If-Match header string true "ETag of the class from GET /classes/{id}, or *"
+// @Param class body models.Class true "Updated class"
+// @Success 200 {object} models.Class
+// @Header 200 {string} ETag "Version of the class, send it back in If-Match"
+// @Failure 400 {object} utils.Problem "Invalid request payload or ID"
+// @Failure 403 {object} utils.Problem "Only admins may change classes"
+// @Failure 404 {object} utils.Problem "Class not found"
+// @Failure 409 {object} utils.Problem "A class with this name already exists in the academic year"
+// @Failure 412 {object} utils.Problem "Class was modified since it was read"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 428 {object} utils.Problem "Missing If-Match header"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /classes/{id} [put]
+func UpdateClassHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Class ID")
+		return
+	}
+
+	version, err := utils.IfMatchVersion(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	var updatedClass models.Class
+	err = json.NewDecoder(r.Body).Decode(&updatedClass)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := validationError(utils.ValidateStruct(updatedClass)); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	updatedClassFromDB, err := sqlconnect.UpdateClassDBHandler(r.Context(), id, version, updatedClass)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	utils.SetVersionETag(w, updatedClassFromDB.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(updatedClassFromDB)
+}
+
+// PatchOneClassHandler godoc
+// @Summary Partially update a class
+// @Description Update some fields of a class by ID, a null homeroom_teacher_id removes the homeroom teacher. Admins only.
+// @Tags classes
+// @Accept json
+// @Produce json,application/problem+json
+// @Param id path int true "Class ID"
+// @Param If-Match header string true "ETag of the class from GET /classes/{id}, or *"
+// @Param updates body map[string]interface{} true "Fields to update"
+// @Success 200 {object} models.Class
+// @Header 200 {string} ETag "Version of the class, send it back in If-Match"
+// @Failure 400 {object} utils.Problem "Invalid request payload or ID"
+// @Failure 403 {object} utils.Problem "Only admins may change classes"
+// @Failure 404 {object} utils.Problem "Class not found"
+// @Failure 409 {object} utils.Problem "A class with this name already exists in the academic year"
+// @Failure 412 {object} utils.Problem "Class was modified since it was read"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 428 {object} utils.Problem "Missing If-Match header"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /classes/{id} [patch]
+func PatchOneClassHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Class ID")
+		return
+	}
+
+	version, err := utils.IfMatchVersion(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	var updates map[string]interface{}
+	err = json.NewDecoder(r.Body).Decode(&updates)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := validationError(utils.ValidateFields(models.Class{}, updates)); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	updatedClass, err := sqlconnect.PatchOneClassDBHandler(r.Context(), id, version, updates)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	utils.SetVersionETag(w, updatedClass.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(updatedClass)
+}
+
+// DeleteOneClassHandler godoc
+// @Summary Delete a class
+// @Description Delete a class by ID for good, it must have no students, deleted ones included. Admins only.
+// @Tags classes
+// @Produce application/problem+json
+// @Param id path int true "Class ID"
+// @Param If-Match header string true "ETag of the class from GET /classes/{id}, or *"
+// @Success 204 "No Content"
+// @Failure 400 {object} utils.Problem "Invalid Class ID"
+// @Failure 403 {object} utils.Problem "Only admins may change classes"
+// @Failure 404 {object} utils.Problem "Class not found"
+// @Failure 409 {object} utils.Problem "Class still has students"
+// @Failure 412 {object} utils.Problem "Class was modified since it was read"
+// @Failure 428 {object} utils.Problem "Missing If-Match header"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /classes/{id} [delete]
+func DeleteOneClassHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Class ID")
+		return
+	}
+
+	version, err := utils.IfMatchVersion(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	err = sqlconnect.DeleteOneClassDBHandler(r.Context(), id, version)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/metrics"
 	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
 	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/repository/sqlconnect"
 	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
@@ -18,20 +19,33 @@ import (
 // @Description Get a list of execs with optional filtering and sorting.
 // @Tags execs
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
 // @Param first_name query string false "Filter by first name (optional)"
 // @Param last_name query string false "Filter by last name (optional)"
 // @Param email query string false "Filter by email (optional)"
 // @Param role query string false "Filter by role (optional)"
 // @Param sortby query string false "Sorting (e.g., first_name:asc, role:desc) (optional)"
+// @Param include_deleted query bool false "Include soft-deleted records, admins only (optional)"
+// @Param If-None-Match header string false "ETag from a previous response, answered with 304 if unchanged"
+// @Param If-Modified-Since header string false "Last-Modified from a previous response"
 // @Success 200 {object} map[string]interface{} "List of execs with metadata"
-// @Failure 500 {string} string "Internal server error"
+// @Header 200 {string} ETag "Hash of the response body"
+// @Header 200 {string} Last-Modified "Latest updated_at of the listed records"
+// @Success 304 "Not Modified"
+// @Failure 403 {object} utils.Problem "include_deleted requires the admin role"
+// @Failure 500 {object} utils.Problem "Internal server error"
 // @Router /execs [get]
 func GetExecsHandler(w http.ResponseWriter, r *http.Request) {
 	var execs []models.Exec
-	execs, err := sqlconnect.GetExecsDBHandler(execs, r)
+	includeDeleted, err := showDeleted(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	execs, err = sqlconnect.GetExecsDBHandler(execs, r, includeDeleted)
+	if err != nil {
+		utils.WriteError(w, r, err)
 		return
 	}
 
@@ -45,6 +59,7 @@ func GetExecsHandler(w http.ResponseWriter, r *http.Request) {
 		Data:   execs,
 	}
 
+	utils.SetLastModified(w, newestUpdate(execs))
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
@@ -54,26 +69,44 @@ func GetExecsHandler(w http.ResponseWriter, r *http.Request) {
 // @Summary Get one exec
 // @Description Retrieve details of an exec by ID
 // @Tags execs
-// @Produce json
+// @Produce json,application/problem+json
 // @Param id path int true "Exec ID"
+// @Param include_deleted query bool false "Include soft-deleted records, admins only (optional)"
+// @Param If-None-Match header string false "ETag from a previous response, answered with 304 if unchanged"
+// @Param If-Modified-Since header string false "Last-Modified from a previous response"
 // @Success 200 {object} models.Exec
-// @Failure 400 {string} string "Invalid Exec ID"
-// @Failure 500 {string} string "Internal server error"
+// @Header 200 {string} ETag "Version of the exec, send it back in If-Match"
+// @Header 200 {string} Last-Modified "When the record was last updated"
+// @Success 304 "Not Modified"
+// @Failure 400 {object} utils.Problem "Invalid Exec ID"
+// @Failure 403 {object} utils.Problem "include_deleted requires the admin role"
+// @Failure 404 {object} utils.Problem "Exec not found"
+// @Failure 500 {object} utils.Problem "Internal server error"
 // @Router /execs/{id} [get]
 func GetOneExecHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid Exec ID", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Exec ID")
 		return
 	}
 
-	exec, err := sqlconnect.GetOneExecDBHandler(id)
+	includeDeleted, err := showDeleted(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
 		return
 	}
 
+	exec, err := sqlconnect.GetOneExecDBHandler(r.Context(), id, includeDeleted)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	utils.SetVersionETag(w, exec.Version)
+	if exec.UpdatedAt != nil {
+		utils.SetLastModified(w, exec.UpdatedAt.Time)
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(exec)
@@ -84,24 +117,35 @@ func GetOneExecHandler(w http.ResponseWriter, r *http.Request) {
 // @Description Add one or more execs
 // @Tags execs
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
 // @Param execs body []models.Exec true "List of execs"
+// @Param Idempotency-Key header string false "Unique key for this request, retries with the same key get the first response replayed"
+// @Param mode query string false "atomic (default) applies all items or none, partial applies each item on its own" Enums(atomic, partial)
 // @Success 201 {object} map[string]interface{}
-// @Failure 400 {string} string "Invalid request payload"
-// @Failure 500 {string} string "Internal server error"
+// @Success 207 {object} map[string]interface{} "Result of each item, in partial mode"
+// @Failure 400 {object} utils.Problem "Invalid request payload"
+// @Failure 409 {object} utils.Problem "Duplicate email or username, or a request with this Idempotency-Key still in progress"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 500 {object} utils.Problem "Internal server error"
 // @Router /execs [post]
 func AddExecHandler(w http.ResponseWriter, r *http.Request) {
 	var rawExecs []map[string]interface{}
 
+	mode, err := utils.BulkMode(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, "Error reading request body.", http.StatusInternalServerError)
+		utils.WriteProblem(w, r, http.StatusInternalServerError, "Error reading request body.")
 		return
 	}
 
 	err = json.Unmarshal(body, &rawExecs)
 	if err != nil {
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 
@@ -111,32 +155,55 @@ func AddExecHandler(w http.ResponseWriter, r *http.Request) {
 		allowedFields[field] = struct{}{}
 	}
 
+	var newExecs []models.Exec
+	err = json.Unmarshal(body, &newExecs)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if mode == utils.BulkModePartial {
+		results := make([]utils.BulkItemResult, len(newExecs))
+		for i, exec := range newExecs {
+			err := unknownFieldsError(rawExecs[i], allowedFields)
+			if err == nil {
+				err = validationError(utils.ValidateStruct(exec))
+			}
+			var added []models.Exec
+			if err == nil {
+				added, err = sqlconnect.AddExecsDBHandler(r.Context(), []models.Exec{exec})
+			}
+			if err != nil {
+				results[i] = utils.BulkItemFailure(i, 0, err)
+				continue
+			}
+			results[i] = utils.BulkItemSuccess(i, added[0].ID, http.StatusCreated)
+		}
+		utils.WriteBulkResults(w, results)
+		return
+	}
+
 	for _, exec := range rawExecs {
 		for key := range exec {
 			if _, ok := allowedFields[key]; !ok {
-				http.Error(w, "Unacceptable field found in request. Only use allowed fields", http.StatusBadRequest)
+				utils.WriteProblem(w, r, http.StatusBadRequest, "Unacceptable field found in request. Only use allowed fields")
 				return
 			}
 		}
 	}
 
-	var newExecs []models.Exec
-	err = json.Unmarshal(body, &newExecs)
-	if err != nil {
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
-		return
+	var validationErrs []utils.FieldError
+	for i, exec := range newExecs {
+		validationErrs = append(validationErrs, utils.WithIndex(utils.ValidateStruct(exec), i)...)
 	}
-
-	for _, exec := range newExecs {
-		if err := CheckBlankFields(exec); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
+	if err := validationError(validationErrs); err != nil {
+		utils.WriteError(w, r, err)
+		return
 	}
 
-	addedExecs, err := sqlconnect.AddExecsDBHandler(newExecs)
+	addedExecs, err := sqlconnect.AddExecsDBHandler(r.Context(), newExecs)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
 		return
 	}
 
@@ -156,25 +223,65 @@ func AddExecHandler(w http.ResponseWriter, r *http.Request) {
 
 // PatchExecsHandler godoc
 // @Summary Partially update multiple execs
-// @Description Apply partial updates to multiple execs
+// @Description Apply partial updates to multiple execs, each item must include the version it was based on
 // @Tags execs
 // @Accept json
+// @Produce application/problem+json
 // @Param updates body []map[string]interface{} true "List of updates with exec IDs"
+// @Param Idempotency-Key header string false "Unique key for this request, retries with the same key get the first response replayed"
+// @Param mode query string false "atomic (default) applies all items or none, partial applies each item on its own" Enums(atomic, partial)
 // @Success 204 "No Content"
-// @Failure 400 {string} string "Invalid request payload"
-// @Failure 500 {string} string "Internal server error"
+// @Success 207 {object} map[string]interface{} "Result of each item, in partial mode"
+// @Failure 400 {object} utils.Problem "Invalid request payload"
+// @Failure 404 {object} utils.Problem "Exec not found"
+// @Failure 409 {object} utils.Problem "Duplicate email or username, or a request with this Idempotency-Key still in progress"
+// @Failure 412 {object} utils.Problem "Exec was modified since it was read"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 500 {object} utils.Problem "Internal server error"
 // @Router /execs [patch]
 func PatchExecsHandler(w http.ResponseWriter, r *http.Request) {
+	mode, err := utils.BulkMode(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
 	var updates []map[string]interface{}
-	err := json.NewDecoder(r.Body).Decode(&updates)
+	err = json.NewDecoder(r.Body).Decode(&updates)
 	if err != nil {
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if mode == utils.BulkModePartial {
+		results := make([]utils.BulkItemResult, len(updates))
+		for i, update := range updates {
+			err := validationError(utils.ValidateFields(models.Exec{}, update))
+			if err == nil {
+				err = sqlconnect.PatchExecsDBHandler(r.Context(), []map[string]interface{}{update})
+			}
+			if err != nil {
+				results[i] = utils.BulkItemFailure(i, updateID(update), err)
+				continue
+			}
+			results[i] = utils.BulkItemSuccess(i, updateID(update), http.StatusOK)
+		}
+		utils.WriteBulkResults(w, results)
 		return
 	}
 
-	err = sqlconnect.PatchExecsDBHandler(updates)
+	var validationErrs []utils.FieldError
+	for i, update := range updates {
+		validationErrs = append(validationErrs, utils.WithIndex(utils.ValidateFields(models.Exec{}, update), i)...)
+	}
+	if err := validationError(validationErrs); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	err = sqlconnect.PatchExecsDBHandler(r.Context(), updates)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -185,34 +292,53 @@ func PatchExecsHandler(w http.ResponseWriter, r *http.Request) {
 // @Description Apply partial updates to a single exec by ID
 // @Tags execs
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
 // @Param id path int true "Exec ID"
+// @Param If-Match header string true "ETag of the exec from GET /execs/{id}, or *"
 // @Param updates body map[string]interface{} true "Partial updates"
 // @Success 200 {object} models.Exec
-// @Failure 400 {string} string "Invalid request payload or ID"
-// @Failure 500 {string} string "Internal server error"
+// @Header 200 {string} ETag "Version of the exec, send it back in If-Match"
+// @Failure 400 {object} utils.Problem "Invalid request payload or ID"
+// @Failure 404 {object} utils.Problem "Exec not found"
+// @Failure 409 {object} utils.Problem "Duplicate email or username"
+// @Failure 412 {object} utils.Problem "Exec was modified since it was read"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 428 {object} utils.Problem "Missing If-Match header"
+// @Failure 500 {object} utils.Problem "Internal server error"
 // @Router /execs/{id} [patch]
 func PatchOneExecHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid Exec ID", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Exec ID")
+		return
+	}
+
+	version, err := utils.IfMatchVersion(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
 		return
 	}
 
 	var updates map[string]interface{}
 	err = json.NewDecoder(r.Body).Decode(&updates)
 	if err != nil {
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 
-	updatedExec, err := sqlconnect.PatchOneExecDBHandler(id, updates)
+	if err := validationError(utils.ValidateFields(models.Exec{}, updates)); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	updatedExec, err := sqlconnect.PatchOneExecDBHandler(r.Context(), id, version, updates)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
 		return
 	}
 
+	utils.SetVersionETag(w, updatedExec.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(updatedExec)
@@ -220,41 +346,95 @@ func PatchOneExecHandler(w http.ResponseWriter, r *http.Request) {
 
 // DeleteOneExecHandler godoc
 // @Summary Delete one exec
-// @Description Delete an exec by ID
+// @Description Soft-delete an exec by ID, it can be restored until it is purged
 // @Tags execs
+// @Produce application/problem+json
 // @Param id path int true "Exec ID"
+// @Param If-Match header string true "ETag of the exec from GET /execs/{id}, or *"
 // @Success 204 "No Content"
-// @Failure 400 {string} string "Invalid Exec ID"
-// @Failure 500 {string} string "Internal server error"
+// @Failure 400 {object} utils.Problem "Invalid Exec ID"
+// @Failure 404 {object} utils.Problem "Exec not found"
+// @Failure 412 {object} utils.Problem "Exec was modified since it was read"
+// @Failure 428 {object} utils.Problem "Missing If-Match header"
+// @Failure 500 {object} utils.Problem "Internal server error"
 // @Router /execs/{id} [delete]
 func DeleteOneExecHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid Exec ID", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Exec ID")
+		return
+	}
+
+	version, err := utils.IfMatchVersion(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
 		return
 	}
 
-	err = sqlconnect.DeleteOneExecDBHandler(id)
+	err = sqlconnect.DeleteOneExecDBHandler(r.Context(), id, version)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// RestoreExecHandler godoc
+// @Summary Restore a deleted exec
+// @Description Undo the soft delete of a exec, admins only
+// @Tags execs
+// @Produce json,application/problem+json
+// @Param id path int true "Exec ID"
+// @Success 200 {object} models.Exec
+// @Header 200 {string} ETag "Version of the exec, send it back in If-Match"
+// @Failure 400 {object} utils.Problem "Invalid Exec ID"
+// @Failure 403 {object} utils.Problem "Only admins may restore records"
+// @Failure 404 {object} utils.Problem "Exec not found"
+// @Failure 409 {object} utils.Problem "Exec is not deleted"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /execs/{id}/restore [post]
+func RestoreExecHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Exec ID")
+		return
+	}
+
+	exec, err := sqlconnect.RestoreExecDBHandler(r.Context(), id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	utils.SetVersionETag(w, exec.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(exec)
+}
+
 // LoginHandler godoc
 // @Summary User Login
 // @Description Authenticates an exec user using username and password and returns a JWT token.
 // @Tags auth
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
 // @Param credentials body models.Exec true "Login Credentials (username and password required)"
 // @Success 200 {object} map[string]string "JWT token in response body and also set as HttpOnly cookie"
-// @Failure 400 {string} string "Invalid request body or missing username/password"
-// @Failure 403 {string} string "Account inactive or password incorrect"
-// @Failure 500 {string} string "Could not create login token"
+// @Failure 400 {object} utils.Problem "Invalid request body or missing username/password"
+// @Failure 403 {object} utils.Problem "Account inactive or password incorrect"
+// @Failure 409 {object} utils.Problem "Duplicate email or username"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 500 {object} utils.Problem "Could not create login token"
 // @Router /execs/login [post]
 func LoginHandler(w http.ResponseWriter, r *http.Request) {
 
@@ -263,41 +443,49 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 	// Data Validation
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		http.Error(w, "Invalid Request Body", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Request Body")
 		return
 	}
 
 	if req.Username == "" || req.Password == "" {
-		http.Error(w, "Username and password are blank", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Username and password are blank")
 		return
 	}
 
 	// Search for user if user actually exists
-	user, err := sqlconnect.LoginDBHandler(req.Username)
+	user, err := sqlconnect.LoginDBHandler(r.Context(), req.Username)
 	if err != nil {
-		http.Error(w, "Invalid username or password", http.StatusBadRequest)
+		metrics.LoginAttemptsTotal.WithLabelValues("failure").Inc()
+		sqlconnect.AddAuditEntryDBHandler(r.Context(), models.AuditEntry{Action: models.AuditLoginFailed, Resource: "execs", ActorUsername: req.Username})
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid username or password")
 		return
 	}
 
 	// is user active
 	if user.InactiveStatus {
-		http.Error(w, "Account is inactive", http.StatusForbidden)
+		metrics.LoginAttemptsTotal.WithLabelValues("failure").Inc()
+		sqlconnect.AddAuditEntryDBHandler(r.Context(), models.AuditEntry{Action: models.AuditLoginFailed, Resource: "execs", ResourceID: &user.ID, ActorUsername: req.Username})
+		utils.WriteProblem(w, r, http.StatusForbidden, "Account is inactive")
 		return
 	}
 
 	// verify password
 	err = utils.VerifyPassword(req.Password, user.Password)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusForbidden)
+		metrics.LoginAttemptsTotal.WithLabelValues("failure").Inc()
+		sqlconnect.AddAuditEntryDBHandler(r.Context(), models.AuditEntry{Action: models.AuditLoginFailed, Resource: "execs", ResourceID: &user.ID, ActorUsername: req.Username})
+		utils.WriteProblem(w, r, http.StatusForbidden, err.Error())
 		return
 	}
 
 	// Generate JWT Token
 	tokenString, err := utils.SignToken(user.ID, req.Username, user.Role)
 	if err != nil {
-		http.Error(w, "Could not create login token", http.StatusInternalServerError)
+		utils.WriteProblem(w, r, http.StatusInternalServerError, "Could not create login token")
 		return
 	}
+	metrics.LoginAttemptsTotal.WithLabelValues("success").Inc()
+	sqlconnect.AddAuditEntryDBHandler(r.Context(), models.AuditEntry{Action: models.AuditLogin, Resource: "execs", ResourceID: &user.ID, ActorID: &user.ID, ActorUsername: user.Username})
 
 	// Send token as a response or as a cookie
 	http.SetCookie(w, &http.Cookie{
@@ -332,11 +520,13 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 // @Summary Log out a user
 // @Description Logs out the currently authenticated user by clearing the JWT cookie.
 // @Tags auth
-// @Produce json
+// @Produce json,application/problem+json
 // @Success 200 {object} map[string]string "Logged out successfully"
-// @Failure 500 {string} string "Internal server error"
+// @Failure 500 {object} utils.Problem "Internal server error"
 // @Router /execs/logout [post]
 func LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	sqlconnect.AddAuditEntryDBHandler(r.Context(), models.AuditEntry{Action: models.AuditLogout, Resource: "execs"})
+
 	http.SetCookie(w, &http.Cookie{
 		Name:     "Bearer",
 		Value:    "",
@@ -356,41 +546,43 @@ func LogoutHandler(w http.ResponseWriter, r *http.Request) {
 // @Description Allows an exec to update their password after providing the current password. A new JWT token is generated upon success.
 // @Tags auth
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
 // @Param id path int true "Exec ID"
 // @Param body body models.UpdatePasswordRequest true "Password update request"
 // @Success 200 {object} map[string]string "Password updated successfully"
-// @Failure 400 {string} string "Invalid input or password update failed"
-// @Failure 500 {string} string "Internal server error"
-// @Router /execs/{id}/updatepassword [patch]
+// @Failure 400 {object} utils.Problem "Invalid input or password update failed"
+// @Failure 404 {object} utils.Problem "Exec not found"
+// @Failure 422 {object} utils.Problem "Current password does not match"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /execs/{id}/updatepassword [post]
 func UpdatePasswordHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	userId, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid exec ID", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid exec ID")
 		return
 	}
 
 	var req models.UpdatePasswordRequest
 	err = json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		http.Error(w, "Invalid Request Body", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Request Body")
 		return
 	}
 	r.Body.Close()
 
 	if req.CurrentPassword == "" || req.NewPassword == "" {
-		http.Error(w, "Please enter password", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Please enter password")
 		return
 	}
 
-	_, token, err := sqlconnect.UpdatePasswordDBHandler(userId, req.CurrentPassword, req.NewPassword)
+	_, token, err := sqlconnect.UpdatePasswordDBHandler(r.Context(), userId, req.CurrentPassword, req.NewPassword)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		utils.WriteError(w, r, err)
 		return
 	}
 	if token == "" {
-		http.Error(w, "Password updated. Could not create token", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Password updated. Could not create token")
 		return
 	}
 
@@ -420,11 +612,14 @@ func UpdatePasswordHandler(w http.ResponseWriter, r *http.Request) {
 // @Description Sends a password reset link to the exec's email.
 // @Tags auth
 // @Accept json
-// @Produce plain
+// @Produce plain,application/problem+json
 // @Param body body object{email=string} true "Exec email"
 // @Success 200 {string} string "Password reset link sent"
-// @Failure 400 {string} string "Invalid request or user not found"
-// @Failure 500 {string} string "Internal server error"
+// @Failure 400 {object} utils.Problem "Invalid request or user not found"
+// @Failure 409 {object} utils.Problem "Duplicate email or username"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 404 {object} utils.Problem "User not found"
+// @Failure 500 {object} utils.Problem "Internal server error"
 // @Router /execs/forgotpassword [post]
 func ForgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
 	var req struct {
@@ -433,19 +628,19 @@ func ForgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
 
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		http.Error(w, "Invalid Request Body", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Request Body")
 		return
 	}
 	r.Body.Close()
 
 	if req.Email == "" {
-		http.Error(w, "Please enter the email", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Please enter the email")
 		return
 	}
 
-	err = sqlconnect.ForgotPasswordDBHandler(req.Email)
+	err = sqlconnect.ForgotPasswordDBHandler(r.Context(), req.Email)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		utils.WriteError(w, r, err)
 		return
 	}
 
@@ -459,12 +654,13 @@ func ForgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
 // @Description Resets the exec's password using a reset token sent via email.
 // @Tags auth
 // @Accept json
-// @Produce plain
+// @Produce plain,application/problem+json
 // @Param resetcode path string true "Password reset token"
 // @Param body body object{new_password=string,confirm_password=string} true "New password request"
 // @Success 200 {string} string "Password reset successfully"
-// @Failure 400 {string} string "Invalid request or password mismatch"
-// @Failure 500 {string} string "Internal server error"
+// @Failure 400 {object} utils.Problem "Invalid request or password mismatch"
+// @Failure 422 {object} utils.Problem "Invalid or expired reset code"
+// @Failure 500 {object} utils.Problem "Internal server error"
 // @Router /execs/resetpassword/reset/{resetcode} [post]
 func ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
 	token := r.PathValue("resetcode")
@@ -477,24 +673,24 @@ func ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
 	var req request
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		http.Error(w, "Invalid values in request", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid values in request")
 		return
 	}
 	
 	if req.ConfirmPassword == "" || req.NewPassword == "" {
-		http.Error(w, "Please enter both the passwords", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Please enter both the passwords")
 		return
 	}
 
 	if req.NewPassword != req.ConfirmPassword {
-		http.Error(w, "Passwords should match", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Passwords should match")
 		return
 	}
 
 	// Hash the new password
-	err = sqlconnect.ResetPasswordDBHandler(token, req.NewPassword)
+	err = sqlconnect.ResetPasswordDBHandler(r.Context(), token, req.NewPassword)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		utils.WriteError(w, r, err)
 		return
 	}
 
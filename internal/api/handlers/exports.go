@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+// exportStream calls each for every record of a listing
+type exportStream func(each func(record interface{}) error) error
+
+// exportListing streams a listing to the response as a CSV, Excel or PDF file called name. Nothing
+// is written until the first record has been read, so a failing query still gets a problem response.
+func exportListing(w http.ResponseWriter, r *http.Request, format, name, title string, model interface{}, stream exportStream) {
+	var export utils.RowWriter
+	start := func() error {
+		if export != nil {
+			return nil
+		}
+		var err error
+		export, err = utils.NewRowWriter(w, format, name, title, utils.ExportHeader(model))
+		return err
+	}
+
+	err := stream(func(record interface{}) error {
+		if err := start(); err != nil {
+			return err
+		}
+		return export.WriteRow(utils.ExportRow(record))
+	})
+	if err == nil {
+		// an empty listing is still a file with a header
+		err = start()
+	}
+	if err == nil {
+		err = export.Close()
+	}
+	if err == nil {
+		return
+	}
+
+	if export == nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	// the 200 has already been sent, abort the response so a cut off file isn't taken for a whole one
+	utils.ErrorHandler(err, "Error exporting "+name)
+	panic(http.ErrAbortHandler)
+}
@@ -0,0 +1,425 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/repository/sqlconnect"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+// GetAssessmentsHandler godoc
+// @Summary List the assessments of a class
+// @Description Get the assessments of a class by date
+// @Tags grades
+// @Produce json,application/problem+json
+// @Param id path int true "Class ID"
+// @Param subject query string false "Filter by subject (optional)"
+// @Param term query string false "Filter by term (optional)"
+// @Success 200 {object} map[string]interface{} "Assessments of the class"
+// @Failure 400 {object} utils.Problem "Invalid Class ID"
+// @Failure 404 {object} utils.Problem "Class not found"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /classes/{id}/assessments [get]
+func GetAssessmentsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Class ID")
+		return
+	}
+
+	assessments, err := sqlconnect.GetAssessmentsDBHandler(r.Context(), id, r.URL.Query().Get("subject"), r.URL.Query().Get("term"))
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status string              `json:"status"`
+		Count  int                 `json:"count"`
+		Data   []models.Assessment `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(assessments),
+		Data:   assessments,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// AddAssessmentsHandler godoc
+// @Summary Add assessments to a class
+// @Description Add one or more assessments to a class, a missing weight counts as 1. The batch is all or nothing.
+// @Tags grades
+// @Accept json
+// @Produce json,application/problem+json
+// @Param id path int true "Class ID"
+// @Param assessments body []models.Assessment true "List of assessments"
+// @Param Idempotency-Key header string false "Unique key for this request, retries with the same key get the first response replayed"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} utils.Problem "Invalid request payload or ID"
+// @Failure 404 {object} utils.Problem "Class not found"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /classes/{id}/assessments [post]
+func AddAssessmentsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Class ID")
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var newAssessments []models.Assessment
+	err = decoder.Decode(&newAssessments)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	var validationErrs []utils.FieldError
+	for i, assessment := range newAssessments {
+		errs := utils.ValidateStruct(assessment)
+		if _, err := time.Parse(time.DateOnly, assessment.Date); assessment.Date != "" && err != nil {
+			errs = append(errs, utils.FieldError{Field: "date", Message: "must be a date like 2026-01-01"})
+		}
+		validationErrs = append(validationErrs, utils.WithIndex(errs, i)...)
+	}
+	if err := validationError(validationErrs); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	added, err := sqlconnect.AddAssessmentsDBHandler(r.Context(), id, newAssessments)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	response := struct {
+		Status string              `json:"status"`
+		Count  int                 `json:"count"`
+		Data   []models.Assessment `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(added),
+		Data:   added,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetOneAssessmentHandler godoc
+// @Summary Get an assessment
+// @Description Get an assessment of a class with its marks
+// @Tags grades
+// @Produce json,application/problem+json
+// @Param id path int true "Class ID"
+// @Param assessmentId path int true "Assessment ID"
+// @Success 200 {object} models.Assessment
+// @Failure 400 {object} utils.Problem "Invalid Class or Assessment ID"
+// @Failure 404 {object} utils.Problem "Assessment not found"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /classes/{id}/assessments/{assessmentId} [get]
+func GetOneAssessmentHandler(w http.ResponseWriter, r *http.Request) {
+	id, assessmentID, ok := assessmentPath(w, r)
+	if !ok {
+		return
+	}
+
+	assessment, err := sqlconnect.GetOneAssessmentDBHandler(r.Context(), id, assessmentID)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(assessment)
+}
+
+// DeleteAssessmentHandler godoc
+// @Summary Delete an assessment
+// @Description Delete an assessment of a class with all of its marks. Admins only.
+// @Tags grades
+// @Produce application/problem+json
+// @Param id path int true "Class ID"
+// @Param assessmentId path int true "Assessment ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} utils.Problem "Invalid Class or Assessment ID"
+// @Failure 403 {object} utils.Problem "Only admins may delete assessments"
+// @Failure 404 {object} utils.Problem "Assessment not found"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /classes/{id}/assessments/{assessmentId} [delete]
+func DeleteAssessmentHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	id, assessmentID, ok := assessmentPath(w, r)
+	if !ok {
+		return
+	}
+
+	err = sqlconnect.DeleteAssessmentDBHandler(r.Context(), id, assessmentID)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SubmitMarksHandler godoc
+// @Summary Enter marks
+// @Description Enter the marks of students of the class in an assessment, replacing marks they already had in it. A null score, e.g. for an excused absence, doesn't count towards averages. The batch is all or nothing.
+// @Tags grades
+// @Accept json
+// @Produce json,application/problem+json
+// @Param id path int true "Class ID"
+// @Param assessmentId path int true "Assessment ID"
+// @Param marks body []models.Mark true "A mark per student"
+// @Param Idempotency-Key header string false "Unique key for this request, retries with the same key get the first response replayed"
+// @Success 200 {object} map[string]interface{} "Created, updated and unchanged counts with the marks"
+// @Failure 400 {object} utils.Problem "Invalid request payload or ID"
+// @Failure 404 {object} utils.Problem "Assessment not found"
+// @Failure 422 {object} utils.Problem "Validation failed, e.g. a score over the max score"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /classes/{id}/assessments/{assessmentId}/marks [put]
+func SubmitMarksHandler(w http.ResponseWriter, r *http.Request) {
+	id, assessmentID, ok := assessmentPath(w, r)
+	if !ok {
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var marks []models.Mark
+	err := decoder.Decode(&marks)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	var validationErrs []utils.FieldError
+	for i, mark := range marks {
+		validationErrs = append(validationErrs, utils.WithIndex(utils.ValidateStruct(mark), i)...)
+	}
+	if err := validationError(validationErrs); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	saved, result, err := sqlconnect.SubmitMarksDBHandler(r.Context(), id, assessmentID, marks)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status string `json:"status"`
+		models.UpsertResult
+		Data []models.Mark `json:"data"`
+	}{
+		Status:       "success",
+		UpsertResult: result,
+		Data:         saved,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetClassGradesHandler godoc
+// @Summary Averages of a class
+// @Description Get the weighted averages of every student of a class by subject and overall, with their grades, and the class averages
+// @Tags grades
+// @Produce json,application/problem+json
+// @Param id path int true "Class ID"
+// @Param subject query string false "Only this subject (optional)"
+// @Param term query string false "Only this term (optional)"
+// @Success 200 {object} map[string]interface{} "Averages of the students and of the class"
+// @Failure 400 {object} utils.Problem "Invalid Class ID"
+// @Failure 404 {object} utils.Problem "Class not found"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /classes/{id}/grades [get]
+func GetClassGradesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Class ID")
+		return
+	}
+
+	grades, err := sqlconnect.GetClassGradesDBHandler(r.Context(), id, r.URL.Query().Get("subject"), r.URL.Query().Get("term"))
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	classAverage, classAverages := models.ClassAverages(grades)
+
+	response := struct {
+		Status          string                 `json:"status"`
+		Average         *float64               `json:"average"`
+		SubjectAverages map[string]float64     `json:"subject_averages"`
+		Count           int                    `json:"count"`
+		Data            []models.StudentGrades `json:"data"`
+	}{
+		Status:          "success",
+		Average:         classAverage,
+		SubjectAverages: classAverages,
+		Count:           len(grades),
+		Data:            grades,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetStudentGradesHandler godoc
+// @Summary Grades of a student
+// @Description Get the marks of a student with their weighted averages by subject and overall, and the grades of those
+// @Tags grades
+// @Produce json,application/problem+json
+// @Param id path int true "Student ID"
+// @Param subject query string false "Only this subject (optional)"
+// @Param term query string false "Only this term (optional)"
+// @Success 200 {object} models.StudentGrades
+// @Failure 400 {object} utils.Problem "Invalid Student ID"
+// @Failure 404 {object} utils.Problem "Student not found"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /students/{id}/grades [get]
+func GetStudentGradesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Student ID")
+		return
+	}
+
+	grades, err := sqlconnect.GetStudentGradesDBHandler(r.Context(), id, r.URL.Query().Get("subject"), r.URL.Query().Get("term"))
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(grades)
+}
+
+// GetGradeBandsHandler godoc
+// @Summary List the grade bands
+// @Description Get the letter grades of the school and the percentage each starts at, highest first
+// @Tags grades
+// @Produce json,application/problem+json
+// @Success 200 {object} map[string]interface{} "Grade bands"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /gradebands [get]
+func GetGradeBandsHandler(w http.ResponseWriter, r *http.Request) {
+	bands, err := sqlconnect.GetGradeBandsDBHandler(r.Context())
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status string             `json:"status"`
+		Count  int                `json:"count"`
+		Data   []models.GradeBand `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(bands),
+		Data:   bands,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// ReplaceGradeBandsHandler godoc
+// @Summary Replace the grade bands
+// @Description Replace the letter grades of the school. An average gets the grade of the highest band it reaches, one band should start at 0. Admins only.
+// @Tags grades
+// @Accept json
+// @Produce json,application/problem+json
+// @Param bands body []models.GradeBand true "Grade bands"
+// @Success 200 {object} map[string]interface{} "Grade bands"
+// @Failure 400 {object} utils.Problem "Invalid request payload"
+// @Failure 403 {object} utils.Problem "Only admins may change grade bands"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /gradebands [put]
+func ReplaceGradeBandsHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var bands []models.GradeBand
+	err = decoder.Decode(&bands)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	var validationErrs []utils.FieldError
+	if len(bands) == 0 {
+		validationErrs = append(validationErrs, utils.FieldError{Field: "bands", Message: "must have at least one band"})
+	}
+	grades := make(map[string]bool)
+	percents := make(map[float64]bool)
+	for i, band := range bands {
+		errs := utils.ValidateStruct(band)
+		if grades[band.Grade] {
+			errs = append(errs, utils.FieldError{Field: "grade", Message: "is already used by another band"})
+		}
+		if percents[band.MinPercent] {
+			errs = append(errs, utils.FieldError{Field: "min_percent", Message: "is already used by another band"})
+		}
+		grades[band.Grade], percents[band.MinPercent] = true, true
+		validationErrs = append(validationErrs, utils.WithIndex(errs, i)...)
+	}
+	if err := validationError(validationErrs); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	err = sqlconnect.ReplaceGradeBandsDBHandler(r.Context(), bands)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	GetGradeBandsHandler(w, r)
+}
+
+// assessmentPath reads the class and assessment IDs of the path, writing the problem when either is invalid
+func assessmentPath(w http.ResponseWriter, r *http.Request) (classID, assessmentID int, ok bool) {
+	classID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Class ID")
+		return 0, 0, false
+	}
+	assessmentID, err = strconv.Atoi(r.PathValue("assessmentId"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Assessment ID")
+		return 0, 0, false
+	}
+	return classID, assessmentID, true
+}
@@ -0,0 +1,532 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/repository/sqlconnect"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+// GetGuardiansHandler godoc
+// @Summary Retrieve all guardians
+// @Description Get a list of guardians with optional filtering and sorting.
+// @Tags guardians
+// @Produce json,application/problem+json
+// @Param first_name query string false "Filter by first name (optional)"
+// @Param last_name query string false "Filter by last name (optional)"
+// @Param relationship query string false "Filter by relationship to their students (optional)"
+// @Param phone query string false "Filter by phone number (optional)"
+// @Param email query string false "Filter by email (optional)"
+// @Param sortby query string false "Sorting (e.g., last_name:asc, first_name:asc) (optional)"
+// @Param page query int false "Page number, starting at 1 (optional)"
+// @Param limit query int false "Page size, defaults to 10 (optional)"
+// @Param If-None-Match header string false "ETag from a previous response, answered with 304 if unchanged"
+// @Param If-Modified-Since header string false "Last-Modified from a previous response"
+// @Success 200 {object} map[string]interface{} "List of guardians with metadata"
+// @Header 200 {string} ETag "Hash of the response body"
+// @Header 200 {string} Last-Modified "Latest updated_at of the listed records"
+// @Success 304 "Not Modified"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /guardians [get]
+func GetGuardiansHandler(w http.ResponseWriter, r *http.Request) {
+	var guardians []models.Guardian
+	page, limit := getPaginationParams(r)
+
+	guardians, totalGuardians, err := sqlconnect.GetGuardiansDBHandler(guardians, r, limit, page)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status   string            `json:"status"`
+		Count    int               `json:"count"`
+		Page     int               `json:"page"`
+		PageSize int               `json:"page_size"`
+		Data     []models.Guardian `json:"data"`
+	}{
+		Status:   "success",
+		Count:    totalGuardians,
+		Page:     page,
+		PageSize: limit,
+		Data:     guardians,
+	}
+
+	utils.SetLastModified(w, newestUpdate(guardians))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetOneGuardianHandler godoc
+// @Summary Get one guardian
+// @Description Retrieve details of a guardian by ID
+// @Tags guardians
+// @Produce json,application/problem+json
+// @Param id path int true "Guardian ID"
+// @Param If-None-Match header string false "ETag from a previous response, answered with 304 if unchanged"
+// @Param If-Modified-Since header string false "Last-Modified from a previous response"
+// @Success 200 {object} models.Guardian
+// @Header 200 {string} ETag "Version of the guardian, send it back in If-Match"
+// @Header 200 {string} Last-Modified "When the record was last updated"
+// @Success 304 "Not Modified"
+// @Failure 400 {object} utils.Problem "Invalid Guardian ID"
+// @Failure 404 {object} utils.Problem "Guardian not found"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /guardians/{id} [get]
+func GetOneGuardianHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Guardian ID")
+		return
+	}
+
+	guardian, err := sqlconnect.GetOneGuardianDBHandler(r.Context(), id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	utils.SetVersionETag(w, guardian.Version)
+	if guardian.UpdatedAt != nil {
+		utils.SetLastModified(w, guardian.UpdatedAt.Time)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(guardian)
+}
+
+// GetGuardianStudentsHandler godoc
+// @Summary Get the students of a guardian
+// @Description List the students a guardian is responsible for, by last name, with whether the guardian is their primary contact and may pick them up
+// @Tags guardians
+// @Produce json,application/problem+json
+// @Param id path int true "Guardian ID"
+// @Success 200 {object} map[string]interface{} "Students of the guardian"
+// @Failure 400 {object} utils.Problem "Invalid Guardian ID"
+// @Failure 404 {object} utils.Problem "Guardian not found"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /guardians/{id}/students [get]
+func GetGuardianStudentsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Guardian ID")
+		return
+	}
+
+	students, err := sqlconnect.GetGuardianStudentsDBHandler(r.Context(), id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status string                 `json:"status"`
+		Count  int                    `json:"count"`
+		Data   []models.LinkedStudent `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(students),
+		Data:   students,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// AddGuardiansHandler godoc
+// @Summary Add new guardians
+// @Description Add one or more guardians, link them to their students with PUT /students/{id}/guardians/{guardianId}. Admins only.
+// @Tags guardians
+// @Accept json
+// @Produce json,application/problem+json
+// @Param guardians body []models.Guardian true "List of guardians"
+// @Param Idempotency-Key header string false "Unique key for this request, retries with the same key get the first response replayed"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} utils.Problem "Invalid request payload"
+// @Failure 403 {object} utils.Problem "Only admins may change guardians"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /guardians [post]
+func AddGuardiansHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusInternalServerError, "Error reading request body.")
+		return
+	}
+
+	var rawGuardians []map[string]interface{}
+	err = json.Unmarshal(body, &rawGuardians)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	var newGuardians []models.Guardian
+	err = json.Unmarshal(body, &newGuardians)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	allowedFields := make(map[string]struct{})
+	for _, field := range GetFieldNames(models.Guardian{}) {
+		allowedFields[field] = struct{}{}
+	}
+	for _, guardian := range rawGuardians {
+		err := unknownFieldsError(guardian, allowedFields)
+		if err != nil {
+			utils.WriteError(w, r, err)
+			return
+		}
+	}
+
+	var validationErrs []utils.FieldError
+	for i, guardian := range newGuardians {
+		validationErrs = append(validationErrs, utils.WithIndex(utils.ValidateStruct(guardian), i)...)
+	}
+	if err := validationError(validationErrs); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	addedGuardians, err := sqlconnect.AddGuardiansDBHandler(r.Context(), newGuardians)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	response := struct {
+		Status string            `json:"status"`
+		Count  int               `json:"count"`
+		Data   []models.Guardian `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(addedGuardians),
+		Data:   addedGuardians,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// UpdateGuardianHandler godoc
+// @Summary Update a guardian
+// @Description Replace a guardian by ID. Admins only.
+// @Tags guardians
+// @Accept json
+// @Produce json,application/problem+json
+// @Param id path int true "Guardian ID"
+// @Param If-Match header string true "ETag of the guardian from GET /guardians/{id}, or *"
+// @Param guardian body models.Guardian true "Updated guardian"
+// @Success 200 {object} models.Guardian
+// @Header 200 {string} ETag "Version of the guardian, send it back in If-Match"
+// @Failure 400 {object} utils.Problem "Invalid request payload or ID"
+// @Failure 403 {object} utils.Problem "Only admins may change guardians"
+// @Failure 404 {object} utils.Problem "Guardian not found"
+// @Failure 412 {object} utils.Problem "Guardian was modified since it was read"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 428 {object} utils.Problem "Missing If-Match header"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /guardians/{id} [put]
+func UpdateGuardianHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Guardian ID")
+		return
+	}
+
+	version, err := utils.IfMatchVersion(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	var updatedGuardian models.Guardian
+	err = json.NewDecoder(r.Body).Decode(&updatedGuardian)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := validationError(utils.ValidateStruct(updatedGuardian)); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	updatedGuardianFromDB, err := sqlconnect.UpdateGuardianDBHandler(r.Context(), id, version, updatedGuardian)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	utils.SetVersionETag(w, updatedGuardianFromDB.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(updatedGuardianFromDB)
+}
+
+// PatchOneGuardianHandler godoc
+// @Summary Partially update a guardian
+// @Description Update some fields of a guardian by ID, an empty email or address removes it. Admins only.
+// @Tags guardians
+// @Accept json
+// @Produce json,application/problem+json
+// @Param id path int true "Guardian ID"
+// @Param If-Match header string true "ETag of the guardian from GET /guardians/{id}, or *"
+// @Param updates body map[string]interface{} true "Fields to update"
+// @Success 200 {object} models.Guardian
+// @Header 200 {string} ETag "Version of the guardian, send it back in If-Match"
+// @Failure 400 {object} utils.Problem "Invalid request payload or ID"
+// @Failure 403 {object} utils.Problem "Only admins may change guardians"
+// @Failure 404 {object} utils.Problem "Guardian not found"
+// @Failure 412 {object} utils.Problem "Guardian was modified since it was read"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 428 {object} utils.Problem "Missing If-Match header"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /guardians/{id} [patch]
+func PatchOneGuardianHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Guardian ID")
+		return
+	}
+
+	version, err := utils.IfMatchVersion(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	var updates map[string]interface{}
+	err = json.NewDecoder(r.Body).Decode(&updates)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := validationError(utils.ValidateFields(models.Guardian{}, updates)); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	updatedGuardian, err := sqlconnect.PatchOneGuardianDBHandler(r.Context(), id, version, updates)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	utils.SetVersionETag(w, updatedGuardian.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(updatedGuardian)
+}
+
+// DeleteOneGuardianHandler godoc
+// @Summary Delete a guardian
+// @Description Delete a guardian by ID for good, unlinking them from their students. Admins only.
+// @Tags guardians
+// @Produce application/problem+json
+// @Param id path int true "Guardian ID"
+// @Param If-Match header string true "ETag of the guardian from GET /guardians/{id}, or *"
+// @Success 204 "No Content"
+// @Failure 400 {object} utils.Problem "Invalid Guardian ID"
+// @Failure 403 {object} utils.Problem "Only admins may change guardians"
+// @Failure 404 {object} utils.Problem "Guardian not found"
+// @Failure 412 {object} utils.Problem "Guardian was modified since it was read"
+// @Failure 428 {object} utils.Problem "Missing If-Match header"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /guardians/{id} [delete]
+func DeleteOneGuardianHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Guardian ID")
+		return
+	}
+
+	version, err := utils.IfMatchVersion(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	err = sqlconnect.DeleteOneGuardianDBHandler(r.Context(), id, version)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetStudentGuardiansHandler godoc
+// @Summary Get the guardians of a student
+// @Description List the guardians of a student with their contact details, the primary contact first
+// @Tags students
+// @Produce json,application/problem+json
+// @Param id path int true "Student ID"
+// @Success 200 {object} map[string]interface{} "Guardians of the student"
+// @Failure 400 {object} utils.Problem "Invalid Student ID"
+// @Failure 404 {object} utils.Problem "Student not found"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /students/{id}/guardians [get]
+func GetStudentGuardiansHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Student ID")
+		return
+	}
+
+	guardians, err := sqlconnect.GetStudentGuardiansDBHandler(r.Context(), id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status string                  `json:"status"`
+		Count  int                     `json:"count"`
+		Data   []models.LinkedGuardian `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(guardians),
+		Data:   guardians,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// LinkGuardianHandler godoc
+// @Summary Link a guardian to a student
+// @Description Make a guardian a guardian of a student, or change whether they are the student's primary contact and may pick them up. A new primary contact replaces the previous one. Admins only.
+// @Tags students
+// @Accept json
+// @Produce json,application/problem+json
+// @Param id path int true "Student ID"
+// @Param guardianId path int true "Guardian ID"
+// @Param link body models.GuardianLink true "How the guardian stands to the student"
+// @Success 200 {object} models.StudentGuardian "Link changed"
+// @Success 201 {object} models.StudentGuardian "Guardian linked"
+// @Failure 400 {object} utils.Problem "Invalid request payload or ID"
+// @Failure 403 {object} utils.Problem "Only admins may change guardians"
+// @Failure 404 {object} utils.Problem "Student or guardian not found"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /students/{id}/guardians/{guardianId} [put]
+func LinkGuardianHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	studentID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Student ID")
+		return
+	}
+	guardianID, err := strconv.Atoi(r.PathValue("guardianId"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Guardian ID")
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var link models.GuardianLink
+	err = decoder.Decode(&link)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	linked, created, err := sqlconnect.LinkGuardianDBHandler(r.Context(), studentID, guardianID, link)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(linked)
+}
+
+// UnlinkGuardianHandler godoc
+// @Summary Unlink a guardian from a student
+// @Description Remove a guardian from the guardians of a student, the guardian is kept. Admins only.
+// @Tags students
+// @Produce application/problem+json
+// @Param id path int true "Student ID"
+// @Param guardianId path int true "Guardian ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} utils.Problem "Invalid Student or Guardian ID"
+// @Failure 403 {object} utils.Problem "Only admins may change guardians"
+// @Failure 404 {object} utils.Problem "Guardian is not a guardian of the student"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /students/{id}/guardians/{guardianId} [delete]
+func UnlinkGuardianHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	studentID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Student ID")
+		return
+	}
+	guardianID, err := strconv.Atoi(r.PathValue("guardianId"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Guardian ID")
+		return
+	}
+
+	err = sqlconnect.UnlinkGuardianDBHandler(r.Context(), studentID, guardianID)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -1,31 +1,36 @@
 package handlers
 
 import (
-	"errors"
+	"net/http"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
 	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
 )
 
-func CheckBlankFields(value interface{}) error {
-	// if teacher.FirstName == "" || teacher.LastName == "" || teacher.Email == "" || teacher.Class == "" || teacher.Subject == "" {
-	// 	http.Error(w, "All fields are required", http.StatusBadRequest)
-	// 	return true
-	// }
+// validationError wraps field errors in a validation error, or returns nil when there are none
+func validationError(errs []utils.FieldError) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return utils.ValidationError("Validation failed", errs...)
+}
 
-	val := reflect.ValueOf(value)
-	for i := 0; i < val.NumField(); i++ {
-		field := val.Field(i)
-		if field.Kind() == reflect.String && field.String() == "" {
-			// fmt.Println("field.Kind():", field.Kind())
-			// fmt.Println("reflect.String:", reflect.String)
-			// fmt.Println("field.String():", field.String())
-			// http.Error(w, "All fields are required", http.StatusBadRequest)
-			return utils.ErrorHandler(errors.New("all fields are required"), "all fields are required")
-		}
+// showDeleted reports whether soft-deleted records were asked for with ?include_deleted=true,
+// which only admins may do
+func showDeleted(r *http.Request) (bool, error) {
+	if r.URL.Query().Get("include_deleted") != "true" {
+		return false, nil
 	}
-	return nil
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 func GetFieldNames(model interface{}) []string {
@@ -39,3 +44,78 @@ func GetFieldNames(model interface{}) []string {
 	}
 	return fields
 }
+
+// newestUpdate returns the latest UpdatedAt in a slice of models, for the Last-Modified of list responses
+func newestUpdate(records interface{}) time.Time {
+	var newest time.Time
+	val := reflect.ValueOf(records)
+	for i := 0; i < val.Len(); i++ {
+		updatedAt, ok := val.Index(i).FieldByName("UpdatedAt").Interface().(*models.Timestamp)
+		if ok && updatedAt != nil && updatedAt.After(newest) {
+			newest = updatedAt.Time
+		}
+	}
+	return newest
+}
+
+const timeParamMessage = "must be an RFC 3339 time or a date like 2026-01-01"
+
+// parseTimeParam reads an optional time query parameter, either RFC 3339 or a plain date
+// meaning midnight UTC. The zero time means the parameter wasn't given.
+func parseTimeParam(r *http.Request, name string) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Parse(time.DateOnly, value)
+	}
+	return t, nil
+}
+
+// parseDateParam reads an optional date query parameter like 2026-01-01, "" means it wasn't given
+func parseDateParam(r *http.Request, name string) (string, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return "", nil
+	}
+	_, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return "", utils.ValidationError("Invalid "+name, utils.FieldError{Field: name, Message: "must be a date like 2026-01-01"})
+	}
+	return value, nil
+}
+
+// unknownFieldsError rejects an item of a payload that has fields outside of allowedFields
+func unknownFieldsError(item map[string]interface{}, allowedFields map[string]struct{}) error {
+	for key := range item {
+		if _, ok := allowedFields[key]; !ok {
+			return utils.BadRequestError("Unacceptable field found in request. Only use allowed fields")
+		}
+	}
+	return nil
+}
+
+// updateID is the ID of an item of a bulk patch, 0 when it has none
+func updateID(update map[string]interface{}) int {
+	idStr, _ := update["id"].(string)
+	id, _ := strconv.Atoi(idStr)
+	return id
+}
+
+// naturalKeys reads the columns an upsert matches existing records on from ?key=, email by default
+func naturalKeys(r *http.Request, model interface{}) ([]string, error) {
+	param := r.URL.Query().Get("key")
+	if param == "" {
+		return []string{"email"}, nil
+	}
+
+	keys := strings.Split(param, ",")
+	for _, key := range keys {
+		if key == "id" || key == "version" || !utils.IsModelColumn(key, model) {
+			return nil, utils.ValidationError("Invalid key", utils.FieldError{Field: "key", Message: key + " is not a column that can identify a record"})
+		}
+	}
+	return keys, nil
+}
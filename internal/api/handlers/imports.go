@@ -0,0 +1,462 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/repository/sqlconnect"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	csvContentType  = "text/csv"
+	xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+	// importMaxBytes limits the size of an uploaded file
+	importMaxBytes = 10 << 20
+	// files with more rows than importSyncRows are imported in the background
+	importSyncRows = 500
+)
+
+// importRecord is a row of an imported file, keyed by column
+type importRecord struct {
+	Row    int
+	Fields map[string]string
+}
+
+// readImportFile reads the rows of a CSV or Excel file sent as the request body, or as the file field
+// of a multipart form. The header row is matched to the columns of model, or mapped with ?columns=.
+func readImportFile(w http.ResponseWriter, r *http.Request, model interface{}) ([]importRecord, error) {
+	allowed := importColumns(model)
+	mapping, err := columnMapping(r, allowed)
+	if err != nil {
+		return nil, err
+	}
+
+	file, contentType, err := importFile(w, r)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var rows [][]string
+	switch contentType {
+	case csvContentType:
+		reader := csv.NewReader(file)
+		reader.FieldsPerRecord = -1
+		rows, err = reader.ReadAll()
+		if err != nil {
+			return nil, utils.BadRequestError("Invalid CSV file: " + err.Error())
+		}
+	case xlsxContentType:
+		workbook, err := excelize.OpenReader(file)
+		if err != nil {
+			return nil, utils.BadRequestError("Invalid Excel file")
+		}
+		defer workbook.Close()
+		// only the first sheet is imported
+		rows, err = workbook.GetRows(workbook.GetSheetName(0))
+		if err != nil {
+			return nil, utils.BadRequestError("Invalid Excel file")
+		}
+	}
+	if len(rows) == 0 {
+		return nil, utils.ValidationError("The file is empty")
+	}
+
+	columns, err := headerColumns(rows[0], mapping, allowed)
+	if err != nil {
+		return nil, err
+	}
+
+	// cells get the same sanitizing as JSON bodies in XSSMiddleware
+	policy := bluemonday.UGCPolicy()
+	var records []importRecord
+	for i, row := range rows[1:] {
+		record := importRecord{Row: i + 2, Fields: make(map[string]string)}
+		for j, cell := range row {
+			cell = strings.TrimSpace(cell)
+			if j >= len(columns) || columns[j] == "" || cell == "" {
+				continue
+			}
+			record.Fields[columns[j]] = policy.Sanitize(cell)
+		}
+		// skip blank rows
+		if len(record.Fields) > 0 {
+			records = append(records, record)
+		}
+	}
+	if len(records) == 0 {
+		return nil, utils.ValidationError("The file has no rows to import")
+	}
+	return records, nil
+}
+
+// importFile returns the uploaded file and whether it is CSV or Excel
+func importFile(w http.ResponseWriter, r *http.Request) (io.ReadCloser, string, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, importMaxBytes)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case csvContentType, xlsxContentType:
+		return r.Body, mediaType, nil
+	case "multipart/form-data":
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			return nil, "", utils.BadRequestError("The form must have a file field with the CSV or Excel file")
+		}
+		switch strings.ToLower(filepath.Ext(header.Filename)) {
+		case ".csv":
+			return file, csvContentType, nil
+		case ".xlsx":
+			return file, xlsxContentType, nil
+		}
+		file.Close()
+		return nil, "", utils.UnsupportedMediaTypeError("Only .csv and .xlsx files can be imported")
+	}
+	return nil, "", utils.UnsupportedMediaTypeError("Please upload a text/csv or .xlsx file, or a multipart form with one")
+}
+
+// importColumns are the columns of model a file may set
+func importColumns(model interface{}) map[string]bool {
+	allowed := make(map[string]bool)
+	for _, field := range GetFieldNames(model) {
+		switch field {
+		case "id", "class_id", "version", "updated_at", "deleted_at":
+		default:
+			allowed[field] = true
+		}
+	}
+	return allowed
+}
+
+// columnMapping reads ?columns=Header:column,... which maps headers of the file to columns,
+// a column of - skips that header
+func columnMapping(r *http.Request, allowed map[string]bool) (map[string]string, error) {
+	mapping := make(map[string]string)
+	param := r.URL.Query().Get("columns")
+	if param == "" {
+		return mapping, nil
+	}
+
+	for _, pair := range strings.Split(param, ",") {
+		header, column, ok := strings.Cut(pair, ":")
+		column = strings.TrimSpace(column)
+		if !ok || (column != "-" && !allowed[column]) {
+			return nil, utils.ValidationError("Invalid columns", utils.FieldError{Field: "columns", Message: fmt.Sprintf("%q must map a header to one of the columns of the record, or to -", pair)})
+		}
+		mapping[normalizeHeader(header)] = column
+	}
+	return mapping, nil
+}
+
+// headerColumns returns the column each position of the header row is stored in, blank for skipped ones
+func headerColumns(header []string, mapping map[string]string, allowed map[string]bool) ([]string, error) {
+	columns := make([]string, len(header))
+	seen := make(map[string]bool)
+	headerRow := 1
+	var errs []utils.FieldError
+	for i, cell := range header {
+		name := normalizeHeader(cell)
+		column, ok := mapping[name]
+		if !ok {
+			column = name
+		}
+		if column == "-" || column == "" {
+			continue
+		}
+		if !allowed[column] {
+			errs = append(errs, utils.FieldError{Row: &headerRow, Field: cell, Message: "unknown column, map it to a column or skip it with ?columns=" + cell + ":-"})
+			continue
+		}
+		if seen[column] {
+			errs = append(errs, utils.FieldError{Row: &headerRow, Field: cell, Message: "more than one header is mapped to " + column})
+			continue
+		}
+		seen[column] = true
+		columns[i] = column
+	}
+	if len(errs) > 0 {
+		return nil, utils.ValidationError("Invalid header row", errs...)
+	}
+	return columns, nil
+}
+
+// normalizeHeader lets headers like "First Name" match the first_name column
+func normalizeHeader(header string) string {
+	header = strings.ToLower(strings.TrimSpace(header))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(header)
+}
+
+// decodeImportRecords fills dest, a pointer to a slice of models, from records and validates every row
+func decodeImportRecords(records []importRecord, dest interface{}) error {
+	fields := make([]map[string]string, len(records))
+	for i, record := range records {
+		fields[i] = record.Fields
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return utils.ErrorHandler(err, "Error reading file")
+	}
+	err = json.Unmarshal(data, dest)
+	if err != nil {
+		return utils.ErrorHandler(err, "Error reading file")
+	}
+
+	var errs []utils.FieldError
+	items := reflect.ValueOf(dest).Elem()
+	for i := 0; i < items.Len(); i++ {
+		errs = append(errs, utils.WithRow(utils.ValidateStruct(items.Index(i).Interface()), records[i].Row)...)
+	}
+	return validationError(errs)
+}
+
+// importUpsert stores the records of an import, or only works out what would change in a dry run,
+// and returns the IDs of the records in file order
+type importUpsert func(ctx context.Context) ([]int, models.UpsertResult, error)
+
+// runImport records an import job and runs it. Small files are imported before responding,
+// large ones in the background while the client polls GET /imports/{id}.
+func runImport(w http.ResponseWriter, r *http.Request, job models.ImportJob, records []importRecord, upsert importUpsert) {
+	id, err := sqlconnect.AddImportJobDBHandler(r.Context(), job)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	job.ID = id
+	job.Status = models.ImportRunning
+
+	if job.TotalRows > importSyncRows {
+		// keep the exec and request id for the audit log, but outlive the request
+		go func(ctx context.Context) {
+			defer func() {
+				if p := recover(); p != nil {
+					log.Printf("Import job %d panicked: %v\n", job.ID, p)
+					failImport(ctx, job, "The import stopped unexpectedly")
+				}
+			}()
+			finishImport(ctx, job, records, upsert)
+		}(context.WithoutCancel(r.Context()))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "/imports/"+strconv.Itoa(job.ID))
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(job)
+		return
+	}
+
+	job, err = finishImport(r.Context(), job, records, upsert)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(job)
+}
+
+func finishImport(ctx context.Context, job models.ImportJob, records []importRecord, upsert importUpsert) (models.ImportJob, error) {
+	ids, result, err := upsert(ctx)
+	if err != nil {
+		job.Status = models.ImportFailed
+		job.Error = err.Error()
+	} else {
+		job.Status = models.ImportCompleted
+		job.UpsertResult = result
+		for i, record := range records {
+			row := models.ImportRow{Row: record.Row, Action: result.Actions[i]}
+			// records created in a dry run were never kept
+			if !job.DryRun || row.Action != models.UpsertCreated {
+				row.ID = ids[i]
+			}
+			job.Rows = append(job.Rows, row)
+		}
+	}
+	saveErr := sqlconnect.FinishImportJobDBHandler(ctx, job)
+	if saveErr != nil {
+		log.Printf("Error saving the outcome of import job %d: %v\n", job.ID, saveErr)
+		// don't leave the job running forever
+		failImport(ctx, job, "The outcome of the import could not be saved")
+	}
+	return job, err
+}
+
+// failImport marks job failed with message, without its rows, when the import stopped or its outcome
+// could not be stored
+func failImport(ctx context.Context, job models.ImportJob, message string) {
+	job.Status = models.ImportFailed
+	job.Error = message
+	job.UpsertResult = models.UpsertResult{}
+	job.Rows = nil
+	err := sqlconnect.FinishImportJobDBHandler(ctx, job)
+	if err != nil {
+		log.Printf("Error marking import job %d failed: %v\n", job.ID, err)
+	}
+}
+
+// importRequest checks that an admin is importing and reads the natural key and dry run options
+func importRequest(r *http.Request, model interface{}) ([]string, bool, error) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		return nil, false, err
+	}
+	keys, err := naturalKeys(r, model)
+	if err != nil {
+		return nil, false, err
+	}
+	return keys, r.URL.Query().Get("dry_run") == "true", nil
+}
+
+// ImportStudentsHandler godoc
+// @Summary Import students from a CSV or Excel file
+// @Description Insert or update the students in a CSV or .xlsx file, matched by email or by the columns in key. The first row names the columns, headers like "First Name" match first_name and others can be mapped with columns. Every row is validated first and the file is imported all or nothing. Files of more than 500 rows are imported in the background, poll the returned job for its status. Admins only.
+// @Tags students
+// @Accept text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,multipart/form-data
+// @Produce json,application/problem+json
+// @Param file formData file false "CSV or .xlsx file, when sent as a multipart form"
+// @Param columns query string false "Comma separated Header:column pairs, e.g. Given Name:first_name,Notes:- to skip a header (optional)"
+// @Param key query string false "Comma separated columns that identify a student, defaults to email (optional)"
+// @Param dry_run query bool false "Report what the import would change without changing anything (optional)"
+// @Param Idempotency-Key header string false "Unique key for this request, retries with the same key get the first response replayed"
+// @Success 200 {object} models.ImportJob "The finished import"
+// @Success 202 {object} models.ImportJob "The import is running in the background"
+// @Failure 400 {object} utils.Problem "Unreadable file"
+// @Failure 403 {object} utils.Problem "Only admins may import"
+// @Failure 409 {object} utils.Problem "Duplicate email, or a request with this Idempotency-Key still in progress"
+// @Failure 415 {object} utils.Problem "Not a CSV or Excel file"
+// @Failure 422 {object} utils.Problem "Invalid header or rows, with the row of each error"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /students/import [post]
+func ImportStudentsHandler(w http.ResponseWriter, r *http.Request) {
+	keys, dryRun, err := importRequest(r, models.Student{})
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	records, err := readImportFile(w, r, models.Student{})
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	var students []models.Student
+	err = decodeImportRecords(records, &students)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	job := models.ImportJob{Resource: "students", DryRun: dryRun, TotalRows: len(students)}
+	runImport(w, r, job, records, func(ctx context.Context) ([]int, models.UpsertResult, error) {
+		saved, result, err := sqlconnect.UpsertStudentsDBHandler(ctx, students, keys, dryRun)
+		ids := make([]int, len(saved))
+		for i, student := range saved {
+			ids[i] = student.ID
+		}
+		return ids, result, err
+	})
+}
+
+// ImportTeachersHandler godoc
+// @Summary Import teachers from a CSV or Excel file
+// @Description Insert or update the teachers in a CSV or .xlsx file, matched by email or by the columns in key. The first row names the columns, headers like "First Name" match first_name and others can be mapped with columns. Every row is validated first and the file is imported all or nothing. Files of more than 500 rows are imported in the background, poll the returned job for its status. Admins only.
+// @Tags teachers
+// @Accept text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,multipart/form-data
+// @Produce json,application/problem+json
+// @Param file formData file false "CSV or .xlsx file, when sent as a multipart form"
+// @Param columns query string false "Comma separated Header:column pairs, e.g. Given Name:first_name,Notes:- to skip a header (optional)"
+// @Param key query string false "Comma separated columns that identify a teacher, defaults to email (optional)"
+// @Param dry_run query bool false "Report what the import would change without changing anything (optional)"
+// @Param Idempotency-Key header string false "Unique key for this request, retries with the same key get the first response replayed"
+// @Success 200 {object} models.ImportJob "The finished import"
+// @Success 202 {object} models.ImportJob "The import is running in the background"
+// @Failure 400 {object} utils.Problem "Unreadable file"
+// @Failure 403 {object} utils.Problem "Only admins may import"
+// @Failure 409 {object} utils.Problem "Duplicate email, or a request with this Idempotency-Key still in progress"
+// @Failure 415 {object} utils.Problem "Not a CSV or Excel file"
+// @Failure 422 {object} utils.Problem "Invalid header or rows, with the row of each error"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /teachers/import [post]
+func ImportTeachersHandler(w http.ResponseWriter, r *http.Request) {
+	keys, dryRun, err := importRequest(r, models.Teacher{})
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	records, err := readImportFile(w, r, models.Teacher{})
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	var teachers []models.Teacher
+	err = decodeImportRecords(records, &teachers)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	job := models.ImportJob{Resource: "teachers", DryRun: dryRun, TotalRows: len(teachers)}
+	runImport(w, r, job, records, func(ctx context.Context) ([]int, models.UpsertResult, error) {
+		saved, result, err := sqlconnect.UpsertTeachersDBHandler(ctx, teachers, keys, dryRun)
+		ids := make([]int, len(saved))
+		for i, teacher := range saved {
+			ids[i] = teacher.ID
+		}
+		return ids, result, err
+	})
+}
+
+// GetImportJobHandler godoc
+// @Summary Get an import job
+// @Description Get the status of an import and, once it has finished, what was done with every row. Admins only.
+// @Tags imports
+// @Produce json,application/problem+json
+// @Param id path int true "Import job ID"
+// @Success 200 {object} models.ImportJob
+// @Failure 400 {object} utils.Problem "Invalid import job ID"
+// @Failure 403 {object} utils.Problem "Only admins may read imports"
+// @Failure 404 {object} utils.Problem "Import job not found"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /imports/{id} [get]
+func GetImportJobHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid import job ID")
+		return
+	}
+
+	job, err := sqlconnect.GetImportJobDBHandler(r.Context(), id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(job)
+}
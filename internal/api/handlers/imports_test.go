@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+var teacherColumns = importColumns(models.Teacher{})
+
+// invalidFields returns the fields of a validation error, or nil for any other error
+func invalidFields(err error) []string {
+	var appErr *utils.AppError
+	if !errors.As(err, &appErr) || appErr.Status() != http.StatusUnprocessableEntity {
+		return nil
+	}
+	fields := []string{}
+	for _, field := range appErr.Fields {
+		fields = append(fields, field.Field)
+	}
+	return fields
+}
+
+func TestNormalizeHeader(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"first_name", "first_name"},
+		{"First Name", "first_name"},
+		{"  EMAIL ", "email"},
+		{"last-name", "last_name"},
+		{"Date of Birth", "date_of_birth"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeHeader(tt.in); got != tt.want {
+			t.Errorf("normalizeHeader(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestColumnMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"none", "", map[string]string{}, false},
+		{"one", "Given Name:first_name", map[string]string{"given_name": "first_name"}, false},
+		{"several", "Given Name:first_name,Surname: last_name", map[string]string{"given_name": "first_name", "surname": "last_name"}, false},
+		{"skip", "Notes:-", map[string]string{"notes": "-"}, false},
+		{"unknown column", "Notes:notes", nil, true},
+		{"id can't be imported", "Number:id", nil, true},
+		{"no colon", "first_name", nil, true},
+		{"empty column", "Notes:", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/students/import?columns="+url.QueryEscape(tt.columns), nil)
+			got, err := columnMapping(r, teacherColumns)
+			if tt.wantErr {
+				if fields := invalidFields(err); !reflect.DeepEqual(fields, []string{"columns"}) {
+					t.Errorf("columnMapping() error = %v, want a validation error of columns", err)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("columnMapping() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeaderColumns(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     []string
+		mapping    map[string]string
+		want       []string
+		wantFields []string
+	}{
+		{"column names", []string{"first_name", "email"}, nil, []string{"first_name", "email"}, nil},
+		{"friendly headers", []string{"First Name", "Last-Name", " Email "}, nil, []string{"first_name", "last_name", "email"}, nil},
+		{"mapped", []string{"Given Name", "Email"}, map[string]string{"given_name": "first_name"}, []string{"first_name", "email"}, nil},
+		{"skipped", []string{"Email", "Notes"}, map[string]string{"notes": "-"}, []string{"email", ""}, nil},
+		{"blank header", []string{"Email", ""}, nil, []string{"email", ""}, nil},
+		{"unknown", []string{"Email", "Notes", "Phone"}, nil, nil, []string{"Notes", "Phone"}},
+		{"version can't be imported", []string{"Email", "Version"}, nil, nil, []string{"Version"}},
+		{"duplicate", []string{"Email", "E-mail"}, map[string]string{"e_mail": "email"}, nil, []string{"E-mail"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := headerColumns(tt.header, tt.mapping, teacherColumns)
+			if tt.wantFields != nil {
+				if fields := invalidFields(err); !reflect.DeepEqual(fields, tt.wantFields) {
+					t.Errorf("headerColumns() error = %v, want a validation error of %v", err, tt.wantFields)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("headerColumns() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
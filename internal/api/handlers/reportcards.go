@@ -0,0 +1,466 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/repository/sqlconnect"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+// GetReportCardHandler godoc
+// @Summary Get the report card of a student
+// @Description Put together the report card of a student for a term: their averages and grades by subject with who taught it, their whole-day attendance in their class during the term and the comments of their teachers. It is rendered as a PDF with ?format=pdf or Accept: application/pdf.
+// @Tags reportcards
+// @Produce json,application/pdf,application/problem+json
+// @Param id path int true "Student ID"
+// @Param term query string false "Term, e.g. Term 1, defaults to the current term (optional)"
+// @Param format query string false "json or pdf, overrides the Accept header (optional)"
+// @Success 200 {object} models.ReportCard
+// @Failure 400 {object} utils.Problem "Invalid Student ID"
+// @Failure 404 {object} utils.Problem "Student not found"
+// @Failure 422 {object} utils.Problem "Missing or unknown term or invalid format"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /students/{id}/reportcard [get]
+func GetReportCardHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Student ID")
+		return
+	}
+
+	format, err := reportCardFormat(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	term, err := sqlconnect.TermOrCurrentDBHandler(r.Context(), r.URL.Query().Get("term"))
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	card, err := sqlconnect.GetReportCardDBHandler(r.Context(), id, term)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	card.GeneratedAt = &models.Timestamp{Time: time.Now().UTC()}
+	writeReportCard(w, r, format, card, nil)
+}
+
+// GetStoredReportCardHandler godoc
+// @Summary Get a stored report card
+// @Description Get a report card stored by a report card job, as it was when it was generated. It is the stored PDF with ?format=pdf or Accept: application/pdf.
+// @Tags reportcards
+// @Produce json,application/pdf,application/problem+json
+// @Param id path int true "Report card ID"
+// @Param format query string false "json or pdf, overrides the Accept header (optional)"
+// @Success 200 {object} models.ReportCard
+// @Failure 400 {object} utils.Problem "Invalid report card ID"
+// @Failure 404 {object} utils.Problem "Report card not found"
+// @Failure 422 {object} utils.Problem "Invalid format"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /reportcards/{id} [get]
+func GetStoredReportCardHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid report card ID")
+		return
+	}
+
+	format, err := reportCardFormat(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	card, pdf, err := sqlconnect.GetStoredReportCardDBHandler(r.Context(), id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	writeReportCard(w, r, format, card, pdf)
+}
+
+// GetReportCommentsHandler godoc
+// @Summary List the comments on a student
+// @Description List what teachers wrote about a student for their report cards, by term and subject
+// @Tags reportcards
+// @Produce json,application/problem+json
+// @Param id path int true "Student ID"
+// @Param term query string false "Only the comments of this term (optional)"
+// @Success 200 {object} map[string]interface{} "Comments on the student"
+// @Failure 400 {object} utils.Problem "Invalid Student ID"
+// @Failure 404 {object} utils.Problem "Student not found"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /students/{id}/comments [get]
+func GetReportCommentsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Student ID")
+		return
+	}
+
+	comments, err := sqlconnect.GetReportCommentsDBHandler(r.Context(), id, r.URL.Query().Get("term"))
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status string                 `json:"status"`
+		Count  int                    `json:"count"`
+		Data   []models.ReportComment `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(comments),
+		Data:   comments,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// SaveReportCommentsHandler godoc
+// @Summary Comment on a student
+// @Description Write the comments of teachers on a student for their report card, replacing the comment of the same term and subject. A subject is commented on by a teacher assigned to it in the student's class for the term, the general comment without a subject by the homeroom teacher. The batch is all or nothing.
+// @Tags reportcards
+// @Accept json
+// @Produce json,application/problem+json
+// @Param id path int true "Student ID"
+// @Param comments body []models.ReportComment true "Comments by term and subject"
+// @Param Idempotency-Key header string false "Unique key for this request, retries with the same key get the first response replayed"
+// @Success 200 {object} map[string]interface{} "Created, updated and unchanged counts with the comments"
+// @Failure 400 {object} utils.Problem "Invalid request payload or ID"
+// @Failure 404 {object} utils.Problem "Student not found"
+// @Failure 422 {object} utils.Problem "Validation failed, e.g. a teacher who doesn't teach the subject"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /students/{id}/comments [put]
+func SaveReportCommentsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Student ID")
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var comments []models.ReportComment
+	err = decoder.Decode(&comments)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	var validationErrs []utils.FieldError
+	seen := make(map[[2]string]bool)
+	for i, comment := range comments {
+		errs := utils.ValidateStruct(comment)
+		key := [2]string{comment.Term, comment.Subject}
+		if seen[key] {
+			errs = append(errs, utils.FieldError{Field: "subject", Message: "must not be commented on twice in the same term"})
+		}
+		seen[key] = true
+		validationErrs = append(validationErrs, utils.WithIndex(errs, i)...)
+	}
+	if err := validationError(validationErrs); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	saved, result, err := sqlconnect.SaveReportCommentsDBHandler(r.Context(), id, comments)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status string `json:"status"`
+		models.UpsertResult
+		Data []models.ReportComment `json:"data"`
+	}{
+		Status:       "success",
+		UpsertResult: result,
+		Data:         saved,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GenerateReportCardsHandler godoc
+// @Summary Generate the report cards of a class
+// @Description Start a job that generates the report card of every student of a class for a term and emails it to the student as a PDF, or stores it to be fetched from /reportcards/{id}. Storing a report card again replaces the student's earlier one for the term. Poll the returned job for its outcome. Admins only.
+// @Tags reportcards
+// @Produce json,application/problem+json
+// @Param id path int true "Class ID"
+// @Param term query string false "Term, e.g. Term 1, defaults to the current term (optional)"
+// @Param delivery query string false "email or store, defaults to store (optional)"
+// @Success 202 {object} models.ReportCardJob
+// @Header 202 {string} Location "URL of the job to poll"
+// @Failure 400 {object} utils.Problem "Invalid Class ID"
+// @Failure 403 {object} utils.Problem "Only admins may generate report cards"
+// @Failure 404 {object} utils.Problem "Class not found"
+// @Failure 422 {object} utils.Problem "Missing term or invalid delivery"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /classes/{id}/reportcards [post]
+func GenerateReportCardsHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Class ID")
+		return
+	}
+
+	job := models.ReportCardJob{ClassID: id, Term: r.URL.Query().Get("term"), Delivery: r.URL.Query().Get("delivery")}
+	if job.Delivery == "" {
+		job.Delivery = models.ReportCardStore
+	}
+	if job.Delivery != models.ReportCardEmail && job.Delivery != models.ReportCardStore {
+		utils.WriteError(w, r, utils.ValidationError("Invalid delivery", utils.FieldError{Field: "delivery", Message: "must be one of email or store"}))
+		return
+	}
+	job.Term, err = sqlconnect.TermOrCurrentDBHandler(r.Context(), job.Term)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	students, err := sqlconnect.GetClassStudentsDBHandler(r.Context(), id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	job.Total = len(students)
+	job.ID, err = sqlconnect.AddReportCardJobDBHandler(r.Context(), job)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	job.Status = models.ImportRunning
+
+	// keep the exec and request id for the audit log, but outlive the request
+	go runReportCardJob(context.WithoutCancel(r.Context()), job, students)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/reportcards/jobs/"+strconv.Itoa(job.ID))
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// runReportCardJob generates and delivers the report card of every student, a student whose report card
+// fails is recorded in the results and the others are still delivered
+func runReportCardJob(ctx context.Context, job models.ReportCardJob, students []models.Student) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("Report card job %d panicked: %v\n", job.ID, p)
+			failReportCardJob(ctx, job, "The report card job stopped unexpectedly")
+		}
+	}()
+
+	for _, student := range students {
+		result := models.ReportCardResult{StudentID: student.ID}
+		err := func() error {
+			card, err := sqlconnect.GetReportCardDBHandler(ctx, student.ID, job.Term)
+			if err != nil {
+				return err
+			}
+			card.GeneratedAt = &models.Timestamp{Time: time.Now().UTC()}
+			var pdf bytes.Buffer
+			err = writeReportCardPDF(&pdf, card)
+			if err != nil {
+				return utils.ErrorHandler(err, "Error rendering report card")
+			}
+
+			if job.Delivery == models.ReportCardEmail {
+				return sqlconnect.EmailReportCard(card, pdf.Bytes(), reportCardFilename(card))
+			}
+			result.ReportCardID, err = sqlconnect.StoreReportCardDBHandler(ctx, job.ID, card, pdf.Bytes())
+			return err
+		}()
+		if err != nil {
+			result.Error = err.Error()
+			job.Failed++
+		} else {
+			job.Delivered++
+		}
+		job.Results = append(job.Results, result)
+	}
+
+	job.Status = models.ImportCompleted
+	if job.Total > 0 && job.Failed == job.Total {
+		job.Status = models.ImportFailed
+		job.Error = "No report card could be delivered"
+	}
+	err := sqlconnect.FinishReportCardJobDBHandler(ctx, job)
+	if err != nil {
+		log.Printf("Error saving the outcome of report card job %d: %v\n", job.ID, err)
+		// don't leave the job running forever
+		failReportCardJob(ctx, job, "The outcome of the report card job could not be saved")
+	}
+}
+
+// failReportCardJob marks job failed with message, keeping the counts of what was delivered, when the job
+// stopped or its outcome could not be stored
+func failReportCardJob(ctx context.Context, job models.ReportCardJob, message string) {
+	job.Status = models.ImportFailed
+	job.Error = message
+	job.Results = nil
+	err := sqlconnect.FinishReportCardJobDBHandler(ctx, job)
+	if err != nil {
+		log.Printf("Error marking report card job %d failed: %v\n", job.ID, err)
+	}
+}
+
+// GetReportCardJobHandler godoc
+// @Summary Get a report card job
+// @Description Get the status of a report card job and, once it has finished, the outcome for every student. Admins only.
+// @Tags reportcards
+// @Produce json,application/problem+json
+// @Param id path int true "Report card job ID"
+// @Success 200 {object} models.ReportCardJob
+// @Failure 400 {object} utils.Problem "Invalid report card job ID"
+// @Failure 403 {object} utils.Problem "Only admins may read report card jobs"
+// @Failure 404 {object} utils.Problem "Report card job not found"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /reportcards/jobs/{id} [get]
+func GetReportCardJobHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid report card job ID")
+		return
+	}
+
+	job, err := sqlconnect.GetReportCardJobDBHandler(r.Context(), id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(job)
+}
+
+// reportCardFormat returns utils.ExportPDF when a report card was asked for as a PDF, "" for JSON
+func reportCardFormat(r *http.Request) (string, error) {
+	format, err := utils.ExportFormat(r)
+	if err != nil || (format != "" && format != utils.ExportPDF) {
+		return "", utils.ValidationError("Invalid format", utils.FieldError{Field: "format", Message: "must be one of json or pdf"})
+	}
+	return format, nil
+}
+
+// writeReportCard writes a report card as JSON or as a PDF, rendering it unless pdf is given
+func writeReportCard(w http.ResponseWriter, r *http.Request, format string, card models.ReportCard, pdf []byte) {
+	if format != utils.ExportPDF {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(card)
+		return
+	}
+
+	if pdf == nil {
+		var buf bytes.Buffer
+		err := writeReportCardPDF(&buf, card)
+		if err != nil {
+			utils.WriteError(w, r, utils.ErrorHandler(err, "Error rendering report card"))
+			return
+		}
+		pdf = buf.Bytes()
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", reportCardFilename(card)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(pdf)
+}
+
+func reportCardFilename(card models.ReportCard) string {
+	term := strings.ToLower(strings.Join(strings.Fields(card.Term), "-"))
+	return fmt.Sprintf("reportcard-%d-%s.pdf", card.StudentID, term)
+}
+
+// writeReportCardPDF lays a report card out as a PDF
+func writeReportCardPDF(w io.Writer, card models.ReportCard) error {
+	lines := []string{
+		fmt.Sprintf("Class: %s, %s", card.Class, card.AcademicYear),
+		"Term: " + card.Term,
+		"Overall average: " + formatPercent(card.Average, card.Grade),
+	}
+	if card.GeneratedAt != nil {
+		lines = append(lines, "Issued: "+card.GeneratedAt.Format(time.DateOnly))
+	}
+
+	grades := utils.PDFSection{Title: "Grades", Header: []string{"Subject", "Teachers", "Assessments", "Average", "Grade"}}
+	for _, subject := range card.Subjects {
+		grades.Rows = append(grades.Rows, []string{subject.Subject, strings.Join(subject.Teachers, ", "), strconv.Itoa(subject.Assessments),
+			formatPercent(subject.Average, ""), subject.Grade})
+	}
+	if len(card.Subjects) == 0 {
+		grades.Lines = []string{"No grades for this term."}
+	}
+
+	attendance := card.Attendance
+	rate := "-"
+	if attendance.Rate != nil {
+		rate = strconv.FormatFloat(*attendance.Rate*100, 'f', 1, 64) + "%"
+	}
+	attendanceSection := utils.PDFSection{
+		Title: "Attendance",
+		Lines: []string{fmt.Sprintf("%d days recorded: %d present, %d late, %d absent, %d excused. Attendance rate: %s",
+			attendance.Total, attendance.Present, attendance.Late, attendance.Absent, attendance.Excused, rate)},
+	}
+
+	comments := utils.PDFSection{Title: "Comments"}
+	for _, comment := range card.Comments {
+		subject := comment.Subject
+		if subject == "" {
+			subject = "General"
+		}
+		comments.Lines = append(comments.Lines, fmt.Sprintf("%s (%s): %s", subject, comment.TeacherName, comment.Comment))
+	}
+	if len(card.Comments) == 0 {
+		comments.Lines = []string{"No comments for this term."}
+	}
+
+	title := fmt.Sprintf("Report card of %s %s", card.FirstName, card.LastName)
+	return utils.WritePDFReport(w, title, lines, []utils.PDFSection{grades, attendanceSection, comments})
+}
+
+// formatPercent renders an average like 84.50%, followed by its grade when there is one
+func formatPercent(percent *float64, grade string) string {
+	if percent == nil {
+		return "-"
+	}
+	formatted := strconv.FormatFloat(*percent, 'f', 2, 64) + "%"
+	if grade != "" {
+		formatted += " (" + grade + ")"
+	}
+	return formatted
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/repository/sqlconnect"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+// rolloverKeyPattern matches what a rollover mapping maps from and to, a grade or a class name
+var rolloverKeyPattern = regexp.MustCompile(`^[0-9]{1,2}[A-Z]?$`)
+
+// RolloverHandler godoc
+// @Summary Roll an academic year over
+// @Description Move the students of an academic year on to the classes of the next one. Every class moves up a grade keeping its section and grade 12 graduates, unless mapping says otherwise for a class, e.g. {"10A": "11B"}, or a whole grade, e.g. {"12": "graduated"} or {"9": "10"}. Held back students stay in a class of the same name, or the one given, in the next year. The enrollments of the year are ended with their outcome and graduates keep their last class. Missing classes of the next year are added with create_classes. Everything happens in one transaction, with dry_run=true it is rolled back and only the outcome reported. Admins only.
+// @Tags terms
+// @Accept json
+// @Produce json,application/problem+json
+// @Param id path int true "ID of the academic year ending"
+// @Param dry_run query bool false "Report what the rollover would do without changing anything (optional)"
+// @Param rollover body models.Rollover true "The next academic year, mapping and exceptions"
+// @Success 200 {object} models.RolloverResult
+// @Failure 400 {object} utils.Problem "Invalid request payload or ID"
+// @Failure 403 {object} utils.Problem "Only admins may roll over academic years"
+// @Failure 404 {object} utils.Problem "Academic year not found"
+// @Failure 409 {object} utils.Problem "A class of the next year would be over its capacity"
+// @Failure 422 {object} utils.Problem "Validation failed, e.g. a missing class of the next year"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /academicyears/{id}/rollover [post]
+func RolloverHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Academic Year ID")
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var rollover models.Rollover
+	err = decoder.Decode(&rollover)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	validationErrs := utils.ValidateStruct(rollover)
+	for from, to := range rollover.Mapping {
+		if !rolloverKeyPattern.MatchString(from) || (to != models.RolloverGraduated && !rolloverKeyPattern.MatchString(to)) {
+			validationErrs = append(validationErrs, utils.FieldError{Field: "mapping",
+				Message: "must map a grade like 12 or a class like 10A to a grade, a class or " + models.RolloverGraduated + ", not " + from + " to " + to})
+		}
+	}
+	seen := map[int]bool{}
+	for i, h := range rollover.HeldBack {
+		validationErrs = append(validationErrs, utils.WithIndex(utils.ValidateStruct(h), i)...)
+		if seen[h.StudentID] {
+			validationErrs = append(validationErrs, utils.WithIndex([]utils.FieldError{{Field: "student_id", Message: "must not be held back twice"}}, i)...)
+		}
+		seen[h.StudentID] = true
+	}
+	if err := validationError(validationErrs); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	result, err := sqlconnect.RolloverDBHandler(r.Context(), id, rollover, r.URL.Query().Get("dry_run") == "true")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
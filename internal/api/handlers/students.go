@@ -8,21 +8,37 @@ import (
 
 	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
 	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/repository/sqlconnect"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
 )
 
 // GetStudentsHandler godoc
 // @Summary Retrieve all students
-// @Description Get a list of students with optional filtering and sorting.
+// @Description Get a list of students with optional filtering and sorting. Address and medical notes are only shown to admins and managers.
 // @Tags students
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,application/pdf
 // @Param first_name query string false "Filter by first name (optional)"
 // @Param last_name query string false "Filter by last name (optional)"
 // @Param email query string false "Filter by email (optional)"
 // @Param class query string false "Filter by class (optional)"
+// @Param student_number query string false "Filter by student number (optional)"
+// @Param gender query string false "Filter by gender (optional)" Enums(female, male, other)
+// @Param date_of_birth query string false "Filter by date of birth, YYYY-MM-DD (optional)"
+// @Param term query string false "Only students enrolled during this term, its ID or current (optional)"
 // @Param sortby query string false "Sorting (e.g., first_name:asc, class:desc) (optional)"
-// @Success 200 {object} map[string]interface{} "List of students with metadata"
-// @Failure 500 {string} string "Internal server error"
+// @Param page query int false "Page number, starting at 1 (optional)"
+// @Param limit query int false "Page size, defaults to 10 (optional)"
+// @Param include_deleted query bool false "Include soft-deleted records, admins only (optional)"
+// @Param format query string false "Export the listing as a file instead of JSON, also chosen with the Accept header (optional)" Enums(json, csv, xlsx, pdf)
+// @Param If-None-Match header string false "ETag from a previous response, answered with 304 if unchanged"
+// @Param If-Modified-Since header string false "Last-Modified from a previous response"
+// @Success 200 {object} map[string]interface{} "List of students with metadata, or the exported file"
+// @Header 200 {string} ETag "Hash of the response body"
+// @Header 200 {string} Last-Modified "Latest updated_at of the listed records"
+// @Success 304 "Not Modified"
+// @Failure 403 {object} utils.Problem "include_deleted requires the admin role"
+// @Failure 422 {object} utils.Problem "Invalid format or unknown term"
+// @Failure 500 {object} utils.Problem "Internal server error"
 // @Router /students [get]
 func GetStudentsHandler(w http.ResponseWriter, r *http.Request) {
 	var students []models.Student
@@ -34,9 +50,30 @@ func GetStudentsHandler(w http.ResponseWriter, r *http.Request) {
 	//  (3 - 1) * 50 = 100
 	page, limit := getPaginationParams(r)
 
-	students,totalStudents, err := sqlconnect.GetStudentsDBHandler(students, r, limit, page)
+	includeDeleted, err := showDeleted(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	format, err := utils.ExportFormat(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	if format != "" {
+		// exports hold every matching student, not a page of them
+		exportListing(w, r, format, "students", "Students", models.Student{}, func(each func(interface{}) error) error {
+			return sqlconnect.StreamStudentsDBHandler(r, includeDeleted, func(student models.Student) error {
+				return each(student)
+			})
+		})
+		return
+	}
+
+	students,totalStudents, err := sqlconnect.GetStudentsDBHandler(students, r, limit, page, includeDeleted)
+	if err != nil {
+		utils.WriteError(w, r, err)
 		return
 	}
 
@@ -54,6 +91,7 @@ func GetStudentsHandler(w http.ResponseWriter, r *http.Request) {
 		Data:   students,
 	}
 
+	utils.SetLastModified(w, newestUpdate(students))
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
@@ -76,56 +114,142 @@ func getPaginationParams(r *http.Request) (limit, offset int) {
 
 // GetOneStudentHandler godoc
 // @Summary Get one student
-// @Description Retrieve details of a student by ID
+// @Description Retrieve details of a student by ID. Address and medical notes are only shown to admins and managers.
 // @Tags students
-// @Produce json
+// @Produce json,application/problem+json
 // @Param id path int true "Student ID"
+// @Param include_deleted query bool false "Include soft-deleted records, admins only (optional)"
+// @Param as_of query string false "Show the student as it was at this RFC 3339 time or date, e.g. 2026-01-01 (optional)"
+// @Param If-None-Match header string false "ETag from a previous response, answered with 304 if unchanged"
+// @Param If-Modified-Since header string false "Last-Modified from a previous response"
 // @Success 200 {object} models.Student
-// @Failure 400 {string} string "Invalid Student ID"
-// @Failure 500 {string} string "Internal server error"
+// @Header 200 {string} ETag "Version of the student, send it back in If-Match"
+// @Header 200 {string} Last-Modified "When the record was last updated"
+// @Success 304 "Not Modified"
+// @Failure 400 {object} utils.Problem "Invalid Student ID"
+// @Failure 403 {object} utils.Problem "include_deleted requires the admin role"
+// @Failure 404 {object} utils.Problem "Student not found, or did not exist at as_of"
+// @Failure 422 {object} utils.Problem "Invalid as_of"
+// @Failure 500 {object} utils.Problem "Internal server error"
 // @Router /students/{id} [get]
 func GetOneStudentHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid Student ID", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Student ID")
 		return
 	}
 
-	student, err := sqlconnect.GetOneStudentDBHandler(id)
+	includeDeleted, err := showDeleted(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
 		return
 	}
 
+	asOf, err := parseTimeParam(r, "as_of")
+	if err != nil {
+		utils.WriteError(w, r, utils.ValidationError("Invalid as_of", utils.FieldError{Field: "as_of", Message: timeParamMessage}))
+		return
+	}
+
+	var student models.Student
+	if asOf.IsZero() {
+		student, err = sqlconnect.GetOneStudentDBHandler(r.Context(), id, includeDeleted)
+	} else {
+		student, err = sqlconnect.GetStudentAsOfDBHandler(r.Context(), id, asOf, includeDeleted)
+	}
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	// the version of a past revision is no use in If-Match
+	if asOf.IsZero() {
+		utils.SetVersionETag(w, student.Version)
+	}
+	if student.UpdatedAt != nil {
+		utils.SetLastModified(w, student.UpdatedAt.Time)
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(student)
 }
 
+// GetStudentHistoryHandler godoc
+// @Summary Get the history of a student
+// @Description List every recorded revision of a student, newest first
+// @Tags students
+// @Produce json,application/problem+json
+// @Param id path int true "Student ID"
+// @Success 200 {object} map[string]interface{} "Revisions of the student"
+// @Failure 400 {object} utils.Problem "Invalid Student ID"
+// @Failure 404 {object} utils.Problem "No history found for student"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /students/{id}/history [get]
+func GetStudentHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Student ID")
+		return
+	}
+
+	revisions, err := sqlconnect.GetStudentHistoryDBHandler(r.Context(), id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status string                   `json:"status"`
+		Count  int                      `json:"count"`
+		Data   []models.StudentRevision `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(revisions),
+		Data:   revisions,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
 // AddStudentHandler godoc
 // @Summary Add new students
-// @Description Add one or more students
+// @Description Add one or more students, enrolled today unless enrollment_date is given. Only admins and managers may set the address and medical notes.
 // @Tags students
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
 // @Param students body []models.Student true "List of students"
+// @Param Idempotency-Key header string false "Unique key for this request, retries with the same key get the first response replayed"
+// @Param mode query string false "atomic (default) applies all items or none, partial applies each item on its own" Enums(atomic, partial)
 // @Success 201 {object} map[string]interface{}
-// @Failure 400 {string} string "Invalid request payload"
-// @Failure 500 {string} string "Internal server error"
+// @Success 207 {object} map[string]interface{} "Result of each item, in partial mode"
+// @Failure 400 {object} utils.Problem "Invalid request payload"
+// @Failure 403 {object} utils.Problem "Only admins and managers may change the address and medical notes"
+// @Failure 409 {object} utils.Problem "Duplicate email, or a request with this Idempotency-Key still in progress"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 500 {object} utils.Problem "Internal server error"
 // @Router /students [post]
 func AddStudentHandler(w http.ResponseWriter, r *http.Request) {
 	var rawStudents []map[string]interface{}
 
+	mode, err := utils.BulkMode(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, "Error reading request body.", http.StatusInternalServerError)
+		utils.WriteProblem(w, r, http.StatusInternalServerError, "Error reading request body.")
 		return
 	}
 
 	err = json.Unmarshal(body, &rawStudents)
 	if err != nil {
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 
@@ -135,34 +259,56 @@ func AddStudentHandler(w http.ResponseWriter, r *http.Request) {
 		allowedFields[field] = struct{}{}
 	}
 
+	var newStudents []models.Student
+	err = json.Unmarshal(body, &newStudents)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if mode == utils.BulkModePartial {
+		results := make([]utils.BulkItemResult, len(newStudents))
+		for i, student := range newStudents {
+			err := unknownFieldsError(rawStudents[i], allowedFields)
+			if err == nil {
+				err = validationError(utils.ValidateStruct(student))
+			}
+			var added []models.Student
+			if err == nil {
+				added, err = sqlconnect.AddStudentsDBHandler(r.Context(), []models.Student{student})
+			}
+			if err != nil {
+				results[i] = utils.BulkItemFailure(i, 0, err)
+				continue
+			}
+			results[i] = utils.BulkItemSuccess(i, added[0].ID, http.StatusCreated)
+		}
+		utils.WriteBulkResults(w, results)
+		return
+	}
+
 	for _, student := range rawStudents {
 		for key := range student {
 			_, ok := allowedFields[key]
 			if !ok {
-				http.Error(w, "Unacceptable field found in request. Only use allowed fields", http.StatusBadRequest)
+				utils.WriteProblem(w, r, http.StatusBadRequest, "Unacceptable field found in request. Only use allowed fields")
 				return
 			}
 		}
 	}
 
-	var newStudents []models.Student
-	err = json.Unmarshal(body, &newStudents)
-	if err != nil {
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
-		return
+	var validationErrs []utils.FieldError
+	for i, student := range newStudents {
+		validationErrs = append(validationErrs, utils.WithIndex(utils.ValidateStruct(student), i)...)
 	}
-
-	for _, student := range newStudents {
-		err := CheckBlankFields(student)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
+	if err := validationError(validationErrs); err != nil {
+		utils.WriteError(w, r, err)
+		return
 	}
 
-	addedStudents, err := sqlconnect.AddStudentsDBHandler(newStudents)
+	addedStudents, err := sqlconnect.AddStudentsDBHandler(r.Context(), newStudents)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
 		return
 	}
 
@@ -182,63 +328,208 @@ func AddStudentHandler(w http.ResponseWriter, r *http.Request) {
 
 // UpdateStudentHandler godoc
 // @Summary Update a student
-// @Description Update an existing student by ID
+// @Description Update an existing student by ID. Other roles than admins and managers leave out the address and medical notes, which are kept.
 // @Tags students
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
 // @Param id path int true "Student ID"
+// @Param If-Match header string true "ETag of the student from GET /students/{id}, or *"
 // @Param student body models.Student true "Updated student"
 // @Success 200 {object} models.Student
-// @Failure 400 {string} string "Invalid request payload or ID"
-// @Failure 500 {string} string "Internal server error"
+// @Header 200 {string} ETag "Version of the student, send it back in If-Match"
+// @Failure 400 {object} utils.Problem "Invalid request payload or ID"
+// @Failure 403 {object} utils.Problem "Only admins and managers may change the address and medical notes"
+// @Failure 404 {object} utils.Problem "Student not found"
+// @Failure 409 {object} utils.Problem "Duplicate email"
+// @Failure 412 {object} utils.Problem "Student was modified since it was read"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 428 {object} utils.Problem "Missing If-Match header"
+// @Failure 500 {object} utils.Problem "Internal server error"
 // @Router /students/{id} [put]
 func UpdateStudentHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid Student ID", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Student ID")
+		return
+	}
+
+	version, err := utils.IfMatchVersion(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
 		return
 	}
 
 	var updatedStudent models.Student
 	err = json.NewDecoder(r.Body).Decode(&updatedStudent)
 	if err != nil {
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 
-	updatedStudentFromDB, err := sqlconnect.UpdateStudentDBHandler(id, updatedStudent)
+	if err := validationError(utils.ValidateStruct(updatedStudent)); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	updatedStudentFromDB, err := sqlconnect.UpdateStudentDBHandler(r.Context(), id, version, updatedStudent)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
 		return
 	}
 
+	utils.SetVersionETag(w, updatedStudentFromDB.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(updatedStudentFromDB)
 }
 
+// UpsertStudentsHandler godoc
+// @Summary Insert or update students
+// @Description Insert the students that don't exist yet and update the ones that do, matching them by email or by the columns in key. The batch is all or nothing.
+// @Tags students
+// @Accept json
+// @Produce json,application/problem+json
+// @Param students body []models.Student true "List of students"
+// @Param key query string false "Comma separated columns that identify a student, defaults to email (optional)"
+// @Param Idempotency-Key header string false "Unique key for this request, retries with the same key get the first response replayed"
+// @Success 200 {object} map[string]interface{} "Created, updated and unchanged counts with the stored students"
+// @Failure 400 {object} utils.Problem "Invalid request payload"
+// @Failure 403 {object} utils.Problem "Only admins and managers may change the address and medical notes"
+// @Failure 409 {object} utils.Problem "Duplicate email, or a request with this Idempotency-Key still in progress"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /students [put]
+func UpsertStudentsHandler(w http.ResponseWriter, r *http.Request) {
+	keys, err := naturalKeys(r, models.Student{})
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusInternalServerError, "Error reading request body.")
+		return
+	}
+
+	var rawStudents []map[string]interface{}
+	err = json.Unmarshal(body, &rawStudents)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	allowedFields := make(map[string]struct{})
+	for _, field := range GetFieldNames(models.Student{}) {
+		allowedFields[field] = struct{}{}
+	}
+	for _, student := range rawStudents {
+		if err := unknownFieldsError(student, allowedFields); err != nil {
+			utils.WriteError(w, r, err)
+			return
+		}
+	}
+
+	var students []models.Student
+	err = json.Unmarshal(body, &students)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	var validationErrs []utils.FieldError
+	for i, student := range students {
+		validationErrs = append(validationErrs, utils.WithIndex(utils.ValidateStruct(student), i)...)
+	}
+	if err := validationError(validationErrs); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	saved, result, err := sqlconnect.UpsertStudentsDBHandler(r.Context(), students, keys, false)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status string `json:"status"`
+		models.UpsertResult
+		Data []models.Student `json:"data"`
+	}{
+		Status:       "success",
+		UpsertResult: result,
+		Data:         saved,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
 // PatchStudentsHandler godoc
 // @Summary Partially update multiple students
-// @Description Apply partial updates to multiple students
+// @Description Apply partial updates to multiple students, each item must include the version it was based on
 // @Tags students
 // @Accept json
+// @Produce application/problem+json
 // @Param updates body []map[string]interface{} true "List of updates"
+// @Param Idempotency-Key header string false "Unique key for this request, retries with the same key get the first response replayed"
+// @Param mode query string false "atomic (default) applies all items or none, partial applies each item on its own" Enums(atomic, partial)
 // @Success 204 "No Content"
-// @Failure 400 {string} string "Invalid request payload"
-// @Failure 500 {string} string "Internal server error"
+// @Success 207 {object} map[string]interface{} "Result of each item, in partial mode"
+// @Failure 400 {object} utils.Problem "Invalid request payload"
+// @Failure 403 {object} utils.Problem "Only admins and managers may change the address and medical notes"
+// @Failure 404 {object} utils.Problem "Student not found"
+// @Failure 409 {object} utils.Problem "Duplicate email, or a request with this Idempotency-Key still in progress"
+// @Failure 412 {object} utils.Problem "Student was modified since it was read"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 500 {object} utils.Problem "Internal server error"
 // @Router /students [patch]
 func PatchStudentsHandler(w http.ResponseWriter, r *http.Request) {
+	mode, err := utils.BulkMode(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
 	var updates []map[string]interface{}
-	err := json.NewDecoder(r.Body).Decode(&updates)
+	err = json.NewDecoder(r.Body).Decode(&updates)
 	if err != nil {
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if mode == utils.BulkModePartial {
+		results := make([]utils.BulkItemResult, len(updates))
+		for i, update := range updates {
+			err := validationError(utils.ValidateFields(models.Student{}, update))
+			if err == nil {
+				err = sqlconnect.PatchStudentsDBHandler(r.Context(), []map[string]interface{}{update})
+			}
+			if err != nil {
+				results[i] = utils.BulkItemFailure(i, updateID(update), err)
+				continue
+			}
+			results[i] = utils.BulkItemSuccess(i, updateID(update), http.StatusOK)
+		}
+		utils.WriteBulkResults(w, results)
+		return
+	}
+
+	var validationErrs []utils.FieldError
+	for i, update := range updates {
+		validationErrs = append(validationErrs, utils.WithIndex(utils.ValidateFields(models.Student{}, update), i)...)
+	}
+	if err := validationError(validationErrs); err != nil {
+		utils.WriteError(w, r, err)
 		return
 	}
 
-	err = sqlconnect.PatchStudentsDBHandler(updates)
+	err = sqlconnect.PatchStudentsDBHandler(r.Context(), updates)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -249,34 +540,54 @@ func PatchStudentsHandler(w http.ResponseWriter, r *http.Request) {
 // @Description Apply partial updates to a single student by ID
 // @Tags students
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
 // @Param id path int true "Student ID"
+// @Param If-Match header string true "ETag of the student from GET /students/{id}, or *"
 // @Param updates body map[string]interface{} true "Partial updates"
 // @Success 200 {object} models.Student
-// @Failure 400 {string} string "Invalid request payload or ID"
-// @Failure 500 {string} string "Internal server error"
+// @Header 200 {string} ETag "Version of the student, send it back in If-Match"
+// @Failure 400 {object} utils.Problem "Invalid request payload or ID"
+// @Failure 403 {object} utils.Problem "Only admins and managers may change the address and medical notes"
+// @Failure 404 {object} utils.Problem "Student not found"
+// @Failure 409 {object} utils.Problem "Duplicate email"
+// @Failure 412 {object} utils.Problem "Student was modified since it was read"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 428 {object} utils.Problem "Missing If-Match header"
+// @Failure 500 {object} utils.Problem "Internal server error"
 // @Router /students/{id} [patch]
 func PatchOneStudentHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid Student ID", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Student ID")
+		return
+	}
+
+	version, err := utils.IfMatchVersion(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
 		return
 	}
 
 	var updates map[string]interface{}
 	err = json.NewDecoder(r.Body).Decode(&updates)
 	if err != nil {
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := validationError(utils.ValidateFields(models.Student{}, updates)); err != nil {
+		utils.WriteError(w, r, err)
 		return
 	}
 
-	updatedStudent, err := sqlconnect.PatchOneStudentDBHandler(id, updates)
+	updatedStudent, err := sqlconnect.PatchOneStudentDBHandler(r.Context(), id, version, updates)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
 		return
 	}
 
+	utils.SetVersionETag(w, updatedStudent.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(updatedStudent)
@@ -284,52 +595,130 @@ func PatchOneStudentHandler(w http.ResponseWriter, r *http.Request) {
 
 // DeleteOneStudentHandler godoc
 // @Summary Delete one student
-// @Description Delete a student by ID
+// @Description Soft-delete a student by ID, it can be restored until it is purged
 // @Tags students
+// @Produce application/problem+json
 // @Param id path int true "Student ID"
+// @Param If-Match header string true "ETag of the student from GET /students/{id}, or *"
 // @Success 204 "No Content"
-// @Failure 400 {string} string "Invalid Student ID"
-// @Failure 500 {string} string "Internal server error"
+// @Failure 400 {object} utils.Problem "Invalid Student ID"
+// @Failure 404 {object} utils.Problem "Student not found"
+// @Failure 412 {object} utils.Problem "Student was modified since it was read"
+// @Failure 428 {object} utils.Problem "Missing If-Match header"
+// @Failure 500 {object} utils.Problem "Internal server error"
 // @Router /students/{id} [delete]
 func DeleteOneStudentHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid Student ID", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Student ID")
 		return
 	}
 
-	err = sqlconnect.DeleteOneStudentDBHandler(id)
+	version, err := utils.IfMatchVersion(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	err = sqlconnect.DeleteOneStudentDBHandler(r.Context(), id, version)
+	if err != nil {
+		utils.WriteError(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// RestoreStudentHandler godoc
+// @Summary Restore a deleted student
+// @Description Undo the soft delete of a student, admins only
+// @Tags students
+// @Produce json,application/problem+json
+// @Param id path int true "Student ID"
+// @Success 200 {object} models.Student
+// @Header 200 {string} ETag "Version of the student, send it back in If-Match"
+// @Failure 400 {object} utils.Problem "Invalid Student ID"
+// @Failure 403 {object} utils.Problem "Only admins may restore records"
+// @Failure 404 {object} utils.Problem "Student not found"
+// @Failure 409 {object} utils.Problem "Student is not deleted"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /students/{id}/restore [post]
+func RestoreStudentHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Student ID")
+		return
+	}
+
+	student, err := sqlconnect.RestoreStudentDBHandler(r.Context(), id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	utils.SetVersionETag(w, student.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(student)
+}
+
 // DeleteStudentsHandler godoc
 // @Summary Delete multiple students
-// @Description Delete multiple students by their IDs
+// @Description Soft-delete multiple students by their IDs, they can be restored until they are purged
 // @Tags students
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
 // @Param ids body []int true "List of student IDs"
+// @Param Idempotency-Key header string false "Unique key for this request, retries with the same key get the first response replayed"
+// @Param mode query string false "atomic (default) applies all items or none, partial applies each item on its own" Enums(atomic, partial)
 // @Success 200 {object} map[string]interface{}
-// @Failure 400 {string} string "Invalid request payload"
-// @Failure 500 {string} string "Internal server error"
+// @Success 207 {object} map[string]interface{} "Result of each item, in partial mode"
+// @Failure 400 {object} utils.Problem "Invalid request payload"
+// @Failure 404 {object} utils.Problem "Student not found"
+// @Failure 409 {object} utils.Problem "A request with this Idempotency-Key is still in progress"
+// @Failure 422 {object} utils.Problem "Idempotency-Key was already used for a different request"
+// @Failure 500 {object} utils.Problem "Internal server error"
 // @Router /students [delete]
 func DeleteStudentsHandler(w http.ResponseWriter, r *http.Request) {
+	mode, err := utils.BulkMode(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
 	var ids []int
-	err := json.NewDecoder(r.Body).Decode(&ids)
+	err = json.NewDecoder(r.Body).Decode(&ids)
 	if err != nil {
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if mode == utils.BulkModePartial {
+		results := make([]utils.BulkItemResult, len(ids))
+		for i, id := range ids {
+			_, err := sqlconnect.DeleteStudentsDBHandler(r.Context(), []int{id})
+			if err != nil {
+				results[i] = utils.BulkItemFailure(i, id, err)
+				continue
+			}
+			results[i] = utils.BulkItemSuccess(i, id, http.StatusOK)
+		}
+		utils.WriteBulkResults(w, results)
 		return
 	}
 
-	deletedIds, err := sqlconnect.DeleteStudentsDBHandler(ids)
+	deletedIds, err := sqlconnect.DeleteStudentsDBHandler(r.Context(), ids)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
 		return
 	}
 
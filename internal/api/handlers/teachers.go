@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"strconv"
@@ -16,21 +17,51 @@ import (
 // @Description Get a list of teachers with optional filtering and sorting.
 // @Tags teachers
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,application/pdf
 // @Param first_name query string false "Filter by first name (optional)"
 // @Param last_name query string false "Filter by last name (optional)"
 // @Param email query string false "Filter by email (optional)"
 // @Param class query string false "Filter by class (optional)"
 // @Param subject query string false "Filter by subject (optional)"
+// @Param term query string false "Only teachers assigned to a class in this term, its ID or current (optional)"
 // @Param sortby query string false "Sorting (e.g., first_name:asc, class:desc) (optional)"
-// @Success 200 {object} map[string]interface{} "List of teachers with metadata"
-// @Failure 500 {string} string "Internal server error"
+// @Param include_deleted query bool false "Include soft-deleted records, admins only (optional)"
+// @Param format query string false "Export the listing as a file instead of JSON, also chosen with the Accept header (optional)" Enums(json, csv, xlsx, pdf)
+// @Param If-None-Match header string false "ETag from a previous response, answered with 304 if unchanged"
+// @Param If-Modified-Since header string false "Last-Modified from a previous response"
+// @Success 200 {object} map[string]interface{} "List of teachers with metadata, or the exported file"
+// @Header 200 {string} ETag "Hash of the response body"
+// @Header 200 {string} Last-Modified "Latest updated_at of the listed records"
+// @Success 304 "Not Modified"
+// @Failure 403 {object} utils.Problem "include_deleted requires the admin role"
+// @Failure 422 {object} utils.Problem "Invalid format or unknown term"
+// @Failure 500 {object} utils.Problem "Internal server error"
 // @Router /teachers [get]
 func GetTeachersHandler(w http.ResponseWriter, r *http.Request) {
 	var teachers []models.Teacher
-	teachers, err := sqlconnect.GetTeachersDBHandler(teachers, r)
+	includeDeleted, err := showDeleted(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	format, err := utils.ExportFormat(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	if format != "" {
+		exportListing(w, r, format, "teachers", "Teachers", models.Teacher{}, func(each func(interface{}) error) error {
+			return sqlconnect.StreamTeachersDBHandler(r, includeDeleted, func(teacher models.Teacher) error {
+				return each(teacher)
+			})
+		})
+		return
+	}
+
+	teachers, err = sqlconnect.GetTeachersDBHandler(teachers, r, includeDeleted)
+	if err != nil {
+		utils.WriteError(w, r, err)
 		return
 	}
 
@@ -44,6 +75,7 @@ func GetTeachersHandler(w http.ResponseWriter, r *http.Request) {
 		Data:   teachers,
 	}
 
+	utils.SetLastModified(w, newestUpdate(teachers))
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
@@ -53,56 +85,141 @@ func GetTeachersHandler(w http.ResponseWriter, r *http.Request) {
 // @Summary Get one teacher
 // @Description Retrieve details of a teacher by ID
 // @Tags teachers
-// @Produce json
+// @Produce json,application/problem+json
 // @Param id path int true "Teacher ID"
+// @Param include_deleted query bool false "Include soft-deleted records, admins only (optional)"
+// @Param as_of query string false "Show the teacher as it was at this RFC 3339 time or date, e.g. 2026-01-01 (optional)"
+// @Param If-None-Match header string false "ETag from a previous response, answered with 304 if unchanged"
+// @Param If-Modified-Since header string false "Last-Modified from a previous response"
 // @Success 200 {object} models.Teacher
-// @Failure 400 {string} string "Invalid Teacher ID"
-// @Failure 500 {string} string "Internal server error"
+// @Header 200 {string} ETag "Version of the teacher, send it back in If-Match"
+// @Header 200 {string} Last-Modified "When the record was last updated"
+// @Success 304 "Not Modified"
+// @Failure 400 {object} utils.Problem "Invalid Teacher ID"
+// @Failure 403 {object} utils.Problem "include_deleted requires the admin role"
+// @Failure 404 {object} utils.Problem "Teacher not found, or did not exist at as_of"
+// @Failure 422 {object} utils.Problem "Invalid as_of"
+// @Failure 500 {object} utils.Problem "Internal server error"
 // @Router /teachers/{id} [get]
 func GetOneTeacherHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid Teacher ID", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Teacher ID")
+		return
+	}
+
+	includeDeleted, err := showDeleted(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	asOf, err := parseTimeParam(r, "as_of")
+	if err != nil {
+		utils.WriteError(w, r, utils.ValidationError("Invalid as_of", utils.FieldError{Field: "as_of", Message: timeParamMessage}))
 		return
 	}
 
-	teacher, err := sqlconnect.GetOneTeacherDBHandler(id)
+	var teacher models.Teacher
+	if asOf.IsZero() {
+		teacher, err = sqlconnect.GetOneTeacherDBHandler(r.Context(), id, includeDeleted)
+	} else {
+		teacher, err = sqlconnect.GetTeacherAsOfDBHandler(r.Context(), id, asOf, includeDeleted)
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
 		return
 	}
 
+	// the version of a past revision is no use in If-Match
+	if asOf.IsZero() {
+		utils.SetVersionETag(w, teacher.Version)
+	}
+	if teacher.UpdatedAt != nil {
+		utils.SetLastModified(w, teacher.UpdatedAt.Time)
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(teacher)
 }
 
+// GetTeacherHistoryHandler godoc
+// @Summary Get the history of a teacher
+// @Description List every recorded revision of a teacher, newest first
+// @Tags teachers
+// @Produce json,application/problem+json
+// @Param id path int true "Teacher ID"
+// @Success 200 {object} map[string]interface{} "Revisions of the teacher"
+// @Failure 400 {object} utils.Problem "Invalid Teacher ID"
+// @Failure 404 {object} utils.Problem "No history found for teacher"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /teachers/{id}/history [get]
+func GetTeacherHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Teacher ID")
+		return
+	}
+
+	revisions, err := sqlconnect.GetTeacherHistoryDBHandler(r.Context(), id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status string                   `json:"status"`
+		Count  int                      `json:"count"`
+		Data   []models.TeacherRevision `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(revisions),
+		Data:   revisions,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
 // AddTeacherHandler godoc
 // @Summary Add new teachers
 // @Description Add one or more teachers
 // @Tags teachers
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
 // @Param teachers body []models.Teacher true "List of teachers"
+// @Param Idempotency-Key header string false "Unique key for this request, retries with the same key get the first response replayed"
+// @Param mode query string false "atomic (default) applies all items or none, partial applies each item on its own" Enums(atomic, partial)
 // @Success 201 {object} map[string]interface{}
-// @Failure 400 {string} string "Invalid request payload"
-// @Failure 500 {string} string "Internal server error"
+// @Success 207 {object} map[string]interface{} "Result of each item, in partial mode"
+// @Failure 400 {object} utils.Problem "Invalid request payload"
+// @Failure 409 {object} utils.Problem "Duplicate email, or a request with this Idempotency-Key still in progress"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 500 {object} utils.Problem "Internal server error"
 // @Router /teachers [post]
 func AddTeacherHandler(w http.ResponseWriter, r *http.Request) {
 
 	// validate the data before sending furthur
 	var rawTeachers []map[string]interface{}
 
+	mode, err := utils.BulkMode(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, "Error reading request body.", http.StatusInternalServerError)
+		utils.WriteProblem(w, r, http.StatusInternalServerError, "Error reading request body.")
 		return
 	}
 
 	err = json.Unmarshal(body, &rawTeachers)
 	if err != nil {
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 
@@ -114,35 +231,57 @@ func AddTeacherHandler(w http.ResponseWriter, r *http.Request) {
 		allowedFields[field] = struct{}{}
 	}
 
+	var newTeachers []models.Teacher
+	err = json.Unmarshal(body, &newTeachers)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if mode == utils.BulkModePartial {
+		results := make([]utils.BulkItemResult, len(newTeachers))
+		for i, teacher := range newTeachers {
+			err := unknownFieldsError(rawTeachers[i], allowedFields)
+			if err == nil {
+				err = validationError(utils.ValidateStruct(teacher))
+			}
+			var added []models.Teacher
+			if err == nil {
+				added, err = sqlconnect.AddTeachersDBHandler(r.Context(), []models.Teacher{teacher})
+			}
+			if err != nil {
+				results[i] = utils.BulkItemFailure(i, 0, err)
+				continue
+			}
+			results[i] = utils.BulkItemSuccess(i, added[0].ID, http.StatusCreated)
+		}
+		utils.WriteBulkResults(w, results)
+		return
+	}
+
 	for _, teacher := range rawTeachers {
 		for key := range teacher {
 			_, ok := allowedFields[key]
 			if !ok {
-				http.Error(w, "Unacceptable field found in request. Only use allowed fields", http.StatusBadRequest)
+				utils.WriteProblem(w, r, http.StatusBadRequest, "Unacceptable field found in request. Only use allowed fields")
 				return
 			}
 		}
 	}
 
-	var newTeachers []models.Teacher
-	err = json.Unmarshal(body, &newTeachers)
-	if err != nil {
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
-		return
+	// for blank, over-length or malformed values in fields
+	var validationErrs []utils.FieldError
+	for i, teacher := range newTeachers {
+		validationErrs = append(validationErrs, utils.WithIndex(utils.ValidateStruct(teacher), i)...)
 	}
-
-	// for blank value in fields
-	for _, teacher := range newTeachers {
-		err := CheckBlankFields(teacher)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
+	if err := validationError(validationErrs); err != nil {
+		utils.WriteError(w, r, err)
+		return
 	}
 
-	addedTeachers, err := sqlconnect.AddTeachersDBHandler(newTeachers)
+	addedTeachers, err := sqlconnect.AddTeachersDBHandler(r.Context(), newTeachers)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
 		return
 	}
 
@@ -165,60 +304,202 @@ func AddTeacherHandler(w http.ResponseWriter, r *http.Request) {
 // @Description Update an existing teacher by ID
 // @Tags teachers
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
 // @Param id path int true "Teacher ID"
+// @Param If-Match header string true "ETag of the teacher from GET /teachers/{id}, or *"
 // @Param teacher body models.Teacher true "Updated teacher"
 // @Success 200 {object} models.Teacher
-// @Failure 400 {string} string "Invalid request payload or ID"
-// @Failure 500 {string} string "Internal server error"
+// @Header 200 {string} ETag "Version of the teacher, send it back in If-Match"
+// @Failure 400 {object} utils.Problem "Invalid request payload or ID"
+// @Failure 404 {object} utils.Problem "Teacher not found"
+// @Failure 409 {object} utils.Problem "Duplicate email"
+// @Failure 412 {object} utils.Problem "Teacher was modified since it was read"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 428 {object} utils.Problem "Missing If-Match header"
+// @Failure 500 {object} utils.Problem "Internal server error"
 // @Router /teachers/{id} [put]
 func UpdateTeacherHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid Teacher ID", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Teacher ID")
+		return
+	}
+
+	version, err := utils.IfMatchVersion(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
 		return
 	}
 
 	var updatedTeacher models.Teacher
 	err = json.NewDecoder(r.Body).Decode(&updatedTeacher)
 	if err != nil {
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 
-	updatedTeacherFromDB, err := sqlconnect.UpdateTeacherDBHandler(id, updatedTeacher)
+	if err := validationError(utils.ValidateStruct(updatedTeacher)); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	updatedTeacherFromDB, err := sqlconnect.UpdateTeacherDBHandler(r.Context(), id, version, updatedTeacher)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
 		return
 	}
 
+	utils.SetVersionETag(w, updatedTeacherFromDB.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(updatedTeacherFromDB)
 }
 
+// UpsertTeachersHandler godoc
+// @Summary Insert or update teachers
+// @Description Insert the teachers that don't exist yet and update the ones that do, matching them by email or by the columns in key. The batch is all or nothing.
+// @Tags teachers
+// @Accept json
+// @Produce json,application/problem+json
+// @Param teachers body []models.Teacher true "List of teachers"
+// @Param key query string false "Comma separated columns that identify a teacher, defaults to email (optional)"
+// @Param Idempotency-Key header string false "Unique key for this request, retries with the same key get the first response replayed"
+// @Success 200 {object} map[string]interface{} "Created, updated and unchanged counts with the stored teachers"
+// @Failure 400 {object} utils.Problem "Invalid request payload"
+// @Failure 409 {object} utils.Problem "Duplicate email, or a request with this Idempotency-Key still in progress"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /teachers [put]
+func UpsertTeachersHandler(w http.ResponseWriter, r *http.Request) {
+	keys, err := naturalKeys(r, models.Teacher{})
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusInternalServerError, "Error reading request body.")
+		return
+	}
+
+	var rawTeachers []map[string]interface{}
+	err = json.Unmarshal(body, &rawTeachers)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	allowedFields := make(map[string]struct{})
+	for _, field := range GetFieldNames(models.Teacher{}) {
+		allowedFields[field] = struct{}{}
+	}
+	for _, teacher := range rawTeachers {
+		if err := unknownFieldsError(teacher, allowedFields); err != nil {
+			utils.WriteError(w, r, err)
+			return
+		}
+	}
+
+	var teachers []models.Teacher
+	err = json.Unmarshal(body, &teachers)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	var validationErrs []utils.FieldError
+	for i, teacher := range teachers {
+		validationErrs = append(validationErrs, utils.WithIndex(utils.ValidateStruct(teacher), i)...)
+	}
+	if err := validationError(validationErrs); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	saved, result, err := sqlconnect.UpsertTeachersDBHandler(r.Context(), teachers, keys, false)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status string `json:"status"`
+		models.UpsertResult
+		Data []models.Teacher `json:"data"`
+	}{
+		Status:       "success",
+		UpsertResult: result,
+		Data:         saved,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
 // PatchTeachersHandler godoc
 // @Summary Partially update multiple teachers
-// @Description Apply partial updates to multiple teachers
+// @Description Apply partial updates to multiple teachers, each item must include the version it was based on
 // @Tags teachers
 // @Accept json
+// @Produce application/problem+json
 // @Param updates body []map[string]interface{} true "List of updates"
+// @Param Idempotency-Key header string false "Unique key for this request, retries with the same key get the first response replayed"
+// @Param mode query string false "atomic (default) applies all items or none, partial applies each item on its own" Enums(atomic, partial)
 // @Success 204 "No Content"
-// @Failure 400 {string} string "Invalid request payload"
-// @Failure 500 {string} string "Internal server error"
+// @Success 207 {object} map[string]interface{} "Result of each item, in partial mode"
+// @Failure 400 {object} utils.Problem "Invalid request payload"
+// @Failure 404 {object} utils.Problem "Teacher not found"
+// @Failure 409 {object} utils.Problem "Duplicate email, or a request with this Idempotency-Key still in progress"
+// @Failure 412 {object} utils.Problem "Teacher was modified since it was read"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 500 {object} utils.Problem "Internal server error"
 // @Router /teachers [patch]
 func PatchTeachersHandler(w http.ResponseWriter, r *http.Request) {
+	mode, err := utils.BulkMode(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
 	var updates []map[string]interface{}
-	err := json.NewDecoder(r.Body).Decode(&updates)
+	err = json.NewDecoder(r.Body).Decode(&updates)
 	if err != nil {
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if mode == utils.BulkModePartial {
+		results := make([]utils.BulkItemResult, len(updates))
+		for i, update := range updates {
+			err := validationError(utils.ValidateFields(models.Teacher{}, update))
+			if err == nil {
+				err = sqlconnect.PatchTeachersDBHandler(r.Context(), []map[string]interface{}{update})
+			}
+			if err != nil {
+				results[i] = utils.BulkItemFailure(i, updateID(update), err)
+				continue
+			}
+			results[i] = utils.BulkItemSuccess(i, updateID(update), http.StatusOK)
+		}
+		utils.WriteBulkResults(w, results)
+		return
+	}
+
+	var validationErrs []utils.FieldError
+	for i, update := range updates {
+		validationErrs = append(validationErrs, utils.WithIndex(utils.ValidateFields(models.Teacher{}, update), i)...)
+	}
+	if err := validationError(validationErrs); err != nil {
+		utils.WriteError(w, r, err)
 		return
 	}
 
-	err = sqlconnect.PatchTeachersDBHandler(updates)
+	err = sqlconnect.PatchTeachersDBHandler(r.Context(), updates)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -229,34 +510,53 @@ func PatchTeachersHandler(w http.ResponseWriter, r *http.Request) {
 // @Description Apply partial updates to a single teacher by ID
 // @Tags teachers
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
 // @Param id path int true "Teacher ID"
+// @Param If-Match header string true "ETag of the teacher from GET /teachers/{id}, or *"
 // @Param updates body map[string]interface{} true "Partial updates"
 // @Success 200 {object} models.Teacher
-// @Failure 400 {string} string "Invalid request payload or ID"
-// @Failure 500 {string} string "Internal server error"
+// @Header 200 {string} ETag "Version of the teacher, send it back in If-Match"
+// @Failure 400 {object} utils.Problem "Invalid request payload or ID"
+// @Failure 404 {object} utils.Problem "Teacher not found"
+// @Failure 409 {object} utils.Problem "Duplicate email"
+// @Failure 412 {object} utils.Problem "Teacher was modified since it was read"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 428 {object} utils.Problem "Missing If-Match header"
+// @Failure 500 {object} utils.Problem "Internal server error"
 // @Router /teachers/{id} [patch]
 func PatchOneTeacherHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid Teacher ID", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Teacher ID")
+		return
+	}
+
+	version, err := utils.IfMatchVersion(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
 		return
 	}
 
 	var updates map[string]interface{}
 	err = json.NewDecoder(r.Body).Decode(&updates)
 	if err != nil {
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 
-	updatedTeacher, err := sqlconnect.PatchOneTeacherDBHandler(id, updates)
+	if err := validationError(utils.ValidateFields(models.Teacher{}, updates)); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	updatedTeacher, err := sqlconnect.PatchOneTeacherDBHandler(r.Context(), id, version, updates)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
 		return
 	}
 
+	utils.SetVersionETag(w, updatedTeacher.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(updatedTeacher)
@@ -264,52 +564,130 @@ func PatchOneTeacherHandler(w http.ResponseWriter, r *http.Request) {
 
 // DeleteOneTeacherHandler godoc
 // @Summary Delete one teacher
-// @Description Delete a teacher by ID
+// @Description Soft-delete a teacher by ID, it can be restored until it is purged
 // @Tags teachers
+// @Produce application/problem+json
 // @Param id path int true "Teacher ID"
+// @Param If-Match header string true "ETag of the teacher from GET /teachers/{id}, or *"
 // @Success 204 "No Content"
-// @Failure 400 {string} string "Invalid Teacher ID"
-// @Failure 500 {string} string "Internal server error"
+// @Failure 400 {object} utils.Problem "Invalid Teacher ID"
+// @Failure 404 {object} utils.Problem "Teacher not found"
+// @Failure 412 {object} utils.Problem "Teacher was modified since it was read"
+// @Failure 428 {object} utils.Problem "Missing If-Match header"
+// @Failure 500 {object} utils.Problem "Internal server error"
 // @Router /teachers/{id} [delete]
 func DeleteOneTeacherHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid Teacher ID", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Teacher ID")
+		return
+	}
+
+	version, err := utils.IfMatchVersion(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
 		return
 	}
 
-	err = sqlconnect.DeleteOneTeacherDBHandler(id)
+	err = sqlconnect.DeleteOneTeacherDBHandler(r.Context(), id, version)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// RestoreTeacherHandler godoc
+// @Summary Restore a deleted teacher
+// @Description Undo the soft delete of a teacher, admins only
+// @Tags teachers
+// @Produce json,application/problem+json
+// @Param id path int true "Teacher ID"
+// @Success 200 {object} models.Teacher
+// @Header 200 {string} ETag "Version of the teacher, send it back in If-Match"
+// @Failure 400 {object} utils.Problem "Invalid Teacher ID"
+// @Failure 403 {object} utils.Problem "Only admins may restore records"
+// @Failure 404 {object} utils.Problem "Teacher not found"
+// @Failure 409 {object} utils.Problem "Teacher is not deleted"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /teachers/{id}/restore [post]
+func RestoreTeacherHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Teacher ID")
+		return
+	}
+
+	teacher, err := sqlconnect.RestoreTeacherDBHandler(r.Context(), id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	utils.SetVersionETag(w, teacher.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(teacher)
+}
+
 // DeleteTeachersHandler godoc
 // @Summary Delete multiple teachers
-// @Description Delete multiple teachers by their IDs
+// @Description Soft-delete multiple teachers by their IDs, they can be restored until they are purged
 // @Tags teachers
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
 // @Param ids body []int true "List of teacher IDs"
+// @Param Idempotency-Key header string false "Unique key for this request, retries with the same key get the first response replayed"
+// @Param mode query string false "atomic (default) applies all items or none, partial applies each item on its own" Enums(atomic, partial)
 // @Success 200 {object} map[string]interface{}
-// @Failure 400 {string} string "Invalid request payload"
-// @Failure 500 {string} string "Internal server error"
+// @Success 207 {object} map[string]interface{} "Result of each item, in partial mode"
+// @Failure 400 {object} utils.Problem "Invalid request payload"
+// @Failure 404 {object} utils.Problem "Teacher not found"
+// @Failure 409 {object} utils.Problem "A request with this Idempotency-Key is still in progress"
+// @Failure 422 {object} utils.Problem "Idempotency-Key was already used for a different request"
+// @Failure 500 {object} utils.Problem "Internal server error"
 // @Router /teachers [delete]
 func DeleteTeachersHandler(w http.ResponseWriter, r *http.Request) {
+	mode, err := utils.BulkMode(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
 	var ids []int
-	err := json.NewDecoder(r.Body).Decode(&ids)
+	err = json.NewDecoder(r.Body).Decode(&ids)
 	if err != nil {
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 
-	deletedIds, err := sqlconnect.DeleteTeachersDBHandler(ids)
+	if mode == utils.BulkModePartial {
+		results := make([]utils.BulkItemResult, len(ids))
+		for i, id := range ids {
+			_, err := sqlconnect.DeleteTeachersDBHandler(r.Context(), []int{id})
+			if err != nil {
+				results[i] = utils.BulkItemFailure(i, id, err)
+				continue
+			}
+			results[i] = utils.BulkItemSuccess(i, id, http.StatusOK)
+		}
+		utils.WriteBulkResults(w, results)
+		return
+	}
+
+	deletedIds, err := sqlconnect.DeleteTeachersDBHandler(r.Context(), ids)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
 		return
 	}
 
@@ -327,28 +705,54 @@ func DeleteTeachersHandler(w http.ResponseWriter, r *http.Request) {
 
 // GetStudentsByTeacherIDHandler godoc
 // @Summary Retrieve students by teacher ID
-// @Description Get all students assigned to a specific teacher.
+// @Description Get the students of every class a teacher is assigned to.
 // @Tags teachers
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,application/pdf
 // @Param id path int true "Teacher ID"
-// @Success 200 {object} map[string]interface{} "List of students with metadata"
-// @Failure 400 {string} string "Invalid Teacher ID"
-// @Failure 500 {string} string "Internal server error"
+// @Param subject query string false "Only the classes the teacher teaches this subject to (optional)"
+// @Param format query string false "Export the listing as a file instead of JSON, also chosen with the Accept header (optional)" Enums(json, csv, xlsx, pdf)
+// @Param sortby query string false "Sorting of exports (e.g., first_name:asc) (optional)"
+// @Param If-None-Match header string false "ETag from a previous response, answered with 304 if unchanged"
+// @Param If-Modified-Since header string false "Last-Modified from a previous response"
+// @Success 200 {object} map[string]interface{} "List of students with metadata, or the exported file"
+// @Header 200 {string} ETag "Hash of the response body"
+// @Header 200 {string} Last-Modified "Latest updated_at of the listed records"
+// @Success 304 "Not Modified"
+// @Failure 400 {object} utils.Problem "Invalid Teacher ID"
+// @Failure 422 {object} utils.Problem "Invalid format"
+// @Failure 500 {object} utils.Problem "Internal server error"
 // @Router /teachers/{id}/students [get]
 func GetStudentsByTeacherIDHandler(w http.ResponseWriter, r *http.Request){
 	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid Teacher ID", http.StatusBadRequest)
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Teacher ID")
+		return
+	}
+
+	subject := r.URL.Query().Get("subject")
+
+	format, err := utils.ExportFormat(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	if format != "" {
+		name := fmt.Sprintf("teacher-%d-students", id)
+		exportListing(w, r, format, name, fmt.Sprintf("Students of teacher %d", id), models.Student{}, func(each func(interface{}) error) error {
+			return sqlconnect.StreamStudentsByTeacherIdDBHandler(r, id, subject, func(student models.Student) error {
+				return each(student)
+			})
+		})
 		return
 	}
 
 	var students []models.Student
 
-	students, err = sqlconnect.GetStudentsByTeacherIdDBHandler(id, students)
+	students, err = sqlconnect.GetStudentsByTeacherIdDBHandler(r.Context(), id, subject, students)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
 		return
 	}
 
@@ -362,6 +766,7 @@ func GetStudentsByTeacherIDHandler(w http.ResponseWriter, r *http.Request){
 		Data:   students,
 	}
 
+	utils.SetLastModified(w, newestUpdate(students))
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
@@ -369,29 +774,35 @@ func GetStudentsByTeacherIDHandler(w http.ResponseWriter, r *http.Request){
 
 // GetStudentsCountByTeacherIDHandler godoc
 // @Summary Retrieve student count by teacher ID
-// @Description Get the total number of students assigned to a specific teacher.
+// @Description Get the number of students in the classes a teacher is assigned to.
 // @Tags teachers
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
 // @Param id path int true "Teacher ID"
+// @Param subject query string false "Only the classes the teacher teaches this subject to (optional)"
 // @Success 200 {object} map[string]interface{} "Student count"
-// @Failure 400 {string} string "Invalid Teacher ID"
-// @Failure 500 {string} string "Internal server error"
+// @Failure 400 {object} utils.Problem "Invalid Teacher ID"
+// @Failure 403 {object} utils.Problem "Forbidden"
+// @Failure 500 {object} utils.Problem "Internal server error"
 // @Router /teachers/{id}/studentcount [get]
 func GetStudentsCountByTeacherIDHandler(w http.ResponseWriter, r *http.Request) {
 
 	// admin, manager, exec
 	authorized, err := utils.AuthorizeUser(r.Context().Value(utils.ContextKey("role")).(string), "admin", "manager", "exec")
 	if err != nil || !authorized {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		utils.WriteError(w, r, err)
 		return
 	}
 
-	teacherId := r.PathValue("id")
+	teacherId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Teacher ID")
+		return
+	}
 
-	studentCount, err := sqlconnect.GetStudentsCountByTeacherIdDBHandler(teacherId)
+	studentCount, err := sqlconnect.GetStudentsCountByTeacherIdDBHandler(r.Context(), teacherId, r.URL.Query().Get("subject"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
 		return
 	}
 
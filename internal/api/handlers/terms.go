@@ -0,0 +1,555 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/repository/sqlconnect"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+// dateRangeErrors reports a start and end date that aren't real dates or don't follow each other
+func dateRangeErrors(start, end string) []utils.FieldError {
+	startDate, startErr := time.Parse(time.DateOnly, start)
+	endDate, endErr := time.Parse(time.DateOnly, end)
+	var errs []utils.FieldError
+	if startErr != nil {
+		errs = append(errs, utils.FieldError{Field: "start_date", Message: "must be a date like 2026-01-01"})
+	}
+	if endErr != nil {
+		errs = append(errs, utils.FieldError{Field: "end_date", Message: "must be a date like 2026-01-01"})
+	}
+	if startErr == nil && endErr == nil && !endDate.After(startDate) {
+		errs = append(errs, utils.FieldError{Field: "end_date", Message: "must be after start_date"})
+	}
+	return errs
+}
+
+// GetAcademicYearsHandler godoc
+// @Summary List the academic years
+// @Description Get the academic years of the school, latest first, with their terms
+// @Tags terms
+// @Produce json,application/problem+json
+// @Success 200 {object} map[string]interface{} "Academic years"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /academicyears [get]
+func GetAcademicYearsHandler(w http.ResponseWriter, r *http.Request) {
+	years, err := sqlconnect.GetAcademicYearsDBHandler(r.Context())
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status string                `json:"status"`
+		Count  int                   `json:"count"`
+		Data   []models.AcademicYear `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(years),
+		Data:   years,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetOneAcademicYearHandler godoc
+// @Summary Get an academic year
+// @Description Get an academic year by ID with its terms
+// @Tags terms
+// @Produce json,application/problem+json
+// @Param id path int true "Academic year ID"
+// @Success 200 {object} models.AcademicYear
+// @Failure 400 {object} utils.Problem "Invalid Academic Year ID"
+// @Failure 404 {object} utils.Problem "Academic year not found"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /academicyears/{id} [get]
+func GetOneAcademicYearHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Academic Year ID")
+		return
+	}
+
+	year, err := sqlconnect.GetOneAcademicYearDBHandler(r.Context(), id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(year)
+}
+
+// AddAcademicYearsHandler godoc
+// @Summary Add academic years
+// @Description Add one or more academic years. They must not overlap, classes can only be added to an existing year. Admins only.
+// @Tags terms
+// @Accept json
+// @Produce json,application/problem+json
+// @Param years body []models.AcademicYear true "List of academic years"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} utils.Problem "Invalid request payload"
+// @Failure 403 {object} utils.Problem "Only admins may change academic years"
+// @Failure 409 {object} utils.Problem "An academic year with this name already exists"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /academicyears [post]
+func AddAcademicYearsHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var newYears []models.AcademicYear
+	err = decoder.Decode(&newYears)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	var validationErrs []utils.FieldError
+	for i, year := range newYears {
+		validationErrs = append(validationErrs, utils.WithIndex(utils.ValidateStruct(year), i)...)
+		validationErrs = append(validationErrs, utils.WithIndex(dateRangeErrors(year.StartDate, year.EndDate), i)...)
+	}
+	if err := validationError(validationErrs); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	added, err := sqlconnect.AddAcademicYearsDBHandler(r.Context(), newYears)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	response := struct {
+		Status string                `json:"status"`
+		Count  int                   `json:"count"`
+		Data   []models.AcademicYear `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(added),
+		Data:   added,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// UpdateAcademicYearHandler godoc
+// @Summary Update an academic year
+// @Description Move the dates of an academic year, its terms must stay inside them. The name can't be changed. Admins only.
+// @Tags terms
+// @Accept json
+// @Produce json,application/problem+json
+// @Param id path int true "Academic year ID"
+// @Param year body models.AcademicYear true "Updated academic year"
+// @Success 200 {object} models.AcademicYear
+// @Failure 400 {object} utils.Problem "Invalid request payload or ID"
+// @Failure 403 {object} utils.Problem "Only admins may change academic years"
+// @Failure 404 {object} utils.Problem "Academic year not found"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /academicyears/{id} [put]
+func UpdateAcademicYearHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Academic Year ID")
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var updatedYear models.AcademicYear
+	err = decoder.Decode(&updatedYear)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	validationErrs := append(utils.ValidateStruct(updatedYear), dateRangeErrors(updatedYear.StartDate, updatedYear.EndDate)...)
+	if err := validationError(validationErrs); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	year, err := sqlconnect.UpdateAcademicYearDBHandler(r.Context(), id, updatedYear)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(year)
+}
+
+// DeleteAcademicYearHandler godoc
+// @Summary Delete an academic year
+// @Description Delete an academic year with its terms, as long as no class belongs to it. Admins only.
+// @Tags terms
+// @Produce application/problem+json
+// @Param id path int true "Academic year ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} utils.Problem "Invalid Academic Year ID"
+// @Failure 403 {object} utils.Problem "Only admins may delete academic years"
+// @Failure 404 {object} utils.Problem "Academic year not found"
+// @Failure 409 {object} utils.Problem "Classes still belong to the academic year"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /academicyears/{id} [delete]
+func DeleteAcademicYearHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Academic Year ID")
+		return
+	}
+
+	err = sqlconnect.DeleteAcademicYearDBHandler(r.Context(), id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetTermsHandler godoc
+// @Summary List the terms
+// @Description Get the terms of the school in the order they take place, optionally only those of an academic year
+// @Tags terms
+// @Produce json,application/problem+json
+// @Param academic_year query string false "Only the terms of this academic year, e.g. 2025-2026 (optional)"
+// @Success 200 {object} map[string]interface{} "Terms"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /terms [get]
+func GetTermsHandler(w http.ResponseWriter, r *http.Request) {
+	terms, err := sqlconnect.GetTermsDBHandler(r.Context(), r.URL.Query().Get("academic_year"))
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status string        `json:"status"`
+		Count  int           `json:"count"`
+		Data   []models.Term `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(terms),
+		Data:   terms,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetOneTermHandler godoc
+// @Summary Get a term
+// @Description Get a term by ID
+// @Tags terms
+// @Produce json,application/problem+json
+// @Param id path int true "Term ID"
+// @Success 200 {object} models.Term
+// @Failure 400 {object} utils.Problem "Invalid Term ID"
+// @Failure 404 {object} utils.Problem "Term not found"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /terms/{id} [get]
+func GetOneTermHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Term ID")
+		return
+	}
+
+	term, err := sqlconnect.GetOneTermDBHandler(r.Context(), id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(term)
+}
+
+// AddTermsHandler godoc
+// @Summary Add terms
+// @Description Add one or more terms to academic years. A term lies within its year and doesn't overlap the other terms of the year, its name is unique in the year. Admins only.
+// @Tags terms
+// @Accept json
+// @Produce json,application/problem+json
+// @Param terms body []models.Term true "List of terms"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} utils.Problem "Invalid request payload"
+// @Failure 403 {object} utils.Problem "Only admins may change terms"
+// @Failure 409 {object} utils.Problem "A term with this name already exists in the academic year"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /terms [post]
+func AddTermsHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var newTerms []models.Term
+	err = decoder.Decode(&newTerms)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	var validationErrs []utils.FieldError
+	for i, term := range newTerms {
+		validationErrs = append(validationErrs, utils.WithIndex(utils.ValidateStruct(term), i)...)
+		validationErrs = append(validationErrs, utils.WithIndex(dateRangeErrors(term.StartDate, term.EndDate), i)...)
+	}
+	if err := validationError(validationErrs); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	added, err := sqlconnect.AddTermsDBHandler(r.Context(), newTerms)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	response := struct {
+		Status string        `json:"status"`
+		Count  int           `json:"count"`
+		Data   []models.Term `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(added),
+		Data:   added,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// UpdateTermHandler godoc
+// @Summary Update a term
+// @Description Move the dates of a term within its academic year. Its name and year can't be changed. Admins only.
+// @Tags terms
+// @Accept json
+// @Produce json,application/problem+json
+// @Param id path int true "Term ID"
+// @Param term body models.Term true "Updated term"
+// @Success 200 {object} models.Term
+// @Failure 400 {object} utils.Problem "Invalid request payload or ID"
+// @Failure 403 {object} utils.Problem "Only admins may change terms"
+// @Failure 404 {object} utils.Problem "Term not found"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /terms/{id} [put]
+func UpdateTermHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Term ID")
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var updatedTerm models.Term
+	err = decoder.Decode(&updatedTerm)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	validationErrs := append(utils.ValidateStruct(updatedTerm), dateRangeErrors(updatedTerm.StartDate, updatedTerm.EndDate)...)
+	if err := validationError(validationErrs); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	term, err := sqlconnect.UpdateTermDBHandler(r.Context(), id, updatedTerm)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(term)
+}
+
+// DeleteTermHandler godoc
+// @Summary Delete a term
+// @Description Delete a term no teaching assignment or assessment refers to. Admins only.
+// @Tags terms
+// @Produce application/problem+json
+// @Param id path int true "Term ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} utils.Problem "Invalid Term ID"
+// @Failure 403 {object} utils.Problem "Only admins may delete terms"
+// @Failure 404 {object} utils.Problem "Term not found"
+// @Failure 409 {object} utils.Problem "The term is in use"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /terms/{id} [delete]
+func DeleteTermHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Term ID")
+		return
+	}
+
+	err = sqlconnect.DeleteTermDBHandler(r.Context(), id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetCurrentTermHandler godoc
+// @Summary Get the current term
+// @Description Get the term the school is in: the one set under PUT /terms/current or else the one today falls in
+// @Tags terms
+// @Produce json,application/problem+json
+// @Success 200 {object} models.Term
+// @Failure 404 {object} utils.Problem "There is no current term"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /terms/current [get]
+func GetCurrentTermHandler(w http.ResponseWriter, r *http.Request) {
+	term, err := sqlconnect.GetCurrentTermDBHandler(r.Context())
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(term)
+}
+
+// SetCurrentTermHandler godoc
+// @Summary Set the current term
+// @Description Make a term the current one whatever the date, e.g. to prepare the next term early. Admins only.
+// @Tags terms
+// @Accept json
+// @Produce json,application/problem+json
+// @Param current body models.CurrentTerm true "The current term"
+// @Success 200 {object} models.Term
+// @Failure 400 {object} utils.Problem "Invalid request payload"
+// @Failure 403 {object} utils.Problem "Only admins may set the current term"
+// @Failure 422 {object} utils.Problem "Unknown term"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /terms/current [put]
+func SetCurrentTermHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var current models.CurrentTerm
+	err = decoder.Decode(&current)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if err := validationError(utils.ValidateStruct(current)); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	term, err := sqlconnect.SetCurrentTermDBHandler(r.Context(), current.TermID)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(term)
+}
+
+// GetStudentEnrollmentsHandler godoc
+// @Summary Class history of a student
+// @Description List the classes a student has been in with when they joined and left them, latest first
+// @Tags students
+// @Produce json,application/problem+json
+// @Param id path int true "Student ID"
+// @Success 200 {object} map[string]interface{} "Enrollments of the student"
+// @Failure 400 {object} utils.Problem "Invalid Student ID"
+// @Failure 404 {object} utils.Problem "Student not found"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /students/{id}/enrollments [get]
+func GetStudentEnrollmentsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Student ID")
+		return
+	}
+
+	enrollments, err := sqlconnect.GetStudentEnrollmentsDBHandler(r.Context(), id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status string              `json:"status"`
+		Count  int                 `json:"count"`
+		Data   []models.Enrollment `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(enrollments),
+		Data:   enrollments,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
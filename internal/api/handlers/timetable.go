@@ -0,0 +1,603 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/repository/sqlconnect"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+// calendarFormat tells whether a timetable was asked for as an iCalendar file, with ?format=ics or
+// else the Accept header
+func calendarFormat(r *http.Request) (bool, error) {
+	switch r.URL.Query().Get("format") {
+	case "ics":
+		return true, nil
+	case "json":
+		return false, nil
+	case "":
+	default:
+		return false, utils.ValidationError("Invalid format", utils.FieldError{Field: "format", Message: "must be one of json or ics"})
+	}
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err == nil && mediaType == utils.ICalContentType {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// writeTimetable writes the slots of a term as JSON or as an iCalendar file called name with a
+// weekly event per slot from the first of its weekdays in the term to the end of the term
+func writeTimetable(w http.ResponseWriter, r *http.Request, ics bool, name string, term models.Term, slots []models.Slot, summary func(models.Slot) string) {
+	if !ics {
+		response := struct {
+			Status string        `json:"status"`
+			Term   models.Term   `json:"term"`
+			Count  int           `json:"count"`
+			Data   []models.Slot `json:"data"`
+		}{
+			Status: "success",
+			Term:   term,
+			Count:  len(slots),
+			Data:   slots,
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	start, err := time.Parse(time.DateOnly, term.StartDate)
+	if err != nil {
+		utils.WriteError(w, r, utils.ErrorHandler(err, "Invalid term dates"))
+		return
+	}
+	end, err := time.Parse(time.DateOnly, term.EndDate)
+	if err != nil {
+		utils.WriteError(w, r, utils.ErrorHandler(err, "Invalid term dates"))
+		return
+	}
+	until := end.Add(24*time.Hour - time.Second)
+
+	var events []utils.ICalEvent
+	for _, slot := range slots {
+		day := start.AddDate(0, 0, (int(time.Weekday(slot.Weekday%7))-int(start.Weekday())+7)%7)
+		from, errFrom := time.Parse("15:04", slot.StartTime)
+		to, errTo := time.Parse("15:04", slot.EndTime)
+		if errFrom != nil || errTo != nil || day.After(end) {
+			continue
+		}
+		events = append(events, utils.ICalEvent{
+			UID:         fmt.Sprintf("slot-%d@school-mgmt", slot.ID),
+			Summary:     summary(slot),
+			Location:    slot.Room,
+			Description: fmt.Sprintf("%s, period %d", slot.Term, slot.Period),
+			Start:       day.Add(time.Duration(from.Hour())*time.Hour + time.Duration(from.Minute())*time.Minute),
+			End:         day.Add(time.Duration(to.Hour())*time.Hour + time.Duration(to.Minute())*time.Minute),
+			Until:       until,
+		})
+	}
+
+	w.Header().Set("Content-Type", utils.ICalContentType+"; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", strings.ToLower(strings.ReplaceAll(name, " ", "-"))+".ics"))
+	w.WriteHeader(http.StatusOK)
+	err = utils.WriteICal(w, name, events)
+	if err != nil {
+		utils.ErrorHandler(err, "Error writing timetable")
+	}
+}
+
+// GetTimetableHandler godoc
+// @Summary List the timetable
+// @Description Get the lessons of the timetable of a term by weekday and period, optionally only those of a class, teacher or room
+// @Tags timetable
+// @Produce json,application/problem+json
+// @Param term query string false "ID of a term or current, defaults to current (optional)"
+// @Param class_id query int false "Only the lessons of this class (optional)"
+// @Param teacher_id query int false "Only the lessons of this teacher (optional)"
+// @Param room_id query int false "Only the lessons in this room (optional)"
+// @Success 200 {object} map[string]interface{} "The term and its lessons"
+// @Failure 400 {object} utils.Problem "Invalid class, teacher or room ID"
+// @Failure 422 {object} utils.Problem "Unknown term or no current term"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /timetable [get]
+func GetTimetableHandler(w http.ResponseWriter, r *http.Request) {
+	ids := map[string]int{}
+	for _, name := range []string{"class_id", "teacher_id", "room_id"} {
+		if value := r.URL.Query().Get(name); value != "" {
+			id, err := strconv.Atoi(value)
+			if err != nil {
+				utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid "+name)
+				return
+			}
+			ids[name] = id
+		}
+	}
+
+	term, slots, err := sqlconnect.GetTimetableDBHandler(r.Context(), r.URL.Query().Get("term"), ids["class_id"], ids["teacher_id"], ids["room_id"])
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	writeTimetable(w, r, false, "", term, slots, nil)
+}
+
+// GetClassTimetableHandler godoc
+// @Summary Weekly timetable of a class
+// @Description Get the lessons of a class in a term by weekday and period. With ?format=ics or Accept: text/calendar it is an iCalendar file with a weekly event per lesson until the end of the term.
+// @Tags timetable
+// @Produce json,application/problem+json,text/calendar
+// @Param id path int true "Class ID"
+// @Param term query string false "ID of a term or current, defaults to current (optional)"
+// @Param format query string false "json or ics, overrides the Accept header (optional)"
+// @Success 200 {object} map[string]interface{} "The term and the lessons of the class, or the calendar"
+// @Failure 400 {object} utils.Problem "Invalid Class ID"
+// @Failure 404 {object} utils.Problem "Class not found"
+// @Failure 422 {object} utils.Problem "Unknown term, no current term or invalid format"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /classes/{id}/timetable [get]
+func GetClassTimetableHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Class ID")
+		return
+	}
+	ics, err := calendarFormat(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	term, slots, err := sqlconnect.GetClassTimetableDBHandler(r.Context(), id, r.URL.Query().Get("term"))
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	name := "Timetable " + term.Name
+	if len(slots) > 0 {
+		name = "Timetable " + slots[0].Class + " " + term.Name
+	}
+	writeTimetable(w, r, ics, name, term, slots, func(slot models.Slot) string {
+		return slot.Subject + " with " + slot.TeacherName
+	})
+}
+
+// GetTeacherTimetableHandler godoc
+// @Summary Weekly timetable of a teacher
+// @Description Get the lessons a teacher gives in a term by weekday and period. With ?format=ics or Accept: text/calendar it is an iCalendar file with a weekly event per lesson until the end of the term.
+// @Tags timetable
+// @Produce json,application/problem+json,text/calendar
+// @Param id path int true "Teacher ID"
+// @Param term query string false "ID of a term or current, defaults to current (optional)"
+// @Param format query string false "json or ics, overrides the Accept header (optional)"
+// @Success 200 {object} map[string]interface{} "The term and the lessons of the teacher, or the calendar"
+// @Failure 400 {object} utils.Problem "Invalid Teacher ID"
+// @Failure 404 {object} utils.Problem "Teacher not found"
+// @Failure 422 {object} utils.Problem "Unknown term, no current term or invalid format"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /teachers/{id}/timetable [get]
+func GetTeacherTimetableHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Teacher ID")
+		return
+	}
+	ics, err := calendarFormat(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	term, slots, err := sqlconnect.GetTeacherTimetableDBHandler(r.Context(), id, r.URL.Query().Get("term"))
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	name := "Timetable " + term.Name
+	if len(slots) > 0 {
+		name = "Timetable " + slots[0].TeacherName + " " + term.Name
+	}
+	writeTimetable(w, r, ics, name, term, slots, func(slot models.Slot) string {
+		return slot.Subject + " " + slot.Class
+	})
+}
+
+// GetOneSlotHandler godoc
+// @Summary Get a lesson of the timetable
+// @Description Get a slot of the timetable by ID
+// @Tags timetable
+// @Produce json,application/problem+json
+// @Param id path int true "Slot ID"
+// @Success 200 {object} models.Slot
+// @Failure 400 {object} utils.Problem "Invalid Slot ID"
+// @Failure 404 {object} utils.Problem "Slot not found"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /timetable/{id} [get]
+func GetOneSlotHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Slot ID")
+		return
+	}
+
+	slot, err := sqlconnect.GetOneSlotDBHandler(r.Context(), id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(slot)
+}
+
+// AddSlotsHandler godoc
+// @Summary Add lessons to the timetable
+// @Description Add weekly lessons of a term. The teacher must be assigned to teach the subject to the class in the term, which must be one of the academic year of the class. A teacher, class or room can't have two lessons at the same period of a weekday. The batch is all or nothing. Admins only.
+// @Tags timetable
+// @Accept json
+// @Produce json,application/problem+json
+// @Param slots body []models.Slot true "List of lessons"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} utils.Problem "Invalid request payload"
+// @Failure 403 {object} utils.Problem "Only admins may change the timetable"
+// @Failure 409 {object} utils.Problem "A teacher, class or room is already booked at that time"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /timetable [post]
+func AddSlotsHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var newSlots []models.Slot
+	err = decoder.Decode(&newSlots)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	var validationErrs []utils.FieldError
+	for i, slot := range newSlots {
+		validationErrs = append(validationErrs, utils.WithIndex(utils.ValidateStruct(slot), i)...)
+	}
+	if err := validationError(validationErrs); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	added, err := sqlconnect.AddSlotsDBHandler(r.Context(), newSlots)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	response := struct {
+		Status string        `json:"status"`
+		Count  int           `json:"count"`
+		Data   []models.Slot `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(added),
+		Data:   added,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// UpdateSlotHandler godoc
+// @Summary Move a lesson of the timetable
+// @Description Replace a slot of the timetable, with the same checks as adding one. Admins only.
+// @Tags timetable
+// @Accept json
+// @Produce json,application/problem+json
+// @Param id path int true "Slot ID"
+// @Param slot body models.Slot true "Updated lesson"
+// @Success 200 {object} models.Slot
+// @Failure 400 {object} utils.Problem "Invalid request payload or ID"
+// @Failure 403 {object} utils.Problem "Only admins may change the timetable"
+// @Failure 404 {object} utils.Problem "Slot not found"
+// @Failure 409 {object} utils.Problem "A teacher, class or room is already booked at that time"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /timetable/{id} [put]
+func UpdateSlotHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Slot ID")
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var updatedSlot models.Slot
+	err = decoder.Decode(&updatedSlot)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if err := validationError(utils.ValidateStruct(updatedSlot)); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	slot, err := sqlconnect.UpdateSlotDBHandler(r.Context(), id, updatedSlot)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(slot)
+}
+
+// DeleteSlotHandler godoc
+// @Summary Remove a lesson from the timetable
+// @Description Delete a slot of the timetable. Admins only.
+// @Tags timetable
+// @Produce application/problem+json
+// @Param id path int true "Slot ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} utils.Problem "Invalid Slot ID"
+// @Failure 403 {object} utils.Problem "Only admins may change the timetable"
+// @Failure 404 {object} utils.Problem "Slot not found"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /timetable/{id} [delete]
+func DeleteSlotHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Slot ID")
+		return
+	}
+
+	err = sqlconnect.DeleteSlotDBHandler(r.Context(), id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetPeriodsHandler godoc
+// @Summary List the periods
+// @Description Get the periods of the school day with their times, the same on every weekday
+// @Tags timetable
+// @Produce json,application/problem+json
+// @Success 200 {object} map[string]interface{} "Periods"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /timetable/periods [get]
+func GetPeriodsHandler(w http.ResponseWriter, r *http.Request) {
+	periods, err := sqlconnect.GetPeriodsDBHandler(r.Context())
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status string          `json:"status"`
+		Count  int             `json:"count"`
+		Data   []models.Period `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(periods),
+		Data:   periods,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// ReplacePeriodsHandler godoc
+// @Summary Replace the periods
+// @Description Replace the periods of the school day. Periods have distinct numbers and don't overlap, a period the timetable uses can't be left out. Admins only.
+// @Tags timetable
+// @Accept json
+// @Produce json,application/problem+json
+// @Param periods body []models.Period true "Periods"
+// @Success 200 {object} map[string]interface{} "Periods"
+// @Failure 400 {object} utils.Problem "Invalid request payload"
+// @Failure 403 {object} utils.Problem "Only admins may change periods"
+// @Failure 409 {object} utils.Problem "A period left out is used by the timetable"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /timetable/periods [put]
+func ReplacePeriodsHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var periods []models.Period
+	err = decoder.Decode(&periods)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	var validationErrs []utils.FieldError
+	for i, period := range periods {
+		errs := utils.ValidateStruct(period)
+		if len(errs) == 0 && period.EndTime <= period.StartTime {
+			errs = append(errs, utils.FieldError{Field: "end_time", Message: "must be after start_time"})
+		}
+		for j, other := range periods[:i] {
+			if other.Number == period.Number {
+				errs = append(errs, utils.FieldError{Field: "number", Message: fmt.Sprintf("must not be used twice, item %d has it too", j)})
+			} else if other.StartTime < period.EndTime && period.StartTime < other.EndTime {
+				errs = append(errs, utils.FieldError{Field: "start_time", Message: fmt.Sprintf("must not overlap period %d", other.Number)})
+			}
+		}
+		validationErrs = append(validationErrs, utils.WithIndex(errs, i)...)
+	}
+	if err := validationError(validationErrs); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	err = sqlconnect.ReplacePeriodsDBHandler(r.Context(), periods)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	GetPeriodsHandler(w, r)
+}
+
+// GetRoomsHandler godoc
+// @Summary List the rooms
+// @Description Get the rooms lessons take place in, by name
+// @Tags timetable
+// @Produce json,application/problem+json
+// @Success 200 {object} map[string]interface{} "Rooms"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /timetable/rooms [get]
+func GetRoomsHandler(w http.ResponseWriter, r *http.Request) {
+	rooms, err := sqlconnect.GetRoomsDBHandler(r.Context())
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status string        `json:"status"`
+		Count  int           `json:"count"`
+		Data   []models.Room `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(rooms),
+		Data:   rooms,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// AddRoomsHandler godoc
+// @Summary Add rooms
+// @Description Add one or more rooms, their names are unique. Admins only.
+// @Tags timetable
+// @Accept json
+// @Produce json,application/problem+json
+// @Param rooms body []models.Room true "List of rooms"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} utils.Problem "Invalid request payload"
+// @Failure 403 {object} utils.Problem "Only admins may change rooms"
+// @Failure 409 {object} utils.Problem "A room with this name already exists"
+// @Failure 422 {object} utils.Problem "Validation failed"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /timetable/rooms [post]
+func AddRoomsHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var newRooms []models.Room
+	err = decoder.Decode(&newRooms)
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	var validationErrs []utils.FieldError
+	for i, room := range newRooms {
+		validationErrs = append(validationErrs, utils.WithIndex(utils.ValidateStruct(room), i)...)
+	}
+	if err := validationError(validationErrs); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	added, err := sqlconnect.AddRoomsDBHandler(r.Context(), newRooms)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	response := struct {
+		Status string        `json:"status"`
+		Count  int           `json:"count"`
+		Data   []models.Room `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(added),
+		Data:   added,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// DeleteRoomHandler godoc
+// @Summary Delete a room
+// @Description Delete a room no lesson of the timetable takes place in. Admins only.
+// @Tags timetable
+// @Produce application/problem+json
+// @Param id path int true "Room ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} utils.Problem "Invalid Room ID"
+// @Failure 403 {object} utils.Problem "Only admins may change rooms"
+// @Failure 404 {object} utils.Problem "Room not found"
+// @Failure 409 {object} utils.Problem "The room is used by the timetable"
+// @Failure 500 {object} utils.Problem "Internal server error"
+// @Router /timetable/rooms/{id} [delete]
+func DeleteRoomHandler(w http.ResponseWriter, r *http.Request) {
+	role, _ := r.Context().Value(utils.ContextKey("role")).(string)
+	_, err := utils.AuthorizeUser(role, "admin")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteProblem(w, r, http.StatusBadRequest, "Invalid Room ID")
+		return
+	}
+
+	err = sqlconnect.DeleteRoomDBHandler(r.Context(), id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,39 @@
+package middlewares
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// bufferedResponseWriter holds the response back so a middleware can inspect
+// it, e.g. to validate it or answer with 304, before it reaches the client
+type bufferedResponseWriter struct {
+	header      http.Header
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (bw *bufferedResponseWriter) Header() http.Header {
+	return bw.header
+}
+
+func (bw *bufferedResponseWriter) WriteHeader(code int) {
+	if !bw.wroteHeader {
+		bw.wroteHeader = true
+		bw.status = code
+	}
+}
+
+func (bw *bufferedResponseWriter) Write(b []byte) (int, error) {
+	bw.wroteHeader = true
+	return bw.body.Write(b)
+}
+
+func (bw *bufferedResponseWriter) flushTo(w http.ResponseWriter) {
+	for key, values := range bw.header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(bw.status)
+	w.Write(bw.body.Bytes())
+}
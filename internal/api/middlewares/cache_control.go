@@ -0,0 +1,36 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// CacheOptions maps route patterns, as registered on the mux but without the
+// method (e.g. "/teachers/{id}"), to the Cache-Control of their GET responses
+type CacheOptions struct {
+	Default  string
+	Policies map[string]string
+}
+
+// CacheControl sets the Cache-Control header per route. Only GET and HEAD use
+// the route's policy, other methods and unknown routes get the default.
+func CacheControl(mux *http.ServeMux, options CacheOptions) func(http.Handler) http.Handler {
+	fmt.Println("CacheControl Middleware...")
+	return func(next http.Handler) http.Handler {
+		fmt.Println("CacheControl Middleware being returned...")
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			policy := options.Default
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				if routePolicy, ok := options.Policies[routePattern(mux, r)]; ok {
+					policy = routePolicy
+				}
+			}
+			if policy != "" {
+				w.Header().Set("Cache-Control", policy)
+			}
+
+			next.ServeHTTP(w, r)
+			fmt.Println("CacheControl Middleware ends...")
+		})
+	}
+}
@@ -3,6 +3,8 @@ package middlewares
 import (
 	"fmt"
 	"net/http"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
 )
 
 // api is hosted at www.myapi.com
@@ -25,13 +27,13 @@ func Cors(next http.Handler) http.Handler {
 		if isOriginAllowed(origin) {
 			w.Header().Set("Access-Control-Allow-Origin", origin)
 		} else {
-			http.Error(w, "Not allowed by CORS", http.StatusForbidden)
+			utils.WriteProblem(w, r, http.StatusForbidden, "Not allowed by CORS")
 			return
 		}
 
 		// Set other CORS headers
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		w.Header().Set("Access-Control-Expose-Headers", "Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, If-Match, X-Request-ID, Idempotency-Key")
+		w.Header().Set("Access-Control-Expose-Headers", "Authorization, ETag, Location, X-Request-ID, Idempotent-Replayed")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE")
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
 		w.Header().Set("Access-Control-Max-Age", "3600")
@@ -0,0 +1,120 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/repository/sqlconnect"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+// replayedHeaders are the response headers stored with an idempotent response
+var replayedHeaders = []string{"Content-Type", "ETag", "Last-Modified", "Location"}
+
+// Idempotency makes POST, PUT, PATCH and DELETE requests safe to retry. The first response to a
+// request carrying an Idempotency-Key header is stored for ttl and replayed for every retry with the
+// same key, instead of running the request again. Keys are scoped to the logged in exec, so it must
+// run after the JWT middleware.
+func Idempotency(ttl time.Duration) func(http.Handler) http.Handler {
+	fmt.Println("Idempotency Middleware...")
+	return func(next http.Handler) http.Handler {
+		fmt.Println("Idempotency Middleware being returned...")
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get("Idempotency-Key")
+			if key == "" || r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > 255 {
+				utils.WriteProblem(w, r, http.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				utils.WriteProblem(w, r, http.StatusBadRequest, "Error reading request body")
+				return
+			}
+			r.Body.Close()
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			scope := idempotencyScope(r)
+			hash := requestHash(r, body)
+
+			stored, err := sqlconnect.ClaimIdempotencyKeyDBHandler(r.Context(), scope, key, hash, ttl)
+			if err != nil {
+				utils.WriteError(w, r, err)
+				return
+			}
+			if stored != nil {
+				replay(w, r, stored, hash)
+				return
+			}
+
+			recorder := &bufferedResponseWriter{header: make(http.Header), status: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			// the key outlives a cancelled request, otherwise a retry would be stuck in progress
+			ctx := context.WithoutCancel(r.Context())
+			if recorder.status >= http.StatusInternalServerError {
+				// server errors are not final, let the client retry with the same key
+				sqlconnect.ReleaseIdempotencyKeyDBHandler(ctx, scope, key)
+			} else {
+				response := models.IdempotentResponse{Status: recorder.status, Header: make(map[string]string), Body: recorder.body.Bytes()}
+				for _, name := range replayedHeaders {
+					if value := recorder.header.Get(name); value != "" {
+						response.Header[name] = value
+					}
+				}
+				sqlconnect.SaveIdempotentResponseDBHandler(ctx, scope, key, response)
+			}
+
+			recorder.flushTo(w)
+			fmt.Println("Idempotency Middleware ends...")
+		})
+	}
+}
+
+func replay(w http.ResponseWriter, r *http.Request, stored *models.IdempotentResponse, hash string) {
+	if stored.RequestHash != hash {
+		utils.WriteError(w, r, utils.ValidationError("Idempotency-Key was already used for a different request",
+			utils.FieldError{Field: "Idempotency-Key", Message: "must be unique per request"}))
+		return
+	}
+	if !stored.Completed {
+		utils.WriteProblem(w, r, http.StatusConflict, "A request with this Idempotency-Key is still in progress")
+		return
+	}
+
+	for name, value := range stored.Header {
+		w.Header().Set(name, value)
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(stored.Status)
+	w.Write(stored.Body)
+}
+
+// idempotencyScope keeps the keys of different execs apart, requests made
+// before logging in share the anonymous scope
+func idempotencyScope(r *http.Request) string {
+	if uid, ok := r.Context().Value(utils.ContextKey("userId")).(float64); ok {
+		return "exec:" + strconv.Itoa(int(uid))
+	}
+	return "anonymous"
+}
+
+// requestHash identifies a request by its method, path, query and body
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
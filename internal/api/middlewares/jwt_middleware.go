@@ -21,7 +21,7 @@ func JWTMiddleware(next http.Handler) http.Handler {
 
 		token, err := r.Cookie("Bearer")
 		if err != nil {
-			http.Error(w, "Authorization Header Missing", http.StatusUnauthorized)
+			utils.WriteProblem(w, r, http.StatusUnauthorized, "Authorization Header Missing")
 			return
 		}
 
@@ -37,27 +37,28 @@ func JWTMiddleware(next http.Handler) http.Handler {
 		})
 		if err != nil {
 			if errors.Is(err, jwt.ErrTokenExpired) {
-				http.Error(w, "Token Expired", http.StatusUnauthorized)
+				utils.WriteProblem(w, r, http.StatusUnauthorized, "Token Expired")
 				return
 			} else if errors.Is(err, jwt.ErrTokenMalformed) {
-				http.Error(w, "Token Malformed", http.StatusUnauthorized)
+				utils.WriteProblem(w, r, http.StatusUnauthorized, "Token Malformed")
 				return
 			}
 			utils.ErrorHandler(err, "")
-			http.Error(w, err.Error(), http.StatusUnauthorized)
+			utils.WriteProblem(w, r, http.StatusUnauthorized, err.Error())
 			return
 		}
 
 		if parsedToken.Valid {
 			log.Println("Valid JWT")
 		} else {
-			http.Error(w, "Invalid Login Token", http.StatusUnauthorized)
+			utils.WriteProblem(w, r, http.StatusUnauthorized, "Invalid Login Token")
 			log.Println("Invalid JWT:", token.Value)
+			return
 		}
 
 		claims, ok := parsedToken.Claims.(jwt.MapClaims)
 		if !ok {
-			http.Error(w, "Invalid Login Token", http.StatusUnauthorized)
+			utils.WriteProblem(w, r, http.StatusUnauthorized, "Invalid Login Token")
 			log.Println("Invalid Login Token:", token.Value)
 			return
 		}
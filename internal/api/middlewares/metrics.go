@@ -0,0 +1,46 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/metrics"
+)
+
+// Metrics records request counts and latencies labelled by the route pattern
+// that mux resolves for the request, so path parameters don't explode label cardinality.
+func Metrics(mux *http.ServeMux) func(http.Handler) http.Handler {
+	fmt.Println("Metrics Middleware...")
+	return func(next http.Handler) http.Handler {
+		fmt.Println("Metrics Middleware being returned...")
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			route := routePattern(mux, r)
+			wrappedWriter := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+
+			next.ServeHTTP(wrappedWriter, r)
+
+			status := strconv.Itoa(wrappedWriter.status)
+			metrics.HTTPRequestsTotal.WithLabelValues(route, r.Method, status).Inc()
+			metrics.HTTPRequestDuration.WithLabelValues(route, r.Method, status).Observe(time.Since(start).Seconds())
+			fmt.Println("Metrics Middleware ends...")
+		})
+	}
+}
+
+// routePattern returns the path part of the pattern registered for r, e.g. "/students/{id}"
+func routePattern(mux *http.ServeMux, r *http.Request) string {
+	_, pattern := mux.Handler(r)
+	if pattern == "" {
+		return "unmatched"
+	}
+	// strip the method prefix from patterns like "GET /students/{id}"
+	if i := strings.Index(pattern, " "); i >= 0 {
+		pattern = pattern[i+1:]
+	}
+	return pattern
+}
@@ -0,0 +1,211 @@
+package middlewares
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+const (
+	xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	pdfContentType  = "application/pdf"
+)
+
+// fileContentTypes are the files that are uploaded or exported rather than JSON
+var fileContentTypes = map[string]bool{
+	"text/csv":      true,
+	xlsxContentType: true,
+	pdfContentType:  true,
+}
+
+func init() {
+	openapi3filter.RegisterBodyDecoder(xlsxContentType, openapi3filter.FileBodyDecoder)
+	openapi3filter.RegisterBodyDecoder(pdfContentType, openapi3filter.FileBodyDecoder)
+}
+
+// OpenAPI validation modes
+const (
+	OpenAPIValidationOff      = ""
+	OpenAPIValidationRequests = "requests"
+	OpenAPIValidationStrict   = "strict"
+)
+
+type OpenAPIValidationOptions struct {
+	// SpecPath is the Swagger 2.0 document generated by swag, e.g. docs/swagger.json
+	SpecPath string
+	// Mode is one of OpenAPIValidationOff, OpenAPIValidationRequests or OpenAPIValidationStrict.
+	// Strict mode also validates responses and is meant for development only, as it buffers every response.
+	Mode string
+}
+
+// OpenAPIValidation validates requests, and in strict mode responses, against the API spec.
+// Requests that don't match get a 400, responses that don't match are logged and replaced by a 500
+// so that drift between the handlers and the spec is noticed early.
+// Routes that are not in the spec, like /metrics, are passed through untouched.
+func OpenAPIValidation(options OpenAPIValidationOptions) (func(http.Handler) http.Handler, error) {
+	fmt.Println("OpenAPI Validation Middleware...")
+	if options.Mode == OpenAPIValidationOff {
+		return func(next http.Handler) http.Handler { return next }, nil
+	}
+	if options.Mode != OpenAPIValidationRequests && options.Mode != OpenAPIValidationStrict {
+		return nil, fmt.Errorf("unknown OpenAPI validation mode %q", options.Mode)
+	}
+
+	router, err := loadSpecRouter(options.SpecPath)
+	if err != nil {
+		return nil, err
+	}
+
+	filterOptions := &openapi3filter.Options{
+		MultiError:            true,
+		IncludeResponseStatus: true,
+		AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+	}
+
+	return func(next http.Handler) http.Handler {
+		fmt.Println("OpenAPI Validation Middleware being returned...")
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, pathParams, err := router.FindRoute(r)
+			if err != nil {
+				if options.Mode == OpenAPIValidationStrict {
+					log.Printf("OpenAPI: %s %s is not described in the spec\n", r.Method, r.URL.Path)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    filterOptions,
+			}
+			err = openapi3filter.ValidateRequest(r.Context(), input)
+			if err != nil {
+				writeSpecMismatch(w, r, utils.KindBadRequest, "Request does not match the API specification", err)
+				return
+			}
+
+			if options.Mode != OpenAPIValidationStrict {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			recorder := &bufferedResponseWriter{header: make(http.Header), status: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			err = openapi3filter.ValidateResponse(r.Context(), &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: input,
+				Status:                 recorder.status,
+				Header:                 recorder.header,
+				Body:                   io.NopCloser(bytes.NewReader(recorder.body.Bytes())),
+				Options:                filterOptions,
+			})
+			if err != nil {
+				log.Printf("OpenAPI: response of %s %s (status %d) does not match the spec: %v\n", r.Method, r.URL.Path, recorder.status, err)
+				writeSpecMismatch(w, r, utils.KindInternal, "Response does not match the API specification", err)
+				return
+			}
+
+			recorder.flushTo(w)
+			fmt.Println("OpenAPI Validation Middleware ends...")
+		})
+	}, nil
+}
+
+func loadSpecRouter(specPath string) (routers.Router, error) {
+	data, err := os.ReadFile(specPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading OpenAPI spec: %w", err)
+	}
+
+	var doc2 openapi2.T
+	if err := doc2.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("parsing OpenAPI spec: %w", err)
+	}
+
+	doc3, err := openapi2conv.ToV3(&doc2)
+	if err != nil {
+		return nil, fmt.Errorf("converting OpenAPI spec: %w", err)
+	}
+	// match requests by path only, whatever host and scheme the API is served on
+	doc3.Servers = nil
+
+	// swag gives every content type of an operation the same schema, so the CSV and Excel files
+	// the import endpoints accept get the form as their schema and exports the JSON listing
+	for _, pathItem := range doc3.Paths.Map() {
+		for _, operation := range pathItem.Operations() {
+			if operation.RequestBody != nil && operation.RequestBody.Value != nil {
+				setFileSchemas(operation.RequestBody.Value.Content)
+			}
+			for _, response := range operation.Responses.Map() {
+				if response.Value != nil {
+					setFileSchemas(response.Value.Content)
+				}
+			}
+		}
+	}
+
+	return gorillamux.NewRouter(doc3)
+}
+
+func setFileSchemas(content openapi3.Content) {
+	for contentType, media := range content {
+		if fileContentTypes[contentType] {
+			media.Schema = openapi3.NewStringSchema().WithFormat("binary").NewRef()
+		}
+	}
+}
+
+// writeSpecMismatch writes a problem response listing every validation failure in err
+func writeSpecMismatch(w http.ResponseWriter, r *http.Request, kind utils.ErrorKind, detail string, err error) {
+	var fieldErrs []utils.FieldError
+	for _, e := range flattenErrors(err) {
+		fieldErrs = append(fieldErrs, specFieldError(e))
+	}
+	utils.WriteError(w, r, &utils.AppError{Kind: kind, Message: detail, Fields: fieldErrs, Err: err})
+}
+
+func flattenErrors(err error) []error {
+	var multi openapi3.MultiError
+	if !errors.As(err, &multi) {
+		return []error{err}
+	}
+	var errs []error
+	for _, e := range multi {
+		errs = append(errs, flattenErrors(e)...)
+	}
+	return errs
+}
+
+// specFieldError names the parameter or body property that failed validation
+func specFieldError(err error) utils.FieldError {
+	fieldErr := utils.FieldError{Field: "body", Message: err.Error()}
+
+	var requestErr *openapi3filter.RequestError
+	if errors.As(err, &requestErr) && requestErr.Parameter != nil {
+		fieldErr.Field = requestErr.Parameter.In + "." + requestErr.Parameter.Name
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		fieldErr.Message = schemaErr.Reason
+		if pointer := schemaErr.JSONPointer(); len(pointer) > 0 && fieldErr.Field == "body" {
+			fieldErr.Field = "body." + strings.Join(pointer, ".")
+		}
+	}
+	return fieldErr
+}
@@ -6,6 +6,9 @@ import (
 	"net/http"
 	"sync"
 	"time"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/metrics"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
 )
 
 type rateLimiter struct {
@@ -47,7 +50,8 @@ func (rl *rateLimiter) Middleware(next http.Handler) http.Handler {
 		// fmt.Printf("Vistor count from %v is %v\n", visitorIP, rl.visitors[visitorIP])
 
 		if rl.visitors[visitorIP] > rl.limit {
-			http.Error(w, "Too many requests", http.StatusTooManyRequests)
+			metrics.RateLimitRejectionsTotal.Inc()
+			utils.WriteProblem(w, r, http.StatusTooManyRequests, "Too many requests")
 			return
 		}
 
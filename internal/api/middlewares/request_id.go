@@ -0,0 +1,52 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+// a client supplied X-Request-ID is kept only if it looks like an id, so it can't inject into logs
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID tags every request with an id, taken from X-Request-ID or generated, and echoes it
+// back in the response. The id and the client address are stored in the request context for
+// logging and for the audit log.
+func RequestID(next http.Handler) http.Handler {
+	fmt.Println("Request ID Middleware...")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println("Request ID Middleware being returned...")
+
+		requestID := r.Header.Get("X-Request-ID")
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", requestID)
+
+		clientIP, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			clientIP = r.RemoteAddr
+		}
+
+		ctx := context.WithValue(r.Context(), utils.ContextKey("requestId"), requestID)
+		ctx = context.WithValue(ctx, utils.ContextKey("clientIP"), clientIP)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+		fmt.Println("Request ID Middleware ends...")
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -12,16 +12,13 @@ func ResponseTimeMiddleware(next http.Handler) http.Handler {
 		fmt.Println("Response Time Middleware being returned...")
 		start := time.Now()
 
-		//Create a custom ResponseWriter to capture the status code
-		wrappedWriter := &responseWriter{ResponseWriter: w, status: http.StatusOK}
-
-		// Calculate the duration
-		duration := time.Since(start)
-		w.Header().Set("X-Response-Time", duration.String())
+		// Create a custom ResponseWriter to capture the status code
+		// X-Response-Time is set once the handler writes its headers
+		wrappedWriter := &responseWriter{ResponseWriter: w, status: http.StatusOK, start: start}
 		next.ServeHTTP(wrappedWriter, r)
 
 		// Log the request details
-		duration = time.Since(start)
+		duration := time.Since(start)
 		fmt.Printf("Method: %s, URL: %s, Status: %d, Duration: %v\n", r.Method, r.URL, wrappedWriter.status, duration.String())
 		fmt.Println("Sent Response from Response Time Middleware")
 	})
@@ -30,10 +27,25 @@ func ResponseTimeMiddleware(next http.Handler) http.Handler {
 // custom response writer to capture status code
 type responseWriter struct {
 	http.ResponseWriter
-	status int
+	status      int
+	start       time.Time
+	wroteHeader bool
 }
 
 func (rw *responseWriter) WriteHeader(code int) {
-	rw.status = code
+	if !rw.wroteHeader {
+		rw.wroteHeader = true
+		rw.status = code
+		if !rw.start.IsZero() {
+			rw.Header().Set("X-Response-Time", time.Since(rw.start).String())
+		}
+	}
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+	return rw.ResponseWriter.Write(b)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"mime"
 	"net/http"
 	"net/url"
 	"strings"
@@ -23,7 +24,7 @@ func XSSMiddleware(next http.Handler) http.Handler {
 		// Sanitize the URL Path
 		sanitizedPath, err := clean(r.URL.Path)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			utils.WriteError(w, r, err)
 			return
 		}
 
@@ -33,7 +34,7 @@ func XSSMiddleware(next http.Handler) http.Handler {
 		for key, values := range params {
 			sanitizedKey, err := clean(key)
 			if err != nil {
-				http.Error(w, err.Error(), http.StatusBadRequest)
+				utils.WriteError(w, r, err)
 				return
 			}
 
@@ -41,7 +42,7 @@ func XSSMiddleware(next http.Handler) http.Handler {
 			for _, value := range values {
 				cleanValue, err := clean(value)
 				if err != nil {
-					http.Error(w, err.Error(), http.StatusBadRequest)
+					utils.WriteError(w, r, err)
 					return
 				}
 				sanitizedValues = append(sanitizedValues, cleanValue.(string))
@@ -57,7 +58,7 @@ func XSSMiddleware(next http.Handler) http.Handler {
 			if r.Body != nil {
 				bodyBytes, err := io.ReadAll(r.Body)
 				if err != nil {
-					http.Error(w, utils.ErrorHandler(err, "Error reading request body").Error(), http.StatusBadRequest)
+					utils.WriteProblem(w, r, http.StatusBadRequest, utils.ErrorHandler(err, "Error reading request body").Error())
 					return
 				}
 
@@ -70,35 +71,36 @@ func XSSMiddleware(next http.Handler) http.Handler {
 					var inputData interface{}
 					err := json.NewDecoder(bytes.NewReader([]byte(bodyString))).Decode(&inputData)
 					if err != nil {
-						http.Error(w, utils.ErrorHandler(err, "Invalid JSON body").Error(), http.StatusBadRequest)
+						utils.WriteProblem(w, r, http.StatusBadRequest, utils.ErrorHandler(err, "Invalid JSON body").Error())
 						return
 					}
 
 					// Sanitize the JSON body
 					sanitizedData, err := clean(inputData)
 					if err != nil {
-						http.Error(w, err.Error(), http.StatusBadRequest)
+						utils.WriteError(w, r, err)
 						return
 					}
 
 					// Marshal the sanitized data back to the body
 					sanitizedBody, err := json.Marshal(sanitizedData)
 					if err != nil {
-						http.Error(w, utils.ErrorHandler(err, "Error sanitizing body").Error(), http.StatusBadRequest)
+						utils.WriteProblem(w, r, http.StatusBadRequest, utils.ErrorHandler(err, "Error sanitizing body").Error())
 						return
 					}
 
 					r.Body = io.NopCloser(bytes.NewReader(sanitizedBody))
-					fmt.Println("Sanitized body:", string(sanitizedBody))
 				} else {
 					log.Println("Request body is empty")
 				}
 			} else {
 				log.Println("No body in the request")
 			}
+		} else if isUpload(r) {
+			log.Printf("Received %s upload, its cells are sanitized by the import handler.\n", r.Header.Get("Content-Type"))
 		} else if r.Header.Get("Content-Type") != "" {
 			log.Printf("Received request with unsupported Content-Type: %s. Expected application/json.\n", r.Header.Get("Content-Type"))
-			http.Error(w, "Unsupported Content-Type. Please use application/json.", http.StatusUnsupportedMediaType)
+			utils.WriteProblem(w, r, http.StatusUnsupportedMediaType, "Unsupported Content-Type. Please use application/json.")
 			return
 		}
 
@@ -107,6 +109,23 @@ func XSSMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// uploadPaths accept CSV and Excel files as well as JSON
+var uploadPaths = map[string]bool{
+	"/students/import": true,
+	"/teachers/import": true,
+}
+
+var uploadContentTypes = map[string]bool{
+	"text/csv":            true,
+	"multipart/form-data": true,
+	xlsxContentType:       true,
+}
+
+func isUpload(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && uploadPaths[r.URL.Path] && uploadContentTypes[mediaType]
+}
+
 // Clean sanitizes input data to prevent XSS attacks
 func clean(data interface{}) (interface{}, error) {
 
@@ -124,7 +143,7 @@ func clean(data interface{}) (interface{}, error) {
 	case string:
 		return sanitizeString(v), nil
 	default:
-		return nil, utils.ErrorHandler(fmt.Errorf("unsupported type: %T", data), fmt.Sprintf("unsupported type: %T", data))
+		return nil, utils.BadRequestError("Request body must be a JSON object, array or string")
 	}
 }
 
@@ -21,7 +21,6 @@ func SecurityHeaders(next http.Handler) http.Handler {
 		w.Header().Set("X-Powered-By", "Django")
 		w.Header().Set("Server", "")
 		w.Header().Set("X-Permitted-Cross-Domain-Policies", "none")
-		w.Header().Set("Cache-Control", "no-store, no-cache, must-revalidate, max-age=0")
 		w.Header().Set("Cross-Origin-Resource-Policy", "same-origin")
 		w.Header().Set("Cross-Origin-Opener-Policy", "same-origin")
 		w.Header().Set("Cross-Origin-Embedder-Policy", "require-corp")
@@ -0,0 +1,50 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing starts the server span for each request, continuing the trace from an incoming
+// W3C traceparent header. It must wrap the whole middleware chain so that middleware,
+// handler and SQL spans become its children.
+func Tracing(mux *http.ServeMux) func(http.Handler) http.Handler {
+	fmt.Println("Tracing Middleware...")
+	return func(next http.Handler) http.Handler {
+		fmt.Println("Tracing Middleware being returned...")
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+			route := routePattern(mux, r)
+			ctx, span := utils.Tracer().Start(ctx, r.Method+" "+route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(r.Method),
+					semconv.HTTPRoute(route),
+					semconv.URLPath(r.URL.Path),
+					semconv.ClientAddress(r.RemoteAddr),
+				),
+			)
+			defer span.End()
+
+			// let clients correlate their request with the trace
+			otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(w.Header()))
+
+			wrappedWriter := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(wrappedWriter, r.WithContext(ctx))
+
+			span.SetAttributes(semconv.HTTPResponseStatusCode(wrappedWriter.status))
+			if wrappedWriter.status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(wrappedWriter.status))
+			}
+			fmt.Println("Tracing Middleware ends...")
+		})
+	}
+}
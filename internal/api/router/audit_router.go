@@ -0,0 +1,11 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/api/handlers"
+)
+
+func auditRouter(mux *http.ServeMux) {
+	mux.HandleFunc("GET /audit", handlers.GetAuditEntriesHandler)
+}
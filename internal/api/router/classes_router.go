@@ -0,0 +1,38 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/api/handlers"
+)
+
+func classesRouter(mux *http.ServeMux) {
+	mux.HandleFunc("GET /classes", handlers.GetClassesHandler)
+	mux.HandleFunc("POST /classes", handlers.AddClassesHandler)
+
+	mux.HandleFunc("GET /classes/{id}", handlers.GetOneClassHandler)
+	mux.HandleFunc("PUT /classes/{id}", handlers.UpdateClassHandler)
+	mux.HandleFunc("PATCH /classes/{id}", handlers.PatchOneClassHandler)
+	mux.HandleFunc("DELETE /classes/{id}", handlers.DeleteOneClassHandler)
+	mux.HandleFunc("GET /classes/{id}/students", handlers.GetClassStudentsHandler)
+
+	// Attendance
+	mux.HandleFunc("GET /classes/{id}/attendance", handlers.GetClassAttendanceHandler)
+	mux.HandleFunc("POST /classes/{id}/attendance", handlers.SubmitAttendanceHandler)
+
+	// Gradebook
+	mux.HandleFunc("GET /classes/{id}/assessments", handlers.GetAssessmentsHandler)
+	mux.HandleFunc("POST /classes/{id}/assessments", handlers.AddAssessmentsHandler)
+	mux.HandleFunc("GET /classes/{id}/assessments/{assessmentId}", handlers.GetOneAssessmentHandler)
+	mux.HandleFunc("DELETE /classes/{id}/assessments/{assessmentId}", handlers.DeleteAssessmentHandler)
+	mux.HandleFunc("PUT /classes/{id}/assessments/{assessmentId}/marks", handlers.SubmitMarksHandler)
+	mux.HandleFunc("GET /classes/{id}/grades", handlers.GetClassGradesHandler)
+
+	mux.HandleFunc("GET /gradebands", handlers.GetGradeBandsHandler)
+	mux.HandleFunc("PUT /gradebands", handlers.ReplaceGradeBandsHandler)
+
+	// Report cards
+	mux.HandleFunc("POST /classes/{id}/reportcards", handlers.GenerateReportCardsHandler)
+
+	mux.HandleFunc("GET /classes/{id}/timetable", handlers.GetClassTimetableHandler)
+}
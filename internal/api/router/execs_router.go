@@ -15,6 +15,7 @@ func execsRouter(mux *http.ServeMux) {
 	mux.HandleFunc("GET /execs/{id}", handlers.GetOneExecHandler)
 	mux.HandleFunc("PATCH /execs/{id}", handlers.PatchOneExecHandler)
 	mux.HandleFunc("DELETE /execs/{id}", handlers.DeleteOneExecHandler)
+	mux.HandleFunc("POST /execs/{id}/restore", handlers.RestoreExecHandler)
 	mux.HandleFunc("POST /execs/{id}/updatepassword", handlers.UpdatePasswordHandler)
 	
 	mux.HandleFunc("POST /execs/login", handlers.LoginHandler)
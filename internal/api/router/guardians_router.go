@@ -0,0 +1,18 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/api/handlers"
+)
+
+func guardiansRouter(mux *http.ServeMux) {
+	mux.HandleFunc("GET /guardians", handlers.GetGuardiansHandler)
+	mux.HandleFunc("POST /guardians", handlers.AddGuardiansHandler)
+
+	mux.HandleFunc("GET /guardians/{id}", handlers.GetOneGuardianHandler)
+	mux.HandleFunc("PUT /guardians/{id}", handlers.UpdateGuardianHandler)
+	mux.HandleFunc("PATCH /guardians/{id}", handlers.PatchOneGuardianHandler)
+	mux.HandleFunc("DELETE /guardians/{id}", handlers.DeleteOneGuardianHandler)
+	mux.HandleFunc("GET /guardians/{id}/students", handlers.GetGuardianStudentsHandler)
+}
@@ -0,0 +1,11 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/api/handlers"
+)
+
+func importsRouter(mux *http.ServeMux) {
+	mux.HandleFunc("GET /imports/{id}", handlers.GetImportJobHandler)
+}
@@ -0,0 +1,12 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/api/handlers"
+)
+
+func reportCardsRouter(mux *http.ServeMux) {
+	mux.HandleFunc("GET /reportcards/{id}", handlers.GetStoredReportCardHandler)
+	mux.HandleFunc("GET /reportcards/jobs/{id}", handlers.GetReportCardJobHandler)
+}
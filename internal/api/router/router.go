@@ -6,6 +6,8 @@ import (
 	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/api/handlers"
 	_ "github.com/aayushxrj/go-rest-api-school-mgmt/docs" 
     httpSwagger "github.com/swaggo/http-swagger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func Router() *http.ServeMux {
@@ -17,9 +19,19 @@ func Router() *http.ServeMux {
 	// Swagger UI route
     mux.Handle("/swagger/", httpSwagger.WrapHandler)
 
+	// Prometheus metrics, compression is left to the Compression middleware
+	mux.Handle("GET /metrics", promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{DisableCompression: true}))
+
 	studentsRouter(mux)
 	teachersRouter(mux)
+	classesRouter(mux)
 	execsRouter(mux)
+	auditRouter(mux)
+	importsRouter(mux)
+	reportCardsRouter(mux)
+	termsRouter(mux)
+	timetableRouter(mux)
+	guardiansRouter(mux)
 
 	return mux
 }
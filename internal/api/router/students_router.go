@@ -10,11 +10,24 @@ func studentsRouter(mux *http.ServeMux) {
 	// Student CRUD
 	mux.HandleFunc("GET /students", handlers.GetStudentsHandler)
 	mux.HandleFunc("POST /students", handlers.AddStudentHandler)
+	mux.HandleFunc("PUT /students", handlers.UpsertStudentsHandler)
 	mux.HandleFunc("PATCH /students", handlers.PatchStudentsHandler)
 	mux.HandleFunc("DELETE /students", handlers.DeleteStudentsHandler)
+	mux.HandleFunc("POST /students/import", handlers.ImportStudentsHandler)
 
 	mux.HandleFunc("GET /students/{id}", handlers.GetOneStudentHandler)
 	mux.HandleFunc("PUT /students/{id}", handlers.UpdateStudentHandler)
 	mux.HandleFunc("PATCH /students/{id}", handlers.PatchOneStudentHandler)
 	mux.HandleFunc("DELETE /students/{id}", handlers.DeleteOneStudentHandler)
+	mux.HandleFunc("POST /students/{id}/restore", handlers.RestoreStudentHandler)
+	mux.HandleFunc("GET /students/{id}/history", handlers.GetStudentHistoryHandler)
+	mux.HandleFunc("GET /students/{id}/attendance", handlers.GetStudentAttendanceHandler)
+	mux.HandleFunc("GET /students/{id}/grades", handlers.GetStudentGradesHandler)
+	mux.HandleFunc("GET /students/{id}/reportcard", handlers.GetReportCardHandler)
+	mux.HandleFunc("GET /students/{id}/enrollments", handlers.GetStudentEnrollmentsHandler)
+	mux.HandleFunc("GET /students/{id}/guardians", handlers.GetStudentGuardiansHandler)
+	mux.HandleFunc("PUT /students/{id}/guardians/{guardianId}", handlers.LinkGuardianHandler)
+	mux.HandleFunc("DELETE /students/{id}/guardians/{guardianId}", handlers.UnlinkGuardianHandler)
+	mux.HandleFunc("GET /students/{id}/comments", handlers.GetReportCommentsHandler)
+	mux.HandleFunc("PUT /students/{id}/comments", handlers.SaveReportCommentsHandler)
 }
@@ -10,15 +10,26 @@ func teachersRouter(mux *http.ServeMux) {
 	// Teacher CRUD
 	mux.HandleFunc("GET /teachers", handlers.GetTeachersHandler)
 	mux.HandleFunc("POST /teachers", handlers.AddTeacherHandler)
+	mux.HandleFunc("PUT /teachers", handlers.UpsertTeachersHandler)
 	mux.HandleFunc("PATCH /teachers", handlers.PatchTeachersHandler)
 	mux.HandleFunc("DELETE /teachers", handlers.DeleteTeachersHandler)
+	mux.HandleFunc("POST /teachers/import", handlers.ImportTeachersHandler)
 
 	mux.HandleFunc("GET /teachers/{id}", handlers.GetOneTeacherHandler)
 	mux.HandleFunc("PUT /teachers/{id}", handlers.UpdateTeacherHandler)
 	mux.HandleFunc("PATCH /teachers/{id}", handlers.PatchOneTeacherHandler)
 	mux.HandleFunc("DELETE /teachers/{id}", handlers.DeleteOneTeacherHandler)
+	mux.HandleFunc("POST /teachers/{id}/restore", handlers.RestoreTeacherHandler)
+	mux.HandleFunc("GET /teachers/{id}/history", handlers.GetTeacherHistoryHandler)
 
 	// Teacher-specific student routes
 	mux.HandleFunc("GET /teachers/{id}/students", handlers.GetStudentsByTeacherIDHandler)
 	mux.HandleFunc("GET /teachers/{id}/studentcount", handlers.GetStudentsCountByTeacherIDHandler)
+
+	// Teaching assignments
+	mux.HandleFunc("GET /teachers/{id}/assignments", handlers.GetTeachingAssignmentsHandler)
+	mux.HandleFunc("POST /teachers/{id}/assignments", handlers.AddTeachingAssignmentsHandler)
+	mux.HandleFunc("DELETE /teachers/{id}/assignments/{assignmentId}", handlers.DeleteTeachingAssignmentHandler)
+
+	mux.HandleFunc("GET /teachers/{id}/timetable", handlers.GetTeacherTimetableHandler)
 }
@@ -0,0 +1,24 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/api/handlers"
+)
+
+func termsRouter(mux *http.ServeMux) {
+	mux.HandleFunc("GET /academicyears", handlers.GetAcademicYearsHandler)
+	mux.HandleFunc("POST /academicyears", handlers.AddAcademicYearsHandler)
+	mux.HandleFunc("GET /academicyears/{id}", handlers.GetOneAcademicYearHandler)
+	mux.HandleFunc("PUT /academicyears/{id}", handlers.UpdateAcademicYearHandler)
+	mux.HandleFunc("DELETE /academicyears/{id}", handlers.DeleteAcademicYearHandler)
+	mux.HandleFunc("POST /academicyears/{id}/rollover", handlers.RolloverHandler)
+
+	mux.HandleFunc("GET /terms", handlers.GetTermsHandler)
+	mux.HandleFunc("POST /terms", handlers.AddTermsHandler)
+	mux.HandleFunc("GET /terms/current", handlers.GetCurrentTermHandler)
+	mux.HandleFunc("PUT /terms/current", handlers.SetCurrentTermHandler)
+	mux.HandleFunc("GET /terms/{id}", handlers.GetOneTermHandler)
+	mux.HandleFunc("PUT /terms/{id}", handlers.UpdateTermHandler)
+	mux.HandleFunc("DELETE /terms/{id}", handlers.DeleteTermHandler)
+}
@@ -0,0 +1,22 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/api/handlers"
+)
+
+func timetableRouter(mux *http.ServeMux) {
+	mux.HandleFunc("GET /timetable", handlers.GetTimetableHandler)
+	mux.HandleFunc("POST /timetable", handlers.AddSlotsHandler)
+	mux.HandleFunc("GET /timetable/{id}", handlers.GetOneSlotHandler)
+	mux.HandleFunc("PUT /timetable/{id}", handlers.UpdateSlotHandler)
+	mux.HandleFunc("DELETE /timetable/{id}", handlers.DeleteSlotHandler)
+
+	mux.HandleFunc("GET /timetable/periods", handlers.GetPeriodsHandler)
+	mux.HandleFunc("PUT /timetable/periods", handlers.ReplacePeriodsHandler)
+
+	mux.HandleFunc("GET /timetable/rooms", handlers.GetRoomsHandler)
+	mux.HandleFunc("POST /timetable/rooms", handlers.AddRoomsHandler)
+	mux.HandleFunc("DELETE /timetable/rooms/{id}", handlers.DeleteRoomHandler)
+}
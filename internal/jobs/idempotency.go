@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/repository/sqlconnect"
+)
+
+// PurgeIdempotencyKeys removes expired idempotency keys. It runs once at start
+// and then every interval until ctx is done.
+func PurgeIdempotencyKeys(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := sqlconnect.PurgeIdempotencyKeysDBHandler(ctx)
+		if err != nil {
+			log.Printf("Error purging expired idempotency keys: %v\n", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d expired idempotency keys\n", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
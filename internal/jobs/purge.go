@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/repository/sqlconnect"
+)
+
+// PurgeDeleted permanently removes records that have been soft-deleted for longer
+// than retention. It runs once at start and then every interval until ctx is done.
+func PurgeDeleted(ctx context.Context, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := sqlconnect.PurgeDeletedDBHandler(ctx, retention)
+		if err != nil {
+			log.Printf("Error purging records deleted more than %s ago, %d purged before it: %v\n", retention, purged, err)
+		} else if purged > 0 {
+			log.Printf("Purged %d records deleted more than %s ago\n", purged, retention)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
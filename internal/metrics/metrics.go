@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "school_api"
+
+var (
+	// HTTPRequestsTotal counts handled requests by route pattern, method and status
+	HTTPRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Total number of HTTP requests handled.",
+	}, []string{"route", "method", "status"})
+
+	// HTTPRequestDuration observes request latency by route pattern, method and status
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency in seconds.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	// LoginAttemptsTotal counts exec logins by result (success or failure)
+	LoginAttemptsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_attempts_total",
+		Help:      "Total number of login attempts by result.",
+	}, []string{"result"})
+
+	// RateLimitRejectionsTotal counts requests rejected by the rate limiter
+	RateLimitRejectionsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_rejections_total",
+		Help:      "Total number of requests rejected by the rate limiter.",
+	})
+
+	// EmailsSentTotal counts outgoing emails by outcome (sent or failed)
+	EmailsSentTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "emails_sent_total",
+		Help:      "Total number of emails sent by outcome.",
+	}, []string{"outcome"})
+)
+
+// RegisterDBStats exposes connection pool statistics of db
+func RegisterDBStats(db *sql.DB, dbName string) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, dbName))
+}
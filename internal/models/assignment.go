@@ -0,0 +1,12 @@
+package models
+
+// TeachingAssignment is a subject a teacher teaches to a class in a term
+type TeachingAssignment struct {
+	ID        int        `json:"id,omitempty" db:"id,omitempty"`
+	TeacherID int        `json:"teacher_id,omitempty" db:"teacher_id,omitempty" validate:"readonly" readonly:"true"`
+	ClassID   int        `json:"class_id,omitempty" db:"class_id,omitempty" validate:"required,min=1"`
+	ClassName string     `json:"class_name,omitempty" validate:"readonly" readonly:"true"`
+	Subject   string     `json:"subject,omitempty" db:"subject,omitempty" validate:"required,maxlen=50"`
+	Term      string     `json:"term,omitempty" db:"term,omitempty" validate:"required,maxlen=20"`
+	CreatedAt *Timestamp `json:"created_at,omitempty" validate:"readonly" swaggertype:"string" format:"date-time" readonly:"true"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import "math"
+
+// Attendance statuses
+const (
+	AttendancePresent = "present"
+	AttendanceAbsent  = "absent"
+	AttendanceLate    = "late"
+	AttendanceExcused = "excused"
+)
+
+// Attendance is whether a student was at school on a day, period 0, or at one period of it
+type Attendance struct {
+	ID         int        `json:"id,omitempty"`
+	StudentID  int        `json:"student_id,omitempty" validate:"required,min=1"`
+	ClassID    int        `json:"class_id,omitempty" validate:"readonly" readonly:"true"`
+	TeacherID  int        `json:"teacher_id,omitempty" validate:"readonly" readonly:"true"`
+	Date       string     `json:"date,omitempty" validate:"readonly" readonly:"true" format:"date"`
+	Period     int        `json:"period" validate:"readonly" readonly:"true"`
+	Status     string     `json:"status,omitempty" validate:"required,enum=present|absent|late|excused" enums:"present,absent,late,excused"`
+	Note       string     `json:"note,omitempty" validate:"maxlen=255"`
+	RecordedAt *Timestamp `json:"recorded_at,omitempty" validate:"readonly" swaggertype:"string" format:"date-time" readonly:"true"`
+}
+
+// AttendanceSheet is the attendance a teacher takes of a class for a day or one of its periods
+type AttendanceSheet struct {
+	Date      string       `json:"date" validate:"required,pattern=^[0-9]{4}-[0-9]{2}-[0-9]{2}$" format:"date"`
+	Period    int          `json:"period" validate:"min=0,max=12"`
+	TeacherID int          `json:"teacher_id" validate:"required,min=1"`
+	Records   []Attendance `json:"records"`
+}
+
+// AttendanceSummary counts attendance records by status. Rate is the share of the records that
+// count, excused absences don't, where the student was present or late, to 4 decimals. It is null
+// without any.
+type AttendanceSummary struct {
+	Date    string   `json:"date,omitempty" format:"date"`
+	Present int      `json:"present"`
+	Absent  int      `json:"absent"`
+	Late    int      `json:"late"`
+	Excused int      `json:"excused"`
+	Total   int      `json:"total"`
+	Rate    *float64 `json:"rate"`
+}
+
+// Add counts count records of status
+func (s *AttendanceSummary) Add(status string, count int) {
+	switch status {
+	case AttendancePresent:
+		s.Present += count
+	case AttendanceAbsent:
+		s.Absent += count
+	case AttendanceLate:
+		s.Late += count
+	case AttendanceExcused:
+		s.Excused += count
+	}
+	s.Total += count
+
+	s.Rate = nil
+	if counted := s.Total - s.Excused; counted > 0 {
+		rate := math.Round(float64(s.Present+s.Late)/float64(counted)*10000) / 10000
+		s.Rate = &rate
+	}
+}
@@ -0,0 +1,37 @@
+package models
+
+// Audit actions
+const (
+	AuditCreate               = "create"
+	AuditUpdate               = "update"
+	AuditDelete               = "delete"
+	AuditRestore              = "restore"
+	AuditPurge                = "purge"
+	AuditLogin                = "login"
+	AuditLoginFailed          = "login_failed"
+	AuditLogout               = "logout"
+	AuditPasswordChange       = "password_change"
+	AuditPasswordResetRequest = "password_reset_request"
+	AuditPasswordReset        = "password_reset"
+)
+
+// AuditEntry records who did what to which record, and from where
+type AuditEntry struct {
+	ID            int                    `json:"id"`
+	CreatedAt     *Timestamp             `json:"created_at,omitempty" swaggertype:"string" format:"date-time"`
+	ActorID       *int                   `json:"actor_id,omitempty" db:"actor_id"`
+	ActorUsername string                 `json:"actor_username,omitempty" db:"actor_username"`
+	Action        string                 `json:"action" db:"action" enums:"create,update,delete,restore,purge,login,login_failed,logout,password_change,password_reset_request,password_reset"`
+	Resource      string                 `json:"resource" db:"resource"`
+	ResourceID    *int                   `json:"resource_id,omitempty" db:"resource_id"`
+	Changes       map[string]AuditChange `json:"changes,omitempty"`
+	IP            string                 `json:"ip,omitempty" db:"ip"`
+	RequestID     string                 `json:"request_id,omitempty" db:"request_id"`
+}
+
+// AuditChange is the value of a field before and after a change, From is empty for
+// records that were created and To for records that were deleted
+type AuditChange struct {
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
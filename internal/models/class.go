@@ -0,0 +1,22 @@
+package models
+
+import "fmt"
+
+// Class is a section of a grade in an academic year, e.g. 10A in 2025-2026. Students belong to one
+// class, their class field holds its name.
+type Class struct {
+	ID                int        `json:"id,omitempty" db:"id,omitempty"`
+	Name              string     `json:"name,omitempty" db:"name,omitempty" validate:"readonly" readonly:"true"`
+	Grade             int        `json:"grade,omitempty" db:"grade,omitempty" validate:"required,min=1,max=12"`
+	Section           string     `json:"section,omitempty" db:"section,omitempty" validate:"required,pattern=^[A-Z]$"`
+	HomeroomTeacherID *int       `json:"homeroom_teacher_id,omitempty" db:"homeroom_teacher_id,omitempty" validate:"min=1"`
+	Capacity          int        `json:"capacity,omitempty" db:"capacity,omitempty" validate:"min=0,max=200"`
+	AcademicYear      string     `json:"academic_year,omitempty" db:"academic_year,omitempty" validate:"required,pattern=^[0-9]{4}-[0-9]{4}$"`
+	Version           int        `json:"version,omitempty" db:"version,omitempty"`
+	UpdatedAt         *Timestamp `json:"updated_at,omitempty" validate:"readonly" swaggertype:"string" format:"date-time" readonly:"true"`
+}
+
+// ClassName is the name of the section of grade, it is what students and teachers refer to a class by
+func ClassName(grade int, section string) string {
+	return fmt.Sprintf("%d%s", grade, section)
+}
@@ -37,17 +37,20 @@ func (ns NullString) Value() (driver.Value, error) {
 
 type Exec struct {
 	ID                   int        `json:"id,omitempty" db:"id,omitempty"`
-	FirstName            string     `json:"first_name,omitempty" db:"first_name,omitempty"`
-	LastName             string     `json:"last_name,omitempty" db:"last_name,omitempty"`
-	Email                string     `json:"email,omitempty" db:"email,omitempty"`
-	Username             string     `json:"username,omitempty" db:"username,omitempty"`
-	Password             string     `json:"password,omitempty" db:"password,omitempty"`
+	FirstName            string     `json:"first_name,omitempty" db:"first_name,omitempty" validate:"required,maxlen=50"`
+	LastName             string     `json:"last_name,omitempty" db:"last_name,omitempty" validate:"required,maxlen=50"`
+	Email                string     `json:"email,omitempty" db:"email,omitempty" validate:"required,email,maxlen=100"`
+	Username             string     `json:"username,omitempty" db:"username,omitempty" validate:"required,maxlen=50,pattern=^[A-Za-z0-9_.-]+$"`
+	Password             string     `json:"password,omitempty" db:"password,omitempty" validate:"required"`
 	PasswordChangedAt    NullString `json:"password_changed_at,omitempty" db:"password_changed_at,omitempty"`
 	UserCreatedAt        NullString `json:"user_created_at,omitempty" db:"user_created_at,omitempty"`
 	PasswordResetToken   NullString `json:"password_reset_token,omitempty" db:"password_reset_token,omitempty"`
 	PasswordTokenExpires NullString `json:"password_token_expires,omitempty" db:"password_token_expires,omitempty"`
 	InactiveStatus       bool       `json:"inactive_status,omitempty" db:"inactive_status,omitempty"`
-	Role                 string     `json:"role,omitempty" db:"role,omitempty"`
+	Role                 string     `json:"role,omitempty" db:"role,omitempty" validate:"required,enum=admin|manager|exec"`
+	Version              int        `json:"version,omitempty" db:"version,omitempty"`
+	UpdatedAt            *Timestamp `json:"updated_at,omitempty" validate:"readonly" swaggertype:"string" format:"date-time" readonly:"true"`
+	DeletedAt            *Timestamp `json:"deleted_at,omitempty" validate:"readonly" swaggertype:"string" format:"date-time" readonly:"true"`
 }
 
 type UpdatePasswordRequest struct {
@@ -0,0 +1,159 @@
+package models
+
+import (
+	"math"
+	"sort"
+)
+
+// Assessment is a test, assignment or exam of a class in a subject. Marks are scored out of MaxScore,
+// Weight is how much the assessment counts in the averages of its subject.
+type Assessment struct {
+	ID        int        `json:"id,omitempty"`
+	ClassID   int        `json:"class_id,omitempty" validate:"readonly" readonly:"true"`
+	Subject   string     `json:"subject,omitempty" validate:"required,maxlen=50"`
+	Name      string     `json:"name,omitempty" validate:"required,maxlen=100"`
+	MaxScore  float64    `json:"max_score,omitempty" validate:"required,min=0.01,max=10000"`
+	Weight    float64    `json:"weight,omitempty" validate:"min=0,max=100"`
+	Date      string     `json:"date,omitempty" validate:"required,pattern=^[0-9]{4}-[0-9]{2}-[0-9]{2}$" format:"date"`
+	Term      string     `json:"term,omitempty" validate:"required,maxlen=20"`
+	CreatedAt *Timestamp `json:"created_at,omitempty" validate:"readonly" swaggertype:"string" format:"date-time" readonly:"true"`
+	Marks     []Mark     `json:"marks,omitempty" validate:"readonly" readonly:"true"`
+}
+
+// Mark is the score of a student in an assessment, a null score, e.g. for an excused absence,
+// doesn't count towards averages
+type Mark struct {
+	StudentID  int        `json:"student_id,omitempty" validate:"required,min=1"`
+	Score      *float64   `json:"score" validate:"min=0"`
+	Note       string     `json:"note,omitempty" validate:"maxlen=255"`
+	RecordedAt *Timestamp `json:"recorded_at,omitempty" validate:"readonly" swaggertype:"string" format:"date-time" readonly:"true"`
+}
+
+// GradeBand is a letter grade given from MinPercent up to the next band
+type GradeBand struct {
+	Grade      string  `json:"grade" validate:"required,maxlen=2"`
+	MinPercent float64 `json:"min_percent" validate:"min=0,max=100"`
+}
+
+// GradedMark is a mark of a student with the assessment it was given in
+type GradedMark struct {
+	AssessmentID int      `json:"assessment_id"`
+	Assessment   string   `json:"assessment"`
+	Subject      string   `json:"subject"`
+	Term         string   `json:"term"`
+	Date         string   `json:"date" format:"date"`
+	Score        *float64 `json:"score"`
+	MaxScore     float64  `json:"max_score"`
+	Weight       float64  `json:"weight"`
+	Note         string   `json:"note,omitempty"`
+}
+
+// SubjectGrade is the weighted average of a student in a subject, as a percentage
+type SubjectGrade struct {
+	Subject string       `json:"subject"`
+	Average *float64     `json:"average"`
+	Grade   string       `json:"grade,omitempty"`
+	Marks   []GradedMark `json:"marks,omitempty"`
+}
+
+// StudentGrades are the averages of a student by subject, and overall as the mean of the subjects
+type StudentGrades struct {
+	StudentID int            `json:"student_id"`
+	FirstName string         `json:"first_name,omitempty"`
+	LastName  string         `json:"last_name,omitempty"`
+	Average   *float64       `json:"average"`
+	Grade     string         `json:"grade,omitempty"`
+	Subjects  []SubjectGrade `json:"subjects"`
+}
+
+// GradeFor returns the grade of the highest band percent reaches, "" without bands or a percent
+func GradeFor(bands []GradeBand, percent *float64) string {
+	if percent == nil {
+		return ""
+	}
+	grade, best := "", -1.0
+	for _, band := range bands {
+		if *percent >= band.MinPercent && band.MinPercent > best {
+			grade, best = band.Grade, band.MinPercent
+		}
+	}
+	return grade
+}
+
+// GradeMarks works out the weighted average of every subject in marks, and of the student overall.
+// An assessment scores score / max score of its weight, marks without a score are left out.
+func GradeMarks(marks []GradedMark, bands []GradeBand, keepMarks bool) StudentGrades {
+	bySubject := make(map[string][]GradedMark)
+	for _, mark := range marks {
+		bySubject[mark.Subject] = append(bySubject[mark.Subject], mark)
+	}
+
+	grades := StudentGrades{Subjects: []SubjectGrade{}}
+	var sum float64
+	var counted int
+	for subject, subjectMarks := range bySubject {
+		var scored, weights float64
+		for _, mark := range subjectMarks {
+			if mark.Score == nil || mark.MaxScore <= 0 {
+				continue
+			}
+			scored += *mark.Score / mark.MaxScore * mark.Weight
+			weights += mark.Weight
+		}
+
+		grade := SubjectGrade{Subject: subject}
+		if weights > 0 {
+			average := roundPercent(scored / weights * 100)
+			grade.Average = &average
+			sum += average
+			counted++
+		}
+		grade.Grade = GradeFor(bands, grade.Average)
+		if keepMarks {
+			grade.Marks = subjectMarks
+		}
+		grades.Subjects = append(grades.Subjects, grade)
+	}
+	sort.Slice(grades.Subjects, func(i, j int) bool { return grades.Subjects[i].Subject < grades.Subjects[j].Subject })
+
+	if counted > 0 {
+		average := roundPercent(sum / float64(counted))
+		grades.Average = &average
+	}
+	grades.Grade = GradeFor(bands, grades.Average)
+	return grades
+}
+
+// ClassAverages are the means of the averages of students, overall and by subject
+func ClassAverages(grades []StudentGrades) (*float64, map[string]float64) {
+	sums := make(map[string]float64)
+	counts := make(map[string]int)
+	var sum float64
+	var counted int
+	for _, student := range grades {
+		for _, subject := range student.Subjects {
+			if subject.Average != nil {
+				sums[subject.Subject] += *subject.Average
+				counts[subject.Subject]++
+			}
+		}
+		if student.Average != nil {
+			sum += *student.Average
+			counted++
+		}
+	}
+
+	bySubject := make(map[string]float64, len(sums))
+	for subject, subjectSum := range sums {
+		bySubject[subject] = roundPercent(subjectSum / float64(counts[subject]))
+	}
+	if counted == 0 {
+		return nil, bySubject
+	}
+	average := roundPercent(sum / float64(counted))
+	return &average, bySubject
+}
+
+func roundPercent(percent float64) float64 {
+	return math.Round(percent*100) / 100
+}
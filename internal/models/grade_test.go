@@ -0,0 +1,161 @@
+package models
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func score(f float64) *float64 { return &f }
+
+var testBands = []GradeBand{
+	{Grade: "F", MinPercent: 0},
+	{Grade: "C", MinPercent: 50},
+	{Grade: "B", MinPercent: 70},
+	{Grade: "A", MinPercent: 90},
+}
+
+func TestGradeFor(t *testing.T) {
+	tests := []struct {
+		name    string
+		bands   []GradeBand
+		percent *float64
+		want    string
+	}{
+		{"no percent", testBands, nil, ""},
+		{"no bands", nil, score(80), ""},
+		{"lowest band", testBands, score(0), "F"},
+		{"just below boundary", testBands, score(69.99), "C"},
+		{"on boundary", testBands, score(70), "B"},
+		{"top", testBands, score(100), "A"},
+		{"bands in any order", []GradeBand{{"A", 90}, {"F", 0}, {"B", 70}}, score(75), "B"},
+		{"below every band", []GradeBand{{"P", 40}}, score(39.5), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GradeFor(tt.bands, tt.percent); got != tt.want {
+				t.Errorf("GradeFor() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGradeMarks(t *testing.T) {
+	tests := []struct {
+		name  string
+		marks []GradedMark
+		want  StudentGrades
+	}{
+		{
+			name:  "no marks",
+			marks: nil,
+			want:  StudentGrades{Subjects: []SubjectGrade{}},
+		},
+		{
+			name: "weighted average",
+			marks: []GradedMark{
+				{Subject: "Math", Score: score(40), MaxScore: 50, Weight: 1},  // 80%
+				{Subject: "Math", Score: score(60), MaxScore: 100, Weight: 3}, // 60%
+			},
+			want: StudentGrades{Average: score(65), Grade: "C", Subjects: []SubjectGrade{
+				{Subject: "Math", Average: score(65), Grade: "C"},
+			}},
+		},
+		{
+			name: "null scores are left out",
+			marks: []GradedMark{
+				{Subject: "Math", Score: score(9), MaxScore: 10, Weight: 1},
+				{Subject: "Math", Score: nil, MaxScore: 10, Weight: 5},
+			},
+			want: StudentGrades{Average: score(90), Grade: "A", Subjects: []SubjectGrade{
+				{Subject: "Math", Average: score(90), Grade: "A"},
+			}},
+		},
+		{
+			name: "weight 0 doesn't count",
+			marks: []GradedMark{
+				{Subject: "Math", Score: score(10), MaxScore: 10, Weight: 2},
+				{Subject: "Math", Score: score(0), MaxScore: 10, Weight: 0},
+			},
+			want: StudentGrades{Average: score(100), Grade: "A", Subjects: []SubjectGrade{
+				{Subject: "Math", Average: score(100), Grade: "A"},
+			}},
+		},
+		{
+			name: "only weight 0 or unscored gives no average",
+			marks: []GradedMark{
+				{Subject: "Art", Score: score(5), MaxScore: 10, Weight: 0},
+				{Subject: "Art", Score: nil, MaxScore: 10, Weight: 1},
+			},
+			want: StudentGrades{Subjects: []SubjectGrade{{Subject: "Art"}}},
+		},
+		{
+			name: "overall is the mean of subjects, sorted by name",
+			marks: []GradedMark{
+				{Subject: "Science", Score: score(1), MaxScore: 3, Weight: 1}, // 33.33%
+				{Subject: "English", Score: score(2), MaxScore: 3, Weight: 1}, // 66.67%
+				{Subject: "Art", Score: nil, MaxScore: 10, Weight: 1},
+			},
+			want: StudentGrades{Average: score(50), Grade: "C", Subjects: []SubjectGrade{
+				{Subject: "Art"},
+				{Subject: "English", Average: score(66.67), Grade: "C"},
+				{Subject: "Science", Average: score(33.33), Grade: "F"},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GradeMarks(tt.marks, testBands, false)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GradeMarks() = %s, want %s", describeGrades(got), describeGrades(tt.want))
+			}
+		})
+	}
+}
+
+func TestGradeMarksKeepsMarks(t *testing.T) {
+	marks := []GradedMark{{AssessmentID: 1, Subject: "Math", Score: score(5), MaxScore: 10, Weight: 1}}
+	got := GradeMarks(marks, nil, true)
+	if len(got.Subjects) != 1 || !reflect.DeepEqual(got.Subjects[0].Marks, marks) {
+		t.Errorf("GradeMarks() with keepMarks = %+v, want the marks kept", got.Subjects)
+	}
+	if got.Subjects[0].Grade != "" {
+		t.Errorf("GradeMarks() without bands graded %q", got.Subjects[0].Grade)
+	}
+}
+
+func TestClassAverages(t *testing.T) {
+	grades := []StudentGrades{
+		{Average: score(80), Subjects: []SubjectGrade{{Subject: "Math", Average: score(90)}, {Subject: "Art", Average: score(70)}}},
+		{Average: score(55), Subjects: []SubjectGrade{{Subject: "Math", Average: score(55)}, {Subject: "Art"}}},
+		{Subjects: []SubjectGrade{{Subject: "Math"}}},
+	}
+	average, bySubject := ClassAverages(grades)
+	if average == nil || *average != 67.5 {
+		t.Errorf("ClassAverages() average = %v, want 67.5", toString(average))
+	}
+	want := map[string]float64{"Math": 72.5, "Art": 70}
+	if !reflect.DeepEqual(bySubject, want) {
+		t.Errorf("ClassAverages() by subject = %v, want %v", bySubject, want)
+	}
+
+	average, bySubject = ClassAverages([]StudentGrades{{Subjects: []SubjectGrade{{Subject: "Math"}}}})
+	if average != nil || len(bySubject) != 0 {
+		t.Errorf("ClassAverages() without averages = %v, %v, want nil and none", toString(average), bySubject)
+	}
+}
+
+func describeGrades(g StudentGrades) string {
+	s := "{average " + toString(g.Average) + " " + g.Grade + " ["
+	for _, subject := range g.Subjects {
+		s += " " + subject.Subject + ":" + toString(subject.Average) + ":" + subject.Grade
+	}
+	return s + " ]}"
+}
+
+func toString(p *float64) string {
+	if p == nil {
+		return "nil"
+	}
+	return strconv.FormatFloat(*p, 'f', -1, 64)
+}
@@ -0,0 +1,43 @@
+package models
+
+// Guardian is a parent or other adult responsible for students, the person to call about them
+type Guardian struct {
+	ID           int        `json:"id,omitempty" db:"id,omitempty"`
+	FirstName    string     `json:"first_name,omitempty" db:"first_name,omitempty" validate:"required,maxlen=50"`
+	LastName     string     `json:"last_name,omitempty" db:"last_name,omitempty" validate:"required,maxlen=50"`
+	Relationship string     `json:"relationship,omitempty" db:"relationship,omitempty" validate:"required,enum=mother|father|parent|grandparent|sibling|relative|guardian|other" enums:"mother,father,parent,grandparent,sibling,relative,guardian,other"`
+	Phone        string     `json:"phone,omitempty" db:"phone,omitempty" validate:"required,maxlen=20,pattern=^\\+?[0-9(][0-9 ()-]{5,19}$"`
+	Email        string     `json:"email,omitempty" db:"email,omitempty" validate:"email,maxlen=100"`
+	Address      string     `json:"address,omitempty" db:"address,omitempty" validate:"maxlen=255"`
+	Version      int        `json:"version,omitempty" db:"version,omitempty"`
+	UpdatedAt    *Timestamp `json:"updated_at,omitempty" validate:"readonly" swaggertype:"string" format:"date-time" readonly:"true"`
+}
+
+// GuardianLink is how a guardian stands to a student. A student has at most one primary contact,
+// the first to call, and only guardians who can pick them up may collect them from school.
+type GuardianLink struct {
+	PrimaryContact bool `json:"primary_contact"`
+	CanPickUp      bool `json:"can_pick_up"`
+}
+
+// StudentGuardian links a student to one of their guardians
+type StudentGuardian struct {
+	StudentID  int `json:"student_id"`
+	GuardianID int `json:"guardian_id"`
+	GuardianLink
+}
+
+// LinkedGuardian is a guardian of a student
+type LinkedGuardian struct {
+	Guardian
+	GuardianLink
+}
+
+// LinkedStudent is a student a guardian is responsible for
+type LinkedStudent struct {
+	StudentID int    `json:"student_id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Class     string `json:"class"`
+	GuardianLink
+}
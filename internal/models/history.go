@@ -0,0 +1,21 @@
+package models
+
+// StudentRevision is the state of a student right after one change to it
+type StudentRevision struct {
+	Revision  int        `json:"revision"`
+	Operation string     `json:"operation" enums:"create,update,delete,restore"`
+	ChangedAt *Timestamp `json:"changed_at" swaggertype:"string" format:"date-time"`
+	ChangedBy *int       `json:"changed_by,omitempty"`
+	RequestID string     `json:"request_id,omitempty"`
+	Student   Student    `json:"student"`
+}
+
+// TeacherRevision is the state of a teacher right after one change to it
+type TeacherRevision struct {
+	Revision  int        `json:"revision"`
+	Operation string     `json:"operation" enums:"create,update,delete,restore"`
+	ChangedAt *Timestamp `json:"changed_at" swaggertype:"string" format:"date-time"`
+	ChangedBy *int       `json:"changed_by,omitempty"`
+	RequestID string     `json:"request_id,omitempty"`
+	Teacher   Teacher    `json:"teacher"`
+}
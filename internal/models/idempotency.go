@@ -0,0 +1,12 @@
+package models
+
+// IdempotentResponse is the response stored for a request made with an Idempotency-Key
+type IdempotentResponse struct {
+	// RequestHash identifies the request the key was first used for
+	RequestHash string
+	// Completed is false while the first request is still being handled
+	Completed bool
+	Status    int
+	Header    map[string]string
+	Body      []byte
+}
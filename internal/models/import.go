@@ -0,0 +1,30 @@
+package models
+
+// Import job statuses
+const (
+	ImportRunning   = "running"
+	ImportCompleted = "completed"
+	ImportFailed    = "failed"
+)
+
+// ImportJob tracks an import of a CSV or Excel file, large files are imported in the background
+type ImportJob struct {
+	ID        int    `json:"id"`
+	Resource  string `json:"resource"`
+	Status    string `json:"status" enums:"running,completed,failed"`
+	DryRun    bool   `json:"dry_run"`
+	TotalRows int    `json:"total_rows"`
+	UpsertResult
+	Rows       []ImportRow `json:"rows,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	CreatedBy  *int        `json:"created_by,omitempty"`
+	CreatedAt  *Timestamp  `json:"created_at,omitempty" swaggertype:"string" format:"date-time"`
+	FinishedAt *Timestamp  `json:"finished_at,omitempty" swaggertype:"string" format:"date-time"`
+}
+
+// ImportRow is what an import did, or in a dry run would do, with one row of the file
+type ImportRow struct {
+	Row    int    `json:"row"`
+	ID     int    `json:"id,omitempty"`
+	Action string `json:"action" enums:"created,updated,unchanged"`
+}
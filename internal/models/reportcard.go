@@ -0,0 +1,72 @@
+package models
+
+// Report card deliveries
+const (
+	ReportCardEmail = "email"
+	ReportCardStore = "store"
+)
+
+// ReportCard is what a student achieved in a term: their grades by subject, attendance and
+// the comments of their teachers
+type ReportCard struct {
+	ID           int                 `json:"id,omitempty"`
+	StudentID    int                 `json:"student_id"`
+	FirstName    string              `json:"first_name"`
+	LastName     string              `json:"last_name"`
+	Email        string              `json:"email,omitempty"`
+	ClassID      int                 `json:"class_id"`
+	Class        string              `json:"class"`
+	AcademicYear string              `json:"academic_year"`
+	Term         string              `json:"term"`
+	Average      *float64            `json:"average"`
+	Grade        string              `json:"grade,omitempty"`
+	Subjects     []ReportCardSubject `json:"subjects"`
+	Attendance   AttendanceSummary   `json:"attendance"`
+	Comments     []ReportComment     `json:"comments"`
+	GeneratedAt  *Timestamp          `json:"generated_at,omitempty" swaggertype:"string" format:"date-time"`
+}
+
+// ReportCardSubject is the average of a student in a subject over a term and who taught it
+type ReportCardSubject struct {
+	Subject     string   `json:"subject"`
+	Teachers    []string `json:"teachers"`
+	Average     *float64 `json:"average"`
+	Grade       string   `json:"grade,omitempty"`
+	Assessments int      `json:"assessments"`
+}
+
+// ReportComment is what a teacher writes about a student for a term, in a subject or,
+// without one, as the homeroom teacher's general comment
+type ReportComment struct {
+	Term        string     `json:"term" validate:"required,maxlen=20"`
+	Subject     string     `json:"subject,omitempty" validate:"maxlen=50"`
+	TeacherID   int        `json:"teacher_id" validate:"required,min=1"`
+	TeacherName string     `json:"teacher_name,omitempty" validate:"readonly" readonly:"true"`
+	Comment     string     `json:"comment" validate:"required,maxlen=1000"`
+	UpdatedAt   *Timestamp `json:"updated_at,omitempty" validate:"readonly" swaggertype:"string" format:"date-time" readonly:"true"`
+}
+
+// ReportCardJob generates the report cards of a class for a term in the background and emails
+// them to the students or stores them. Its statuses are those of imports.
+type ReportCardJob struct {
+	ID         int                `json:"id"`
+	ClassID    int                `json:"class_id"`
+	Term       string             `json:"term"`
+	Delivery   string             `json:"delivery" enums:"email,store"`
+	Status     string             `json:"status" enums:"running,completed,failed"`
+	Total      int                `json:"total"`
+	Delivered  int                `json:"delivered"`
+	Failed     int                `json:"failed"`
+	Results    []ReportCardResult `json:"results,omitempty"`
+	Error      string             `json:"error,omitempty"`
+	CreatedBy  *int               `json:"created_by,omitempty"`
+	CreatedAt  *Timestamp         `json:"created_at,omitempty" swaggertype:"string" format:"date-time"`
+	FinishedAt *Timestamp         `json:"finished_at,omitempty" swaggertype:"string" format:"date-time"`
+}
+
+// ReportCardResult is what a report card job did for one student, the stored report card or why it failed
+type ReportCardResult struct {
+	StudentID    int    `json:"student_id"`
+	ReportCardID int    `json:"report_card_id,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import "strconv"
+
+// Outcomes of a rollover for a student, kept on the enrollment it ends
+const (
+	RolloverPromoted  = "promoted"
+	RolloverHeldBack  = "held_back"
+	RolloverGraduated = "graduated"
+)
+
+// FinalGrade is the last grade of the school, its students graduate at a rollover unless mapped otherwise
+const FinalGrade = 12
+
+// Rollover moves the students of an academic year on to the classes of the next one. By default
+// every class moves up a grade keeping its section and the final grade graduates. Mapping overrides
+// that by class name, e.g. 10A, or for a whole grade, e.g. 12, with a class name, a grade keeping the
+// section or graduated. Held back students stay in the same class, or the one given, of the next year.
+type Rollover struct {
+	ToYear        string            `json:"to_year" validate:"required,pattern=^[0-9]{4}-[0-9]{4}$"`
+	Mapping       map[string]string `json:"mapping,omitempty"`
+	HeldBack      []HeldBack        `json:"held_back,omitempty"`
+	CreateClasses bool              `json:"create_classes,omitempty"`
+}
+
+// HeldBack is a student who repeats their grade at a rollover
+type HeldBack struct {
+	StudentID int    `json:"student_id" validate:"required,min=1"`
+	Class     string `json:"class,omitempty" validate:"pattern=^([1-9]|1[0-2])[A-Z]$"`
+}
+
+// Target is the name of the class a class moves on to at the rollover, or RolloverGraduated
+func (r Rollover) Target(class Class) string {
+	target, ok := r.Mapping[class.Name]
+	if !ok {
+		target, ok = r.Mapping[strconv.Itoa(class.Grade)]
+	}
+	if !ok {
+		if class.Grade >= FinalGrade {
+			return RolloverGraduated
+		}
+		return ClassName(class.Grade+1, class.Section)
+	}
+	if grade, err := strconv.Atoi(target); err == nil {
+		return ClassName(grade, class.Section)
+	}
+	return target
+}
+
+// RolloverResult is what a rollover did, or would do in a dry run, to every student of the year
+type RolloverResult struct {
+	FromYear       string         `json:"from_year"`
+	ToYear         string         `json:"to_year"`
+	DryRun         bool           `json:"dry_run"`
+	Promoted       int            `json:"promoted"`
+	HeldBack       int            `json:"held_back"`
+	Graduated      int            `json:"graduated"`
+	CreatedClasses []string       `json:"created_classes"`
+	Students       []RolloverMove `json:"students"`
+}
+
+// RolloverMove is the class a student leaves and the one they join at a rollover, none when they graduate
+type RolloverMove struct {
+	StudentID int    `json:"student_id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	FromClass string `json:"from_class"`
+	ToClass   string `json:"to_class,omitempty"`
+	Outcome   string `json:"outcome" enums:"promoted,held_back,graduated"`
+}
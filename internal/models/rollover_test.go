@@ -0,0 +1,33 @@
+package models
+
+import "testing"
+
+func TestRolloverTarget(t *testing.T) {
+	class10A := Class{Name: "10A", Grade: 10, Section: "A"}
+	final := Class{Name: ClassName(FinalGrade, "B"), Grade: FinalGrade, Section: "B"}
+	tests := []struct {
+		name    string
+		mapping map[string]string
+		class   Class
+		want    string
+	}{
+		{"moves up a grade", nil, class10A, "11A"},
+		{"first grade", nil, Class{Name: "1C", Grade: 1, Section: "C"}, "2C"},
+		{"final grade graduates", nil, final, RolloverGraduated},
+		{"grade key keeps the section", map[string]string{"10": "12"}, class10A, "12A"},
+		{"grade key to a class name", map[string]string{"10": "11C"}, class10A, "11C"},
+		{"class key", map[string]string{"10A": "11B"}, class10A, "11B"},
+		{"class key wins over grade key", map[string]string{"10": "12", "10A": "11B"}, class10A, "11B"},
+		{"class key with a grade", map[string]string{"10A": "10"}, class10A, "10A"},
+		{"mapped to graduated", map[string]string{"10": RolloverGraduated}, class10A, RolloverGraduated},
+		{"final grade kept on", map[string]string{"12": "12"}, final, ClassName(FinalGrade, "B")},
+		{"other keys are ignored", map[string]string{"9": "12", "10B": "12B"}, class10A, "11A"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Rollover{Mapping: tt.mapping}).Target(tt.class); got != tt.want {
+				t.Errorf("Target(%s) = %q, want %q", tt.class.Name, got, tt.want)
+			}
+		})
+	}
+}
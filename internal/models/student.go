@@ -1,9 +1,26 @@
 package models
 
+// Student is a pupil of the school. The address and medical notes are sensitive, they are stored
+// encrypted and only roles in SensitiveStudentRoles may see or change them.
 type Student struct {
-	ID        int    `json:"id,omitempty" db:"id,omitempty"`
-	FirstName string `json:"first_name,omitempty" db:"first_name,omitempty"`
-	LastName  string `json:"last_name,omitempty" db:"last_name,omitempty"`
-	Email     string `json:"email,omitempty" db:"email,omitempty"`
-	Class     string `json:"class,omitempty" db:"class,omitempty"`
+	ID             int        `json:"id,omitempty" db:"id,omitempty"`
+	FirstName      string     `json:"first_name,omitempty" db:"first_name,omitempty" validate:"required,maxlen=50"`
+	LastName       string     `json:"last_name,omitempty" db:"last_name,omitempty" validate:"required,maxlen=50"`
+	Email          string     `json:"email,omitempty" db:"email,omitempty" validate:"required,email,maxlen=100"`
+	Class          string     `json:"class,omitempty" db:"class,omitempty" validate:"required,maxlen=10,pattern=^([1-9]|1[0-2])[A-Z]$"`
+	ClassID        int        `json:"class_id,omitempty" db:"class_id,omitempty" validate:"readonly" readonly:"true"`
+	StudentNumber  *string    `json:"student_number,omitempty" db:"student_number,omitempty" validate:"maxlen=20"`
+	DateOfBirth    *string    `json:"date_of_birth,omitempty" db:"date_of_birth,omitempty" validate:"pattern=^[0-9]{4}-[0-9]{2}-[0-9]{2}$" format:"date"`
+	Gender         string     `json:"gender,omitempty" db:"gender,omitempty" validate:"enum=female|male|other" enums:"female,male,other"`
+	EnrollmentDate *string    `json:"enrollment_date,omitempty" db:"enrollment_date,omitempty" validate:"pattern=^[0-9]{4}-[0-9]{2}-[0-9]{2}$" format:"date"`
+	Address        string     `json:"address,omitempty" db:"address,omitempty" validate:"maxlen=255" encrypted:"true"`
+	MedicalNotes   string     `json:"medical_notes,omitempty" db:"medical_notes,omitempty" validate:"maxlen=1000" encrypted:"true"`
+	Allergies      string     `json:"allergies,omitempty" db:"allergies,omitempty" validate:"maxlen=255"`
+	Version        int        `json:"version,omitempty" db:"version,omitempty"`
+	UpdatedAt      *Timestamp `json:"updated_at,omitempty" validate:"readonly" swaggertype:"string" format:"date-time" readonly:"true"`
+	DeletedAt      *Timestamp `json:"deleted_at,omitempty" validate:"readonly" swaggertype:"string" format:"date-time" readonly:"true"`
 }
+
+// SensitiveStudentRoles may see and change the encrypted fields of students, for other roles they
+// are left out of responses
+var SensitiveStudentRoles = []string{"admin", "manager"}
@@ -1,10 +1,13 @@
 package models
 
 type Teacher struct {
-	ID        int    `json:"id,omitempty" db:"id,omitempty"`
-	FirstName string `json:"first_name,omitempty" db:"first_name,omitempty"`
-	LastName  string `json:"last_name,omitempty" db:"last_name,omitempty"`
-	Email     string `json:"email,omitempty" db:"email,omitempty"`
-	Class     string `json:"class,omitempty" db:"class,omitempty"`
-	Subject   string `json:"subject,omitempty" db:"subject,omitempty"`
+	ID        int        `json:"id,omitempty" db:"id,omitempty"`
+	FirstName string     `json:"first_name,omitempty" db:"first_name,omitempty" validate:"required,maxlen=50"`
+	LastName  string     `json:"last_name,omitempty" db:"last_name,omitempty" validate:"required,maxlen=50"`
+	Email     string     `json:"email,omitempty" db:"email,omitempty" validate:"required,email,maxlen=100"`
+	Class     string     `json:"class,omitempty" db:"class,omitempty" validate:"required,maxlen=10,pattern=^([1-9]|1[0-2])[A-Z]$"`
+	Subject   string     `json:"subject,omitempty" db:"subject,omitempty" validate:"required,maxlen=50"`
+	Version   int        `json:"version,omitempty" db:"version,omitempty"`
+	UpdatedAt *Timestamp `json:"updated_at,omitempty" validate:"readonly" swaggertype:"string" format:"date-time" readonly:"true"`
+	DeletedAt *Timestamp `json:"deleted_at,omitempty" validate:"readonly" swaggertype:"string" format:"date-time" readonly:"true"`
 }
@@ -0,0 +1,40 @@
+package models
+
+// AcademicYear is a school year, e.g. 2025-2026, classes belong to one and it is divided into terms
+type AcademicYear struct {
+	ID        int    `json:"id,omitempty"`
+	Name      string `json:"name,omitempty" validate:"required,pattern=^[0-9]{4}-[0-9]{4}$"`
+	StartDate string `json:"start_date,omitempty" validate:"required,pattern=^[0-9]{4}-[0-9]{2}-[0-9]{2}$" format:"date"`
+	EndDate   string `json:"end_date,omitempty" validate:"required,pattern=^[0-9]{4}-[0-9]{2}-[0-9]{2}$" format:"date"`
+	Terms     []Term `json:"terms,omitempty" validate:"readonly" readonly:"true"`
+}
+
+// Term is a part of an academic year. Assignments, assessments and report cards refer to a term by
+// its name, which is unique within the academic year of their class.
+type Term struct {
+	ID             int    `json:"id,omitempty"`
+	AcademicYearID int    `json:"academic_year_id,omitempty" validate:"required,min=1"`
+	AcademicYear   string `json:"academic_year,omitempty" validate:"readonly" readonly:"true"`
+	Name           string `json:"name,omitempty" validate:"required,maxlen=20"`
+	StartDate      string `json:"start_date,omitempty" validate:"required,pattern=^[0-9]{4}-[0-9]{2}-[0-9]{2}$" format:"date"`
+	EndDate        string `json:"end_date,omitempty" validate:"required,pattern=^[0-9]{4}-[0-9]{2}-[0-9]{2}$" format:"date"`
+	Current        bool   `json:"current" validate:"readonly" readonly:"true"`
+}
+
+// CurrentTerm sets the term the school is in
+type CurrentTerm struct {
+	TermID int `json:"term_id" validate:"required,min=1"`
+}
+
+// Enrollment is a stay of a student in a class, EndDate is empty while the student is still in it.
+// An enrollment ended by a rollover has its outcome.
+type Enrollment struct {
+	ID           int     `json:"id"`
+	StudentID    int     `json:"student_id"`
+	ClassID      int     `json:"class_id"`
+	Class        string  `json:"class"`
+	AcademicYear string  `json:"academic_year"`
+	StartDate    string  `json:"start_date" format:"date"`
+	EndDate      *string `json:"end_date" format:"date"`
+	Outcome      *string `json:"outcome,omitempty" enums:"promoted,held_back,graduated"`
+}
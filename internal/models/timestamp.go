@@ -0,0 +1,37 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Timestamp is scanned from UNIX_TIMESTAMP(column) so it doesn't depend on the
+// connection's time zone, and is rendered as RFC 3339 in UTC.
+type Timestamp struct {
+	time.Time
+}
+
+// Scan implements the sql.Scanner interface
+func (t *Timestamp) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case int64:
+		t.Time = time.Unix(v, 0).UTC()
+	case []byte:
+		// UNIX_TIMESTAMP of a column with fractional seconds is a decimal
+		seconds, err := strconv.ParseFloat(string(v), 64)
+		if err != nil {
+			return errors.New("failed to scan Timestamp: " + err.Error())
+		}
+		t.Time = time.Unix(int64(seconds), 0).UTC()
+	default:
+		return errors.New("failed to scan Timestamp: incompatible type")
+	}
+	return nil
+}
+
+// MarshalJSON renders the timestamp as RFC 3339 in UTC
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Time.UTC().Format(time.RFC3339))
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Period is a lesson of the school day, the same on every weekday. Its number is the period
+// attendance is taken for.
+type Period struct {
+	Number    int    `json:"number" validate:"required,min=1,max=12"`
+	Name      string `json:"name,omitempty" validate:"maxlen=30"`
+	StartTime string `json:"start_time" validate:"required,pattern=^([01][0-9]|2[0-3]):[0-5][0-9]$"`
+	EndTime   string `json:"end_time" validate:"required,pattern=^([01][0-9]|2[0-3]):[0-5][0-9]$"`
+}
+
+// Room is where lessons take place
+type Room struct {
+	ID       int    `json:"id,omitempty"`
+	Name     string `json:"name" validate:"required,maxlen=50"`
+	Capacity int    `json:"capacity,omitempty" validate:"min=0,max=1000"`
+}
+
+// Slot is a weekly lesson of a timetable: a subject a teacher teaches to a class in a room at a
+// period of a weekday, for a term. A teacher, class or room has at most one slot at a time.
+type Slot struct {
+	ID          int    `json:"id,omitempty"`
+	TermID      int    `json:"term_id" validate:"required,min=1"`
+	Term        string `json:"term,omitempty" validate:"readonly" readonly:"true"`
+	Weekday     int    `json:"weekday" validate:"required,min=1,max=7" enums:"1,2,3,4,5,6,7"`
+	Period      int    `json:"period" validate:"required,min=1,max=12"`
+	StartTime   string `json:"start_time,omitempty" validate:"readonly" readonly:"true"`
+	EndTime     string `json:"end_time,omitempty" validate:"readonly" readonly:"true"`
+	ClassID     int    `json:"class_id" validate:"required,min=1"`
+	Class       string `json:"class,omitempty" validate:"readonly" readonly:"true"`
+	Subject     string `json:"subject" validate:"required,maxlen=50"`
+	TeacherID   int    `json:"teacher_id" validate:"required,min=1"`
+	TeacherName string `json:"teacher_name,omitempty" validate:"readonly" readonly:"true"`
+	RoomID      *int   `json:"room_id,omitempty" validate:"min=1"`
+	Room        string `json:"room,omitempty" validate:"readonly" readonly:"true"`
+}
+
+// WeekdayName is the name of a weekday of a slot, 1 is Monday and 7 Sunday
+func WeekdayName(weekday int) string {
+	return time.Weekday(weekday % 7).String()
+}
@@ -0,0 +1,17 @@
+package models
+
+// What an upsert did with a record
+const (
+	UpsertCreated   = "created"
+	UpsertUpdated   = "updated"
+	UpsertUnchanged = "unchanged"
+)
+
+// UpsertResult counts what a batch upsert did with its records
+type UpsertResult struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	// Actions holds what was done with each record, in the order of the batch
+	Actions []string `json:"-"`
+}
@@ -0,0 +1,201 @@
+package sqlconnect
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+// teacherAssignmentsFilter narrows students down to the classes a teacher is assigned to, in
+// one subject when subject isn't empty. Its arguments are the teacher ID and the subject.
+func teacherAssignmentsFilter(teacherID int, subject string) (string, []any) {
+	filter := ` AND class_id IN (SELECT a.class_id FROM teaching_assignments a JOIN teachers t ON t.id = a.teacher_id
+		WHERE a.teacher_id = ? AND t.deleted_at IS NULL`
+	args := []any{teacherID}
+	if subject != "" {
+		filter += " AND a.subject = ?"
+		args = append(args, subject)
+	}
+	return filter + ")", args
+}
+
+// checkTeacherExists reports a teacher that doesn't exist or is deleted as not found
+func checkTeacherExists(ctx context.Context, q queryer, id int) error {
+	var exists bool
+	err := q.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM teachers WHERE id = ? AND deleted_at IS NULL)", id).Scan(&exists)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+	if !exists {
+		return utils.NotFoundError(nil, "Teacher not found")
+	}
+	return nil
+}
+
+// GetTeachingAssignmentsDBHandler lists the assignments of a teacher, optionally only those of a subject or term
+func GetTeachingAssignmentsDBHandler(ctx context.Context, teacherID int, subject, term string) ([]models.TeachingAssignment, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	err = checkTeacherExists(ctx, db, teacherID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT a.id, a.teacher_id, a.class_id, c.name, a.subject, a.term, UNIX_TIMESTAMP(a.created_at)
+		FROM teaching_assignments a JOIN classes c ON c.id = a.class_id WHERE a.teacher_id = ?`
+	args := []any{teacherID}
+	if subject != "" {
+		query += " AND a.subject = ?"
+		args = append(args, subject)
+	}
+	if term != "" {
+		query += " AND a.term = ?"
+		args = append(args, term)
+	}
+	query += " ORDER BY a.term, c.grade, c.section, a.subject"
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	assignments := []models.TeachingAssignment{}
+	for rows.Next() {
+		var a models.TeachingAssignment
+		err := rows.Scan(&a.ID, &a.TeacherID, &a.ClassID, &a.ClassName, &a.Subject, &a.Term, &a.CreatedAt)
+		if err != nil {
+			return nil, dbError(err, "Database error")
+		}
+		assignments = append(assignments, a)
+	}
+	return assignments, nil
+}
+
+// AddTeachingAssignmentsDBHandler assigns a teacher to classes, the batch is all or nothing
+func AddTeachingAssignmentsDBHandler(ctx context.Context, teacherID int, newAssignments []models.TeachingAssignment) ([]models.TeachingAssignment, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+
+	err = checkTeacherExists(ctx, tx, teacherID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	added := make([]models.TeachingAssignment, len(newAssignments))
+	for i, assignment := range newAssignments {
+		assignment.TeacherID = teacherID
+		var academicYear string
+		err = tx.QueryRowContext(ctx, "SELECT name, academic_year FROM classes WHERE id = ?", assignment.ClassID).Scan(&assignment.ClassName, &academicYear)
+		if err == sql.ErrNoRows {
+			tx.Rollback()
+			return nil, utils.ValidationError("Unknown class", utils.WithIndex([]utils.FieldError{{Field: "class_id", Message: "must be the ID of an existing class"}}, i)...)
+		} else if err != nil {
+			tx.Rollback()
+			return nil, dbError(err, "Database error")
+		}
+		_, err = yearTerm(ctx, tx, academicYear, assignment.Term, "term")
+		if err != nil {
+			tx.Rollback()
+			return nil, atIndex(err, i)
+		}
+
+		res, err := tx.ExecContext(ctx, "INSERT INTO teaching_assignments (teacher_id, class_id, subject, term) VALUES (?, ?, ?, ?)",
+			assignment.TeacherID, assignment.ClassID, assignment.Subject, assignment.Term)
+		if isDuplicateEntry(err) {
+			tx.Rollback()
+			return nil, utils.ConflictError(err, "Teacher already teaches "+assignment.Subject+" to "+assignment.ClassName+" in "+assignment.Term)
+		} else if err != nil {
+			tx.Rollback()
+			return nil, dbError(err, "Database error")
+		}
+		lastID, err := res.LastInsertId()
+		if err != nil {
+			tx.Rollback()
+			return nil, dbError(err, "Database error")
+		}
+		assignment.ID = int(lastID)
+		added[i] = assignment
+
+		err = recordAudit(ctx, tx, changeEntry(models.AuditCreate, "teaching_assignments", assignment.ID, nil, assignment))
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, dbError(err, "Error committing transaction")
+	}
+	return added, nil
+}
+
+// DeleteTeachingAssignmentDBHandler unassigns a teacher from a class, as long as the timetable has no
+// lessons of the assignment left
+func DeleteTeachingAssignmentDBHandler(ctx context.Context, teacherID, id int) error {
+	db, err := ConnectDB()
+	if err != nil {
+		return utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+
+	var existing models.TeachingAssignment
+	err = tx.QueryRowContext(ctx, "SELECT id, teacher_id, class_id, subject, term FROM teaching_assignments WHERE id = ? AND teacher_id = ? FOR UPDATE", id, teacherID).Scan(
+		&existing.ID, &existing.TeacherID, &existing.ClassID, &existing.Subject, &existing.Term)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return utils.NotFoundError(err, "Teaching assignment not found")
+	} else if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+
+	var scheduled bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM timetable_slots s JOIN terms t ON t.id = s.term_id
+		WHERE s.teacher_id = ? AND s.class_id = ? AND s.subject = ? AND t.name = ?)`,
+		existing.TeacherID, existing.ClassID, existing.Subject, existing.Term).Scan(&scheduled)
+	if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+	if scheduled {
+		tx.Rollback()
+		return utils.ConflictError(nil, "The timetable still has lessons of this assignment, remove them first")
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM teaching_assignments WHERE id = ?", id)
+	if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+
+	err = recordAudit(ctx, tx, changeEntry(models.AuditDelete, "teaching_assignments", id, existing, nil))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return dbError(err, "Error committing transaction")
+	}
+	return nil
+}
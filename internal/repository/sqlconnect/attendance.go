@@ -0,0 +1,228 @@
+package sqlconnect
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+// checkClassExists reports a class that doesn't exist as not found
+func checkClassExists(ctx context.Context, q queryer, id int) error {
+	var exists bool
+	err := q.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM classes WHERE id = ?)", id).Scan(&exists)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+	if !exists {
+		return utils.NotFoundError(nil, "Class not found")
+	}
+	return nil
+}
+
+// SubmitAttendanceDBHandler records the attendance of students of a class, replacing what was recorded
+// before for the same day and period. The teacher must be assigned to the class or be its homeroom teacher.
+func SubmitAttendanceDBHandler(ctx context.Context, classID int, sheet models.AttendanceSheet) ([]models.Attendance, models.UpsertResult, error) {
+	var result models.UpsertResult
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, result, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, result, dbError(err, "Database error")
+	}
+
+	err = checkClassExists(ctx, tx, classID)
+	if err != nil {
+		tx.Rollback()
+		return nil, result, err
+	}
+
+	var teaches bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM teachers t WHERE t.id = ? AND t.deleted_at IS NULL AND (
+		EXISTS(SELECT 1 FROM teaching_assignments a WHERE a.teacher_id = t.id AND a.class_id = ?)
+		OR EXISTS(SELECT 1 FROM classes c WHERE c.id = ? AND c.homeroom_teacher_id = t.id)))`,
+		sheet.TeacherID, classID, classID).Scan(&teaches)
+	if err != nil {
+		tx.Rollback()
+		return nil, result, dbError(err, "Database error")
+	}
+	if !teaches {
+		tx.Rollback()
+		return nil, result, utils.ValidationError("Teacher does not teach this class", utils.FieldError{Field: "teacher_id", Message: "must be a teacher assigned to the class or its homeroom teacher"})
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO attendance (student_id, class_id, teacher_id, date, period, status, note) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id), class_id = VALUES(class_id), teacher_id = VALUES(teacher_id), status = VALUES(status), note = VALUES(note)`)
+	if err != nil {
+		tx.Rollback()
+		return nil, result, dbError(err, "Database error")
+	}
+	defer stmt.Close()
+
+	var fieldErrs []utils.FieldError
+	for i, record := range sheet.Records {
+		var studentClassID int
+		err = tx.QueryRowContext(ctx, "SELECT class_id FROM students WHERE id = ? AND deleted_at IS NULL", record.StudentID).Scan(&studentClassID)
+		if err == sql.ErrNoRows || (err == nil && studentClassID != classID) {
+			fieldErrs = append(fieldErrs, utils.WithIndex([]utils.FieldError{{Field: "student_id", Message: "must be a student of the class"}}, i)...)
+		} else if err != nil {
+			tx.Rollback()
+			return nil, result, dbError(err, "Database error")
+		}
+	}
+	if len(fieldErrs) > 0 {
+		tx.Rollback()
+		return nil, result, utils.ValidationError("Validation failed", fieldErrs...)
+	}
+
+	saved := make([]models.Attendance, len(sheet.Records))
+	for i, record := range sheet.Records {
+		record.ClassID, record.TeacherID, record.Date, record.Period = classID, sheet.TeacherID, sheet.Date, sheet.Period
+		res, err := stmt.ExecContext(ctx, record.StudentID, record.ClassID, record.TeacherID, record.Date, record.Period, record.Status, record.Note)
+		if err != nil {
+			tx.Rollback()
+			return nil, result, dbError(err, "Database error")
+		}
+		lastID, err := res.LastInsertId()
+		if err != nil {
+			tx.Rollback()
+			return nil, result, dbError(err, "Database error")
+		}
+		record.ID = int(lastID)
+
+		// MySQL reports 1 row for an insert, 2 for an update and 0 when nothing changed
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
+			tx.Rollback()
+			return nil, result, dbError(err, "Database error")
+		}
+		switch rowsAffected {
+		case 1:
+			result.Created++
+		case 2:
+			result.Updated++
+		default:
+			result.Unchanged++
+		}
+		saved[i] = record
+	}
+
+	if result.Created+result.Updated > 0 {
+		err = recordAudit(ctx, tx, models.AuditEntry{
+			Action:     models.AuditUpdate,
+			Resource:   "attendance",
+			ResourceID: &classID,
+			Changes: map[string]models.AuditChange{
+				"date":    {To: sheet.Date},
+				"period":  {To: sheet.Period},
+				"records": {To: len(sheet.Records)},
+			},
+		})
+		if err != nil {
+			tx.Rollback()
+			return nil, result, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, result, dbError(err, "Error committing transaction")
+	}
+	return saved, result, nil
+}
+
+// GetStudentAttendanceDBHandler lists the attendance of a student between from and to, both included
+// and either one open when empty, and of one period when period isn't nil
+func GetStudentAttendanceDBHandler(ctx context.Context, studentID int, from, to string, period *int) ([]models.Attendance, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	var exists bool
+	err = db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM students WHERE id = ? AND deleted_at IS NULL)", studentID).Scan(&exists)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	if !exists {
+		return nil, utils.NotFoundError(nil, "Student not found")
+	}
+
+	filter, args := attendanceFilter(from, to, period)
+	rows, err := db.QueryContext(ctx, `SELECT id, student_id, class_id, COALESCE(teacher_id, 0), date, period, status, note, UNIX_TIMESTAMP(recorded_at)
+		FROM attendance WHERE student_id = ?`+filter+" ORDER BY date DESC, period", append([]any{studentID}, args...)...)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	records := []models.Attendance{}
+	for rows.Next() {
+		var record models.Attendance
+		err := rows.Scan(&record.ID, &record.StudentID, &record.ClassID, &record.TeacherID, &record.Date, &record.Period, &record.Status, &record.Note, &record.RecordedAt)
+		if err != nil {
+			return nil, dbError(err, "Database error")
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// GetClassAttendanceSummaryDBHandler counts the attendance of a class by day between from and to,
+// see GetStudentAttendanceDBHandler. Days without any records are left out.
+func GetClassAttendanceSummaryDBHandler(ctx context.Context, classID int, from, to string, period *int) ([]models.AttendanceSummary, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	err = checkClassExists(ctx, db, classID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, args := attendanceFilter(from, to, period)
+	rows, err := db.QueryContext(ctx, "SELECT date, status, COUNT(*) FROM attendance WHERE class_id = ?"+filter+" GROUP BY date, status ORDER BY date",
+		append([]any{classID}, args...)...)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	summaries := []models.AttendanceSummary{}
+	for rows.Next() {
+		var date, status string
+		var count int
+		err := rows.Scan(&date, &status, &count)
+		if err != nil {
+			return nil, dbError(err, "Database error")
+		}
+		if len(summaries) == 0 || summaries[len(summaries)-1].Date != date {
+			summaries = append(summaries, models.AttendanceSummary{Date: date})
+		}
+		summaries[len(summaries)-1].Add(status, count)
+	}
+	return summaries, nil
+}
+
+func attendanceFilter(from, to string, period *int) (string, []any) {
+	var filter string
+	var args []any
+	if from != "" {
+		filter += " AND date >= ?"
+		args = append(args, from)
+	}
+	if to != "" {
+		filter += " AND date <= ?"
+		args = append(args, to)
+	}
+	if period != nil {
+		filter += " AND period = ?"
+		args = append(args, *period)
+	}
+	return filter, args
+}
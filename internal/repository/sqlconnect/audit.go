@@ -0,0 +1,271 @@
+package sqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+// auditIgnoredFields are never written to the audit log, either because they are
+// secrets or because they change on every write
+var auditIgnoredFields = map[string]bool{
+	"id":                     true,
+	"password":               true,
+	"password_changed_at":    true,
+	"user_created_at":        true,
+	"password_reset_token":   true,
+	"password_token_expires": true,
+	"version":                true,
+	"updated_at":             true,
+	"deleted_at":             true,
+}
+
+// redacted stands in for the values of encrypted fields, the audit log records that they
+// changed but not what they were
+const redacted = "[redacted]"
+
+// execer is implemented by both *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// changeEntry builds the audit entry of a change to a record, before is nil for
+// created records and after is nil for deleted ones
+func changeEntry(action, resource string, id int, before, after interface{}) models.AuditEntry {
+	return models.AuditEntry{
+		Action:     action,
+		Resource:   resource,
+		ResourceID: &id,
+		Changes:    diffRecords(before, after),
+	}
+}
+
+// recordAudit writes entry using exec, so that inside a transaction the entry is only kept if the change is.
+// The actor, IP and request id are taken from ctx unless they were set on entry.
+func recordAudit(ctx context.Context, exec execer, entry models.AuditEntry) error {
+	if entry.ActorID == nil {
+		entry.ActorID = actorID(ctx)
+	}
+	if entry.ActorUsername == "" {
+		entry.ActorUsername, _ = ctx.Value(utils.ContextKey("username")).(string)
+	}
+	if entry.IP == "" {
+		entry.IP, _ = ctx.Value(utils.ContextKey("clientIP")).(string)
+	}
+	if entry.RequestID == "" {
+		entry.RequestID = requestID(ctx)
+	}
+
+	var changes []byte
+	if len(entry.Changes) > 0 {
+		var err error
+		changes, err = json.Marshal(entry.Changes)
+		if err != nil {
+			return utils.ErrorHandler(err, "Error recording audit entry")
+		}
+	}
+
+	_, err := exec.ExecContext(ctx, `INSERT INTO audit_log (actor_id, actor_username, action, resource, resource_id, changes, ip, request_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.ActorID, nullIfEmpty(entry.ActorUsername), entry.Action, entry.Resource, entry.ResourceID,
+		nullIfEmpty(string(changes)), nullIfEmpty(entry.IP), nullIfEmpty(entry.RequestID))
+	if err != nil {
+		return dbError(err, "Error recording audit entry")
+	}
+	return nil
+}
+
+// AddAuditEntryDBHandler records an audit entry outside of any transaction, for auth events
+// such as logins and password changes. Changes to records are audited with recordAudit in
+// the transaction that makes them. The action has already happened, so a failure is logged
+// rather than returned.
+func AddAuditEntryDBHandler(ctx context.Context, entry models.AuditEntry) {
+	db, err := ConnectDB()
+	if err != nil {
+		utils.ErrorHandler(err, "Database connection error")
+		return
+	}
+	// the entry is written even if the client has gone away
+	recordAudit(context.WithoutCancel(ctx), db, entry)
+}
+
+// GetAuditEntriesDBHandler lists audit entries, newest first, filtered by the query
+// parameters of r and by the from/to time range when those are set
+func GetAuditEntriesDBHandler(entries []models.AuditEntry, r *http.Request, limit, page int, from, to time.Time) ([]models.AuditEntry, int, error) {
+	ctx := r.Context()
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, 0, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	filter := " WHERE 1=1"
+	var args []any
+	filter, args = utils.AddFilters(r, filter, args, models.AuditEntry{})
+	if !from.IsZero() {
+		filter += " AND created_at >= FROM_UNIXTIME(?)"
+		args = append(args, from.Unix())
+	}
+	if !to.IsZero() {
+		filter += " AND created_at < FROM_UNIXTIME(?)"
+		args = append(args, to.Unix())
+	}
+
+	var total int
+	err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_log"+filter, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, dbError(err, "Database error")
+	}
+
+	query := `SELECT id, UNIX_TIMESTAMP(created_at), actor_id, actor_username, action, resource, resource_id, changes, ip, request_id
+		FROM audit_log` + filter + " ORDER BY id DESC LIMIT ? OFFSET ?"
+	args = append(args, limit, (page-1)*limit)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry models.AuditEntry
+		var actorID, resourceID sql.NullInt64
+		var actorUsername, changes, ip, requestID sql.NullString
+		err := rows.Scan(&entry.ID, &entry.CreatedAt, &actorID, &actorUsername, &entry.Action, &entry.Resource, &resourceID, &changes, &ip, &requestID)
+		if err != nil {
+			return nil, 0, dbError(err, "Database error")
+		}
+		if actorID.Valid {
+			id := int(actorID.Int64)
+			entry.ActorID = &id
+		}
+		if resourceID.Valid {
+			id := int(resourceID.Int64)
+			entry.ResourceID = &id
+		}
+		if changes.Valid {
+			err = json.Unmarshal([]byte(changes.String), &entry.Changes)
+			if err != nil {
+				return nil, 0, utils.ErrorHandler(err, "Error reading audit entry")
+			}
+		}
+		entry.ActorUsername = actorUsername.String
+		entry.IP = ip.String
+		entry.RequestID = requestID.String
+		entries = append(entries, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, dbError(err, "Database error")
+	}
+	return entries, total, nil
+}
+
+// diffRecords compares two models field by field, using their JSON names, and returns the fields that differ
+func diffRecords(before, after interface{}) map[string]models.AuditChange {
+	beforeFields := recordFields(before)
+	afterFields := recordFields(after)
+
+	encrypted := encryptedFields(before, after)
+
+	changes := make(map[string]models.AuditChange)
+	for key := range fieldNames(beforeFields, afterFields) {
+		if auditIgnoredFields[key] {
+			continue
+		}
+		from, to := beforeFields[key], afterFields[key]
+		if !reflect.DeepEqual(from, to) {
+			if encrypted[key] {
+				from, to = redactValue(from), redactValue(to)
+			}
+			changes[key] = models.AuditChange{From: from, To: to}
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
+
+// redactValue hides a value that is set, an empty one shows the field was added or removed
+func redactValue(value interface{}) interface{} {
+	if value == nil || value == "" {
+		return value
+	}
+	return redacted
+}
+
+// encryptedFields returns the JSON names of the fields of records tagged encrypted:"true"
+func encryptedFields(records ...interface{}) map[string]bool {
+	names := make(map[string]bool)
+	for _, record := range records {
+		recordType := reflect.TypeOf(record)
+		if recordType == nil {
+			continue
+		}
+		if recordType.Kind() == reflect.Ptr {
+			recordType = recordType.Elem()
+		}
+		if recordType.Kind() != reflect.Struct {
+			continue
+		}
+		for i := 0; i < recordType.NumField(); i++ {
+			field := recordType.Field(i)
+			if field.Tag.Get("encrypted") == "true" {
+				name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+				names[name] = true
+			}
+		}
+	}
+	return names
+}
+
+func recordFields(record interface{}) map[string]interface{} {
+	if record == nil {
+		return nil
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil
+	}
+	var fields map[string]interface{}
+	json.Unmarshal(data, &fields)
+	return fields
+}
+
+func fieldNames(records ...map[string]interface{}) map[string]bool {
+	names := make(map[string]bool)
+	for _, record := range records {
+		for name := range record {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// actorID is the ID of the logged in exec making the request, nil for anonymous requests
+func actorID(ctx context.Context) *int {
+	uid, ok := ctx.Value(utils.ContextKey("userId")).(float64)
+	if !ok {
+		return nil
+	}
+	id := int(uid)
+	return &id
+}
+
+func requestID(ctx context.Context) string {
+	id, _ := ctx.Value(utils.ContextKey("requestId")).(string)
+	return id
+}
+
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
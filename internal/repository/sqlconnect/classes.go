@@ -0,0 +1,435 @@
+package sqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+// queryer is implemented by both *sql.DB and *sql.Tx
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+const classColumns = "id, name, grade, section, homeroom_teacher_id, capacity, academic_year, version, UNIX_TIMESTAMP(updated_at)"
+
+func scanClass(row interface{ Scan(...any) error }, class *models.Class) error {
+	return row.Scan(&class.ID, &class.Name, &class.Grade, &class.Section, &class.HomeroomTeacherID, &class.Capacity, &class.AcademicYear, &class.Version, &class.UpdatedAt)
+}
+
+// assignClass links student to the class named by student.Class, the one of the current academic year
+// or else the latest one when a name is used in several years. Inside a transaction the class row stays
+// locked until the end, so concurrent assignments can't take the class over its capacity.
+func assignClass(ctx context.Context, q queryer, student *models.Student) error {
+	currentYear, err := currentAcademicYear(ctx, q)
+	if err != nil {
+		return err
+	}
+
+	var id, capacity int
+	err = q.QueryRowContext(ctx, "SELECT id, capacity FROM classes WHERE name = ? ORDER BY academic_year = ? DESC, academic_year DESC LIMIT 1 FOR UPDATE",
+		student.Class, currentYear).Scan(&id, &capacity)
+	if err == sql.ErrNoRows {
+		return utils.ValidationError("Unknown class", utils.FieldError{Field: "class", Message: fmt.Sprintf("class %s does not exist, create it under /classes first", student.Class)})
+	} else if err != nil {
+		return dbError(err, "Database error")
+	}
+
+	if capacity > 0 {
+		var enrolled int
+		err = q.QueryRowContext(ctx, "SELECT COUNT(*) FROM students WHERE class_id = ? AND id != ? AND deleted_at IS NULL", id, student.ID).Scan(&enrolled)
+		if err != nil {
+			return dbError(err, "Database error")
+		}
+		if enrolled >= capacity {
+			return utils.ConflictError(nil, fmt.Sprintf("Class %s is full, it has room for %d students", student.Class, capacity))
+		}
+	}
+
+	student.ClassID = id
+	return nil
+}
+
+// checkHomeroomTeacher reports a homeroom teacher that doesn't exist or is deleted
+func checkHomeroomTeacher(ctx context.Context, q queryer, class models.Class) error {
+	if class.HomeroomTeacherID == nil {
+		return nil
+	}
+	var exists bool
+	err := q.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM teachers WHERE id = ? AND deleted_at IS NULL)", *class.HomeroomTeacherID).Scan(&exists)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+	if !exists {
+		return utils.ValidationError("Unknown homeroom teacher", utils.FieldError{Field: "homeroom_teacher_id", Message: "must be the ID of an existing teacher"})
+	}
+	return nil
+}
+
+func GetClassesDBHandler(classes []models.Class, r *http.Request, limit, page int) ([]models.Class, int, error) {
+	ctx := r.Context()
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, 0, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	term, err := termParam(ctx, db, r.URL.Query().Get("term"))
+	if err != nil {
+		return nil, 0, err
+	}
+	filter, args := utils.AddFilters(r, " WHERE 1=1", nil, models.Class{})
+	if term != nil {
+		filter += " AND academic_year = ?"
+		args = append(args, term.AcademicYear)
+	}
+
+	var totalClasses int
+	err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM classes"+filter, args...).Scan(&totalClasses)
+	if err != nil {
+		return nil, 0, dbError(err, "Database error")
+	}
+
+	query := utils.AddSorting(r, "SELECT "+classColumns+" FROM classes"+filter, models.Class{})
+	query += " LIMIT ? OFFSET ?"
+	args = append(args, limit, (page-1)*limit)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var class models.Class
+		err := scanClass(rows, &class)
+		if err != nil {
+			return nil, 0, dbError(err, "Database error")
+		}
+		classes = append(classes, class)
+	}
+	return classes, totalClasses, nil
+}
+
+func GetOneClassDBHandler(ctx context.Context, id int) (models.Class, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return models.Class{}, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	var class models.Class
+	err = scanClass(db.QueryRowContext(ctx, "SELECT "+classColumns+" FROM classes WHERE id = ?", id), &class)
+	if err == sql.ErrNoRows {
+		return models.Class{}, utils.NotFoundError(err, "Class not found")
+	} else if err != nil {
+		return models.Class{}, dbError(err, "Database error")
+	}
+	return class, nil
+}
+
+func AddClassesDBHandler(ctx context.Context, newClasses []models.Class) ([]models.Class, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+
+	stmt, err := tx.PrepareContext(ctx, utils.GenerateInsertQuery("classes", models.Class{}))
+	if err != nil {
+		tx.Rollback()
+		return nil, dbError(err, "Database error")
+	}
+	defer stmt.Close()
+
+	addedClasses := make([]models.Class, len(newClasses))
+
+	for i, newClass := range newClasses {
+		err = checkHomeroomTeacher(ctx, tx, newClass)
+		if err == nil {
+			err = checkAcademicYear(ctx, tx, newClass.AcademicYear)
+		}
+		if err != nil {
+			tx.Rollback()
+			return nil, atIndex(err, i)
+		}
+
+		newClass.Name = models.ClassName(newClass.Grade, newClass.Section)
+		newClass.Version = 1
+		newClass.UpdatedAt = nil
+		res, err := stmt.ExecContext(ctx, utils.GetStructValues(newClass)...)
+		if err != nil {
+			tx.Rollback()
+			return nil, dbError(err, "Database error")
+		}
+		lastID, err := res.LastInsertId()
+		if err != nil {
+			tx.Rollback()
+			return nil, dbError(err, "Database error")
+		}
+		newClass.ID = int(lastID)
+		addedClasses[i] = newClass
+
+		err = recordAudit(ctx, tx, changeEntry(models.AuditCreate, "classes", newClass.ID, nil, newClass))
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, dbError(err, "Error committing transaction")
+	}
+	return addedClasses, nil
+}
+
+// UpdateClassDBHandler replaces the class with ID id, see updateClass
+func UpdateClassDBHandler(ctx context.Context, id, version int, updatedClass models.Class) (models.Class, error) {
+	return updateClass(ctx, id, version, func(class *models.Class) error {
+		updatedClass.ID, updatedClass.Version, updatedClass.UpdatedAt = class.ID, class.Version, class.UpdatedAt
+		*class = updatedClass
+		return nil
+	})
+}
+
+// PatchOneClassDBHandler changes the fields of the class in updates, a null homeroom_teacher_id
+// removes the homeroom teacher
+func PatchOneClassDBHandler(ctx context.Context, id, version int, updates map[string]interface{}) (models.Class, error) {
+	return updateClass(ctx, id, version, func(class *models.Class) error {
+		current, err := json.Marshal(class)
+		if err != nil {
+			return utils.ErrorHandler(err, "Error patching class")
+		}
+		merged := make(map[string]interface{})
+		err = json.Unmarshal(current, &merged)
+		if err != nil {
+			return utils.ErrorHandler(err, "Error patching class")
+		}
+		for k, v := range updates {
+			if k != "version" {
+				merged[k] = v
+			}
+		}
+		patched, err := json.Marshal(merged)
+		if err != nil {
+			return utils.ErrorHandler(err, "Error patching class")
+		}
+		var patchedClass models.Class
+		err = json.Unmarshal(patched, &patchedClass)
+		if err != nil {
+			return utils.ValidationError("Invalid class update", utils.FieldError{Field: "body", Message: "has a field of the wrong type"})
+		}
+		*class = patchedClass
+		return nil
+	})
+}
+
+// updateClass applies change to the class with ID id if it is still at version. Renaming a class,
+// by changing its grade or section, renames it in the class of its students too.
+func updateClass(ctx context.Context, id, version int, change func(*models.Class) error) (models.Class, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return models.Class{}, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Class{}, dbError(err, "Database error")
+	}
+
+	var existingClass models.Class
+	err = scanClass(tx.QueryRowContext(ctx, "SELECT "+classColumns+" FROM classes WHERE id = ? FOR UPDATE", id), &existingClass)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return models.Class{}, utils.NotFoundError(err, "Class not found")
+	} else if err != nil {
+		tx.Rollback()
+		return models.Class{}, dbError(err, "Database error")
+	}
+
+	err = checkVersion("Class", id, version, existingClass.Version)
+	if err != nil {
+		tx.Rollback()
+		return models.Class{}, err
+	}
+
+	updatedClass := existingClass
+	err = change(&updatedClass)
+	if err != nil {
+		tx.Rollback()
+		return models.Class{}, err
+	}
+	updatedClass.ID = existingClass.ID
+	updatedClass.Name = models.ClassName(updatedClass.Grade, updatedClass.Section)
+	updatedClass.Version = existingClass.Version + 1
+	updatedClass.UpdatedAt = nil
+
+	err = checkHomeroomTeacher(ctx, tx, updatedClass)
+	if err == nil && updatedClass.AcademicYear != existingClass.AcademicYear {
+		err = checkAcademicYear(ctx, tx, updatedClass.AcademicYear)
+	}
+	if err != nil {
+		tx.Rollback()
+		return models.Class{}, err
+	}
+
+	_, err = tx.ExecContext(ctx, utils.GenerateUpdateQuery("classes", models.Class{}), append(utils.GetStructValues(updatedClass), id)...)
+	if err != nil {
+		tx.Rollback()
+		return models.Class{}, dbError(err, "Database error")
+	}
+
+	if updatedClass.Name != existingClass.Name {
+		err = renameClassStudents(ctx, tx, id, updatedClass.Name)
+		if err != nil {
+			tx.Rollback()
+			return models.Class{}, err
+		}
+	}
+
+	err = recordAudit(ctx, tx, changeEntry(models.AuditUpdate, "classes", id, existingClass, updatedClass))
+	if err != nil {
+		tx.Rollback()
+		return models.Class{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.Class{}, dbError(err, "Error committing transaction")
+	}
+	return updatedClass, nil
+}
+
+// renameClassStudents copies a new class name to the students of the class, including deleted ones,
+// as a new revision of each
+func renameClassStudents(ctx context.Context, tx *sql.Tx, classID int, name string) error {
+	rows, err := tx.QueryContext(ctx, "SELECT id FROM students WHERE class_id = ? FOR UPDATE", classID)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		err = rows.Scan(&id)
+		if err != nil {
+			rows.Close()
+			return dbError(err, "Database error")
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	_, err = tx.ExecContext(ctx, "UPDATE students SET class = ?, version = version + 1 WHERE class_id = ?", name, classID)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+	for _, id := range ids {
+		err = recordHistory(ctx, tx, "students", id, models.AuditUpdate)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteOneClassDBHandler removes a class for good, it must not have any students left,
+// deleted ones included
+func DeleteOneClassDBHandler(ctx context.Context, id, version int) error {
+	db, err := ConnectDB()
+	if err != nil {
+		return utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+
+	var existingClass models.Class
+	err = scanClass(tx.QueryRowContext(ctx, "SELECT "+classColumns+" FROM classes WHERE id = ? FOR UPDATE", id), &existingClass)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return utils.NotFoundError(err, "Class not found")
+	} else if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+
+	err = checkVersion("Class", id, version, existingClass.Version)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var students int
+	err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM students WHERE class_id = ?", id).Scan(&students)
+	if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+	if students > 0 {
+		tx.Rollback()
+		return utils.ConflictError(nil, fmt.Sprintf("Class %s still has %d students, move them to another class or purge them first", existingClass.Name, students))
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM classes WHERE id = ?", id)
+	if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+
+	err = recordAudit(ctx, tx, changeEntry(models.AuditDelete, "classes", id, existingClass, nil))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return dbError(err, "Error committing transaction")
+	}
+	return nil
+}
+
+// GetClassStudentsDBHandler lists the students of a class
+func GetClassStudentsDBHandler(ctx context.Context, id int) ([]models.Student, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	var exists bool
+	err = db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM classes WHERE id = ?)", id).Scan(&exists)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	if !exists {
+		return nil, utils.NotFoundError(nil, "Class not found")
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT "+studentColumns+" FROM students WHERE class_id = ? AND deleted_at IS NULL ORDER BY last_name, first_name", id)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	students := []models.Student{}
+	for rows.Next() {
+		var student models.Student
+		err := scanStudent(ctx, rows, &student)
+		if err != nil {
+			return nil, dbError(err, "Database error")
+		}
+		hideSensitive(ctx, &student)
+		students = append(students, student)
+	}
+	return students, nil
+}
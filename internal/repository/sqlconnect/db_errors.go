@@ -0,0 +1,67 @@
+package sqlconnect
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+	"github.com/go-sql-driver/mysql"
+)
+
+// MySQL server error numbers mapped to typed errors
+const (
+	mysqlErrDuplicateEntry  = 1062
+	mysqlErrRowIsReferenced = 1451
+	mysqlErrNoReferencedRow = 1452
+)
+
+// duplicateKeyFields names the field behind unique keys, so a duplicate can be reported without
+// the value MySQL quotes, which may be another record's personal data
+var duplicateKeyFields = map[string]string{
+	"email":                "email",
+	"username":             "username",
+	"student_number":       "student_number",
+	"name":                 "name",
+	"min_percent":          "min_percent",
+	"uq_terms_year_name":   "name",
+	"uq_classes_name_year": "name",
+}
+
+// duplicateMessage describes a duplicate key error of MySQL, naming the field when its key is known
+func duplicateMessage(mysqlErr *mysql.MySQLError) string {
+	// the message ends in "for key 'table.key'", older servers leave out the table
+	_, key, found := strings.Cut(mysqlErr.Message, "for key '")
+	if found {
+		key = strings.TrimSuffix(key, "'")
+		if i := strings.LastIndex(key, "."); i >= 0 {
+			key = key[i+1:]
+		}
+		if field, ok := duplicateKeyFields[key]; ok {
+			return "A record with this " + field + " already exists"
+		}
+	}
+	return "A record with this value already exists"
+}
+
+// dbError turns constraint violations reported by MySQL into typed errors,
+// anything else is logged and returned with the generic message
+func dbError(err error, message string) error {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case mysqlErrDuplicateEntry:
+			return utils.ConflictError(err, duplicateMessage(mysqlErr))
+		case mysqlErrRowIsReferenced:
+			return utils.ConflictError(err, "Record is still referenced by other records")
+		case mysqlErrNoReferencedRow:
+			return utils.ValidationError("Referenced record does not exist")
+		}
+	}
+	return utils.ErrorHandler(err, message)
+}
+
+// isDuplicateEntry reports whether err is MySQL rejecting a duplicate unique key
+func isDuplicateEntry(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry
+}
@@ -0,0 +1,32 @@
+package sqlconnect
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestDuplicateMessage(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"Duplicate entry 'jane@school.com' for key 'students.email'", "A record with this email already exists"},
+		{"Duplicate entry 'jane@school.com' for key 'email'", "A record with this email already exists"},
+		{"Duplicate entry 'S-1' for key 'students.student_number'", "A record with this student_number already exists"},
+		{"Duplicate entry '10A-2025-2026' for key 'classes.uq_classes_name_year'", "A record with this name already exists"},
+		{"Duplicate entry '4-1-2025-09-01-1' for key 'attendance.uq_attendance'", "A record with this value already exists"},
+		{"Duplicate entry 'it's' for key 'x'", "A record with this value already exists"},
+		{"something else", "A record with this value already exists"},
+	}
+	for _, tt := range tests {
+		got := duplicateMessage(&mysql.MySQLError{Number: mysqlErrDuplicateEntry, Message: tt.message})
+		if got != tt.want {
+			t.Errorf("duplicateMessage(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+		if strings.Contains(got, "'") {
+			t.Errorf("duplicateMessage(%q) leaks the value: %q", tt.message, got)
+		}
+	}
+}
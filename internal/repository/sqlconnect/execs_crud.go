@@ -1,6 +1,7 @@
 package sqlconnect
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/sha256"
 	"database/sql"
@@ -14,6 +15,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/metrics"
 	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
 	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
 	"golang.org/x/crypto/argon2"
@@ -22,22 +24,22 @@ import (
 )
 
 // GetExecsDBHandler retrieves a list of execs with optional filters and sorting
-func GetExecsDBHandler(execs []models.Exec, r *http.Request) ([]models.Exec, error) {
+func GetExecsDBHandler(execs []models.Exec, r *http.Request, includeDeleted bool) ([]models.Exec, error) {
+	ctx := r.Context()
 	db, err := ConnectDB()
 	if err != nil {
 		return nil, utils.ErrorHandler(err, "Database connection error")
 	}
-	defer db.Close()
 
-	query := `SELECT id, first_name, last_name, email, username, user_created_at, inactive_status, role FROM execs WHERE 1=1`
+	query := `SELECT id, first_name, last_name, email, username, user_created_at, inactive_status, role, version, UNIX_TIMESTAMP(updated_at), UNIX_TIMESTAMP(deleted_at) FROM execs WHERE 1=1` + deletedFilter(includeDeleted)
 	var args []any
 
 	query, args = utils.AddFilters(r, query, args, models.Exec{})
 	query = utils.AddSorting(r, query, models.Exec{})
 
-	rows, err := db.Query(query, args...)
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, utils.ErrorHandler(err, "Database error")
+		return nil, dbError(err, "Database error")
 	}
 	defer rows.Close()
 
@@ -45,10 +47,10 @@ func GetExecsDBHandler(execs []models.Exec, r *http.Request) ([]models.Exec, err
 		var exec models.Exec
 		err := rows.Scan(
 			&exec.ID, &exec.FirstName, &exec.LastName, &exec.Email,
-			&exec.Username, &exec.UserCreatedAt, &exec.InactiveStatus, &exec.Role,
+			&exec.Username, &exec.UserCreatedAt, &exec.InactiveStatus, &exec.Role, &exec.Version, &exec.UpdatedAt, &exec.DeletedAt,
 		)
 		if err != nil {
-			return nil, utils.ErrorHandler(err, "Database error")
+			return nil, dbError(err, "Database error")
 		}
 		execs = append(execs, exec)
 	}
@@ -56,37 +58,42 @@ func GetExecsDBHandler(execs []models.Exec, r *http.Request) ([]models.Exec, err
 }
 
 // GetOneExecDBHandler retrieves a single exec by ID
-func GetOneExecDBHandler(id int) (models.Exec, error) {
+func GetOneExecDBHandler(ctx context.Context, id int, includeDeleted bool) (models.Exec, error) {
 	db, err := ConnectDB()
 	if err != nil {
 		return models.Exec{}, utils.ErrorHandler(err, "Database connection error")
 	}
-	defer db.Close()
 
 	var exec models.Exec
-	err = db.QueryRow(`SELECT id, first_name, last_name, email, username, user_created_at, inactive_status, role FROM execs WHERE id = ?`, id).Scan(
+	err = db.QueryRowContext(ctx, `SELECT id, first_name, last_name, email, username, user_created_at, inactive_status, role, version, UNIX_TIMESTAMP(updated_at), UNIX_TIMESTAMP(deleted_at) FROM execs WHERE id = ?`+deletedFilter(includeDeleted), id).Scan(
 		&exec.ID, &exec.FirstName, &exec.LastName, &exec.Email,
-		&exec.Username, &exec.UserCreatedAt, &exec.InactiveStatus, &exec.Role,
+		&exec.Username, &exec.UserCreatedAt, &exec.InactiveStatus, &exec.Role, &exec.Version, &exec.UpdatedAt, &exec.DeletedAt,
 	)
 	if err == sql.ErrNoRows {
-		return models.Exec{}, utils.ErrorHandler(err, "Exec not found")
+		return models.Exec{}, utils.NotFoundError(err, "Exec not found")
 	} else if err != nil {
-		return models.Exec{}, utils.ErrorHandler(err, "Database error")
+		return models.Exec{}, dbError(err, "Database error")
 	}
 	return exec, nil
 }
 
 // AddExecsDBHandler inserts new execs
-func AddExecsDBHandler(newExecs []models.Exec) ([]models.Exec, error) {
+func AddExecsDBHandler(ctx context.Context, newExecs []models.Exec) ([]models.Exec, error) {
 	db, err := ConnectDB()
 	if err != nil {
 		return nil, utils.ErrorHandler(err, "Database connection error")
 	}
-	defer db.Close()
 
-	stmt, err := db.Prepare(utils.GenerateInsertQuery("execs", models.Exec{}))
+	// the batch is all or nothing, a retried request never finds half of it already inserted
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, utils.ErrorHandler(err, "Database error")
+		return nil, dbError(err, "Database error")
+	}
+
+	stmt, err := tx.PrepareContext(ctx, utils.GenerateInsertQuery("execs", models.Exec{}))
+	if err != nil {
+		tx.Rollback()
+		return nil, dbError(err, "Database error")
 	}
 	defer stmt.Close()
 
@@ -95,11 +102,13 @@ func AddExecsDBHandler(newExecs []models.Exec) ([]models.Exec, error) {
 	for i, newExec := range newExecs {
 
 		if newExec.Password == "" {
-			return nil, utils.ErrorHandler(errors.New("password is blank"), "Please enter the password")
+			tx.Rollback()
+			return nil, utils.ValidationError("Please enter the password", utils.FieldError{Field: "password", Message: "is required"})
 		}
 		salt := make([]byte, 16)
 		_, err := rand.Read(salt)
 		if err != nil {
+			tx.Rollback()
 			return nil, utils.ErrorHandler(errors.New("failed to generate salt"), "Database error")
 		}
 
@@ -109,65 +118,92 @@ func AddExecsDBHandler(newExecs []models.Exec) ([]models.Exec, error) {
 		encodedHash := fmt.Sprintf("%s.%s", saltBase64, hashBase64)
 
 		newExec.Password = encodedHash
+		newExec.Version = 1
 
 		values := utils.GetStructValues(newExec)
-		res, err := stmt.Exec(values...)
+		res, err := stmt.ExecContext(ctx, values...)
 		if err != nil {
-			return nil, utils.ErrorHandler(err, "Database error")
+			tx.Rollback()
+			return nil, dbError(err, "Database error")
 		}
 		lastID, err := res.LastInsertId()
 		if err != nil {
-			return nil, utils.ErrorHandler(err, "Database error")
+			tx.Rollback()
+			return nil, dbError(err, "Database error")
 		}
 		newExec.ID = int(lastID)
 		addedExecs[i] = newExec
+
+		err = recordAudit(ctx, tx, changeEntry(models.AuditCreate, "execs", newExec.ID, nil, newExec))
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, dbError(err, "Error committing transaction")
 	}
 	return addedExecs, nil
 }
 
 // PatchExecsDBHandler performs partial updates for multiple execs
-func PatchExecsDBHandler(updates []map[string]interface{}) error {
+func PatchExecsDBHandler(ctx context.Context, updates []map[string]interface{}) error {
 	db, err := ConnectDB()
 	if err != nil {
 		return utils.ErrorHandler(err, "Database connection error")
 	}
-	defer db.Close()
 
-	tx, err := db.Begin()
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
-		return utils.ErrorHandler(err, "Database error")
+		return dbError(err, "Database error")
 	}
 
 	for _, update := range updates {
 		idStr, ok := update["id"].(string)
 		if !ok {
 			tx.Rollback()
-			return utils.ErrorHandler(err, "Invalid or missing ID in update object")
+			return utils.ValidationError("Invalid or missing ID in update object", utils.FieldError{Field: "id", Message: "must be a numeric string"})
 		}
 
 		id, err := strconv.Atoi(idStr)
 		if err != nil {
 			tx.Rollback()
-			return utils.ErrorHandler(err, "Error converting string to int")
+			return utils.ValidationError("Invalid ID in update object", utils.FieldError{Field: "id", Message: "must be a numeric string"})
 		}
 
+		version, err := versionFromUpdate(update)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		// lock the row so the version can't change between the check and the update
 		var execFromDb models.Exec
-		err = db.QueryRow(`SELECT id, first_name, last_name, email, username FROM execs WHERE id = ?`, id).Scan(
-			&execFromDb.ID, &execFromDb.FirstName, &execFromDb.LastName, &execFromDb.Email, &execFromDb.Username,
+		err = tx.QueryRowContext(ctx, `SELECT id, first_name, last_name, email, username, version FROM execs WHERE id = ? AND deleted_at IS NULL FOR UPDATE`, id).Scan(
+			&execFromDb.ID, &execFromDb.FirstName, &execFromDb.LastName, &execFromDb.Email, &execFromDb.Username, &execFromDb.Version,
 		)
 		if err == sql.ErrNoRows {
 			tx.Rollback()
-			return utils.ErrorHandler(err, "Exec not found with ID "+strconv.Itoa(id))
+			return utils.NotFoundError(err, "Exec not found with ID "+strconv.Itoa(id))
 		} else if err != nil {
 			tx.Rollback()
-			return utils.ErrorHandler(err, "Database error")
+			return dbError(err, "Database error")
+		}
+
+		err = checkVersion("Exec", id, version, execFromDb.Version)
+		if err != nil {
+			tx.Rollback()
+			return err
 		}
 
+		before := execFromDb
 		execVal := reflect.ValueOf(&execFromDb).Elem()
 		execType := execVal.Type()
 
 		for k, v := range update {
-			if k == "id" {
+			if k == "id" || k == "version" {
 				continue
 			}
 			for i := 0; i < execType.NumField(); i++ {
@@ -181,7 +217,7 @@ func PatchExecsDBHandler(updates []map[string]interface{}) error {
 							fieldVal.Set(val.Convert(fieldVal.Type()))
 						} else {
 							tx.Rollback()
-							return utils.ErrorHandler(err, "Type mismatch for field "+k)
+							return utils.ValidationError("Type mismatch for field "+k, utils.FieldError{Field: k, Message: "has the wrong type"})
 						}
 					}
 					break
@@ -189,44 +225,68 @@ func PatchExecsDBHandler(updates []map[string]interface{}) error {
 			}
 		}
 
-		_, err = tx.Exec(`UPDATE execs SET first_name=?, last_name=?, email=?, username=? WHERE id=?`,
+		_, err = tx.ExecContext(ctx, `UPDATE execs SET first_name=?, last_name=?, email=?, username=?, version=version+1 WHERE id=? AND deleted_at IS NULL`,
 			execFromDb.FirstName, execFromDb.LastName, execFromDb.Email,
 			execFromDb.Username, execFromDb.ID)
 		if err != nil {
 			tx.Rollback()
-			return utils.ErrorHandler(err, "Database error")
+			return dbError(err, "Database error")
+		}
+
+		err = recordAudit(ctx, tx, changeEntry(models.AuditUpdate, "execs", id, before, savedExecFields(execFromDb)))
+		if err != nil {
+			tx.Rollback()
+			return err
 		}
 	}
 
 	err = tx.Commit()
 	if err != nil {
-		return utils.ErrorHandler(err, "Error committing transaction")
+		return dbError(err, "Error committing transaction")
 	}
 	return nil
 }
 
 // PatchOneExecDBHandler performs partial update for one exec
-func PatchOneExecDBHandler(id int, updates map[string]interface{}) (models.Exec, error) {
+func PatchOneExecDBHandler(ctx context.Context, id, version int, updates map[string]interface{}) (models.Exec, error) {
 	db, err := ConnectDB()
 	if err != nil {
 		return models.Exec{}, utils.ErrorHandler(err, "Database connection error")
 	}
-	defer db.Close()
 
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Exec{}, dbError(err, "Database error")
+	}
+
+	// lock the row so the version can't change between the check and the update
 	var existingExec models.Exec
-	err = db.QueryRow(`SELECT id, first_name, last_name, email, username FROM execs WHERE id = ?`, id).Scan(
-		&existingExec.ID, &existingExec.FirstName, &existingExec.LastName, &existingExec.Email, &existingExec.Username,
+	err = tx.QueryRowContext(ctx, `SELECT id, first_name, last_name, email, username, version FROM execs WHERE id = ? AND deleted_at IS NULL FOR UPDATE`, id).Scan(
+		&existingExec.ID, &existingExec.FirstName, &existingExec.LastName, &existingExec.Email, &existingExec.Username, &existingExec.Version,
 	)
 	if err == sql.ErrNoRows {
-		return models.Exec{}, utils.ErrorHandler(err, "Exec not found")
+		tx.Rollback()
+		return models.Exec{}, utils.NotFoundError(err, "Exec not found")
 	} else if err != nil {
-		return models.Exec{}, utils.ErrorHandler(err, "Database error")
+		tx.Rollback()
+		return models.Exec{}, dbError(err, "Database error")
+	}
+
+	err = checkVersion("Exec", id, version, existingExec.Version)
+	if err != nil {
+		tx.Rollback()
+		return models.Exec{}, err
 	}
+	currentVersion := existingExec.Version
+	before := existingExec
 
 	execVal := reflect.ValueOf(&existingExec).Elem()
 	execType := execVal.Type()
 
 	for k, v := range updates {
+		if k == "version" {
+			continue
+		}
 		for i := 0; i < execType.NumField(); i++ {
 			field := execType.Field(i)
 			jsonTag := field.Tag.Get("json")
@@ -241,80 +301,177 @@ func PatchOneExecDBHandler(id int, updates map[string]interface{}) (models.Exec,
 		}
 	}
 
-	_, err = db.Exec(`UPDATE execs 
-		SET first_name=?, last_name=?, email=?, username=? WHERE id=?`,
-		existingExec.FirstName, existingExec.LastName, existingExec.Email, existingExec.Username, existingExec.ID)
+	res, err := tx.ExecContext(ctx, `UPDATE execs 
+		SET first_name=?, last_name=?, email=?, username=?, version=version+1 WHERE id=? AND version=? AND deleted_at IS NULL`,
+		existingExec.FirstName, existingExec.LastName, existingExec.Email, existingExec.Username, existingExec.ID, currentVersion)
 	if err != nil {
-		return models.Exec{}, utils.ErrorHandler(err, "Database error")
+		tx.Rollback()
+		return models.Exec{}, dbError(err, "Database error")
+	}
+	err = checkVersionedWrite(res, "Exec", id)
+	if err != nil {
+		tx.Rollback()
+		return models.Exec{}, err
+	}
+
+	err = recordAudit(ctx, tx, changeEntry(models.AuditUpdate, "execs", id, before, savedExecFields(existingExec)))
+	if err != nil {
+		tx.Rollback()
+		return models.Exec{}, err
 	}
 
+	err = tx.Commit()
+	if err != nil {
+		return models.Exec{}, dbError(err, "Error committing transaction")
+	}
+
+	existingExec.Version = currentVersion + 1
 	return existingExec, nil
 }
 
-// DeleteOneExecDBHandler deletes a single exec
-func DeleteOneExecDBHandler(id int) error {
+// DeleteOneExecDBHandler soft-deletes a single exec
+func DeleteOneExecDBHandler(ctx context.Context, id, version int) error {
 	db, err := ConnectDB()
 	if err != nil {
 		return utils.ErrorHandler(err, "Database connection error")
 	}
-	defer db.Close()
 
-	res, err := db.Exec("DELETE FROM execs WHERE id = ?", id)
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+
+	// lock the row so the version can't change between the check and the update
+	var existingExec models.Exec
+	err = tx.QueryRowContext(ctx, "SELECT id, first_name, last_name, email, username, role, version FROM execs WHERE id = ? AND deleted_at IS NULL FOR UPDATE", id).Scan(
+		&existingExec.ID, &existingExec.FirstName, &existingExec.LastName, &existingExec.Email, &existingExec.Username, &existingExec.Role, &existingExec.Version)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return utils.NotFoundError(err, "Exec not found")
+	} else if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+
+	err = checkVersion("Exec", id, version, existingExec.Version)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, "UPDATE execs SET deleted_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id = ? AND version = ? AND deleted_at IS NULL", id, existingExec.Version)
+	if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+	err = checkVersionedWrite(res, "Exec", id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = recordAudit(ctx, tx, changeEntry(models.AuditDelete, "execs", id, existingExec, nil))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return dbError(err, "Error committing transaction")
+	}
+	return nil
+}
+
+// RestoreExecDBHandler undoes the soft delete of an exec
+func RestoreExecDBHandler(ctx context.Context, id int) (models.Exec, error) {
+	db, err := ConnectDB()
 	if err != nil {
-		return utils.ErrorHandler(err, "Database error")
+		return models.Exec{}, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Exec{}, dbError(err, "Database error")
+	}
+
+	res, err := tx.ExecContext(ctx, "UPDATE execs SET deleted_at = NULL, version = version + 1 WHERE id = ? AND deleted_at IS NOT NULL", id)
+	if err != nil {
+		tx.Rollback()
+		return models.Exec{}, dbError(err, "Database error")
 	}
 
 	rowsAffected, err := res.RowsAffected()
 	if err != nil {
-		return utils.ErrorHandler(err, "Database error")
+		tx.Rollback()
+		return models.Exec{}, dbError(err, "Database error")
 	}
 	if rowsAffected == 0 {
-		return utils.ErrorHandler(err, "Exec not found")
+		// either there is no such exec or it isn't deleted
+		var deleted bool
+		err = tx.QueryRowContext(ctx, "SELECT deleted_at IS NOT NULL FROM execs WHERE id = ?", id).Scan(&deleted)
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return models.Exec{}, utils.NotFoundError(err, "Exec not found")
+		} else if err != nil {
+			return models.Exec{}, dbError(err, "Database error")
+		}
+		return models.Exec{}, utils.ConflictError(nil, "Exec is not deleted")
 	}
-	return nil
+
+	err = recordAudit(ctx, tx, changeEntry(models.AuditRestore, "execs", id, nil, nil))
+	if err != nil {
+		tx.Rollback()
+		return models.Exec{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.Exec{}, dbError(err, "Error committing transaction")
+	}
+
+	return GetOneExecDBHandler(ctx, id, false)
 }
 
-func LoginDBHandler(username string) (*models.Exec, error) {
+func LoginDBHandler(ctx context.Context, username string) (*models.Exec, error) {
 	db, err := ConnectDB()
 	if err != nil {
 		return nil, utils.ErrorHandler(err, "Database connection error")
 	}
-	defer db.Close()
 
 	user := &models.Exec{}
-	err = db.QueryRow(`SELECT id, first_name, last_name, email, username, password, inactive_status, role FROM execs WHERE username = ?`, username).Scan(
+	err = db.QueryRowContext(ctx, `SELECT id, first_name, last_name, email, username, password, inactive_status, role FROM execs WHERE username = ? AND deleted_at IS NULL`, username).Scan(
 		&user.ID, &user.FirstName, &user.LastName, &user.Email,
 		&user.Username, &user.Password, &user.InactiveStatus, &user.Role,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, utils.ErrorHandler(err, "User not found")
+			return nil, utils.NotFoundError(err, "User not found")
 		}
-		return nil, utils.ErrorHandler(err, "Database error")
+		return nil, dbError(err, "Database error")
 	}
 	return user, nil
 }
 
-func UpdatePasswordDBHandler(userId int, currentPassword, newPassword string) (bool, string, error) {
+func UpdatePasswordDBHandler(ctx context.Context, userId int, currentPassword, newPassword string) (bool, string, error) {
 	db, err := ConnectDB()
 	if err != nil {
 		return false, "", utils.ErrorHandler(err, "database connection error")
 	}
-	defer db.Close()
 
 	var username string
 	var userPassword string
 	var userRole string
 
-	err = db.QueryRow("SELECT username, password, role FROM execs WHERE id = ?", userId).Scan(&username, &userPassword, &userRole)
+	err = db.QueryRowContext(ctx, "SELECT username, password, role FROM execs WHERE id = ? AND deleted_at IS NULL", userId).Scan(&username, &userPassword, &userRole)
 	if err != nil {
-		return false, "", utils.ErrorHandler(err, "user not found")
+		return false, "", utils.NotFoundError(err, "user not found")
 	}
 
 	err = utils.VerifyPassword(currentPassword, userPassword)
 	if err != nil {
-		return false, "", utils.ErrorHandler(err, "The password you entered does not match the current password on file.")
+		return false, "", utils.ValidationError("The password you entered does not match the current password on file.", utils.FieldError{Field: "current_password", Message: "does not match"})
 	}
 
 	hashedPassword, err := utils.HashPassword(newPassword)
@@ -324,10 +481,11 @@ func UpdatePasswordDBHandler(userId int, currentPassword, newPassword string) (b
 
 	currentTime := time.Now().Format(time.RFC3339)
 
-	_, err = db.Exec("UPDATE execs SET password = ?, password_changed_at = ? WHERE id = ?", hashedPassword, currentTime, userId)
+	_, err = db.ExecContext(ctx, "UPDATE execs SET password = ?, password_changed_at = ? WHERE id = ?", hashedPassword, currentTime, userId)
 	if err != nil {
-		return false, "", utils.ErrorHandler(err, "failed to update the password")
+		return false, "", dbError(err, "failed to update the password")
 	}
+	AddAuditEntryDBHandler(ctx, changeEntry(models.AuditPasswordChange, "execs", userId, nil, nil))
 
 	token, err := utils.SignToken(userId, username, userRole)
 	if err != nil {
@@ -338,17 +496,16 @@ func UpdatePasswordDBHandler(userId int, currentPassword, newPassword string) (b
 	return true, token, nil
 }
 
-func ForgotPasswordDBHandler(emailId string) error {
+func ForgotPasswordDBHandler(ctx context.Context, emailId string) error {
 	db, err := ConnectDB()
 	if err != nil {
 		return utils.ErrorHandler(err, "Internal error")
 	}
-	defer db.Close()
 
 	var exec models.Exec
-	err = db.QueryRow("SELECT id FROM execs WHERE email = ?", emailId).Scan(&exec.ID)
+	err = db.QueryRowContext(ctx, "SELECT id FROM execs WHERE email = ? AND deleted_at IS NULL", emailId).Scan(&exec.ID)
 	if err != nil {
-		return utils.ErrorHandler(err, "User not found")
+		return utils.NotFoundError(err, "User not found")
 	}
 
 	duration, err := strconv.Atoi(os.Getenv("RESET_TOKEN_EXP_DURATION"))
@@ -371,10 +528,11 @@ func ForgotPasswordDBHandler(emailId string) error {
 
 	hashedTokenString := hex.EncodeToString(hashedToken[:])
 
-	_, err = db.Exec("UPDATE execs SET password_reset_token = ?, password_token_expires = ? WHERE id = ?", hashedTokenString, expiry, exec.ID)
+	_, err = db.ExecContext(ctx, "UPDATE execs SET password_reset_token = ?, password_token_expires = ? WHERE id = ?", hashedTokenString, expiry, exec.ID)
 	if err != nil {
 		return utils.ErrorHandler(err, "Failed to send password reset email")
 	}
+	AddAuditEntryDBHandler(ctx, changeEntry(models.AuditPasswordResetRequest, "execs", exec.ID, nil, nil))
 
 	resetURL := fmt.Sprintf("https://localhost:3000/execs/resetpassword/reset/%s", token)
 	message := fmt.Sprintf("Forgot your password?  Reset your password using the following link: \n%s\nIf you didn't request a password reset, please ignore this email. This link is only valid for %d minutes.", resetURL, int(mins))
@@ -388,13 +546,15 @@ func ForgotPasswordDBHandler(emailId string) error {
 	d := mail.NewDialer("localhost", 1025, "", "")
 	err = d.DialAndSend(m)
 	if err != nil {
+		metrics.EmailsSentTotal.WithLabelValues("failed").Inc()
 		return utils.ErrorHandler(err, "Failed to send password reset email")
 	}
+	metrics.EmailsSentTotal.WithLabelValues("sent").Inc()
 
 	return nil
 }
 
-func ResetPasswordDBHandler(token, newPassword string) error {
+func ResetPasswordDBHandler(ctx context.Context, token, newPassword string) error {
 
 	bytes, err := hex.DecodeString(token)
 	if err != nil {
@@ -408,14 +568,13 @@ func ResetPasswordDBHandler(token, newPassword string) error {
 	if err != nil {
 		return utils.ErrorHandler(err, "Internal error")
 	}
-	defer db.Close()
 
 	var user models.Exec
 
-	query := "SELECT id, email FROM execs WHERE password_reset_token = ? AND password_token_expires > ?"
-	err = db.QueryRow(query, hashedTokenString, time.Now().Format(time.RFC3339)).Scan(&user.ID, &user.Email)
+	query := "SELECT id, email FROM execs WHERE password_reset_token = ? AND password_token_expires > ? AND deleted_at IS NULL"
+	err = db.QueryRowContext(ctx, query, hashedTokenString, time.Now().Format(time.RFC3339)).Scan(&user.ID, &user.Email)
 	if err != nil {
-		return utils.ErrorHandler(err, "Invalid or expired reset code")
+		return utils.ValidationError("Invalid or expired reset code", utils.FieldError{Field: "resetcode", Message: "is invalid or expired"})
 	}
 
 	hashedPassword, err := utils.HashPassword(newPassword)
@@ -424,9 +583,26 @@ func ResetPasswordDBHandler(token, newPassword string) error {
 	}
 
 	updateQuery := "UPDATE execs SET password = ?, password_reset_token = NULL, password_token_expires = NULL, password_changed_at = ? WHERE id = ?"
-	_, err = db.Exec(updateQuery, hashedPassword, time.Now().Format(time.RFC3339), user.ID)
+	_, err = db.ExecContext(ctx, updateQuery, hashedPassword, time.Now().Format(time.RFC3339), user.ID)
 	if err != nil {
 		return utils.ErrorHandler(err, "Internal error")
 	}
+	// nobody is logged in during a reset, the exec who owns the reset code is the actor
+	resetEntry := changeEntry(models.AuditPasswordReset, "execs", user.ID, nil, nil)
+	resetEntry.ActorID = &user.ID
+	AddAuditEntryDBHandler(ctx, resetEntry)
 	return nil
-}
\ No newline at end of file
+}
+
+// savedExecFields keeps only the columns a patch writes, so the audit diff
+// doesn't show fields that were sent but not saved
+func savedExecFields(exec models.Exec) models.Exec {
+	return models.Exec{
+		ID:        exec.ID,
+		FirstName: exec.FirstName,
+		LastName:  exec.LastName,
+		Email:     exec.Email,
+		Username:  exec.Username,
+		Version:   exec.Version,
+	}
+}
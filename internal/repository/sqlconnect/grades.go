@@ -0,0 +1,471 @@
+package sqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+const assessmentColumns = "id, class_id, subject, name, max_score, weight, date, term, UNIX_TIMESTAMP(created_at)"
+
+func scanAssessment(row interface{ Scan(...any) error }, a *models.Assessment) error {
+	return row.Scan(&a.ID, &a.ClassID, &a.Subject, &a.Name, &a.MaxScore, &a.Weight, &a.Date, &a.Term, &a.CreatedAt)
+}
+
+// subjectTermFilter narrows assessments, whose table is aliased prefix, down to a subject and a term
+// when they aren't empty
+func subjectTermFilter(prefix, subject, term string) (string, []any) {
+	var filter string
+	var args []any
+	if subject != "" {
+		filter += " AND " + prefix + "subject = ?"
+		args = append(args, subject)
+	}
+	if term != "" {
+		filter += " AND " + prefix + "term = ?"
+		args = append(args, term)
+	}
+	return filter, args
+}
+
+// GetAssessmentsDBHandler lists the assessments of a class by date, optionally of one subject or term
+func GetAssessmentsDBHandler(ctx context.Context, classID int, subject, term string) ([]models.Assessment, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	err = checkClassExists(ctx, db, classID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, args := subjectTermFilter("", subject, term)
+	rows, err := db.QueryContext(ctx, "SELECT "+assessmentColumns+" FROM assessments WHERE class_id = ?"+filter+" ORDER BY date, id", append([]any{classID}, args...)...)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	assessments := []models.Assessment{}
+	for rows.Next() {
+		var assessment models.Assessment
+		err := scanAssessment(rows, &assessment)
+		if err != nil {
+			return nil, dbError(err, "Database error")
+		}
+		assessments = append(assessments, assessment)
+	}
+	return assessments, nil
+}
+
+// GetOneAssessmentDBHandler returns an assessment of a class with its marks
+func GetOneAssessmentDBHandler(ctx context.Context, classID, id int) (models.Assessment, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return models.Assessment{}, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	var assessment models.Assessment
+	err = scanAssessment(db.QueryRowContext(ctx, "SELECT "+assessmentColumns+" FROM assessments WHERE id = ? AND class_id = ?", id, classID), &assessment)
+	if err == sql.ErrNoRows {
+		return models.Assessment{}, utils.NotFoundError(err, "Assessment not found")
+	} else if err != nil {
+		return models.Assessment{}, dbError(err, "Database error")
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT student_id, score, note, UNIX_TIMESTAMP(recorded_at) FROM marks WHERE assessment_id = ? ORDER BY student_id", id)
+	if err != nil {
+		return models.Assessment{}, dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	assessment.Marks = []models.Mark{}
+	for rows.Next() {
+		var mark models.Mark
+		err := rows.Scan(&mark.StudentID, &mark.Score, &mark.Note, &mark.RecordedAt)
+		if err != nil {
+			return models.Assessment{}, dbError(err, "Database error")
+		}
+		assessment.Marks = append(assessment.Marks, mark)
+	}
+	return assessment, nil
+}
+
+// AddAssessmentsDBHandler adds assessments to a class, the batch is all or nothing. A weight of 0 counts as 1.
+func AddAssessmentsDBHandler(ctx context.Context, classID int, newAssessments []models.Assessment) ([]models.Assessment, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+
+	var academicYear string
+	err = tx.QueryRowContext(ctx, "SELECT academic_year FROM classes WHERE id = ?", classID).Scan(&academicYear)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return nil, utils.NotFoundError(nil, "Class not found")
+	} else if err != nil {
+		tx.Rollback()
+		return nil, dbError(err, "Database error")
+	}
+
+	added := make([]models.Assessment, len(newAssessments))
+	for i, assessment := range newAssessments {
+		assessment.ClassID = classID
+		assessment.Marks, assessment.CreatedAt = nil, nil
+		if assessment.Weight == 0 {
+			assessment.Weight = 1
+		}
+		term, err := yearTerm(ctx, tx, academicYear, assessment.Term, "term")
+		if err != nil {
+			tx.Rollback()
+			return nil, atIndex(err, i)
+		}
+		if assessment.Date < term.StartDate || assessment.Date > term.EndDate {
+			tx.Rollback()
+			return nil, utils.ValidationError("Assessment outside its term", utils.WithIndex([]utils.FieldError{{Field: "date", Message: "must be within " + term.Name + ", " + term.StartDate + " to " + term.EndDate}}, i)...)
+		}
+
+		res, err := tx.ExecContext(ctx, "INSERT INTO assessments (class_id, subject, name, max_score, weight, date, term) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			assessment.ClassID, assessment.Subject, assessment.Name, assessment.MaxScore, assessment.Weight, assessment.Date, assessment.Term)
+		if err != nil {
+			tx.Rollback()
+			return nil, dbError(err, "Database error")
+		}
+		lastID, err := res.LastInsertId()
+		if err != nil {
+			tx.Rollback()
+			return nil, dbError(err, "Database error")
+		}
+		assessment.ID = int(lastID)
+		added[i] = assessment
+
+		err = recordAudit(ctx, tx, changeEntry(models.AuditCreate, "assessments", assessment.ID, nil, assessment))
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, dbError(err, "Error committing transaction")
+	}
+	return added, nil
+}
+
+// DeleteAssessmentDBHandler removes an assessment of a class together with its marks
+func DeleteAssessmentDBHandler(ctx context.Context, classID, id int) error {
+	db, err := ConnectDB()
+	if err != nil {
+		return utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+
+	var existing models.Assessment
+	err = scanAssessment(tx.QueryRowContext(ctx, "SELECT "+assessmentColumns+" FROM assessments WHERE id = ? AND class_id = ? FOR UPDATE", id, classID), &existing)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return utils.NotFoundError(err, "Assessment not found")
+	} else if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM assessments WHERE id = ?", id)
+	if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+
+	err = recordAudit(ctx, tx, changeEntry(models.AuditDelete, "assessments", id, existing, nil))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return dbError(err, "Error committing transaction")
+	}
+	return nil
+}
+
+// SubmitMarksDBHandler records the marks of students of the class in an assessment, replacing
+// the marks they had in it. The batch is all or nothing.
+func SubmitMarksDBHandler(ctx context.Context, classID, assessmentID int, marks []models.Mark) ([]models.Mark, models.UpsertResult, error) {
+	var result models.UpsertResult
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, result, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, result, dbError(err, "Database error")
+	}
+
+	var maxScore float64
+	err = tx.QueryRowContext(ctx, "SELECT max_score FROM assessments WHERE id = ? AND class_id = ?", assessmentID, classID).Scan(&maxScore)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return nil, result, utils.NotFoundError(err, "Assessment not found")
+	} else if err != nil {
+		tx.Rollback()
+		return nil, result, dbError(err, "Database error")
+	}
+
+	var fieldErrs []utils.FieldError
+	for i, mark := range marks {
+		if mark.Score != nil && *mark.Score > maxScore {
+			fieldErrs = append(fieldErrs, utils.WithIndex([]utils.FieldError{{Field: "score", Message: fmt.Sprintf("must be at most the max score of %g", maxScore)}}, i)...)
+		}
+		var studentClassID int
+		err = tx.QueryRowContext(ctx, "SELECT class_id FROM students WHERE id = ? AND deleted_at IS NULL", mark.StudentID).Scan(&studentClassID)
+		if err == sql.ErrNoRows || (err == nil && studentClassID != classID) {
+			fieldErrs = append(fieldErrs, utils.WithIndex([]utils.FieldError{{Field: "student_id", Message: "must be a student of the class"}}, i)...)
+		} else if err != nil {
+			tx.Rollback()
+			return nil, result, dbError(err, "Database error")
+		}
+	}
+	if len(fieldErrs) > 0 {
+		tx.Rollback()
+		return nil, result, utils.ValidationError("Validation failed", fieldErrs...)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO marks (assessment_id, student_id, score, note) VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE score = VALUES(score), note = VALUES(note)`)
+	if err != nil {
+		tx.Rollback()
+		return nil, result, dbError(err, "Database error")
+	}
+	defer stmt.Close()
+
+	saved := make([]models.Mark, len(marks))
+	for i, mark := range marks {
+		mark.RecordedAt = nil
+		res, err := stmt.ExecContext(ctx, assessmentID, mark.StudentID, mark.Score, mark.Note)
+		if err != nil {
+			tx.Rollback()
+			return nil, result, dbError(err, "Database error")
+		}
+		// MySQL reports 1 row for an insert, 2 for an update and 0 when nothing changed
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
+			tx.Rollback()
+			return nil, result, dbError(err, "Database error")
+		}
+		switch rowsAffected {
+		case 1:
+			result.Created++
+		case 2:
+			result.Updated++
+		default:
+			result.Unchanged++
+		}
+		saved[i] = mark
+	}
+
+	if result.Created+result.Updated > 0 {
+		err = recordAudit(ctx, tx, models.AuditEntry{
+			Action:     models.AuditUpdate,
+			Resource:   "assessments",
+			ResourceID: &assessmentID,
+			Changes:    map[string]models.AuditChange{"marks": {To: len(marks)}},
+		})
+		if err != nil {
+			tx.Rollback()
+			return nil, result, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, result, dbError(err, "Error committing transaction")
+	}
+	return saved, result, nil
+}
+
+// GetGradeBandsDBHandler lists the grade bands of the school, highest first
+func GetGradeBandsDBHandler(ctx context.Context) ([]models.GradeBand, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, utils.ErrorHandler(err, "Database connection error")
+	}
+	return gradeBands(ctx, db)
+}
+
+func gradeBands(ctx context.Context, db *sql.DB) ([]models.GradeBand, error) {
+	rows, err := db.QueryContext(ctx, "SELECT grade, min_percent FROM grade_bands ORDER BY min_percent DESC")
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	bands := []models.GradeBand{}
+	for rows.Next() {
+		var band models.GradeBand
+		err := rows.Scan(&band.Grade, &band.MinPercent)
+		if err != nil {
+			return nil, dbError(err, "Database error")
+		}
+		bands = append(bands, band)
+	}
+	return bands, nil
+}
+
+// ReplaceGradeBandsDBHandler replaces every grade band of the school with bands
+func ReplaceGradeBandsDBHandler(ctx context.Context, bands []models.GradeBand) error {
+	db, err := ConnectDB()
+	if err != nil {
+		return utils.ErrorHandler(err, "Database connection error")
+	}
+
+	before, err := gradeBands(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM grade_bands")
+	if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+	for _, band := range bands {
+		_, err = tx.ExecContext(ctx, "INSERT INTO grade_bands (grade, min_percent) VALUES (?, ?)", band.Grade, band.MinPercent)
+		if err != nil {
+			tx.Rollback()
+			return dbError(err, "Database error")
+		}
+	}
+
+	err = recordAudit(ctx, tx, models.AuditEntry{
+		Action:   models.AuditUpdate,
+		Resource: "grade_bands",
+		Changes:  map[string]models.AuditChange{"bands": {From: before, To: bands}},
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return dbError(err, "Error committing transaction")
+	}
+	return nil
+}
+
+// GetStudentGradesDBHandler works out the averages of a student from every mark they have,
+// optionally of one subject or term, with the marks
+func GetStudentGradesDBHandler(ctx context.Context, studentID int, subject, term string) (models.StudentGrades, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return models.StudentGrades{}, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	var student models.Student
+	err = db.QueryRowContext(ctx, "SELECT id, first_name, last_name FROM students WHERE id = ? AND deleted_at IS NULL", studentID).Scan(&student.ID, &student.FirstName, &student.LastName)
+	if err == sql.ErrNoRows {
+		return models.StudentGrades{}, utils.NotFoundError(err, "Student not found")
+	} else if err != nil {
+		return models.StudentGrades{}, dbError(err, "Database error")
+	}
+
+	filter, args := subjectTermFilter("a.", subject, term)
+	marks, err := gradedMarks(ctx, db, "m.student_id = ?"+filter, append([]any{studentID}, args...))
+	if err != nil {
+		return models.StudentGrades{}, err
+	}
+	bands, err := gradeBands(ctx, db)
+	if err != nil {
+		return models.StudentGrades{}, err
+	}
+
+	grades := models.GradeMarks(marks[studentID], bands, true)
+	grades.StudentID, grades.FirstName, grades.LastName = student.ID, student.FirstName, student.LastName
+	return grades, nil
+}
+
+// GetClassGradesDBHandler works out the averages of every student of a class from the marks of its
+// assessments, optionally of one subject or term. Students without marks are listed without averages.
+func GetClassGradesDBHandler(ctx context.Context, classID int, subject, term string) ([]models.StudentGrades, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	err = checkClassExists(ctx, db, classID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, args := subjectTermFilter("a.", subject, term)
+	marks, err := gradedMarks(ctx, db, "a.class_id = ?"+filter, append([]any{classID}, args...))
+	if err != nil {
+		return nil, err
+	}
+	bands, err := gradeBands(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT id, first_name, last_name FROM students WHERE class_id = ? AND deleted_at IS NULL ORDER BY last_name, first_name", classID)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	grades := []models.StudentGrades{}
+	for rows.Next() {
+		var student models.Student
+		err := rows.Scan(&student.ID, &student.FirstName, &student.LastName)
+		if err != nil {
+			return nil, dbError(err, "Database error")
+		}
+		studentGrades := models.GradeMarks(marks[student.ID], bands, false)
+		studentGrades.StudentID, studentGrades.FirstName, studentGrades.LastName = student.ID, student.FirstName, student.LastName
+		grades = append(grades, studentGrades)
+	}
+	return grades, nil
+}
+
+// gradedMarks reads the marks matching where, in which m is marks and a assessments, by student
+func gradedMarks(ctx context.Context, db *sql.DB, where string, args []any) (map[int][]models.GradedMark, error) {
+	rows, err := db.QueryContext(ctx, `SELECT m.student_id, a.id, a.name, a.subject, a.term, a.date, m.score, a.max_score, a.weight, m.note
+		FROM marks m JOIN assessments a ON a.id = m.assessment_id WHERE `+where+" ORDER BY a.date, a.id", args...)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	marks := make(map[int][]models.GradedMark)
+	for rows.Next() {
+		var studentID int
+		var mark models.GradedMark
+		err := rows.Scan(&studentID, &mark.AssessmentID, &mark.Assessment, &mark.Subject, &mark.Term, &mark.Date, &mark.Score, &mark.MaxScore, &mark.Weight, &mark.Note)
+		if err != nil {
+			return nil, dbError(err, "Database error")
+		}
+		marks[studentID] = append(marks[studentID], mark)
+	}
+	return marks, nil
+}
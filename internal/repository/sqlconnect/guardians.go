@@ -0,0 +1,505 @@
+package sqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+const guardianColumns = "id, first_name, last_name, relationship, phone, email, address, version, UNIX_TIMESTAMP(updated_at)"
+
+func scanGuardian(row interface{ Scan(...any) error }, guardian *models.Guardian) error {
+	return row.Scan(&guardian.ID, &guardian.FirstName, &guardian.LastName, &guardian.Relationship, &guardian.Phone, &guardian.Email, &guardian.Address, &guardian.Version, &guardian.UpdatedAt)
+}
+
+func GetGuardiansDBHandler(guardians []models.Guardian, r *http.Request, limit, page int) ([]models.Guardian, int, error) {
+	ctx := r.Context()
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, 0, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	filter, args := utils.AddFilters(r, " WHERE 1=1", nil, models.Guardian{})
+
+	var totalGuardians int
+	err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM guardians"+filter, args...).Scan(&totalGuardians)
+	if err != nil {
+		return nil, 0, dbError(err, "Database error")
+	}
+
+	query := utils.AddSorting(r, "SELECT "+guardianColumns+" FROM guardians"+filter, models.Guardian{})
+	query += " LIMIT ? OFFSET ?"
+	args = append(args, limit, (page-1)*limit)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var guardian models.Guardian
+		err := scanGuardian(rows, &guardian)
+		if err != nil {
+			return nil, 0, dbError(err, "Database error")
+		}
+		guardians = append(guardians, guardian)
+	}
+	return guardians, totalGuardians, nil
+}
+
+func GetOneGuardianDBHandler(ctx context.Context, id int) (models.Guardian, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return models.Guardian{}, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	var guardian models.Guardian
+	err = scanGuardian(db.QueryRowContext(ctx, "SELECT "+guardianColumns+" FROM guardians WHERE id = ?", id), &guardian)
+	if err == sql.ErrNoRows {
+		return models.Guardian{}, utils.NotFoundError(err, "Guardian not found")
+	} else if err != nil {
+		return models.Guardian{}, dbError(err, "Database error")
+	}
+	return guardian, nil
+}
+
+func AddGuardiansDBHandler(ctx context.Context, newGuardians []models.Guardian) ([]models.Guardian, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+
+	stmt, err := tx.PrepareContext(ctx, utils.GenerateInsertQuery("guardians", models.Guardian{}))
+	if err != nil {
+		tx.Rollback()
+		return nil, dbError(err, "Database error")
+	}
+	defer stmt.Close()
+
+	addedGuardians := make([]models.Guardian, len(newGuardians))
+
+	for i, newGuardian := range newGuardians {
+		newGuardian.Version = 1
+		newGuardian.UpdatedAt = nil
+		res, err := stmt.ExecContext(ctx, utils.GetStructValues(newGuardian)...)
+		if err != nil {
+			tx.Rollback()
+			return nil, dbError(err, "Database error")
+		}
+		lastID, err := res.LastInsertId()
+		if err != nil {
+			tx.Rollback()
+			return nil, dbError(err, "Database error")
+		}
+		newGuardian.ID = int(lastID)
+		addedGuardians[i] = newGuardian
+
+		err = recordAudit(ctx, tx, changeEntry(models.AuditCreate, "guardians", newGuardian.ID, nil, newGuardian))
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, dbError(err, "Error committing transaction")
+	}
+	return addedGuardians, nil
+}
+
+// UpdateGuardianDBHandler replaces the guardian with ID id, see updateGuardian
+func UpdateGuardianDBHandler(ctx context.Context, id, version int, updatedGuardian models.Guardian) (models.Guardian, error) {
+	return updateGuardian(ctx, id, version, func(guardian *models.Guardian) error {
+		updatedGuardian.ID, updatedGuardian.Version, updatedGuardian.UpdatedAt = guardian.ID, guardian.Version, guardian.UpdatedAt
+		*guardian = updatedGuardian
+		return nil
+	})
+}
+
+// PatchOneGuardianDBHandler changes the fields of the guardian in updates, an empty email or
+// address removes it
+func PatchOneGuardianDBHandler(ctx context.Context, id, version int, updates map[string]interface{}) (models.Guardian, error) {
+	return updateGuardian(ctx, id, version, func(guardian *models.Guardian) error {
+		current, err := json.Marshal(guardian)
+		if err != nil {
+			return utils.ErrorHandler(err, "Error patching guardian")
+		}
+		merged := make(map[string]interface{})
+		err = json.Unmarshal(current, &merged)
+		if err != nil {
+			return utils.ErrorHandler(err, "Error patching guardian")
+		}
+		for k, v := range updates {
+			if k != "version" {
+				merged[k] = v
+			}
+		}
+		patched, err := json.Marshal(merged)
+		if err != nil {
+			return utils.ErrorHandler(err, "Error patching guardian")
+		}
+		var patchedGuardian models.Guardian
+		err = json.Unmarshal(patched, &patchedGuardian)
+		if err != nil {
+			return utils.ValidationError("Invalid guardian update", utils.FieldError{Field: "body", Message: "has a field of the wrong type"})
+		}
+		*guardian = patchedGuardian
+		return nil
+	})
+}
+
+// updateGuardian applies change to the guardian with ID id if it is still at version
+func updateGuardian(ctx context.Context, id, version int, change func(*models.Guardian) error) (models.Guardian, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return models.Guardian{}, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Guardian{}, dbError(err, "Database error")
+	}
+
+	var existingGuardian models.Guardian
+	err = scanGuardian(tx.QueryRowContext(ctx, "SELECT "+guardianColumns+" FROM guardians WHERE id = ? FOR UPDATE", id), &existingGuardian)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return models.Guardian{}, utils.NotFoundError(err, "Guardian not found")
+	} else if err != nil {
+		tx.Rollback()
+		return models.Guardian{}, dbError(err, "Database error")
+	}
+
+	err = checkVersion("Guardian", id, version, existingGuardian.Version)
+	if err != nil {
+		tx.Rollback()
+		return models.Guardian{}, err
+	}
+
+	updatedGuardian := existingGuardian
+	err = change(&updatedGuardian)
+	if err != nil {
+		tx.Rollback()
+		return models.Guardian{}, err
+	}
+	updatedGuardian.ID = existingGuardian.ID
+	updatedGuardian.Version = existingGuardian.Version + 1
+	updatedGuardian.UpdatedAt = nil
+
+	_, err = tx.ExecContext(ctx, utils.GenerateUpdateQuery("guardians", models.Guardian{}), append(utils.GetStructValues(updatedGuardian), id)...)
+	if err != nil {
+		tx.Rollback()
+		return models.Guardian{}, dbError(err, "Database error")
+	}
+
+	err = recordAudit(ctx, tx, changeEntry(models.AuditUpdate, "guardians", id, existingGuardian, updatedGuardian))
+	if err != nil {
+		tx.Rollback()
+		return models.Guardian{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.Guardian{}, dbError(err, "Error committing transaction")
+	}
+	return updatedGuardian, nil
+}
+
+// DeleteOneGuardianDBHandler removes a guardian for good along with their links to students
+func DeleteOneGuardianDBHandler(ctx context.Context, id, version int) error {
+	db, err := ConnectDB()
+	if err != nil {
+		return utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+
+	var existingGuardian models.Guardian
+	err = scanGuardian(tx.QueryRowContext(ctx, "SELECT "+guardianColumns+" FROM guardians WHERE id = ? FOR UPDATE", id), &existingGuardian)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return utils.NotFoundError(err, "Guardian not found")
+	} else if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+
+	err = checkVersion("Guardian", id, version, existingGuardian.Version)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// the links go with the guardian through ON DELETE CASCADE
+	_, err = tx.ExecContext(ctx, "DELETE FROM guardians WHERE id = ?", id)
+	if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+
+	err = recordAudit(ctx, tx, changeEntry(models.AuditDelete, "guardians", id, existingGuardian, nil))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return dbError(err, "Error committing transaction")
+	}
+	return nil
+}
+
+// GetStudentGuardiansDBHandler lists the guardians of a student, the primary contact first
+func GetStudentGuardiansDBHandler(ctx context.Context, studentID int) ([]models.LinkedGuardian, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	var exists bool
+	err = db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM students WHERE id = ? AND deleted_at IS NULL)", studentID).Scan(&exists)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	if !exists {
+		return nil, utils.NotFoundError(nil, "Student not found")
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT g.id, g.first_name, g.last_name, g.relationship, g.phone, g.email, g.address, g.version, UNIX_TIMESTAMP(g.updated_at),
+		sg.primary_contact, sg.can_pick_up
+		FROM student_guardians sg JOIN guardians g ON g.id = sg.guardian_id
+		WHERE sg.student_id = ? ORDER BY sg.primary_contact DESC, g.last_name, g.first_name`, studentID)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	guardians := []models.LinkedGuardian{}
+	for rows.Next() {
+		var g models.LinkedGuardian
+		err := rows.Scan(&g.ID, &g.FirstName, &g.LastName, &g.Relationship, &g.Phone, &g.Email, &g.Address, &g.Version, &g.UpdatedAt,
+			&g.PrimaryContact, &g.CanPickUp)
+		if err != nil {
+			return nil, dbError(err, "Database error")
+		}
+		guardians = append(guardians, g)
+	}
+	return guardians, nil
+}
+
+// GetGuardianStudentsDBHandler lists the students a guardian is responsible for, deleted ones left out
+func GetGuardianStudentsDBHandler(ctx context.Context, guardianID int) ([]models.LinkedStudent, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	var exists bool
+	err = db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM guardians WHERE id = ?)", guardianID).Scan(&exists)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	if !exists {
+		return nil, utils.NotFoundError(nil, "Guardian not found")
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT s.id, s.first_name, s.last_name, s.class, sg.primary_contact, sg.can_pick_up
+		FROM student_guardians sg JOIN students s ON s.id = sg.student_id
+		WHERE sg.guardian_id = ? AND s.deleted_at IS NULL ORDER BY s.last_name, s.first_name`, guardianID)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	students := []models.LinkedStudent{}
+	for rows.Next() {
+		var s models.LinkedStudent
+		err := rows.Scan(&s.StudentID, &s.FirstName, &s.LastName, &s.Class, &s.PrimaryContact, &s.CanPickUp)
+		if err != nil {
+			return nil, dbError(err, "Database error")
+		}
+		students = append(students, s)
+	}
+	return students, nil
+}
+
+// LinkGuardianDBHandler links a guardian to a student, or changes how they are linked. Making a
+// guardian the primary contact of a student takes that over from their previous primary contact.
+func LinkGuardianDBHandler(ctx context.Context, studentID, guardianID int, link models.GuardianLink) (models.StudentGuardian, bool, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return models.StudentGuardian{}, false, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.StudentGuardian{}, false, dbError(err, "Database error")
+	}
+
+	// locking the student serializes changes to their primary contact
+	var lockedID int
+	err = tx.QueryRowContext(ctx, "SELECT id FROM students WHERE id = ? AND deleted_at IS NULL FOR UPDATE", studentID).Scan(&lockedID)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return models.StudentGuardian{}, false, utils.NotFoundError(err, "Student not found")
+	} else if err != nil {
+		tx.Rollback()
+		return models.StudentGuardian{}, false, dbError(err, "Database error")
+	}
+	var guardianExists bool
+	err = tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM guardians WHERE id = ?)", guardianID).Scan(&guardianExists)
+	if err != nil {
+		tx.Rollback()
+		return models.StudentGuardian{}, false, dbError(err, "Database error")
+	}
+	if !guardianExists {
+		tx.Rollback()
+		return models.StudentGuardian{}, false, utils.NotFoundError(nil, "Guardian not found")
+	}
+
+	var existing *models.StudentGuardian
+	var before models.StudentGuardian
+	err = tx.QueryRowContext(ctx, "SELECT student_id, guardian_id, primary_contact, can_pick_up FROM student_guardians WHERE student_id = ? AND guardian_id = ? FOR UPDATE",
+		studentID, guardianID).Scan(&before.StudentID, &before.GuardianID, &before.PrimaryContact, &before.CanPickUp)
+	if err == nil {
+		existing = &before
+	} else if err != sql.ErrNoRows {
+		tx.Rollback()
+		return models.StudentGuardian{}, false, dbError(err, "Database error")
+	}
+
+	if link.PrimaryContact {
+		err = clearPrimaryContact(ctx, tx, studentID, guardianID)
+		if err != nil {
+			tx.Rollback()
+			return models.StudentGuardian{}, false, err
+		}
+	}
+
+	linked := models.StudentGuardian{StudentID: studentID, GuardianID: guardianID, GuardianLink: link}
+	action := models.AuditUpdate
+	if existing == nil {
+		action = models.AuditCreate
+		_, err = tx.ExecContext(ctx, "INSERT INTO student_guardians (student_id, guardian_id, primary_contact, can_pick_up) VALUES (?, ?, ?, ?)",
+			studentID, guardianID, link.PrimaryContact, link.CanPickUp)
+	} else {
+		_, err = tx.ExecContext(ctx, "UPDATE student_guardians SET primary_contact = ?, can_pick_up = ? WHERE student_id = ? AND guardian_id = ?",
+			link.PrimaryContact, link.CanPickUp, studentID, guardianID)
+	}
+	if err != nil {
+		tx.Rollback()
+		return models.StudentGuardian{}, false, dbError(err, "Database error")
+	}
+
+	err = recordAudit(ctx, tx, linkEntry(action, existing, &linked))
+	if err != nil {
+		tx.Rollback()
+		return models.StudentGuardian{}, false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.StudentGuardian{}, false, dbError(err, "Error committing transaction")
+	}
+	return linked, existing == nil, nil
+}
+
+// clearPrimaryContact makes the current primary contact of a student, other than guardianID, an
+// ordinary guardian of theirs
+func clearPrimaryContact(ctx context.Context, tx *sql.Tx, studentID, guardianID int) error {
+	var previous models.StudentGuardian
+	err := tx.QueryRowContext(ctx, "SELECT student_id, guardian_id, primary_contact, can_pick_up FROM student_guardians WHERE student_id = ? AND guardian_id != ? AND primary_contact FOR UPDATE",
+		studentID, guardianID).Scan(&previous.StudentID, &previous.GuardianID, &previous.PrimaryContact, &previous.CanPickUp)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return dbError(err, "Database error")
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE student_guardians SET primary_contact = FALSE WHERE student_id = ? AND guardian_id = ?", studentID, previous.GuardianID)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+	updated := previous
+	updated.PrimaryContact = false
+	return recordAudit(ctx, tx, linkEntry(models.AuditUpdate, &previous, &updated))
+}
+
+// linkEntry is the audit entry of a change to the link of a guardian to a student, filed under
+// the guardian and always naming the student
+func linkEntry(action string, before, after *models.StudentGuardian) models.AuditEntry {
+	var from, to interface{}
+	link := after
+	if before != nil {
+		from = *before
+		link = before
+	}
+	if after != nil {
+		to = *after
+	}
+	entry := changeEntry(action, "guardians", link.GuardianID, from, to)
+	if _, ok := entry.Changes["student_id"]; !ok {
+		if entry.Changes == nil {
+			entry.Changes = make(map[string]models.AuditChange)
+		}
+		entry.Changes["student_id"] = models.AuditChange{From: link.StudentID, To: link.StudentID}
+	}
+	return entry
+}
+
+// UnlinkGuardianDBHandler removes a guardian from a student, the guardian is kept
+func UnlinkGuardianDBHandler(ctx context.Context, studentID, guardianID int) error {
+	db, err := ConnectDB()
+	if err != nil {
+		return utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+
+	var existing models.StudentGuardian
+	err = tx.QueryRowContext(ctx, "SELECT student_id, guardian_id, primary_contact, can_pick_up FROM student_guardians WHERE student_id = ? AND guardian_id = ? FOR UPDATE",
+		studentID, guardianID).Scan(&existing.StudentID, &existing.GuardianID, &existing.PrimaryContact, &existing.CanPickUp)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return utils.NotFoundError(err, "Guardian is not a guardian of the student")
+	} else if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM student_guardians WHERE student_id = ? AND guardian_id = ?", studentID, guardianID)
+	if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+
+	err = recordAudit(ctx, tx, linkEntry(models.AuditDelete, &existing, nil))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return dbError(err, "Error committing transaction")
+	}
+	return nil
+}
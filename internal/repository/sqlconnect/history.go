@@ -0,0 +1,39 @@
+package sqlconnect
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+// historyTable describes where the revisions of a resource are kept
+type historyTable struct {
+	table   string
+	key     string
+	columns string
+}
+
+// historyTables are the resources whose every change is copied into a history table, encrypted
+// columns stay encrypted in the copy
+var historyTables = map[string]historyTable{
+	"students": {table: "student_history", key: "student_id", columns: "first_name, last_name, email, class, student_number, date_of_birth, gender, enrollment_date, address, medical_notes, allergies"},
+	"teachers": {table: "teacher_history", key: "teacher_id", columns: "first_name, last_name, email, class, subject"},
+}
+
+// recordHistory copies the current row of resource id into its history table. It must run after the
+// change, in the transaction of the change, so the copy is exactly what was stored.
+func recordHistory(ctx context.Context, exec execer, resource string, id int, operation string) error {
+	h, ok := historyTables[resource]
+	if !ok {
+		return utils.ErrorHandler(fmt.Errorf("no history table for %s", resource), "Error recording history")
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (%s, %s, version, deleted_at, operation, changed_by, request_id)
+		SELECT id, %s, version, deleted_at, ?, ?, ? FROM %s WHERE id = ?`, h.table, h.key, h.columns, h.columns, resource)
+	_, err := exec.ExecContext(ctx, query, operation, actorID(ctx), nullIfEmpty(requestID(ctx)), id)
+	if err != nil {
+		return dbError(err, "Error recording history")
+	}
+	return nil
+}
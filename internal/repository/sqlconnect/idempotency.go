@@ -0,0 +1,108 @@
+package sqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+// ClaimIdempotencyKeyDBHandler reserves key within scope for the request identified by requestHash.
+// It returns nil when the key is new and the request should run, or what is stored for the key
+// when it has been used before.
+func ClaimIdempotencyKeyDBHandler(ctx context.Context, scope, key, requestHash string, ttl time.Duration) (*models.IdempotentResponse, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	// an expired key can be used again
+	_, err = db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE scope = ? AND idempotency_key = ? AND expires_at < NOW()", scope, key)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+
+	_, err = db.ExecContext(ctx, "INSERT INTO idempotency_keys (scope, idempotency_key, request_hash, expires_at) VALUES (?, ?, ?, NOW() + INTERVAL ? SECOND)",
+		scope, key, requestHash, int64(ttl.Seconds()))
+	if err == nil {
+		return nil, nil
+	}
+	if !isDuplicateEntry(err) {
+		return nil, dbError(err, "Database error")
+	}
+
+	var stored models.IdempotentResponse
+	var status sql.NullInt64
+	var header []byte
+	err = db.QueryRowContext(ctx, "SELECT request_hash, status, headers, body FROM idempotency_keys WHERE scope = ? AND idempotency_key = ?", scope, key).Scan(
+		&stored.RequestHash, &status, &header, &stored.Body)
+	if err == sql.ErrNoRows {
+		// the first request failed and released the key in the meantime
+		return ClaimIdempotencyKeyDBHandler(ctx, scope, key, requestHash, ttl)
+	} else if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+
+	if status.Valid {
+		stored.Completed = true
+		stored.Status = int(status.Int64)
+		if len(header) > 0 {
+			err = json.Unmarshal(header, &stored.Header)
+			if err != nil {
+				return nil, utils.ErrorHandler(err, "Error reading stored response")
+			}
+		}
+	}
+	return &stored, nil
+}
+
+// SaveIdempotentResponseDBHandler stores the response to the request that claimed key
+func SaveIdempotentResponseDBHandler(ctx context.Context, scope, key string, response models.IdempotentResponse) error {
+	db, err := ConnectDB()
+	if err != nil {
+		return utils.ErrorHandler(err, "Database connection error")
+	}
+
+	header, err := json.Marshal(response.Header)
+	if err != nil {
+		return utils.ErrorHandler(err, "Error storing response")
+	}
+
+	_, err = db.ExecContext(ctx, "UPDATE idempotency_keys SET status = ?, headers = ?, body = ? WHERE scope = ? AND idempotency_key = ?",
+		response.Status, header, response.Body, scope, key)
+	if err != nil {
+		return dbError(err, "Error storing response")
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKeyDBHandler forgets key, so that a request that failed can be retried
+func ReleaseIdempotencyKeyDBHandler(ctx context.Context, scope, key string) error {
+	db, err := ConnectDB()
+	if err != nil {
+		return utils.ErrorHandler(err, "Database connection error")
+	}
+
+	_, err = db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE scope = ? AND idempotency_key = ?", scope, key)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+	return nil
+}
+
+// PurgeIdempotencyKeysDBHandler removes expired keys and returns how many were removed
+func PurgeIdempotencyKeysDBHandler(ctx context.Context) (int64, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return 0, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	res, err := db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE expires_at < NOW()")
+	if err != nil {
+		return 0, dbError(err, "Error purging idempotency keys")
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,89 @@
+package sqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+// AddImportJobDBHandler records a new running import started by the logged in exec and returns its ID
+func AddImportJobDBHandler(ctx context.Context, job models.ImportJob) (int, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return 0, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	res, err := db.ExecContext(ctx, "INSERT INTO import_jobs (resource, status, dry_run, total_rows, created_by) VALUES (?, ?, ?, ?, ?)",
+		job.Resource, models.ImportRunning, job.DryRun, job.TotalRows, actorID(ctx))
+	if err != nil {
+		return 0, dbError(err, "Database error")
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, dbError(err, "Database error")
+	}
+	return int(id), nil
+}
+
+// FinishImportJobDBHandler stores the outcome of an import. The import has already
+// happened, so the job is updated even if the client has gone away.
+func FinishImportJobDBHandler(ctx context.Context, job models.ImportJob) error {
+	db, err := ConnectDB()
+	if err != nil {
+		return utils.ErrorHandler(err, "Database connection error")
+	}
+
+	var rows []byte
+	if len(job.Rows) > 0 {
+		rows, err = json.Marshal(job.Rows)
+		if err != nil {
+			return utils.ErrorHandler(err, "Error saving import job")
+		}
+	}
+
+	_, err = db.ExecContext(context.WithoutCancel(ctx), `UPDATE import_jobs SET status = ?, created = ?, updated = ?, unchanged = ?, results = ?, error = ?, finished_at = NOW()
+		WHERE id = ?`,
+		job.Status, job.Created, job.Updated, job.Unchanged, rows, nullIfEmpty(job.Error), job.ID)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+	return nil
+}
+
+// GetImportJobDBHandler returns an import job with the outcome of every row once it has finished
+func GetImportJobDBHandler(ctx context.Context, id int) (models.ImportJob, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return models.ImportJob{}, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	var job models.ImportJob
+	var createdBy sql.NullInt64
+	var rows []byte
+	var jobError sql.NullString
+	err = db.QueryRowContext(ctx, `SELECT id, resource, status, dry_run, total_rows, created, updated, unchanged, results, error, created_by,
+		UNIX_TIMESTAMP(created_at), UNIX_TIMESTAMP(finished_at) FROM import_jobs WHERE id = ?`, id).Scan(
+		&job.ID, &job.Resource, &job.Status, &job.DryRun, &job.TotalRows, &job.Created, &job.Updated, &job.Unchanged,
+		&rows, &jobError, &createdBy, &job.CreatedAt, &job.FinishedAt)
+	if err == sql.ErrNoRows {
+		return models.ImportJob{}, utils.NotFoundError(err, "Import job not found")
+	} else if err != nil {
+		return models.ImportJob{}, dbError(err, "Database error")
+	}
+
+	if len(rows) > 0 {
+		err = json.Unmarshal(rows, &job.Rows)
+		if err != nil {
+			return models.ImportJob{}, utils.ErrorHandler(err, "Error reading import job")
+		}
+	}
+	if createdBy.Valid {
+		uid := int(createdBy.Int64)
+		job.CreatedBy = &uid
+	}
+	job.Error = jobError.String
+	return job, nil
+}
@@ -0,0 +1,435 @@
+package sqlconnect
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/metrics"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+
+	"github.com/go-mail/mail/v2"
+)
+
+// GetReportCardDBHandler puts together the report card of a student for a term from their marks in the
+// term's assessments, the teachers assigned to their class for the term, their whole-day attendance in
+// their class during the term and the comments of their teachers
+func GetReportCardDBHandler(ctx context.Context, studentID int, term string) (models.ReportCard, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return models.ReportCard{}, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	card := models.ReportCard{Term: term}
+	err = db.QueryRowContext(ctx, `SELECT s.id, s.first_name, s.last_name, s.email, c.id, c.name, c.academic_year
+		FROM students s JOIN classes c ON c.id = s.class_id WHERE s.id = ? AND s.deleted_at IS NULL`, studentID).Scan(
+		&card.StudentID, &card.FirstName, &card.LastName, &card.Email, &card.ClassID, &card.Class, &card.AcademicYear)
+	if err == sql.ErrNoRows {
+		return models.ReportCard{}, utils.NotFoundError(err, "Student not found")
+	} else if err != nil {
+		return models.ReportCard{}, dbError(err, "Database error")
+	}
+	dates, err := yearTerm(ctx, db, card.AcademicYear, term, "term")
+	if err != nil {
+		return models.ReportCard{}, err
+	}
+
+	marks, err := gradedMarks(ctx, db, "m.student_id = ? AND a.term = ?", []any{studentID, term})
+	if err != nil {
+		return models.ReportCard{}, err
+	}
+	bands, err := gradeBands(ctx, db)
+	if err != nil {
+		return models.ReportCard{}, err
+	}
+	grades := models.GradeMarks(marks[studentID], bands, true)
+	card.Average, card.Grade = grades.Average, grades.Grade
+
+	teachers, err := subjectTeachers(ctx, db, card.ClassID, term)
+	if err != nil {
+		return models.ReportCard{}, err
+	}
+	card.Subjects = []models.ReportCardSubject{}
+	for _, grade := range grades.Subjects {
+		names := teachers[grade.Subject]
+		if names == nil {
+			names = []string{}
+		}
+		card.Subjects = append(card.Subjects, models.ReportCardSubject{
+			Subject:     grade.Subject,
+			Teachers:    names,
+			Average:     grade.Average,
+			Grade:       grade.Grade,
+			Assessments: len(grade.Marks),
+		})
+		delete(teachers, grade.Subject)
+	}
+	// subjects taught without any marks yet are listed without an average
+	for subject, names := range teachers {
+		card.Subjects = append(card.Subjects, models.ReportCardSubject{Subject: subject, Teachers: names})
+	}
+	sort.Slice(card.Subjects, func(i, j int) bool { return card.Subjects[i].Subject < card.Subjects[j].Subject })
+
+	rows, err := db.QueryContext(ctx, `SELECT status, COUNT(*) FROM attendance
+		WHERE student_id = ? AND class_id = ? AND period = 0 AND date BETWEEN ? AND ? GROUP BY status`,
+		studentID, card.ClassID, dates.StartDate, dates.EndDate)
+	if err != nil {
+		return models.ReportCard{}, dbError(err, "Database error")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var status string
+		var count int
+		err := rows.Scan(&status, &count)
+		if err != nil {
+			return models.ReportCard{}, dbError(err, "Database error")
+		}
+		card.Attendance.Add(status, count)
+	}
+
+	card.Comments, err = reportComments(ctx, db, studentID, term)
+	if err != nil {
+		return models.ReportCard{}, err
+	}
+	return card, nil
+}
+
+// subjectTeachers returns the names of the teachers of every subject of a class in a term
+func subjectTeachers(ctx context.Context, db *sql.DB, classID int, term string) (map[string][]string, error) {
+	rows, err := db.QueryContext(ctx, `SELECT a.subject, CONCAT(t.first_name, ' ', t.last_name) FROM teaching_assignments a
+		JOIN teachers t ON t.id = a.teacher_id WHERE a.class_id = ? AND a.term = ? AND t.deleted_at IS NULL ORDER BY t.last_name, t.first_name`,
+		classID, term)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	teachers := make(map[string][]string)
+	for rows.Next() {
+		var subject, name string
+		err := rows.Scan(&subject, &name)
+		if err != nil {
+			return nil, dbError(err, "Database error")
+		}
+		teachers[subject] = append(teachers[subject], name)
+	}
+	return teachers, nil
+}
+
+// GetReportCommentsDBHandler lists the comments of teachers on a student, optionally of one term
+func GetReportCommentsDBHandler(ctx context.Context, studentID int, term string) ([]models.ReportComment, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	var exists bool
+	err = db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM students WHERE id = ? AND deleted_at IS NULL)", studentID).Scan(&exists)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	if !exists {
+		return nil, utils.NotFoundError(nil, "Student not found")
+	}
+	return reportComments(ctx, db, studentID, term)
+}
+
+func reportComments(ctx context.Context, db *sql.DB, studentID int, term string) ([]models.ReportComment, error) {
+	query := `SELECT rc.term, rc.subject, COALESCE(rc.teacher_id, 0), COALESCE(CONCAT(t.first_name, ' ', t.last_name), ''), rc.comment,
+		UNIX_TIMESTAMP(rc.updated_at) FROM report_comments rc LEFT JOIN teachers t ON t.id = rc.teacher_id WHERE rc.student_id = ?`
+	args := []any{studentID}
+	if term != "" {
+		query += " AND rc.term = ?"
+		args = append(args, term)
+	}
+	rows, err := db.QueryContext(ctx, query+" ORDER BY rc.term, rc.subject", args...)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	comments := []models.ReportComment{}
+	for rows.Next() {
+		var comment models.ReportComment
+		err := rows.Scan(&comment.Term, &comment.Subject, &comment.TeacherID, &comment.TeacherName, &comment.Comment, &comment.UpdatedAt)
+		if err != nil {
+			return nil, dbError(err, "Database error")
+		}
+		comments = append(comments, comment)
+	}
+	return comments, nil
+}
+
+// SaveReportCommentsDBHandler records the comments of teachers on a student, replacing the comment they
+// had for the same term and subject. A teacher comments on a subject they teach the student's class in
+// the term, the general comment without a subject is left by the class's homeroom teacher.
+func SaveReportCommentsDBHandler(ctx context.Context, studentID int, comments []models.ReportComment) ([]models.ReportComment, models.UpsertResult, error) {
+	var result models.UpsertResult
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, result, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, result, dbError(err, "Database error")
+	}
+
+	var classID int
+	var academicYear string
+	err = tx.QueryRowContext(ctx, "SELECT s.class_id, c.academic_year FROM students s JOIN classes c ON c.id = s.class_id WHERE s.id = ? AND s.deleted_at IS NULL",
+		studentID).Scan(&classID, &academicYear)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return nil, result, utils.NotFoundError(err, "Student not found")
+	} else if err != nil {
+		tx.Rollback()
+		return nil, result, dbError(err, "Database error")
+	}
+
+	var fieldErrs []utils.FieldError
+	for i, comment := range comments {
+		var known bool
+		err = tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM terms t JOIN academic_years y ON y.id = t.academic_year_id WHERE y.name = ? AND t.name = ?)",
+			academicYear, comment.Term).Scan(&known)
+		if err != nil {
+			tx.Rollback()
+			return nil, result, dbError(err, "Database error")
+		}
+		if !known {
+			fieldErrs = append(fieldErrs, utils.WithIndex([]utils.FieldError{{Field: "term", Message: "must be the name of a term of academic year " + academicYear}}, i)...)
+			continue
+		}
+
+		var allowed bool
+		if comment.Subject == "" {
+			err = tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM classes c JOIN teachers t ON t.id = c.homeroom_teacher_id
+				WHERE c.id = ? AND t.id = ? AND t.deleted_at IS NULL)`, classID, comment.TeacherID).Scan(&allowed)
+		} else {
+			err = tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM teaching_assignments a JOIN teachers t ON t.id = a.teacher_id
+				WHERE a.class_id = ? AND a.teacher_id = ? AND a.subject = ? AND a.term = ? AND t.deleted_at IS NULL)`,
+				classID, comment.TeacherID, comment.Subject, comment.Term).Scan(&allowed)
+		}
+		if err != nil {
+			tx.Rollback()
+			return nil, result, dbError(err, "Database error")
+		}
+		if !allowed {
+			message := "must be a teacher of the subject in the student's class for the term"
+			if comment.Subject == "" {
+				message = "must be the homeroom teacher of the student's class"
+			}
+			fieldErrs = append(fieldErrs, utils.WithIndex([]utils.FieldError{{Field: "teacher_id", Message: message}}, i)...)
+		}
+	}
+	if len(fieldErrs) > 0 {
+		tx.Rollback()
+		return nil, result, utils.ValidationError("Validation failed", fieldErrs...)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO report_comments (student_id, term, subject, teacher_id, comment) VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE teacher_id = VALUES(teacher_id), comment = VALUES(comment)`)
+	if err != nil {
+		tx.Rollback()
+		return nil, result, dbError(err, "Database error")
+	}
+	defer stmt.Close()
+
+	saved := make([]models.ReportComment, len(comments))
+	for i, comment := range comments {
+		comment.TeacherName, comment.UpdatedAt = "", nil
+		res, err := stmt.ExecContext(ctx, studentID, comment.Term, comment.Subject, comment.TeacherID, comment.Comment)
+		if err != nil {
+			tx.Rollback()
+			return nil, result, dbError(err, "Database error")
+		}
+		// MySQL reports 1 row for an insert, 2 for an update and 0 when nothing changed
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
+			tx.Rollback()
+			return nil, result, dbError(err, "Database error")
+		}
+		switch rowsAffected {
+		case 1:
+			result.Created++
+		case 2:
+			result.Updated++
+		default:
+			result.Unchanged++
+		}
+		saved[i] = comment
+	}
+
+	if result.Created+result.Updated > 0 {
+		err = recordAudit(ctx, tx, models.AuditEntry{
+			Action:     models.AuditUpdate,
+			Resource:   "report_comments",
+			ResourceID: &studentID,
+			Changes:    map[string]models.AuditChange{"comments": {To: len(comments)}},
+		})
+		if err != nil {
+			tx.Rollback()
+			return nil, result, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, result, dbError(err, "Error committing transaction")
+	}
+	return saved, result, nil
+}
+
+// StoreReportCardDBHandler keeps a generated report card with its PDF, replacing the one the student
+// already had for the term, and returns its ID
+func StoreReportCardDBHandler(ctx context.Context, jobID int, card models.ReportCard, pdf []byte) (int, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return 0, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	card.ID, card.GeneratedAt = 0, nil
+	document, err := json.Marshal(card)
+	if err != nil {
+		return 0, utils.ErrorHandler(err, "Error saving report card")
+	}
+
+	res, err := db.ExecContext(ctx, `INSERT INTO report_cards (student_id, class_id, term, document, pdf, job_id) VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id), class_id = VALUES(class_id), document = VALUES(document), pdf = VALUES(pdf),
+		job_id = VALUES(job_id), generated_at = CURRENT_TIMESTAMP`,
+		card.StudentID, card.ClassID, card.Term, document, pdf, jobID)
+	if err != nil {
+		return 0, dbError(err, "Database error")
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, dbError(err, "Database error")
+	}
+	return int(id), nil
+}
+
+// GetStoredReportCardDBHandler returns a stored report card and its PDF
+func GetStoredReportCardDBHandler(ctx context.Context, id int) (models.ReportCard, []byte, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return models.ReportCard{}, nil, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	var card models.ReportCard
+	var document, pdf []byte
+	var generatedAt models.Timestamp
+	err = db.QueryRowContext(ctx, "SELECT document, pdf, UNIX_TIMESTAMP(generated_at) FROM report_cards WHERE id = ?", id).Scan(&document, &pdf, &generatedAt)
+	if err == sql.ErrNoRows {
+		return models.ReportCard{}, nil, utils.NotFoundError(err, "Report card not found")
+	} else if err != nil {
+		return models.ReportCard{}, nil, dbError(err, "Database error")
+	}
+
+	err = json.Unmarshal(document, &card)
+	if err != nil {
+		return models.ReportCard{}, nil, utils.ErrorHandler(err, "Error reading report card")
+	}
+	card.ID, card.GeneratedAt = id, &generatedAt
+	return card, pdf, nil
+}
+
+// EmailReportCard sends a report card to the student with its PDF attached
+func EmailReportCard(card models.ReportCard, pdf []byte, filename string) error {
+	m := mail.NewMessage()
+	m.SetHeader("From", "schooladmin@shool.com")
+	m.SetHeader("To", card.Email)
+	m.SetHeader("Subject", fmt.Sprintf("Your report card for %s %s", card.Term, card.AcademicYear))
+	m.SetBody("text/plain", fmt.Sprintf("Dear %s,\n\nPlease find attached your report card for %s of %s.", card.FirstName, card.Term, card.AcademicYear))
+	m.AttachReader(filename, bytes.NewReader(pdf))
+
+	d := mail.NewDialer("localhost", 1025, "", "")
+	err := d.DialAndSend(m)
+	if err != nil {
+		metrics.EmailsSentTotal.WithLabelValues("failed").Inc()
+		return utils.ErrorHandler(err, "Failed to send report card email")
+	}
+	metrics.EmailsSentTotal.WithLabelValues("sent").Inc()
+	return nil
+}
+
+// AddReportCardJobDBHandler records a new running report card job started by the logged in exec and returns its ID
+func AddReportCardJobDBHandler(ctx context.Context, job models.ReportCardJob) (int, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return 0, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	res, err := db.ExecContext(ctx, "INSERT INTO report_card_jobs (class_id, term, delivery, status, total, created_by) VALUES (?, ?, ?, ?, ?, ?)",
+		job.ClassID, job.Term, job.Delivery, models.ImportRunning, job.Total, actorID(ctx))
+	if err != nil {
+		return 0, dbError(err, "Database error")
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, dbError(err, "Database error")
+	}
+	return int(id), nil
+}
+
+// FinishReportCardJobDBHandler stores the outcome of a report card job, see FinishImportJobDBHandler
+func FinishReportCardJobDBHandler(ctx context.Context, job models.ReportCardJob) error {
+	db, err := ConnectDB()
+	if err != nil {
+		return utils.ErrorHandler(err, "Database connection error")
+	}
+
+	var results []byte
+	if len(job.Results) > 0 {
+		results, err = json.Marshal(job.Results)
+		if err != nil {
+			return utils.ErrorHandler(err, "Error saving report card job")
+		}
+	}
+
+	_, err = db.ExecContext(context.WithoutCancel(ctx), `UPDATE report_card_jobs SET status = ?, delivered = ?, failed = ?, results = ?, error = ?, finished_at = NOW()
+		WHERE id = ?`,
+		job.Status, job.Delivered, job.Failed, results, nullIfEmpty(job.Error), job.ID)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+	return nil
+}
+
+// GetReportCardJobDBHandler returns a report card job with the outcome for every student once it has finished
+func GetReportCardJobDBHandler(ctx context.Context, id int) (models.ReportCardJob, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return models.ReportCardJob{}, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	var job models.ReportCardJob
+	var createdBy sql.NullInt64
+	var results []byte
+	var jobError sql.NullString
+	err = db.QueryRowContext(ctx, `SELECT id, class_id, term, delivery, status, total, delivered, failed, results, error, created_by,
+		UNIX_TIMESTAMP(created_at), UNIX_TIMESTAMP(finished_at) FROM report_card_jobs WHERE id = ?`, id).Scan(
+		&job.ID, &job.ClassID, &job.Term, &job.Delivery, &job.Status, &job.Total, &job.Delivered, &job.Failed,
+		&results, &jobError, &createdBy, &job.CreatedAt, &job.FinishedAt)
+	if err == sql.ErrNoRows {
+		return models.ReportCardJob{}, utils.NotFoundError(err, "Report card job not found")
+	} else if err != nil {
+		return models.ReportCardJob{}, dbError(err, "Database error")
+	}
+
+	if len(results) > 0 {
+		err = json.Unmarshal(results, &job.Results)
+		if err != nil {
+			return models.ReportCardJob{}, utils.ErrorHandler(err, "Error reading report card job")
+		}
+	}
+	if createdBy.Valid {
+		uid := int(createdBy.Int64)
+		job.CreatedBy = &uid
+	}
+	job.Error = jobError.String
+	return job, nil
+}
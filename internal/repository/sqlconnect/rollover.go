@@ -0,0 +1,324 @@
+package sqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+// classNamePattern matches the name of a class, a grade from 1 to 12 and a section, like Student.Class
+var classNamePattern = regexp.MustCompile(`^([1-9]|1[0-2])([A-Z])$`)
+
+// rolloverStudent is a student of the year being rolled over with the class they are in
+type rolloverStudent struct {
+	student models.Student
+	class   models.Class
+}
+
+// yearClasses returns the classes of an academic year by name
+func yearClasses(ctx context.Context, tx *sql.Tx, academicYear string) (map[string]models.Class, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT "+classColumns+" FROM classes WHERE academic_year = ? FOR UPDATE", academicYear)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	classes := map[string]models.Class{}
+	for rows.Next() {
+		var class models.Class
+		err := scanClass(rows, &class)
+		if err != nil {
+			return nil, dbError(err, "Database error")
+		}
+		classes[class.Name] = class
+	}
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	return classes, nil
+}
+
+// rolloverStudents returns the students of the classes of an academic year that haven't graduated yet
+func rolloverStudents(ctx context.Context, tx *sql.Tx, academicYear string) ([]rolloverStudent, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT s.id, s.first_name, s.last_name, s.email, s.class, s.class_id, s.version, c.grade, c.section
+		FROM students s JOIN classes c ON c.id = s.class_id
+		WHERE c.academic_year = ? AND s.deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM enrollments e WHERE e.student_id = s.id AND e.outcome = ?)
+		ORDER BY c.grade, c.section, s.last_name, s.first_name FOR UPDATE`, academicYear, models.RolloverGraduated)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	var students []rolloverStudent
+	for rows.Next() {
+		var s rolloverStudent
+		err := rows.Scan(&s.student.ID, &s.student.FirstName, &s.student.LastName, &s.student.Email, &s.student.Class, &s.student.ClassID,
+			&s.student.Version, &s.class.Grade, &s.class.Section)
+		if err != nil {
+			return nil, dbError(err, "Database error")
+		}
+		s.class.ID, s.class.Name = s.student.ClassID, s.student.Class
+		students = append(students, s)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	return students, nil
+}
+
+// RolloverDBHandler moves the students of the academic year with ID fromID on to the classes of
+// rollover.ToYear: promoted, held back or graduated. It ends their enrollments of the year with that
+// outcome and starts ones in their new classes, graduates keep their last class. Missing classes of
+// the next year are added when rollover.CreateClasses is set. All of it happens in one transaction,
+// which a dry run rolls back once it knows the outcome.
+func RolloverDBHandler(ctx context.Context, fromID int, rollover models.Rollover, dryRun bool) (models.RolloverResult, error) {
+	result := models.RolloverResult{ToYear: rollover.ToYear, DryRun: dryRun, CreatedClasses: []string{}, Students: []models.RolloverMove{}}
+	db, err := ConnectDB()
+	if err != nil {
+		return result, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, dbError(err, "Database error")
+	}
+
+	var fromEnd, toStart string
+	err = tx.QueryRowContext(ctx, "SELECT name, end_date FROM academic_years WHERE id = ? FOR UPDATE", fromID).Scan(&result.FromYear, &fromEnd)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return result, utils.NotFoundError(err, "Academic year not found")
+	} else if err != nil {
+		tx.Rollback()
+		return result, dbError(err, "Database error")
+	}
+	err = tx.QueryRowContext(ctx, "SELECT start_date FROM academic_years WHERE name = ?", rollover.ToYear).Scan(&toStart)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return result, utils.ValidationError("Unknown academic year", utils.FieldError{Field: "to_year", Message: "academic year " + rollover.ToYear + " does not exist, create it under /academicyears first"})
+	} else if err != nil {
+		tx.Rollback()
+		return result, dbError(err, "Database error")
+	}
+	if toStart <= fromEnd {
+		tx.Rollback()
+		return result, utils.ValidationError("Invalid academic year", utils.FieldError{Field: "to_year", Message: "must start after " + result.FromYear + " ends"})
+	}
+
+	fromClasses, err := yearClasses(ctx, tx, result.FromYear)
+	if err != nil {
+		tx.Rollback()
+		return result, err
+	}
+	toClasses, err := yearClasses(ctx, tx, rollover.ToYear)
+	if err != nil {
+		tx.Rollback()
+		return result, err
+	}
+
+	// a mapping for a class or grade the year doesn't have is most likely a typo
+	grades := map[string]bool{}
+	for _, class := range fromClasses {
+		grades[strconv.Itoa(class.Grade)] = true
+	}
+	var fieldErrs []utils.FieldError
+	for key := range rollover.Mapping {
+		if _, ok := fromClasses[key]; !ok && !grades[key] {
+			fieldErrs = append(fieldErrs, utils.FieldError{Field: "mapping", Message: fmt.Sprintf("%s is not a class or grade of %s", key, result.FromYear)})
+		}
+	}
+
+	students, err := rolloverStudents(ctx, tx, result.FromYear)
+	if err != nil {
+		tx.Rollback()
+		return result, err
+	}
+	inYear := map[int]bool{}
+	for _, s := range students {
+		inYear[s.student.ID] = true
+	}
+	heldBack := map[int]string{}
+	for i, h := range rollover.HeldBack {
+		heldBack[h.StudentID] = h.Class
+		if !inYear[h.StudentID] {
+			fieldErrs = append(fieldErrs, utils.WithIndex([]utils.FieldError{{Field: "student_id", Message: "must be a student of a class of " + result.FromYear}}, i)...)
+		}
+	}
+	if len(fieldErrs) > 0 {
+		tx.Rollback()
+		sort.Slice(fieldErrs, func(i, j int) bool { return fieldErrs[i].Message < fieldErrs[j].Message })
+		return result, utils.ValidationError("Validation failed", fieldErrs...)
+	}
+
+	// work out where every student goes, adding or reporting the classes of the next year that are missing
+	var missing []string
+	for _, s := range students {
+		move := models.RolloverMove{StudentID: s.student.ID, FirstName: s.student.FirstName, LastName: s.student.LastName, FromClass: s.class.Name}
+		if class, ok := heldBack[s.student.ID]; ok {
+			move.Outcome, move.ToClass = models.RolloverHeldBack, class
+			if class == "" {
+				move.ToClass = s.class.Name
+			}
+		} else if target := rollover.Target(s.class); target == models.RolloverGraduated {
+			move.Outcome = models.RolloverGraduated
+		} else {
+			move.Outcome, move.ToClass = models.RolloverPromoted, target
+		}
+		result.Students = append(result.Students, move)
+
+		if move.ToClass == "" {
+			continue
+		}
+		if _, ok := toClasses[move.ToClass]; ok {
+			continue
+		}
+		match := classNamePattern.FindStringSubmatch(move.ToClass)
+		if match != nil {
+			if grade, _ := strconv.Atoi(match[1]); grade > models.FinalGrade {
+				match = nil
+			}
+		}
+		if match == nil {
+			tx.Rollback()
+			return result, utils.ValidationError("Invalid mapping", utils.FieldError{Field: "mapping",
+				Message: fmt.Sprintf("%s maps to %s, which is not a class name like 11A of grade 1 to %d", s.class.Name, move.ToClass, models.FinalGrade)})
+		}
+		if !rollover.CreateClasses {
+			if !slices.Contains(missing, move.ToClass) {
+				missing = append(missing, move.ToClass)
+			}
+			continue
+		}
+
+		grade, _ := strconv.Atoi(match[1])
+		class := models.Class{Grade: grade, Section: match[2], Name: move.ToClass, Capacity: fromClasses[s.class.Name].Capacity, AcademicYear: rollover.ToYear, Version: 1}
+		res, err := tx.ExecContext(ctx, utils.GenerateInsertQuery("classes", models.Class{}), utils.GetStructValues(class)...)
+		if err != nil {
+			tx.Rollback()
+			return result, dbError(err, "Database error")
+		}
+		lastID, err := res.LastInsertId()
+		if err != nil {
+			tx.Rollback()
+			return result, dbError(err, "Database error")
+		}
+		class.ID = int(lastID)
+		toClasses[class.Name] = class
+		result.CreatedClasses = append(result.CreatedClasses, class.Name)
+
+		err = recordAudit(ctx, tx, changeEntry(models.AuditCreate, "classes", class.ID, nil, class))
+		if err != nil {
+			tx.Rollback()
+			return result, err
+		}
+	}
+	if len(missing) > 0 {
+		tx.Rollback()
+		sort.Strings(missing)
+		return result, utils.ValidationError("Missing classes", utils.FieldError{Field: "mapping",
+			Message: fmt.Sprintf("classes %s do not exist in %s, add them under /classes or set create_classes", strings.Join(missing, ", "), rollover.ToYear)})
+	}
+
+	// the classes of the next year must have room for everyone moving in
+	joining := map[string]int{}
+	for _, move := range result.Students {
+		if move.ToClass != "" {
+			joining[move.ToClass]++
+		}
+	}
+	for name, count := range joining {
+		class := toClasses[name]
+		if class.Capacity == 0 {
+			continue
+		}
+		var enrolled int
+		err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM students WHERE class_id = ? AND deleted_at IS NULL", class.ID).Scan(&enrolled)
+		if err != nil {
+			tx.Rollback()
+			return result, dbError(err, "Database error")
+		}
+		if enrolled+count > class.Capacity {
+			tx.Rollback()
+			return result, utils.ConflictError(nil, fmt.Sprintf("Class %s of %s would have %d students, it has room for %d", name, rollover.ToYear, enrolled+count, class.Capacity))
+		}
+	}
+
+	for i, move := range result.Students {
+		before := students[i].student
+		_, err = tx.ExecContext(ctx, "UPDATE enrollments SET end_date = ?, outcome = ? WHERE student_id = ? AND end_date IS NULL",
+			fromEnd, move.Outcome, move.StudentID)
+		if err != nil {
+			tx.Rollback()
+			return result, dbError(err, "Database error")
+		}
+
+		switch move.Outcome {
+		case models.RolloverGraduated:
+			result.Graduated++
+			continue
+		case models.RolloverHeldBack:
+			result.HeldBack++
+		default:
+			result.Promoted++
+		}
+
+		after := before
+		after.Class, after.ClassID, after.Version = move.ToClass, toClasses[move.ToClass].ID, before.Version+1
+		_, err = tx.ExecContext(ctx, "UPDATE students SET class = ?, class_id = ?, version = version + 1 WHERE id = ?", after.Class, after.ClassID, after.ID)
+		if err != nil {
+			tx.Rollback()
+			return result, dbError(err, "Database error")
+		}
+		_, err = tx.ExecContext(ctx, "INSERT INTO enrollments (student_id, class_id, start_date) VALUES (?, ?, ?)", after.ID, after.ClassID, toStart)
+		if err != nil {
+			tx.Rollback()
+			return result, dbError(err, "Database error")
+		}
+		err = recordAudit(ctx, tx, changeEntry(models.AuditUpdate, "students", after.ID, before, after))
+		if err == nil {
+			err = recordHistory(ctx, tx, "students", after.ID, models.AuditUpdate)
+		}
+		if err != nil {
+			tx.Rollback()
+			return result, err
+		}
+	}
+
+	// archive whatever is still open in the year, e.g. enrollments of deleted students
+	_, err = tx.ExecContext(ctx, `UPDATE enrollments e JOIN classes c ON c.id = e.class_id SET e.end_date = ?
+		WHERE c.academic_year = ? AND e.end_date IS NULL`, fromEnd, result.FromYear)
+	if err != nil {
+		tx.Rollback()
+		return result, dbError(err, "Database error")
+	}
+
+	err = recordAudit(ctx, tx, models.AuditEntry{
+		Action:     models.AuditUpdate,
+		Resource:   "academic_years",
+		ResourceID: &fromID,
+		Changes:    map[string]models.AuditChange{"rollover": {From: result.FromYear, To: rollover.ToYear}},
+	})
+	if err != nil {
+		tx.Rollback()
+		return result, err
+	}
+
+	if dryRun {
+		tx.Rollback()
+		return result, nil
+	}
+	err = tx.Commit()
+	if err != nil {
+		return result, dbError(err, "Error committing transaction")
+	}
+	return result, nil
+}
@@ -0,0 +1,65 @@
+package sqlconnect
+
+import (
+	"context"
+	"time"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+// softDeleteTables are the tables whose rows are only marked deleted with deleted_at
+var softDeleteTables = []string{"students", "teachers", "execs"}
+
+// deletedFilter excludes soft-deleted rows from a query unless they were asked for
+func deletedFilter(includeDeleted bool) string {
+	if includeDeleted {
+		return ""
+	}
+	return " AND deleted_at IS NULL"
+}
+
+// PurgeDeletedDBHandler permanently removes rows that were soft-deleted more than retention ago
+// and returns how many were removed. The number purged from each table is written to the audit
+// log, as this is the one place records are gone for good.
+func PurgeDeletedDBHandler(ctx context.Context, retention time.Duration) (int64, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return 0, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	var purged int64
+	for _, table := range softDeleteTables {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return purged, dbError(err, "Database error")
+		}
+		res, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE deleted_at IS NOT NULL AND deleted_at < NOW() - INTERVAL ? SECOND", int64(retention.Seconds()))
+		if err != nil {
+			tx.Rollback()
+			return purged, dbError(err, "Error purging deleted "+table)
+		}
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
+			tx.Rollback()
+			return purged, dbError(err, "Database error")
+		}
+		if rowsAffected > 0 {
+			err = recordAudit(ctx, tx, models.AuditEntry{
+				Action:   models.AuditPurge,
+				Resource: table,
+				Changes:  map[string]models.AuditChange{"purged": {To: rowsAffected}},
+			})
+			if err != nil {
+				tx.Rollback()
+				return purged, err
+			}
+		}
+		err = tx.Commit()
+		if err != nil {
+			return purged, dbError(err, "Error committing transaction")
+		}
+		purged += rowsAffected
+	}
+	return purged, nil
+}
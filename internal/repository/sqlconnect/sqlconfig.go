@@ -4,24 +4,45 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"sync"
 
+	"github.com/XSAM/otelsql"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/metrics"
 	_ "github.com/go-sql-driver/mysql"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 )
 
-func ConnectDB() (*sql.DB, error) {
-
-	user := os.Getenv("DB_USER")
-	password := os.Getenv("DB_PASSWORD")
-	dbName := os.Getenv("DB_NAME")
-	host := os.Getenv("HOST")
-	dbport := os.Getenv("DB_PORT")
+var (
+	db     *sql.DB
+	dbErr  error
+	dbOnce sync.Once
+)
 
-	connectionString := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s", user, password, host, dbport, dbName)
-	db, err := sql.Open("mysql", connectionString)
+// ConnectDB returns the shared connection pool, opening it on first use.
+// Callers must not close the returned *sql.DB.
+// Statements run through the pool are traced, pass the request context to
+// the *Context methods so their spans join the request trace.
+func ConnectDB() (*sql.DB, error) {
+	dbOnce.Do(func() {
+		user := os.Getenv("DB_USER")
+		password := os.Getenv("DB_PASSWORD")
+		dbName := os.Getenv("DB_NAME")
+		host := os.Getenv("HOST")
+		dbport := os.Getenv("DB_PORT")
 
-	if err != nil {
-		return nil, err
-	}
-	fmt.Println("Connected to MariaDB successfully")
-	return db, nil
+		connectionString := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s", user, password, host, dbport, dbName)
+		db, dbErr = otelsql.Open("mysql", connectionString,
+			otelsql.WithAttributes(semconv.DBSystemNameMySQL),
+			otelsql.WithSpanOptions(otelsql.SpanOptions{
+				OmitConnResetSession: true,
+				OmitRows:             true,
+			}),
+		)
+		if dbErr != nil {
+			return
+		}
+		metrics.RegisterDBStats(db, dbName)
+		fmt.Println("Connected to MariaDB successfully")
+	})
+	return db, dbErr
 }
@@ -1,24 +1,227 @@
 package sqlconnect
 
 import (
+	"context"
 	"database/sql"
 	"net/http"
 	"reflect"
+	"slices"
 	"strconv"
+	"time"
 
 	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
 	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
 )
 
-func GetStudentsDBHandler(students []models.Student, r *http.Request, limit, page int) ([]models.Student, int, error) {
+// studentColumns are the stored fields of a student, in the order scanStudent reads them
+const studentColumns = "id, first_name, last_name, email, class, class_id, student_number, date_of_birth, gender, enrollment_date, address, medical_notes, allergies, version, UNIX_TIMESTAMP(updated_at), UNIX_TIMESTAMP(deleted_at)"
+
+// studentAssignments writes the fields of a student a client can change, from studentAssignmentValues
+const studentAssignments = "first_name = ?, last_name = ?, email = ?, class = ?, class_id = ?, student_number = ?, date_of_birth = ?, gender = ?, enrollment_date = ?, address = ?, medical_notes = ?, allergies = ?"
+
+func studentAssignmentValues(student models.Student) []any {
+	return []any{student.FirstName, student.LastName, student.Email, student.Class, student.ClassID, student.StudentNumber, student.DateOfBirth,
+		student.Gender, student.EnrollmentDate, student.Address, student.MedicalNotes, student.Allergies}
+}
+
+// sensitiveStudentFields are the encrypted fields of a student
+var sensitiveStudentFields = []string{"address", "medical_notes"}
+
+// scanStudent reads a row of studentColumns, decrypting the sensitive fields for execs who may see them
+func scanStudent(ctx context.Context, row interface{ Scan(...any) error }, student *models.Student) error {
+	err := row.Scan(&student.ID, &student.FirstName, &student.LastName, &student.Email, &student.Class, &student.ClassID, &student.StudentNumber, &student.DateOfBirth,
+		&student.Gender, &student.EnrollmentDate, &student.Address, &student.MedicalNotes, &student.Allergies, &student.Version, &student.UpdatedAt, &student.DeletedAt)
+	if err != nil {
+		return err
+	}
+	return openStudent(ctx, student)
+}
+
+// openStudent decrypts the sensitive fields of a student read from the database. For execs who may
+// not see them they stay sealed, keepSensitive carries them over as stored on writes and
+// hideSensitive blanks them before the student is returned.
+func openStudent(ctx context.Context, student *models.Student) error {
+	if !seesSensitive(ctx) {
+		return nil
+	}
+	var err error
+	student.Address, err = utils.DecryptField(student.Address, sensitiveFieldAAD("address", student.ID))
+	if err != nil {
+		return err
+	}
+	student.MedicalNotes, err = utils.DecryptField(student.MedicalNotes, sensitiveFieldAAD("medical_notes", student.ID))
+	return err
+}
+
+// sealStudent returns student as it is stored, with its sensitive fields encrypted. Those of execs
+// who may not see them are already sealed, see openStudent.
+func sealStudent(ctx context.Context, student models.Student) (models.Student, error) {
+	if !seesSensitive(ctx) {
+		return student, nil
+	}
+	var err error
+	student.Address, err = utils.EncryptField(student.Address, sensitiveFieldAAD("address", student.ID))
+	if err != nil {
+		return models.Student{}, err
+	}
+	student.MedicalNotes, err = utils.EncryptField(student.MedicalNotes, sensitiveFieldAAD("medical_notes", student.ID))
+	if err != nil {
+		return models.Student{}, err
+	}
+	return student, nil
+}
+
+// sensitiveFieldAAD binds a sealed value to the column and student it belongs to, so it can't be
+// copied to another student or field and still be read
+func sensitiveFieldAAD(column string, id int) string {
+	return "students." + column + "." + strconv.Itoa(id)
+}
+
+// insertSensitive writes the sensitive fields of a student just added. They are sealed with the
+// ID of the student, which is only known once it is inserted without them.
+func insertSensitive(ctx context.Context, q execer, student models.Student) error {
+	if student.Address == "" && student.MedicalNotes == "" {
+		return nil
+	}
+	stored, err := sealStudent(ctx, student)
+	if err != nil {
+		return err
+	}
+	_, err = q.ExecContext(ctx, "UPDATE students SET address = ?, medical_notes = ? WHERE id = ?", stored.Address, stored.MedicalNotes, student.ID)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+	return nil
+}
+
+// seesSensitive reports whether the exec making the request may see and change the sensitive fields of students
+func seesSensitive(ctx context.Context) bool {
+	role, _ := ctx.Value(utils.ContextKey("role")).(string)
+	return slices.Contains(models.SensitiveStudentRoles, role)
+}
+
+// hideSensitive leaves the sensitive fields out of a student going to an exec who may not see them
+func hideSensitive(ctx context.Context, student *models.Student) {
+	if !seesSensitive(ctx) {
+		student.Address, student.MedicalNotes = "", ""
+	}
+}
+
+func sensitiveForbidden() error {
+	return utils.ForbiddenError("Only admins and managers may change the address and medical notes of students")
+}
+
+// keepSensitive lets an exec who may not see the sensitive fields replace a student without them, the
+// stored ones are kept. Setting them is forbidden to such execs.
+func keepSensitive(ctx context.Context, student *models.Student, existing models.Student) error {
+	if seesSensitive(ctx) {
+		return nil
+	}
+	if student.Address != "" || student.MedicalNotes != "" {
+		return sensitiveForbidden()
+	}
+	student.Address, student.MedicalNotes = existing.Address, existing.MedicalNotes
+	return nil
+}
+
+// checkSensitivePatch forbids partial updates of the sensitive fields to execs who may not see them
+func checkSensitivePatch(ctx context.Context, updates map[string]interface{}) error {
+	if seesSensitive(ctx) {
+		return nil
+	}
+	for _, field := range sensitiveStudentFields {
+		if _, ok := updates[field]; ok {
+			return sensitiveForbidden()
+		}
+	}
+	return nil
+}
+
+// normalizeStudent stores empty optional fields as NULL
+func normalizeStudent(student *models.Student) {
+	for _, field := range []**string{&student.StudentNumber, &student.DateOfBirth, &student.EnrollmentDate} {
+		if *field != nil && **field == "" {
+			*field = nil
+		}
+	}
+}
+
+// prepareStudent prepares a student about to be added, who is enrolled today unless told otherwise
+func prepareStudent(ctx context.Context, student *models.Student) error {
+	normalizeStudent(student)
+	if student.EnrollmentDate == nil {
+		today := time.Now().Format(time.DateOnly)
+		student.EnrollmentDate = &today
+	}
+	return keepSensitive(ctx, student, models.Student{})
+}
+
+// patchStudent sets the fields of student named in updates, except id and version. null, or an
+// empty string, clears an optional field.
+func patchStudent(student *models.Student, updates map[string]interface{}) error {
+	studentVal := reflect.ValueOf(student).Elem()
+	studentType := studentVal.Type()
+
+	for k, v := range updates {
+		if k == "id" || k == "version" {
+			continue
+		}
+		for i := 0; i < studentType.NumField(); i++ {
+			field := studentType.Field(i)
+			jsonTag := field.Tag.Get("json")
+			if jsonTag == k+",omitempty" {
+				fieldVal := studentVal.Field(i)
+				if fieldVal.IsValid() && fieldVal.CanSet() && !setField(fieldVal, v) {
+					return utils.ValidationError("Type mismatch for field "+k, utils.FieldError{Field: k, Message: "has the wrong type"})
+				}
+				break
+			}
+		}
+	}
+	normalizeStudent(student)
+	return nil
+}
+
+// setField sets a field to a JSON value, pointing optional fields at it. It reports false
+// if the value can't be converted to the type of the field.
+func setField(fieldVal reflect.Value, v interface{}) bool {
+	if v == nil {
+		fieldVal.SetZero()
+		return true
+	}
+	target := fieldVal.Type()
+	if target.Kind() == reflect.Ptr {
+		target = target.Elem()
+	}
+	val := reflect.ValueOf(v)
+	if !val.Type().ConvertibleTo(target) {
+		return false
+	}
+	converted := val.Convert(target)
+	if fieldVal.Kind() == reflect.Ptr {
+		ptr := reflect.New(target)
+		ptr.Elem().Set(converted)
+		fieldVal.Set(ptr)
+		return true
+	}
+	fieldVal.Set(converted)
+	return true
+}
+
+func GetStudentsDBHandler(students []models.Student, r *http.Request, limit, page int, includeDeleted bool) ([]models.Student, int, error) {
+	ctx := r.Context()
 	db, err := ConnectDB()
 	if err != nil {
 		return nil, 0, utils.ErrorHandler(err, "Database connection error")
 	}
-	defer db.Close()
 
-	query := "SELECT id, first_name, last_name, email, class FROM students WHERE 1=1"
-	var args []any
+	term, err := termParam(ctx, db, r.URL.Query().Get("term"))
+	if err != nil {
+		return nil, 0, err
+	}
+	termFilter, args := studentTermFilter(term)
+
+	query := "SELECT " + studentColumns + " FROM students WHERE 1=1" + deletedFilter(includeDeleted) + termFilter
 
 	query, args = utils.AddFilters(r, query, args, models.Student{})
 
@@ -30,296 +233,852 @@ func GetStudentsDBHandler(students []models.Student, r *http.Request, limit, pag
 	// Add Sorting
 	query = utils.AddSorting(r, query, models.Student{})
 
-	rows, err := db.Query(query, args...)
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, 0, utils.ErrorHandler(err, "Database error")
+		return nil, 0, dbError(err, "Database error")
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var student models.Student
-		err := rows.Scan(&student.ID, &student.FirstName, &student.LastName, &student.Email, &student.Class)
+		err := scanStudent(ctx, rows, &student)
 		if err != nil {
-			return nil, 0, utils.ErrorHandler(err, "Database error")
+			return nil, 0, dbError(err, "Database error")
 		}
+		hideSensitive(ctx, &student)
 		students = append(students, student)
 	}
 
 	// get the count of total students
 	var totalStudents int
-	err = db.QueryRow("SELECT COUNT(*) FROM students WHERE 1=1").Scan(&totalStudents)
+	countFilter, countArgs := studentTermFilter(term)
+	err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM students WHERE 1=1"+deletedFilter(includeDeleted)+countFilter, countArgs...).Scan(&totalStudents)
 	if err != nil {
-		return nil, 0, utils.ErrorHandler(err, "Database error")
+		return nil, 0, dbError(err, "Database error")
 	}
 	return students, totalStudents, nil
 }
 
-func GetOneStudentDBHandler(id int) (models.Student, error) {
+// StreamStudentsDBHandler calls each for every student matching the filters and sorting of r,
+// reading them one at a time rather than loading the whole listing
+func StreamStudentsDBHandler(r *http.Request, includeDeleted bool, each func(models.Student) error) error {
+	db, err := ConnectDB()
+	if err != nil {
+		return utils.ErrorHandler(err, "Database connection error")
+	}
+
+	term, err := termParam(r.Context(), db, r.URL.Query().Get("term"))
+	if err != nil {
+		return err
+	}
+	termFilter, args := studentTermFilter(term)
+
+	query := "SELECT " + studentColumns + " FROM students WHERE 1=1" + deletedFilter(includeDeleted) + termFilter
+	query, args = utils.AddFilters(r, query, args, models.Student{})
+	query = utils.AddSorting(r, query, models.Student{})
+
+	rows, err := db.QueryContext(r.Context(), query, args...)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var student models.Student
+		err := scanStudent(r.Context(), rows, &student)
+		if err != nil {
+			return dbError(err, "Database error")
+		}
+		hideSensitive(r.Context(), &student)
+		err = each(student)
+		if err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return dbError(err, "Database error")
+	}
+	return nil
+}
+
+func GetOneStudentDBHandler(ctx context.Context, id int, includeDeleted bool) (models.Student, error) {
 	db, err := ConnectDB()
 	if err != nil {
 		return models.Student{}, utils.ErrorHandler(err, "Database connection error")
 	}
-	defer db.Close()
 
 	var student models.Student
 
-	err = db.QueryRow("SELECT id, first_name, last_name, email, class FROM students WHERE id = ?", id).Scan(
-		&student.ID, &student.FirstName, &student.LastName, &student.Email, &student.Class)
+	err = scanStudent(ctx, db.QueryRowContext(ctx, "SELECT "+studentColumns+" FROM students WHERE id = ?"+deletedFilter(includeDeleted), id), &student)
 	if err == sql.ErrNoRows {
-		return models.Student{}, utils.ErrorHandler(err, "Student not found")
+		return models.Student{}, utils.NotFoundError(err, "Student not found")
 	} else if err != nil {
-		return models.Student{}, utils.ErrorHandler(err, "Database error")
+		return models.Student{}, dbError(err, "Database error")
 	}
+	hideSensitive(ctx, &student)
 	return student, nil
 }
 
-func AddStudentsDBHandler(newStudents []models.Student) ([]models.Student, error) {
+func AddStudentsDBHandler(ctx context.Context, newStudents []models.Student) ([]models.Student, error) {
 	db, err := ConnectDB()
 	if err != nil {
 		return nil, utils.ErrorHandler(err, "Database connection error")
 	}
-	defer db.Close()
 
-	stmt, err := db.Prepare(utils.GenerateInsertQuery("students", models.Student{}))
+	// the batch is all or nothing, a retried request never finds half of it already inserted
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, utils.ErrorHandler(err, "Database error")
+		return nil, dbError(err, "Database error")
+	}
+
+	stmt, err := tx.PrepareContext(ctx, utils.GenerateInsertQuery("students", models.Student{}))
+	if err != nil {
+		tx.Rollback()
+		return nil, dbError(err, "Database error")
 	}
 	defer stmt.Close()
 
 	addedStudents := make([]models.Student, len(newStudents))
 
 	for i, newStudent := range newStudents {
-		values := utils.GetStructValues(newStudent)
-		res, err := stmt.Exec(values...)
+		err = prepareStudent(ctx, &newStudent)
+		if err == nil {
+			err = assignClass(ctx, tx, &newStudent)
+		}
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		newStudent.Version = 1
+		stored := newStudent
+		stored.Address, stored.MedicalNotes = "", ""
+		values := utils.GetStructValues(stored)
+		res, err := stmt.ExecContext(ctx, values...)
 		if err != nil {
-			return nil, utils.ErrorHandler(err, "Database error")
+			tx.Rollback()
+			return nil, dbError(err, "Database error")
 		}
 		lastID, err := res.LastInsertId()
 		if err != nil {
-			return nil, utils.ErrorHandler(err, "Database error")
+			tx.Rollback()
+			return nil, dbError(err, "Database error")
 		}
 		newStudent.ID = int(lastID)
+
+		err = insertSensitive(ctx, tx, newStudent)
+		if err == nil {
+			err = recordAudit(ctx, tx, changeEntry(models.AuditCreate, "students", newStudent.ID, nil, newStudent))
+		}
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		err = recordHistory(ctx, tx, "students", newStudent.ID, models.AuditCreate)
+		if err == nil {
+			err = recordEnrollment(ctx, tx, newStudent.ID, newStudent.ClassID)
+		}
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		hideSensitive(ctx, &newStudent)
 		addedStudents[i] = newStudent
 	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, dbError(err, "Error committing transaction")
+	}
 	return addedStudents, nil
 }
 
-func UpdateStudentDBHandler(id int, updatedStudent models.Student) (models.Student, error) {
+func UpdateStudentDBHandler(ctx context.Context, id, version int, updatedStudent models.Student) (models.Student, error) {
 	db, err := ConnectDB()
 	if err != nil {
 		return models.Student{}, utils.ErrorHandler(err, "Database connection error")
 	}
-	defer db.Close()
 
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Student{}, dbError(err, "Database error")
+	}
+
+	// lock the row so the version can't change between the check and the update
 	var existingStudent models.Student
-	err = db.QueryRow("SELECT id, first_name, last_name, email, class FROM students WHERE id = ?", id).Scan(
-		&existingStudent.ID, &existingStudent.FirstName, &existingStudent.LastName, &existingStudent.Email, &existingStudent.Class)
+	err = scanStudent(ctx, tx.QueryRowContext(ctx, "SELECT "+studentColumns+" FROM students WHERE id = ? AND deleted_at IS NULL FOR UPDATE", id), &existingStudent)
 	if err == sql.ErrNoRows {
-		return models.Student{}, utils.ErrorHandler(err, "Student not found")
+		tx.Rollback()
+		return models.Student{}, utils.NotFoundError(err, "Student not found")
 	} else if err != nil {
-		return models.Student{}, utils.ErrorHandler(err, "Database error")
+		tx.Rollback()
+		return models.Student{}, dbError(err, "Database error")
+	}
+
+	err = checkVersion("Student", id, version, existingStudent.Version)
+	if err != nil {
+		tx.Rollback()
+		return models.Student{}, err
+	}
+
+	err = keepSensitive(ctx, &updatedStudent, existingStudent)
+	if err != nil {
+		tx.Rollback()
+		return models.Student{}, err
+	}
+	normalizeStudent(&updatedStudent)
+	if updatedStudent.EnrollmentDate == nil {
+		updatedStudent.EnrollmentDate = existingStudent.EnrollmentDate
 	}
 
 	updatedStudent.ID = existingStudent.ID
+	updatedStudent.Version = existingStudent.Version + 1
+	updatedStudent.ClassID = existingStudent.ClassID
+	if updatedStudent.Class != existingStudent.Class {
+		err = assignClass(ctx, tx, &updatedStudent)
+		if err != nil {
+			tx.Rollback()
+			return models.Student{}, err
+		}
+	}
 
-	_, err = db.Exec("UPDATE students SET first_name = ?, last_name = ?, email = ?, class = ? WHERE id = ?",
-		updatedStudent.FirstName, updatedStudent.LastName, updatedStudent.Email, updatedStudent.Class, updatedStudent.ID)
+	stored, err := sealStudent(ctx, updatedStudent)
 	if err != nil {
-		return models.Student{}, utils.ErrorHandler(err, "Database error")
+		tx.Rollback()
+		return models.Student{}, err
 	}
+	res, err := tx.ExecContext(ctx, "UPDATE students SET "+studentAssignments+", version = version + 1 WHERE id = ? AND version = ? AND deleted_at IS NULL",
+		append(studentAssignmentValues(stored), updatedStudent.ID, existingStudent.Version)...)
+	if err != nil {
+		tx.Rollback()
+		return models.Student{}, dbError(err, "Database error")
+	}
+	err = checkVersionedWrite(res, "Student", id)
+	if err != nil {
+		tx.Rollback()
+		return models.Student{}, err
+	}
+
+	err = recordAudit(ctx, tx, changeEntry(models.AuditUpdate, "students", id, existingStudent, updatedStudent))
+	if err != nil {
+		tx.Rollback()
+		return models.Student{}, err
+	}
+
+	err = recordHistory(ctx, tx, "students", id, models.AuditUpdate)
+	if err == nil && updatedStudent.ClassID != existingStudent.ClassID {
+		err = recordEnrollment(ctx, tx, id, updatedStudent.ClassID)
+	}
+	if err != nil {
+		tx.Rollback()
+		return models.Student{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.Student{}, dbError(err, "Error committing transaction")
+	}
+	hideSensitive(ctx, &updatedStudent)
 	return updatedStudent, nil
 }
 
-func PatchStudentsDBHandler(updates []map[string]interface{}) error {
+func PatchStudentsDBHandler(ctx context.Context, updates []map[string]interface{}) error {
 	db, err := ConnectDB()
 	if err != nil {
 		return utils.ErrorHandler(err, "Database connection error")
 	}
-	defer db.Close()
 
-	tx, err := db.Begin()
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
-		return utils.ErrorHandler(err, "Database error")
+		return dbError(err, "Database error")
 	}
 
 	for _, update := range updates {
 		idStr, ok := update["id"].(string)
 		if !ok {
 			tx.Rollback()
-			return utils.ErrorHandler(err, "Invalid or missing ID in update object")
+			return utils.ValidationError("Invalid or missing ID in update object", utils.FieldError{Field: "id", Message: "must be a numeric string"})
 		}
 
 		id, err := strconv.Atoi(idStr)
 		if err != nil {
 			tx.Rollback()
-			return utils.ErrorHandler(err, "Error converting string to int")
+			return utils.ValidationError("Invalid ID in update object", utils.FieldError{Field: "id", Message: "must be a numeric string"})
 		}
 
+		version, err := versionFromUpdate(update)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		// lock the row so the version can't change between the check and the update
 		var studentFromDb models.Student
-		err = db.QueryRow("SELECT id, first_name, last_name, email, class FROM students WHERE id = ?", id).Scan(
-			&studentFromDb.ID, &studentFromDb.FirstName, &studentFromDb.LastName, &studentFromDb.Email, &studentFromDb.Class)
+		err = scanStudent(ctx, tx.QueryRowContext(ctx, "SELECT "+studentColumns+" FROM students WHERE id = ? AND deleted_at IS NULL FOR UPDATE", id), &studentFromDb)
 		if err == sql.ErrNoRows {
 			tx.Rollback()
-			return utils.ErrorHandler(err, "Student not found with ID "+strconv.Itoa(id))
+			return utils.NotFoundError(err, "Student not found with ID "+strconv.Itoa(id))
 		} else if err != nil {
 			tx.Rollback()
-			return utils.ErrorHandler(err, "Database error")
+			return dbError(err, "Database error")
 		}
 
-		studentVal := reflect.ValueOf(&studentFromDb).Elem()
-		studentType := studentVal.Type()
+		err = checkVersion("Student", id, version, studentFromDb.Version)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
 
-		for k, v := range update {
-			if k == "id" {
-				continue
-			}
-			for i := 0; i < studentType.NumField(); i++ {
-				field := studentType.Field(i)
-				jsonTag := field.Tag.Get("json")
-				if jsonTag == k+",omitempty" {
-					fieldVal := studentVal.Field(i)
-					if fieldVal.IsValid() && fieldVal.CanSet() {
-						val := reflect.ValueOf(v)
-						if val.Type().ConvertibleTo(fieldVal.Type()) {
-							fieldVal.Set(val.Convert(fieldVal.Type()))
-						} else {
-							tx.Rollback()
-							return utils.ErrorHandler(err, "Type mismatch for field "+k)
-						}
-					}
-					break
-				}
-			}
+		before := studentFromDb
+		err = checkSensitivePatch(ctx, update)
+		if err == nil {
+			err = patchStudent(&studentFromDb, update)
+		}
+		if err == nil && studentFromDb.Class != before.Class {
+			err = assignClass(ctx, tx, &studentFromDb)
+		}
+		if err != nil {
+			tx.Rollback()
+			return err
 		}
 
-		_, err = tx.Exec("UPDATE students SET first_name = ?, last_name = ?, email = ?, class = ? WHERE id = ?",
-			studentFromDb.FirstName, studentFromDb.LastName, studentFromDb.Email, studentFromDb.Class, studentFromDb.ID)
+		stored, err := sealStudent(ctx, studentFromDb)
 		if err != nil {
 			tx.Rollback()
-			return utils.ErrorHandler(err, "Database error")
+			return err
+		}
+		_, err = tx.ExecContext(ctx, "UPDATE students SET "+studentAssignments+", version = version + 1 WHERE id = ? AND deleted_at IS NULL",
+			append(studentAssignmentValues(stored), studentFromDb.ID)...)
+		if err != nil {
+			tx.Rollback()
+			return dbError(err, "Database error")
+		}
+
+		err = recordAudit(ctx, tx, changeEntry(models.AuditUpdate, "students", id, before, studentFromDb))
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		err = recordHistory(ctx, tx, "students", id, models.AuditUpdate)
+		if err == nil && studentFromDb.ClassID != before.ClassID {
+			err = recordEnrollment(ctx, tx, id, studentFromDb.ClassID)
+		}
+		if err != nil {
+			tx.Rollback()
+			return err
 		}
 	}
 
 	err = tx.Commit()
 	if err != nil {
-		return utils.ErrorHandler(err, "Error committing transaction")
+		return dbError(err, "Error committing transaction")
 	}
 	return nil
 }
 
-func PatchOneStudentDBHandler(id int, updates map[string]interface{}) (models.Student, error) {
+func PatchOneStudentDBHandler(ctx context.Context, id, version int, updates map[string]interface{}) (models.Student, error) {
 	db, err := ConnectDB()
 	if err != nil {
 		return models.Student{}, utils.ErrorHandler(err, "Database connection error")
 	}
-	defer db.Close()
 
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Student{}, dbError(err, "Database error")
+	}
+
+	// lock the row so the version can't change between the check and the update
 	var existingStudent models.Student
-	err = db.QueryRow("SELECT id, first_name, last_name, email, class FROM students WHERE id = ?", id).Scan(
-		&existingStudent.ID, &existingStudent.FirstName, &existingStudent.LastName, &existingStudent.Email, &existingStudent.Class)
+	err = scanStudent(ctx, tx.QueryRowContext(ctx, "SELECT "+studentColumns+" FROM students WHERE id = ? AND deleted_at IS NULL FOR UPDATE", id), &existingStudent)
 	if err == sql.ErrNoRows {
-		return models.Student{}, utils.ErrorHandler(err, "Student not found")
+		tx.Rollback()
+		return models.Student{}, utils.NotFoundError(err, "Student not found")
 	} else if err != nil {
-		return models.Student{}, utils.ErrorHandler(err, "Database error")
+		tx.Rollback()
+		return models.Student{}, dbError(err, "Database error")
 	}
 
-	studentVal := reflect.ValueOf(&existingStudent).Elem()
-	studentType := studentVal.Type()
+	err = checkVersion("Student", id, version, existingStudent.Version)
+	if err != nil {
+		tx.Rollback()
+		return models.Student{}, err
+	}
+	currentVersion := existingStudent.Version
+	before := existingStudent
 
-	for k, v := range updates {
-		for i := 0; i < studentType.NumField(); i++ {
-			field := studentType.Field(i)
-			jsonTag := field.Tag.Get("json")
-			if jsonTag == k+",omitempty" {
-				fieldVal := studentVal.Field(i)
-				if fieldVal.IsValid() && fieldVal.CanSet() {
-					val := reflect.ValueOf(v)
-					fieldVal.Set(val.Convert(fieldVal.Type()))
-				}
-				break
-			}
-		}
+	err = checkSensitivePatch(ctx, updates)
+	if err == nil {
+		err = patchStudent(&existingStudent, updates)
+	}
+	if err == nil && existingStudent.Class != before.Class {
+		err = assignClass(ctx, tx, &existingStudent)
+	}
+	if err != nil {
+		tx.Rollback()
+		return models.Student{}, err
+	}
+
+	stored, err := sealStudent(ctx, existingStudent)
+	if err != nil {
+		tx.Rollback()
+		return models.Student{}, err
+	}
+	res, err := tx.ExecContext(ctx, "UPDATE students SET "+studentAssignments+", version = version + 1 WHERE id = ? AND version = ? AND deleted_at IS NULL",
+		append(studentAssignmentValues(stored), existingStudent.ID, currentVersion)...)
+	if err != nil {
+		tx.Rollback()
+		return models.Student{}, dbError(err, "Database error")
+	}
+	err = checkVersionedWrite(res, "Student", id)
+	if err != nil {
+		tx.Rollback()
+		return models.Student{}, err
+	}
+
+	err = recordAudit(ctx, tx, changeEntry(models.AuditUpdate, "students", id, before, existingStudent))
+	if err != nil {
+		tx.Rollback()
+		return models.Student{}, err
 	}
 
-	_, err = db.Exec("UPDATE students SET first_name = ?, last_name = ?, email = ?, class = ? WHERE id = ?",
-		existingStudent.FirstName, existingStudent.LastName, existingStudent.Email, existingStudent.Class, existingStudent.ID)
+	err = recordHistory(ctx, tx, "students", id, models.AuditUpdate)
+	if err == nil && existingStudent.ClassID != before.ClassID {
+		err = recordEnrollment(ctx, tx, id, existingStudent.ClassID)
+	}
 	if err != nil {
-		return models.Student{}, utils.ErrorHandler(err, "Database error")
+		tx.Rollback()
+		return models.Student{}, err
 	}
 
+	err = tx.Commit()
+	if err != nil {
+		return models.Student{}, dbError(err, "Error committing transaction")
+	}
+
+	existingStudent.Version = currentVersion + 1
+	hideSensitive(ctx, &existingStudent)
 	return existingStudent, nil
 }
 
-func DeleteOneStudentDBHandler(id int) error {
+func DeleteOneStudentDBHandler(ctx context.Context, id, version int) error {
 	db, err := ConnectDB()
 	if err != nil {
 		return utils.ErrorHandler(err, "Database connection error")
 	}
-	defer db.Close()
 
-	res, err := db.Exec("DELETE FROM students WHERE id = ?", id)
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
-		return utils.ErrorHandler(err, "Database error")
+		return dbError(err, "Database error")
 	}
 
-	rowsAffected, err := res.RowsAffected()
+	// lock the row so the version can't change between the check and the update
+	var existingStudent models.Student
+	err = scanStudent(ctx, tx.QueryRowContext(ctx, "SELECT "+studentColumns+" FROM students WHERE id = ? AND deleted_at IS NULL FOR UPDATE", id), &existingStudent)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return utils.NotFoundError(err, "Student not found")
+	} else if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+
+	err = checkVersion("Student", id, version, existingStudent.Version)
 	if err != nil {
-		return utils.ErrorHandler(err, "Database error")
+		tx.Rollback()
+		return err
 	}
-	if rowsAffected == 0 {
-		return utils.ErrorHandler(err, "Student not found")
+
+	res, err := tx.ExecContext(ctx, "UPDATE students SET deleted_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id = ? AND version = ? AND deleted_at IS NULL", id, existingStudent.Version)
+	if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+	err = checkVersionedWrite(res, "Student", id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = recordAudit(ctx, tx, changeEntry(models.AuditDelete, "students", id, existingStudent, nil))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = recordHistory(ctx, tx, "students", id, models.AuditDelete)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return dbError(err, "Error committing transaction")
 	}
 	return nil
 }
 
-func DeleteStudentsDBHandler(ids []int) ([]int, error) {
+func DeleteStudentsDBHandler(ctx context.Context, ids []int) ([]int, error) {
 	db, err := ConnectDB()
 	if err != nil {
 		return nil, utils.ErrorHandler(err, "Database connection error")
 	}
-	defer db.Close()
 
-	tx, err := db.Begin()
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, utils.ErrorHandler(err, "Database error")
+		return nil, dbError(err, "Database error")
 	}
 
-	stmt, err := tx.Prepare("DELETE FROM students WHERE id = ?")
+	stmt, err := tx.PrepareContext(ctx, "UPDATE students SET deleted_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id = ? AND deleted_at IS NULL")
 	if err != nil {
 		tx.Rollback()
-		return nil, utils.ErrorHandler(err, "Database error")
+		return nil, dbError(err, "Database error")
 	}
 
 	deletedIds := []int{}
 
 	for _, id := range ids {
-		res, err := stmt.Exec(id)
+		var existingStudent models.Student
+		err = scanStudent(ctx, tx.QueryRowContext(ctx, "SELECT "+studentColumns+" FROM students WHERE id = ? AND deleted_at IS NULL FOR UPDATE", id), &existingStudent)
+		if err == sql.ErrNoRows {
+			tx.Rollback()
+			return nil, utils.NotFoundError(err, "Student not found with ID "+strconv.Itoa(id))
+		} else if err != nil {
+			tx.Rollback()
+			return nil, dbError(err, "Database error")
+		}
+
+		res, err := stmt.ExecContext(ctx, id)
 		if err != nil {
 			tx.Rollback()
-			return nil, utils.ErrorHandler(err, "Error deleting student")
+			return nil, dbError(err, "Error deleting student")
 		}
 
 		rowsAffected, err := res.RowsAffected()
 		if err != nil {
 			tx.Rollback()
-			return nil, utils.ErrorHandler(err, "Database error")
+			return nil, dbError(err, "Database error")
 		}
 		if rowsAffected > 0 {
 			deletedIds = append(deletedIds, id)
 		}
 		if rowsAffected < 1 {
 			tx.Rollback()
-			return nil, utils.ErrorHandler(err, "Student not found with ID "+strconv.Itoa(id))
+			return nil, utils.NotFoundError(err, "Student not found with ID "+strconv.Itoa(id))
+		}
+
+		err = recordAudit(ctx, tx, changeEntry(models.AuditDelete, "students", id, existingStudent, nil))
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		err = recordHistory(ctx, tx, "students", id, models.AuditDelete)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
 		}
 	}
 
 	err = tx.Commit()
 	if err != nil {
-		return nil, utils.ErrorHandler(err, "Error committing transaction")
+		return nil, dbError(err, "Error committing transaction")
 	}
 
 	if len(deletedIds) < 1 {
-		return nil, utils.ErrorHandler(err, "No students found to delete")
+		return nil, utils.NotFoundError(err, "No students found to delete")
 	}
 	return deletedIds, nil
 }
+
+func RestoreStudentDBHandler(ctx context.Context, id int) (models.Student, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return models.Student{}, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Student{}, dbError(err, "Database error")
+	}
+
+	res, err := tx.ExecContext(ctx, "UPDATE students SET deleted_at = NULL, version = version + 1 WHERE id = ? AND deleted_at IS NOT NULL", id)
+	if err != nil {
+		tx.Rollback()
+		return models.Student{}, dbError(err, "Database error")
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return models.Student{}, dbError(err, "Database error")
+	}
+	if rowsAffected == 0 {
+		// either there is no such student or it isn't deleted
+		var deleted bool
+		err = tx.QueryRowContext(ctx, "SELECT deleted_at IS NOT NULL FROM students WHERE id = ?", id).Scan(&deleted)
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return models.Student{}, utils.NotFoundError(err, "Student not found")
+		} else if err != nil {
+			return models.Student{}, dbError(err, "Database error")
+		}
+		return models.Student{}, utils.ConflictError(nil, "Student is not deleted")
+	}
+
+	err = recordAudit(ctx, tx, changeEntry(models.AuditRestore, "students", id, nil, nil))
+	if err != nil {
+		tx.Rollback()
+		return models.Student{}, err
+	}
+
+	err = recordHistory(ctx, tx, "students", id, models.AuditRestore)
+	if err != nil {
+		tx.Rollback()
+		return models.Student{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.Student{}, dbError(err, "Error committing transaction")
+	}
+
+	return GetOneStudentDBHandler(ctx, id, false)
+}
+
+// GetStudentHistoryDBHandler lists every recorded revision of a student, newest first
+func GetStudentHistoryDBHandler(ctx context.Context, id int) ([]models.StudentRevision, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT revision, operation, UNIX_TIMESTAMP(changed_at), changed_by, request_id,
+		student_id, first_name, last_name, email, class, student_number, date_of_birth, gender, enrollment_date,
+		COALESCE(address, ''), COALESCE(medical_notes, ''), allergies, version, UNIX_TIMESTAMP(deleted_at)
+		FROM student_history WHERE student_id = ? ORDER BY revision DESC`, id)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	revisions := []models.StudentRevision{}
+	for rows.Next() {
+		var rev models.StudentRevision
+		var changedBy sql.NullInt64
+		var requestID sql.NullString
+		err := rows.Scan(&rev.Revision, &rev.Operation, &rev.ChangedAt, &changedBy, &requestID,
+			&rev.Student.ID, &rev.Student.FirstName, &rev.Student.LastName, &rev.Student.Email, &rev.Student.Class, &rev.Student.StudentNumber, &rev.Student.DateOfBirth,
+			&rev.Student.Gender, &rev.Student.EnrollmentDate, &rev.Student.Address, &rev.Student.MedicalNotes, &rev.Student.Allergies, &rev.Student.Version, &rev.Student.DeletedAt)
+		if err == nil {
+			err = openStudent(ctx, &rev.Student)
+		}
+		if err != nil {
+			return nil, dbError(err, "Database error")
+		}
+		hideSensitive(ctx, &rev.Student)
+		if changedBy.Valid {
+			actor := int(changedBy.Int64)
+			rev.ChangedBy = &actor
+		}
+		rev.RequestID = requestID.String
+		rev.Student.UpdatedAt = rev.ChangedAt
+		revisions = append(revisions, rev)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err, "Database error")
+	}
+
+	if len(revisions) == 0 {
+		return nil, utils.NotFoundError(nil, "No history found for student")
+	}
+	return revisions, nil
+}
+
+// GetStudentAsOfDBHandler reconstructs a student as it was at asOf from its history
+func GetStudentAsOfDBHandler(ctx context.Context, id int, asOf time.Time, includeDeleted bool) (models.Student, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return models.Student{}, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	var student models.Student
+	err = db.QueryRowContext(ctx, `SELECT student_id, first_name, last_name, email, class, student_number, date_of_birth, gender, enrollment_date,
+		COALESCE(address, ''), COALESCE(medical_notes, ''), allergies, version, UNIX_TIMESTAMP(changed_at), UNIX_TIMESTAMP(deleted_at)
+		FROM student_history WHERE student_id = ? AND changed_at <= FROM_UNIXTIME(?) ORDER BY revision DESC LIMIT 1`, id, asOf.Unix()).Scan(
+		&student.ID, &student.FirstName, &student.LastName, &student.Email, &student.Class, &student.StudentNumber, &student.DateOfBirth, &student.Gender,
+		&student.EnrollmentDate, &student.Address, &student.MedicalNotes, &student.Allergies, &student.Version, &student.UpdatedAt, &student.DeletedAt)
+	if err == nil {
+		err = openStudent(ctx, &student)
+	}
+	if err == sql.ErrNoRows {
+		return models.Student{}, utils.NotFoundError(err, "Student did not exist at that time")
+	} else if err != nil {
+		return models.Student{}, dbError(err, "Database error")
+	}
+
+	if student.DeletedAt != nil && !includeDeleted {
+		return models.Student{}, utils.NotFoundError(nil, "Student was deleted at that time")
+	}
+	hideSensitive(ctx, &student)
+	return student, nil
+}
+
+// UpsertStudentsDBHandler inserts the students that don't exist yet and updates the ones that do,
+// matching them on the keys columns. The batch is all or nothing, and with dryRun nothing is kept.
+func UpsertStudentsDBHandler(ctx context.Context, students []models.Student, keys []string, dryRun bool) ([]models.Student, models.UpsertResult, error) {
+	var result models.UpsertResult
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, result, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, result, dbError(err, "Database error")
+	}
+
+	insertStmt, err := tx.PrepareContext(ctx, utils.GenerateInsertQuery("students", models.Student{}))
+	if err != nil {
+		tx.Rollback()
+		return nil, result, dbError(err, "Database error")
+	}
+	defer insertStmt.Close()
+
+	updateStmt, err := tx.PrepareContext(ctx, utils.GenerateUpdateQuery("students", models.Student{}))
+	if err != nil {
+		tx.Rollback()
+		return nil, result, dbError(err, "Database error")
+	}
+	defer updateStmt.Close()
+
+	saved := make([]models.Student, len(students))
+
+	for i, student := range students {
+		student.UpdatedAt, student.DeletedAt = nil, nil
+
+		filter, args := naturalKeyFilter(keys, student)
+		var existing models.Student
+		err = scanStudent(ctx, tx.QueryRowContext(ctx, "SELECT "+studentColumns+" FROM students"+filter+" FOR UPDATE", args...), &existing)
+		existing.UpdatedAt, existing.DeletedAt = nil, nil
+
+		switch {
+		case err == sql.ErrNoRows:
+			err = checkDeletedMatch(ctx, tx, "students", "Student", keys, student)
+			if err == nil {
+				err = prepareStudent(ctx, &student)
+			}
+			if err == nil {
+				err = assignClass(ctx, tx, &student)
+			}
+			if err != nil {
+				tx.Rollback()
+				return nil, result, err
+			}
+			student.Version = 1
+			stored := student
+			stored.Address, stored.MedicalNotes = "", ""
+			res, err := insertStmt.ExecContext(ctx, utils.GetStructValues(stored)...)
+			if err != nil {
+				tx.Rollback()
+				return nil, result, dbError(err, "Database error")
+			}
+			lastID, err := res.LastInsertId()
+			if err != nil {
+				tx.Rollback()
+				return nil, result, dbError(err, "Database error")
+			}
+			student.ID = int(lastID)
+
+			err = insertSensitive(ctx, tx, student)
+			if err == nil {
+				err = recordAudit(ctx, tx, changeEntry(models.AuditCreate, "students", student.ID, nil, student))
+			}
+			if err == nil {
+				err = recordHistory(ctx, tx, "students", student.ID, models.AuditCreate)
+			}
+			if err == nil {
+				err = recordEnrollment(ctx, tx, student.ID, student.ClassID)
+			}
+			if err != nil {
+				tx.Rollback()
+				return nil, result, err
+			}
+			result.Created++
+			result.Actions = append(result.Actions, models.UpsertCreated)
+
+		case err != nil:
+			tx.Rollback()
+			return nil, result, dbError(err, "Database error")
+
+		default:
+			err = keepSensitive(ctx, &student, existing)
+			if err != nil {
+				tx.Rollback()
+				return nil, result, err
+			}
+			normalizeStudent(&student)
+			if student.EnrollmentDate == nil {
+				student.EnrollmentDate = existing.EnrollmentDate
+			}
+			student.ID = existing.ID
+			student.Version = existing.Version
+			student.ClassID = existing.ClassID
+			if reflect.DeepEqual(student, existing) {
+				result.Unchanged++
+				result.Actions = append(result.Actions, models.UpsertUnchanged)
+				break
+			}
+
+			if student.Class != existing.Class {
+				err = assignClass(ctx, tx, &student)
+				if err != nil {
+					tx.Rollback()
+					return nil, result, err
+				}
+			}
+
+			student.Version++
+			stored, err := sealStudent(ctx, student)
+			if err != nil {
+				tx.Rollback()
+				return nil, result, err
+			}
+			_, err = updateStmt.ExecContext(ctx, append(utils.GetStructValues(stored), student.ID)...)
+			if err != nil {
+				tx.Rollback()
+				return nil, result, dbError(err, "Database error")
+			}
+
+			err = recordAudit(ctx, tx, changeEntry(models.AuditUpdate, "students", student.ID, existing, student))
+			if err == nil {
+				err = recordHistory(ctx, tx, "students", student.ID, models.AuditUpdate)
+			}
+			if err == nil && student.ClassID != existing.ClassID {
+				err = recordEnrollment(ctx, tx, student.ID, student.ClassID)
+			}
+			if err != nil {
+				tx.Rollback()
+				return nil, result, err
+			}
+			result.Updated++
+			result.Actions = append(result.Actions, models.UpsertUpdated)
+		}
+		hideSensitive(ctx, &student)
+		saved[i] = student
+	}
+
+	if dryRun {
+		// report what would have happened without keeping any of it
+		tx.Rollback()
+		return saved, result, nil
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, models.UpsertResult{}, dbError(err, "Error committing transaction")
+	}
+	return saved, result, nil
+}
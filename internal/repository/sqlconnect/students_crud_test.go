@@ -0,0 +1,146 @@
+package sqlconnect
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+func withRole(role string) context.Context {
+	return context.WithValue(context.Background(), utils.ContextKey("role"), role)
+}
+
+func errorStatus(err error) int {
+	var appErr *utils.AppError
+	if errors.As(err, &appErr) {
+		return appErr.Status()
+	}
+	return 0
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestKeepSensitive(t *testing.T) {
+	existing := models.Student{Address: "v1:sealed-address", MedicalNotes: "v1:sealed-notes"}
+	tests := []struct {
+		name       string
+		role       string
+		student    models.Student
+		want       models.Student
+		wantStatus int
+	}{
+		{"admin sets", "admin", models.Student{Address: "New 1"}, models.Student{Address: "New 1"}, 0},
+		{"manager clears", "manager", models.Student{}, models.Student{}, 0},
+		{"exec keeps stored", "exec", models.Student{FirstName: "Ana"}, models.Student{FirstName: "Ana", Address: existing.Address, MedicalNotes: existing.MedicalNotes}, 0},
+		{"exec sets address", "exec", models.Student{Address: "New 1"}, models.Student{}, http.StatusForbidden},
+		{"exec sets notes", "exec", models.Student{MedicalNotes: "Asthma"}, models.Student{}, http.StatusForbidden},
+		{"no role", "", models.Student{Address: "New 1"}, models.Student{}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			student := tt.student
+			err := keepSensitive(withRole(tt.role), &student, existing)
+			if tt.wantStatus != 0 {
+				if errorStatus(err) != tt.wantStatus {
+					t.Errorf("keepSensitive() error = %v, want status %d", err, tt.wantStatus)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(student, tt.want) {
+				t.Errorf("keepSensitive() = %+v, %v, want %+v", student, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckSensitivePatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       string
+		updates    map[string]interface{}
+		wantStatus int
+	}{
+		{"admin", "admin", map[string]interface{}{"address": "x"}, 0},
+		{"exec other fields", "exec", map[string]interface{}{"allergies": "Peanuts"}, 0},
+		{"exec address", "exec", map[string]interface{}{"address": "x"}, http.StatusForbidden},
+		{"exec clears notes", "exec", map[string]interface{}{"medical_notes": nil}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSensitivePatch(withRole(tt.role), tt.updates)
+			if errorStatus(err) != tt.wantStatus || (tt.wantStatus == 0 && err != nil) {
+				t.Errorf("checkSensitivePatch() error = %v, want status %d", err, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHideSensitive(t *testing.T) {
+	for role, want := range map[string]string{"admin": "Calle Mayor 5", "manager": "Calle Mayor 5", "exec": ""} {
+		student := models.Student{Address: "Calle Mayor 5", MedicalNotes: "Asthma", Allergies: "Peanuts"}
+		hideSensitive(withRole(role), &student)
+		if student.Address != want || (want == "" && student.MedicalNotes != "") || student.Allergies != "Peanuts" {
+			t.Errorf("hideSensitive() for %s = %+v", role, student)
+		}
+	}
+}
+
+func TestPatchStudent(t *testing.T) {
+	base := func() models.Student {
+		return models.Student{ID: 3, FirstName: "Ana", StudentNumber: strPtr("S-1"), DateOfBirth: strPtr("2010-03-14"), Version: 2}
+	}
+	tests := []struct {
+		name    string
+		updates map[string]interface{}
+		want    func(*models.Student)
+		wantErr bool
+	}{
+		{"string", map[string]interface{}{"first_name": "Bea"}, func(s *models.Student) { s.FirstName = "Bea" }, false},
+		{"optional set", map[string]interface{}{"enrollment_date": "2025-09-01"}, func(s *models.Student) { s.EnrollmentDate = strPtr("2025-09-01") }, false},
+		{"optional null clears", map[string]interface{}{"student_number": nil}, func(s *models.Student) { s.StudentNumber = nil }, false},
+		{"optional empty clears", map[string]interface{}{"date_of_birth": ""}, func(s *models.Student) { s.DateOfBirth = nil }, false},
+		{"id and version ignored", map[string]interface{}{"id": "9", "version": float64(7)}, func(s *models.Student) {}, false},
+		{"wrong type", map[string]interface{}{"first_name": float64(1)}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			student := base()
+			err := patchStudent(&student, tt.updates)
+			if tt.wantErr {
+				if errorStatus(err) != http.StatusUnprocessableEntity {
+					t.Errorf("patchStudent() error = %v, want a validation error", err)
+				}
+				return
+			}
+			want := base()
+			tt.want(&want)
+			if err != nil || !reflect.DeepEqual(student, want) {
+				t.Errorf("patchStudent() = %+v, %v, want %+v", student, err, want)
+			}
+		})
+	}
+}
+
+func TestDiffRecordsRedactsEncryptedFields(t *testing.T) {
+	before := models.Student{FirstName: "Ana", Address: "Calle Mayor 5", Allergies: "Peanuts"}
+	after := models.Student{FirstName: "Ana", Address: "Calle Sol 2", MedicalNotes: "Asthma", Allergies: "None"}
+	want := map[string]models.AuditChange{
+		"address":       {From: redacted, To: redacted},
+		"medical_notes": {From: nil, To: redacted},
+		"allergies":     {From: "Peanuts", To: "None"},
+	}
+	if got := diffRecords(before, after); !reflect.DeepEqual(got, want) {
+		t.Errorf("diffRecords() = %+v, want %+v", got, want)
+	}
+
+	// guardians' addresses aren't encrypted and stay readable
+	got := diffRecords(models.Guardian{Address: "A"}, models.Guardian{Address: "B"})
+	if got["address"] != (models.AuditChange{From: "A", To: "B"}) {
+		t.Errorf("diffRecords() of guardians = %+v", got)
+	}
+}
@@ -1,34 +1,41 @@
 package sqlconnect
 
 import (
+	"context"
 	"database/sql"
 	"net/http"
 	"reflect"
 	"strconv"
+	"time"
 
 	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
 	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
 )
 
-func GetTeachersDBHandler(teachers []models.Teacher, r *http.Request) ([]models.Teacher, error) {
+func GetTeachersDBHandler(teachers []models.Teacher, r *http.Request, includeDeleted bool) ([]models.Teacher, error) {
+	ctx := r.Context()
 	db, err := ConnectDB()
 	if err != nil {
 		return nil, utils.ErrorHandler(err, "Database connection error")
 	}
-	defer db.Close()
+
+	term, err := termParam(ctx, db, r.URL.Query().Get("term"))
+	if err != nil {
+		return nil, err
+	}
+	termFilter, args := teacherTermFilter(term)
 
 	//  Handle Query Parameters
-	query := "SELECT id, first_name, last_name, email, class, subject FROM teachers WHERE 1=1"
-	var args []any
+	query := "SELECT id, first_name, last_name, email, class, subject, version, UNIX_TIMESTAMP(updated_at), UNIX_TIMESTAMP(deleted_at) FROM teachers WHERE 1=1" + deletedFilter(includeDeleted) + termFilter
 
 	query, args = utils.AddFilters(r, query, args, models.Teacher{})
 
 	// teachers/?sortby=name:asc&sortby=class:desc
 	query = utils.AddSorting(r, query, models.Teacher{})
 
-	rows, err := db.Query(query, args...)
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, utils.ErrorHandler(err, "Database error")
+		return nil, dbError(err, "Database error")
 	}
 	defer rows.Close()
 
@@ -36,104 +43,206 @@ func GetTeachersDBHandler(teachers []models.Teacher, r *http.Request) ([]models.
 
 	for rows.Next() {
 		var teacher models.Teacher
-		err := rows.Scan(&teacher.ID, &teacher.FirstName, &teacher.LastName, &teacher.Email, &teacher.Class, &teacher.Subject)
+		err := rows.Scan(&teacher.ID, &teacher.FirstName, &teacher.LastName, &teacher.Email, &teacher.Class, &teacher.Subject, &teacher.Version, &teacher.UpdatedAt, &teacher.DeletedAt)
 		if err != nil {
-			return nil, utils.ErrorHandler(err, "Database error")
+			return nil, dbError(err, "Database error")
 		}
 		teachers = append(teachers, teacher)
 	}
 	return teachers, nil
 }
 
-func GetOneTeacherDBHandler(id int) (models.Teacher, error) {
+// StreamTeachersDBHandler calls each for every teacher matching the filters and sorting of r,
+// reading them one at a time rather than loading the whole listing
+func StreamTeachersDBHandler(r *http.Request, includeDeleted bool, each func(models.Teacher) error) error {
+	db, err := ConnectDB()
+	if err != nil {
+		return utils.ErrorHandler(err, "Database connection error")
+	}
+
+	term, err := termParam(r.Context(), db, r.URL.Query().Get("term"))
+	if err != nil {
+		return err
+	}
+	termFilter, args := teacherTermFilter(term)
+
+	query := "SELECT id, first_name, last_name, email, class, subject, version, UNIX_TIMESTAMP(updated_at), UNIX_TIMESTAMP(deleted_at) FROM teachers WHERE 1=1" + deletedFilter(includeDeleted) + termFilter
+	query, args = utils.AddFilters(r, query, args, models.Teacher{})
+	query = utils.AddSorting(r, query, models.Teacher{})
+
+	rows, err := db.QueryContext(r.Context(), query, args...)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var teacher models.Teacher
+		err := rows.Scan(&teacher.ID, &teacher.FirstName, &teacher.LastName, &teacher.Email, &teacher.Class, &teacher.Subject, &teacher.Version, &teacher.UpdatedAt, &teacher.DeletedAt)
+		if err != nil {
+			return dbError(err, "Database error")
+		}
+		err = each(teacher)
+		if err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return dbError(err, "Database error")
+	}
+	return nil
+}
+
+func GetOneTeacherDBHandler(ctx context.Context, id int, includeDeleted bool) (models.Teacher, error) {
 	db, err := ConnectDB()
 	if err != nil {
 		return models.Teacher{}, utils.ErrorHandler(err, "Database connection error")
 	}
-	defer db.Close()
 
 	var teacher models.Teacher
 
-	err = db.QueryRow("SELECT id, first_name, last_name, email, class, subject FROM teachers WHERE id = ?", id).Scan(
-		&teacher.ID, &teacher.FirstName, &teacher.LastName, &teacher.Email, &teacher.Class, &teacher.Subject)
+	err = db.QueryRowContext(ctx, "SELECT id, first_name, last_name, email, class, subject, version, UNIX_TIMESTAMP(updated_at), UNIX_TIMESTAMP(deleted_at) FROM teachers WHERE id = ?"+deletedFilter(includeDeleted), id).Scan(
+		&teacher.ID, &teacher.FirstName, &teacher.LastName, &teacher.Email, &teacher.Class, &teacher.Subject, &teacher.Version, &teacher.UpdatedAt, &teacher.DeletedAt)
 	if err == sql.ErrNoRows {
-		return models.Teacher{}, utils.ErrorHandler(err, "Teacher not found")
+		return models.Teacher{}, utils.NotFoundError(err, "Teacher not found")
 	} else if err != nil {
-		return models.Teacher{}, utils.ErrorHandler(err, "Database error")
+		return models.Teacher{}, dbError(err, "Database error")
 	}
 	return teacher, nil
 }
 
-func AddTeachersDBHandler(newTeachers []models.Teacher) ([]models.Teacher, error) {
+func AddTeachersDBHandler(ctx context.Context, newTeachers []models.Teacher) ([]models.Teacher, error) {
 	db, err := ConnectDB()
 	if err != nil {
 		return nil, utils.ErrorHandler(err, "Database connection error")
 	}
-	defer db.Close()
 
-	// stmt, err := db.Prepare("INSERT INTO teachers (first_name, last_name, email, class, subject) VALUES (?, ?, ?, ?, ?)")
-	stmt, err := db.Prepare(utils.GenerateInsertQuery("TEACHERS", models.Teacher{}))
+	// the batch is all or nothing, a retried request never finds half of it already inserted
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, utils.ErrorHandler(err, "Database error")
+		return nil, dbError(err, "Database error")
+	}
+
+	// stmt, err := db.PrepareContext(ctx, "INSERT INTO teachers (first_name, last_name, email, class, subject) VALUES (?, ?, ?, ?, ?)")
+	stmt, err := tx.PrepareContext(ctx, utils.GenerateInsertQuery("TEACHERS", models.Teacher{}))
+	if err != nil {
+		tx.Rollback()
+		return nil, dbError(err, "Database error")
 	}
 	defer stmt.Close()
 
 	addedTeachers := make([]models.Teacher, len(newTeachers))
 
 	for i, newTeacher := range newTeachers {
-		// res, err := stmt.Exec(newTeacher.FirstName, newTeacher.LastName, newTeacher.Email, newTeacher.Class, newTeacher.Subject)
+		// res, err := stmt.ExecContext(ctx, newTeacher.FirstName, newTeacher.LastName, newTeacher.Email, newTeacher.Class, newTeacher.Subject)
+		newTeacher.Version = 1
 		values := utils.GetStructValues(newTeacher)
-		res, err := stmt.Exec(values...)
+		res, err := stmt.ExecContext(ctx, values...)
 		if err != nil {
-			return nil, utils.ErrorHandler(err, "Database error")
+			tx.Rollback()
+			return nil, dbError(err, "Database error")
 		}
 		lastID, err := res.LastInsertId()
 		if err != nil {
-			return nil, utils.ErrorHandler(err, "Database error")
+			tx.Rollback()
+			return nil, dbError(err, "Database error")
 		}
 		newTeacher.ID = int(lastID)
 		addedTeachers[i] = newTeacher
+
+		err = recordAudit(ctx, tx, changeEntry(models.AuditCreate, "teachers", newTeacher.ID, nil, newTeacher))
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		err = recordHistory(ctx, tx, "teachers", newTeacher.ID, models.AuditCreate)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, dbError(err, "Error committing transaction")
 	}
 	return addedTeachers, nil
 }
 
-func UpdateTeacherDBHandler(id int, updatedTeacher models.Teacher) (models.Teacher, error) {
+func UpdateTeacherDBHandler(ctx context.Context, id, version int, updatedTeacher models.Teacher) (models.Teacher, error) {
 	db, err := ConnectDB()
 	if err != nil {
 		return models.Teacher{}, utils.ErrorHandler(err, "Database connection error")
 	}
-	defer db.Close()
 
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Teacher{}, dbError(err, "Database error")
+	}
+
+	// lock the row so the version can't change between the check and the update
 	var existingTeacher models.Teacher
-	err = db.QueryRow("SELECT id, first_name, last_name, email, class, subject FROM teachers WHERE id = ?", id).Scan(
-		&existingTeacher.ID, &existingTeacher.FirstName, &existingTeacher.LastName, &existingTeacher.Email, &existingTeacher.Class, &existingTeacher.Subject)
+	err = tx.QueryRowContext(ctx, "SELECT id, first_name, last_name, email, class, subject, version FROM teachers WHERE id = ? AND deleted_at IS NULL FOR UPDATE", id).Scan(
+		&existingTeacher.ID, &existingTeacher.FirstName, &existingTeacher.LastName, &existingTeacher.Email, &existingTeacher.Class, &existingTeacher.Subject, &existingTeacher.Version)
 	if err == sql.ErrNoRows {
-		return models.Teacher{}, utils.ErrorHandler(err, "Teacher not found")
+		tx.Rollback()
+		return models.Teacher{}, utils.NotFoundError(err, "Teacher not found")
 	} else if err != nil {
-		return models.Teacher{}, utils.ErrorHandler(err, "Database error")
+		tx.Rollback()
+		return models.Teacher{}, dbError(err, "Database error")
+	}
+
+	err = checkVersion("Teacher", id, version, existingTeacher.Version)
+	if err != nil {
+		tx.Rollback()
+		return models.Teacher{}, err
 	}
 
 	updatedTeacher.ID = existingTeacher.ID
+	updatedTeacher.Version = existingTeacher.Version + 1
 
-	_, err = db.Exec("UPDATE teachers SET first_name = ?, last_name = ?, email = ?, class = ?, subject = ? WHERE id = ?",
-		updatedTeacher.FirstName, updatedTeacher.LastName, updatedTeacher.Email, updatedTeacher.Class, updatedTeacher.Subject, updatedTeacher.ID)
+	res, err := tx.ExecContext(ctx, "UPDATE teachers SET first_name = ?, last_name = ?, email = ?, class = ?, subject = ?, version = version + 1 WHERE id = ? AND version = ? AND deleted_at IS NULL",
+		updatedTeacher.FirstName, updatedTeacher.LastName, updatedTeacher.Email, updatedTeacher.Class, updatedTeacher.Subject, updatedTeacher.ID, existingTeacher.Version)
 	if err != nil {
-		return models.Teacher{}, utils.ErrorHandler(err, "Database error")
+		tx.Rollback()
+		return models.Teacher{}, dbError(err, "Database error")
+	}
+	err = checkVersionedWrite(res, "Teacher", id)
+	if err != nil {
+		tx.Rollback()
+		return models.Teacher{}, err
+	}
+
+	err = recordAudit(ctx, tx, changeEntry(models.AuditUpdate, "teachers", id, existingTeacher, updatedTeacher))
+	if err != nil {
+		tx.Rollback()
+		return models.Teacher{}, err
+	}
+
+	err = recordHistory(ctx, tx, "teachers", id, models.AuditUpdate)
+	if err != nil {
+		tx.Rollback()
+		return models.Teacher{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.Teacher{}, dbError(err, "Error committing transaction")
 	}
 	return updatedTeacher, nil
 }
 
-func PatchTeachersDBHandler(updates []map[string]interface{}) error {
+func PatchTeachersDBHandler(ctx context.Context, updates []map[string]interface{}) error {
 	db, err := ConnectDB()
 	if err != nil {
 		return utils.ErrorHandler(err, "Database connection error")
 	}
-	defer db.Close()
 
 	// transaction
-	tx, err := db.Begin()
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
-		return utils.ErrorHandler(err, "Database error")
+		return dbError(err, "Database error")
 	}
 
 	for _, update := range updates {
@@ -141,33 +250,47 @@ func PatchTeachersDBHandler(updates []map[string]interface{}) error {
 		idStr, ok := update["id"].(string)
 		if !ok {
 			tx.Rollback()
-			return utils.ErrorHandler(err, "Invalid or missing ID in update object")
+			return utils.ValidationError("Invalid or missing ID in update object", utils.FieldError{Field: "id", Message: "must be a numeric string"})
 		}
 
 		id, err := strconv.Atoi(idStr)
 		if err != nil {
 			tx.Rollback()
-			return utils.ErrorHandler(err, "Error converting string to int")
+			return utils.ValidationError("Invalid ID in update object", utils.FieldError{Field: "id", Message: "must be a numeric string"})
 		}
 
+		version, err := versionFromUpdate(update)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		// lock the row so the version can't change between the check and the update
 		var teacherFromDb models.Teacher
-		err = db.QueryRow("SELECT id, first_name, last_name, email, class, subject FROM teachers WHERE id = ?", id).Scan(
-			&teacherFromDb.ID, &teacherFromDb.FirstName, &teacherFromDb.LastName, &teacherFromDb.Email, &teacherFromDb.Class, &teacherFromDb.Subject)
+		err = tx.QueryRowContext(ctx, "SELECT id, first_name, last_name, email, class, subject, version FROM teachers WHERE id = ? AND deleted_at IS NULL FOR UPDATE", id).Scan(
+			&teacherFromDb.ID, &teacherFromDb.FirstName, &teacherFromDb.LastName, &teacherFromDb.Email, &teacherFromDb.Class, &teacherFromDb.Subject, &teacherFromDb.Version)
 
 		if err == sql.ErrNoRows {
 			tx.Rollback()
-			return utils.ErrorHandler(err, "Teacher not found with ID "+strconv.Itoa(id))
+			return utils.NotFoundError(err, "Teacher not found with ID "+strconv.Itoa(id))
 		} else if err != nil {
 			tx.Rollback()
-			return utils.ErrorHandler(err, "Database error")
+			return dbError(err, "Database error")
+		}
+
+		err = checkVersion("Teacher", id, version, teacherFromDb.Version)
+		if err != nil {
+			tx.Rollback()
+			return err
 		}
 
 		// apply updates using reflect pkg
+		before := teacherFromDb
 		teacherVal := reflect.ValueOf(&teacherFromDb).Elem()
 		teacherType := teacherVal.Type()
 
 		for k, v := range update {
-			if k == "id" {
+			if k == "id" || k == "version" {
 				continue
 			}
 			for i := 0; i < teacherType.NumField(); i++ {
@@ -181,7 +304,7 @@ func PatchTeachersDBHandler(updates []map[string]interface{}) error {
 							fieldVal.Set(val.Convert(fieldVal.Type()))
 						} else {
 							tx.Rollback()
-							return utils.ErrorHandler(err, "Type mismatch for field "+k)
+							return utils.ValidationError("Type mismatch for field "+k, utils.FieldError{Field: k, Message: "has the wrong type"})
 						}
 					}
 					break
@@ -189,37 +312,64 @@ func PatchTeachersDBHandler(updates []map[string]interface{}) error {
 			}
 		}
 
-		_, err = tx.Exec("UPDATE teachers SET first_name = ?, last_name = ?, email = ?, class = ?, subject = ? WHERE id = ?",
+		_, err = tx.ExecContext(ctx, "UPDATE teachers SET first_name = ?, last_name = ?, email = ?, class = ?, subject = ?, version = version + 1 WHERE id = ? AND deleted_at IS NULL",
 			teacherFromDb.FirstName, teacherFromDb.LastName, teacherFromDb.Email, teacherFromDb.Class, teacherFromDb.Subject, teacherFromDb.ID)
 		if err != nil {
 			tx.Rollback()
-			return utils.ErrorHandler(err, "Database error")
+			return dbError(err, "Database error")
+		}
+
+		err = recordAudit(ctx, tx, changeEntry(models.AuditUpdate, "teachers", id, before, teacherFromDb))
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		err = recordHistory(ctx, tx, "teachers", id, models.AuditUpdate)
+		if err != nil {
+			tx.Rollback()
+			return err
 		}
 	}
 
 	err = tx.Commit()
 	if err != nil {
-		return utils.ErrorHandler(err, "Error committing transaction")
+		return dbError(err, "Error committing transaction")
 	}
 	return nil
 }
 
-func PatchOneTeacherDBHandler(id int, updates map[string]interface{}) (models.Teacher, error) {
+func PatchOneTeacherDBHandler(ctx context.Context, id, version int, updates map[string]interface{}) (models.Teacher, error) {
 	db, err := ConnectDB()
 	if err != nil {
 		return models.Teacher{}, utils.ErrorHandler(err, "Database connection error")
 	}
-	defer db.Close()
 
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Teacher{}, dbError(err, "Database error")
+	}
+
+	// lock the row so the version can't change between the check and the update
 	var existingTeacher models.Teacher
-	err = db.QueryRow("SELECT id, first_name, last_name, email, class, subject FROM teachers WHERE id = ?", id).Scan(
-		&existingTeacher.ID, &existingTeacher.FirstName, &existingTeacher.LastName, &existingTeacher.Email, &existingTeacher.Class, &existingTeacher.Subject)
+	err = tx.QueryRowContext(ctx, "SELECT id, first_name, last_name, email, class, subject, version FROM teachers WHERE id = ? AND deleted_at IS NULL FOR UPDATE", id).Scan(
+		&existingTeacher.ID, &existingTeacher.FirstName, &existingTeacher.LastName, &existingTeacher.Email, &existingTeacher.Class, &existingTeacher.Subject, &existingTeacher.Version)
 	if err == sql.ErrNoRows {
-		return models.Teacher{}, utils.ErrorHandler(err, "Teacher not found")
+		tx.Rollback()
+		return models.Teacher{}, utils.NotFoundError(err, "Teacher not found")
 	} else if err != nil {
-		return models.Teacher{}, utils.ErrorHandler(err, "Database error")
+		tx.Rollback()
+		return models.Teacher{}, dbError(err, "Database error")
 	}
 
+	err = checkVersion("Teacher", id, version, existingTeacher.Version)
+	if err != nil {
+		tx.Rollback()
+		return models.Teacher{}, err
+	}
+	currentVersion := existingTeacher.Version
+	before := existingTeacher
+
 	// apply updates
 	// for k, v := range updates {
 	// 	switch k {
@@ -244,6 +394,9 @@ func PatchOneTeacherDBHandler(id int, updates map[string]interface{}) (models.Te
 	teacherType := teacherVal.Type()
 
 	for k, v := range updates {
+		if k == "version" {
+			continue
+		}
 		for i := 0; i < teacherType.NumField(); i++ {
 			field := teacherType.Field(i)
 			jsonTag := field.Tag.Get("json")
@@ -259,128 +412,488 @@ func PatchOneTeacherDBHandler(id int, updates map[string]interface{}) (models.Te
 		}
 	}
 
-	_, err = db.Exec("UPDATE teachers SET first_name = ?, last_name = ?, email = ?, class = ?, subject = ? WHERE id = ?",
-		existingTeacher.FirstName, existingTeacher.LastName, existingTeacher.Email, existingTeacher.Class, existingTeacher.Subject, existingTeacher.ID)
+	res, err := tx.ExecContext(ctx, "UPDATE teachers SET first_name = ?, last_name = ?, email = ?, class = ?, subject = ?, version = version + 1 WHERE id = ? AND version = ? AND deleted_at IS NULL",
+		existingTeacher.FirstName, existingTeacher.LastName, existingTeacher.Email, existingTeacher.Class, existingTeacher.Subject, existingTeacher.ID, currentVersion)
+	if err != nil {
+		tx.Rollback()
+		return models.Teacher{}, dbError(err, "Database error")
+	}
+	err = checkVersionedWrite(res, "Teacher", id)
+	if err != nil {
+		tx.Rollback()
+		return models.Teacher{}, err
+	}
+
+	err = recordAudit(ctx, tx, changeEntry(models.AuditUpdate, "teachers", id, before, existingTeacher))
 	if err != nil {
-		return models.Teacher{}, utils.ErrorHandler(err, "Database error")
+		tx.Rollback()
+		return models.Teacher{}, err
 	}
 
+	err = recordHistory(ctx, tx, "teachers", id, models.AuditUpdate)
+	if err != nil {
+		tx.Rollback()
+		return models.Teacher{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.Teacher{}, dbError(err, "Error committing transaction")
+	}
+
+	existingTeacher.Version = currentVersion + 1
 	return existingTeacher, nil
 }
 
-func DeleteOneTeacherDBHandler(id int) error {
+func DeleteOneTeacherDBHandler(ctx context.Context, id, version int) error {
 	db, err := ConnectDB()
 	if err != nil {
 		return utils.ErrorHandler(err, "Database connection error")
 	}
-	defer db.Close()
 
-	res, err := db.Exec("DELETE FROM teachers WHERE id = ?", id)
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
-		return utils.ErrorHandler(err, "Database error")
+		return dbError(err, "Database error")
 	}
 
-	rowsAffected, err := res.RowsAffected()
+	// lock the row so the version can't change between the check and the update
+	var existingTeacher models.Teacher
+	err = tx.QueryRowContext(ctx, "SELECT id, first_name, last_name, email, class, subject, version FROM teachers WHERE id = ? AND deleted_at IS NULL FOR UPDATE", id).Scan(
+		&existingTeacher.ID, &existingTeacher.FirstName, &existingTeacher.LastName, &existingTeacher.Email, &existingTeacher.Class, &existingTeacher.Subject, &existingTeacher.Version)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return utils.NotFoundError(err, "Teacher not found")
+	} else if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+
+	err = checkVersion("Teacher", id, version, existingTeacher.Version)
 	if err != nil {
-		return utils.ErrorHandler(err, "Database error")
+		tx.Rollback()
+		return err
 	}
-	if rowsAffected == 0 {
-		return utils.ErrorHandler(err, "Teacher not found")
+
+	res, err := tx.ExecContext(ctx, "UPDATE teachers SET deleted_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id = ? AND version = ? AND deleted_at IS NULL", id, existingTeacher.Version)
+	if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+	err = checkVersionedWrite(res, "Teacher", id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = recordAudit(ctx, tx, changeEntry(models.AuditDelete, "teachers", id, existingTeacher, nil))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = recordHistory(ctx, tx, "teachers", id, models.AuditDelete)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return dbError(err, "Error committing transaction")
 	}
 	return nil
 }
 
-func DeleteTeachersDBHandler(ids []int) ([]int, error) {
+func DeleteTeachersDBHandler(ctx context.Context, ids []int) ([]int, error) {
 	db, err := ConnectDB()
 	if err != nil {
 		return nil, utils.ErrorHandler(err, "Database connection error")
 	}
-	defer db.Close()
 
-	tx, err := db.Begin()
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, utils.ErrorHandler(err, "Database error")
+		return nil, dbError(err, "Database error")
 	}
 
-	stmt, err := tx.Prepare("DELETE FROM teachers WHERE id = ?")
+	stmt, err := tx.PrepareContext(ctx, "UPDATE teachers SET deleted_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id = ? AND deleted_at IS NULL")
 	if err != nil {
 		tx.Rollback()
-		return nil, utils.ErrorHandler(err, "Database error")
+		return nil, dbError(err, "Database error")
 	}
 
 	deletedIds := []int{}
 
 	for _, id := range ids {
-		res, err := stmt.Exec(id)
+		var existingTeacher models.Teacher
+		err = tx.QueryRowContext(ctx, "SELECT id, first_name, last_name, email, class, subject FROM teachers WHERE id = ? AND deleted_at IS NULL FOR UPDATE", id).Scan(
+			&existingTeacher.ID, &existingTeacher.FirstName, &existingTeacher.LastName, &existingTeacher.Email, &existingTeacher.Class, &existingTeacher.Subject)
+		if err == sql.ErrNoRows {
+			tx.Rollback()
+			return nil, utils.NotFoundError(err, "Teacher not found with ID "+strconv.Itoa(id))
+		} else if err != nil {
+			tx.Rollback()
+			return nil, dbError(err, "Database error")
+		}
+
+		res, err := stmt.ExecContext(ctx, id)
 		if err != nil {
 			tx.Rollback()
-			return nil, utils.ErrorHandler(err, "Error deleteing teacher")
+			return nil, dbError(err, "Error deleteing teacher")
 		}
 
 		rowsAffected, err := res.RowsAffected()
 		if err != nil {
 			tx.Rollback()
-			return nil, utils.ErrorHandler(err, "Database error")
+			return nil, dbError(err, "Database error")
 		}
 		if rowsAffected > 0 {
 			deletedIds = append(deletedIds, id)
 		}
 		if rowsAffected < 1 {
 			tx.Rollback()
-			return nil, utils.ErrorHandler(err, "Teacher not found with ID "+strconv.Itoa(id))
+			return nil, utils.NotFoundError(err, "Teacher not found with ID "+strconv.Itoa(id))
+		}
+
+		err = recordAudit(ctx, tx, changeEntry(models.AuditDelete, "teachers", id, existingTeacher, nil))
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		err = recordHistory(ctx, tx, "teachers", id, models.AuditDelete)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
 		}
 	}
 
 	err = tx.Commit()
 	if err != nil {
-		return nil, utils.ErrorHandler(err, "Error committing transaction")
+		return nil, dbError(err, "Error committing transaction")
 	}
 
 	if len(deletedIds) < 1 {
-		return nil, utils.ErrorHandler(err, "No teachers found to delete")
+		return nil, utils.NotFoundError(err, "No teachers found to delete")
 	}
 	return deletedIds, nil
 }
 
-func GetStudentsByTeacherIdDBHandler(id int, students []models.Student) ([]models.Student, error){
+func RestoreTeacherDBHandler(ctx context.Context, id int) (models.Teacher, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return models.Teacher{}, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Teacher{}, dbError(err, "Database error")
+	}
+
+	res, err := tx.ExecContext(ctx, "UPDATE teachers SET deleted_at = NULL, version = version + 1 WHERE id = ? AND deleted_at IS NOT NULL", id)
+	if err != nil {
+		tx.Rollback()
+		return models.Teacher{}, dbError(err, "Database error")
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return models.Teacher{}, dbError(err, "Database error")
+	}
+	if rowsAffected == 0 {
+		// either there is no such teacher or it isn't deleted
+		var deleted bool
+		err = tx.QueryRowContext(ctx, "SELECT deleted_at IS NOT NULL FROM teachers WHERE id = ?", id).Scan(&deleted)
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return models.Teacher{}, utils.NotFoundError(err, "Teacher not found")
+		} else if err != nil {
+			return models.Teacher{}, dbError(err, "Database error")
+		}
+		return models.Teacher{}, utils.ConflictError(nil, "Teacher is not deleted")
+	}
+
+	err = recordAudit(ctx, tx, changeEntry(models.AuditRestore, "teachers", id, nil, nil))
+	if err != nil {
+		tx.Rollback()
+		return models.Teacher{}, err
+	}
+
+	err = recordHistory(ctx, tx, "teachers", id, models.AuditRestore)
+	if err != nil {
+		tx.Rollback()
+		return models.Teacher{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.Teacher{}, dbError(err, "Error committing transaction")
+	}
+
+	return GetOneTeacherDBHandler(ctx, id, false)
+}
+
+// GetStudentsByTeacherIdDBHandler lists the students of every class the teacher is assigned to,
+// or only of the classes the teacher teaches subject to when it isn't empty
+func GetStudentsByTeacherIdDBHandler(ctx context.Context, id int, subject string, students []models.Student) ([]models.Student, error){
 	db, err := ConnectDB()
 	if err != nil {
 		return nil, utils.ErrorHandler(err, "Database connection error")
 	}
-	defer db.Close()
 
-	query := "SELECT id, first_name, last_name, email ,class FROM students WHERE class = (SELECT class FROM teachers where id = ?)"
-	rows, err := db.Query(query, id)
+	filter, args := teacherAssignmentsFilter(id, subject)
+	query := "SELECT "+studentColumns+" FROM students WHERE deleted_at IS NULL" + filter
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, utils.ErrorHandler(err, "Database error")
+		return nil, dbError(err, "Database error")
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var student models.Student
-		err := rows.Scan(&student.ID, &student.FirstName, &student.LastName, &student.Email, &student.Class)
+		err := scanStudent(ctx, rows, &student)
 		if err != nil {
-			return nil, utils.ErrorHandler(err, "Database error")
+			return nil, dbError(err, "Database error")
 		}
+		hideSensitive(ctx, &student)
 		students = append(students, student)
 	}
 	return students, nil
 }
 
-func GetStudentsCountByTeacherIdDBHandler(teacherId string) (int, error) {
+// StreamStudentsByTeacherIdDBHandler calls each for every student of a teacher's classes, like
+// GetStudentsByTeacherIdDBHandler, narrowed down and sorted by the query parameters of r
+func StreamStudentsByTeacherIdDBHandler(r *http.Request, id int, subject string, each func(models.Student) error) error {
 	db, err := ConnectDB()
 	if err != nil {
-		return 0, utils.ErrorHandler(err, "Database connection error")
+		return utils.ErrorHandler(err, "Database connection error")
+	}
+
+	filter, args := teacherAssignmentsFilter(id, subject)
+	query := "SELECT "+studentColumns+" FROM students WHERE deleted_at IS NULL" + filter
+	query, args = utils.AddFilters(r, query, args, models.Student{})
+	query = utils.AddSorting(r, query, models.Student{})
+
+	rows, err := db.QueryContext(r.Context(), query, args...)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var student models.Student
+		err := scanStudent(r.Context(), rows, &student)
+		if err != nil {
+			return dbError(err, "Database error")
+		}
+		hideSensitive(r.Context(), &student)
+		err = each(student)
+		if err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return dbError(err, "Database error")
 	}
+	return nil
+}
 
-	defer db.Close()
+// GetStudentsCountByTeacherIdDBHandler counts the students GetStudentsByTeacherIdDBHandler lists
+func GetStudentsCountByTeacherIdDBHandler(ctx context.Context, teacherId int, subject string) (int, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return 0, utils.ErrorHandler(err, "Database connection error")
+	}
 
-	query := `SELECT COUNT(*) FROM students WHERE class = (SELECT class FROM teachers WHERE id = ?)`
+	filter, args := teacherAssignmentsFilter(teacherId, subject)
+	query := "SELECT COUNT(*) FROM students WHERE deleted_at IS NULL" + filter
 	var studentCount int
-	err = db.QueryRow(query, teacherId).Scan(&studentCount)
+	err = db.QueryRowContext(ctx, query, args...).Scan(&studentCount)
 	if err != nil {
-		return 0, utils.ErrorHandler(err, "Database error")
+		return 0, dbError(err, "Database error")
 	}
 
 	return studentCount, nil
-}
\ No newline at end of file
+}
+
+// GetTeacherHistoryDBHandler lists every recorded revision of a teacher, newest first
+func GetTeacherHistoryDBHandler(ctx context.Context, id int) ([]models.TeacherRevision, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT revision, operation, UNIX_TIMESTAMP(changed_at), changed_by, request_id,
+		teacher_id, first_name, last_name, email, class, subject, version, UNIX_TIMESTAMP(deleted_at)
+		FROM teacher_history WHERE teacher_id = ? ORDER BY revision DESC`, id)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	revisions := []models.TeacherRevision{}
+	for rows.Next() {
+		var rev models.TeacherRevision
+		var changedBy sql.NullInt64
+		var requestID sql.NullString
+		err := rows.Scan(&rev.Revision, &rev.Operation, &rev.ChangedAt, &changedBy, &requestID,
+			&rev.Teacher.ID, &rev.Teacher.FirstName, &rev.Teacher.LastName, &rev.Teacher.Email, &rev.Teacher.Class, &rev.Teacher.Subject, &rev.Teacher.Version, &rev.Teacher.DeletedAt)
+		if err != nil {
+			return nil, dbError(err, "Database error")
+		}
+		if changedBy.Valid {
+			actor := int(changedBy.Int64)
+			rev.ChangedBy = &actor
+		}
+		rev.RequestID = requestID.String
+		rev.Teacher.UpdatedAt = rev.ChangedAt
+		revisions = append(revisions, rev)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err, "Database error")
+	}
+
+	if len(revisions) == 0 {
+		return nil, utils.NotFoundError(nil, "No history found for teacher")
+	}
+	return revisions, nil
+}
+
+// GetTeacherAsOfDBHandler reconstructs a teacher as it was at asOf from its history
+func GetTeacherAsOfDBHandler(ctx context.Context, id int, asOf time.Time, includeDeleted bool) (models.Teacher, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return models.Teacher{}, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	var teacher models.Teacher
+	err = db.QueryRowContext(ctx, `SELECT teacher_id, first_name, last_name, email, class, subject, version, UNIX_TIMESTAMP(changed_at), UNIX_TIMESTAMP(deleted_at)
+		FROM teacher_history WHERE teacher_id = ? AND changed_at <= FROM_UNIXTIME(?) ORDER BY revision DESC LIMIT 1`, id, asOf.Unix()).Scan(
+		&teacher.ID, &teacher.FirstName, &teacher.LastName, &teacher.Email, &teacher.Class, &teacher.Subject, &teacher.Version, &teacher.UpdatedAt, &teacher.DeletedAt)
+	if err == sql.ErrNoRows {
+		return models.Teacher{}, utils.NotFoundError(err, "Teacher did not exist at that time")
+	} else if err != nil {
+		return models.Teacher{}, dbError(err, "Database error")
+	}
+
+	if teacher.DeletedAt != nil && !includeDeleted {
+		return models.Teacher{}, utils.NotFoundError(nil, "Teacher was deleted at that time")
+	}
+	return teacher, nil
+}
+
+// UpsertTeachersDBHandler inserts the teachers that don't exist yet and updates the ones that do,
+// matching them on the keys columns. The batch is all or nothing, and with dryRun nothing is kept.
+func UpsertTeachersDBHandler(ctx context.Context, teachers []models.Teacher, keys []string, dryRun bool) ([]models.Teacher, models.UpsertResult, error) {
+	var result models.UpsertResult
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, result, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, result, dbError(err, "Database error")
+	}
+
+	insertStmt, err := tx.PrepareContext(ctx, utils.GenerateInsertQuery("teachers", models.Teacher{}))
+	if err != nil {
+		tx.Rollback()
+		return nil, result, dbError(err, "Database error")
+	}
+	defer insertStmt.Close()
+
+	updateStmt, err := tx.PrepareContext(ctx, utils.GenerateUpdateQuery("teachers", models.Teacher{}))
+	if err != nil {
+		tx.Rollback()
+		return nil, result, dbError(err, "Database error")
+	}
+	defer updateStmt.Close()
+
+	saved := make([]models.Teacher, len(teachers))
+
+	for i, teacher := range teachers {
+		teacher.UpdatedAt, teacher.DeletedAt = nil, nil
+
+		filter, args := naturalKeyFilter(keys, teacher)
+		var existing models.Teacher
+		err = tx.QueryRowContext(ctx, "SELECT id, first_name, last_name, email, class, subject, version FROM teachers"+filter+" FOR UPDATE", args...).Scan(
+			&existing.ID, &existing.FirstName, &existing.LastName, &existing.Email, &existing.Class, &existing.Subject, &existing.Version)
+
+		switch {
+		case err == sql.ErrNoRows:
+			err = checkDeletedMatch(ctx, tx, "teachers", "Teacher", keys, teacher)
+			if err != nil {
+				tx.Rollback()
+				return nil, result, err
+			}
+			teacher.Version = 1
+			res, err := insertStmt.ExecContext(ctx, utils.GetStructValues(teacher)...)
+			if err != nil {
+				tx.Rollback()
+				return nil, result, dbError(err, "Database error")
+			}
+			lastID, err := res.LastInsertId()
+			if err != nil {
+				tx.Rollback()
+				return nil, result, dbError(err, "Database error")
+			}
+			teacher.ID = int(lastID)
+
+			err = recordAudit(ctx, tx, changeEntry(models.AuditCreate, "teachers", teacher.ID, nil, teacher))
+			if err == nil {
+				err = recordHistory(ctx, tx, "teachers", teacher.ID, models.AuditCreate)
+			}
+			if err != nil {
+				tx.Rollback()
+				return nil, result, err
+			}
+			result.Created++
+			result.Actions = append(result.Actions, models.UpsertCreated)
+
+		case err != nil:
+			tx.Rollback()
+			return nil, result, dbError(err, "Database error")
+
+		default:
+			teacher.ID = existing.ID
+			teacher.Version = existing.Version
+			if teacher == existing {
+				result.Unchanged++
+				result.Actions = append(result.Actions, models.UpsertUnchanged)
+				break
+			}
+
+			teacher.Version++
+			_, err = updateStmt.ExecContext(ctx, append(utils.GetStructValues(teacher), teacher.ID)...)
+			if err != nil {
+				tx.Rollback()
+				return nil, result, dbError(err, "Database error")
+			}
+
+			err = recordAudit(ctx, tx, changeEntry(models.AuditUpdate, "teachers", teacher.ID, existing, teacher))
+			if err == nil {
+				err = recordHistory(ctx, tx, "teachers", teacher.ID, models.AuditUpdate)
+			}
+			if err != nil {
+				tx.Rollback()
+				return nil, result, err
+			}
+			result.Updated++
+			result.Actions = append(result.Actions, models.UpsertUpdated)
+		}
+		saved[i] = teacher
+	}
+
+	if dryRun {
+		// report what would have happened without keeping any of it
+		tx.Rollback()
+		return saved, result, nil
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, models.UpsertResult{}, dbError(err, "Error committing transaction")
+	}
+	return saved, result, nil
+}
@@ -0,0 +1,775 @@
+package sqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+const termColumns = "t.id, t.academic_year_id, y.name, t.name, t.start_date, t.end_date"
+
+func scanTerm(row interface{ Scan(...any) error }, term *models.Term) error {
+	return row.Scan(&term.ID, &term.AcademicYearID, &term.AcademicYear, &term.Name, &term.StartDate, &term.EndDate)
+}
+
+// currentTermID returns the ID of the current term, the one set with SetCurrentTermDBHandler or else the
+// one today falls in, and 0 when there is none
+func currentTermID(ctx context.Context, q queryer) (int, error) {
+	var id int
+	err := q.QueryRowContext(ctx, `SELECT COALESCE(
+		(SELECT CAST(value AS UNSIGNED) FROM settings WHERE name = 'current_term_id'),
+		(SELECT id FROM terms WHERE CURDATE() BETWEEN start_date AND end_date ORDER BY start_date DESC LIMIT 1), 0)`).Scan(&id)
+	if err != nil {
+		return 0, dbError(err, "Database error")
+	}
+	return id, nil
+}
+
+// getTerm returns a term, found is false when there is no term with the ID
+// currentAcademicYear returns the name of the academic year of the current term, "" when there is none
+func currentAcademicYear(ctx context.Context, q queryer) (string, error) {
+	id, err := currentTermID(ctx, q)
+	if err != nil || id == 0 {
+		return "", err
+	}
+	term, _, err := getTerm(ctx, q, id)
+	return term.AcademicYear, err
+}
+
+func getTerm(ctx context.Context, q queryer, id int) (term models.Term, found bool, err error) {
+	err = scanTerm(q.QueryRowContext(ctx, "SELECT "+termColumns+" FROM terms t JOIN academic_years y ON y.id = t.academic_year_id WHERE t.id = ?", id), &term)
+	if err == sql.ErrNoRows {
+		return models.Term{}, false, nil
+	} else if err != nil {
+		return models.Term{}, false, dbError(err, "Database error")
+	}
+	currentID, err := currentTermID(ctx, q)
+	if err != nil {
+		return models.Term{}, false, err
+	}
+	term.Current = term.ID == currentID
+	return term, true, nil
+}
+
+// atIndex tags the field errors of a validation error with the index of the item of a batch they belong to
+func atIndex(err error, index int) error {
+	var appErr *utils.AppError
+	if errors.As(err, &appErr) {
+		utils.WithIndex(appErr.Fields, index)
+	}
+	return err
+}
+
+// termParam resolves the ?term= filter of a listing, the ID of a term or current. It is nil when value is empty.
+func termParam(ctx context.Context, q queryer, value string) (*models.Term, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	id, err := strconv.Atoi(value)
+	if value == "current" {
+		id, err = currentTermID(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		if id == 0 {
+			return nil, utils.ValidationError("No current term", utils.FieldError{Field: "term", Message: "there is no current term, set one under /terms/current"})
+		}
+	} else if err != nil {
+		return nil, utils.ValidationError("Invalid term", utils.FieldError{Field: "term", Message: "must be the ID of a term or current"})
+	}
+
+	term, found, err := getTerm(ctx, q, id)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, utils.ValidationError("Unknown term", utils.FieldError{Field: "term", Message: "must be the ID of an existing term"})
+	}
+	return &term, nil
+}
+
+// yearTerm returns the term of an academic year called name, a name that isn't one is reported on field
+func yearTerm(ctx context.Context, q queryer, academicYear, name, field string) (models.Term, error) {
+	var term models.Term
+	err := scanTerm(q.QueryRowContext(ctx, "SELECT "+termColumns+" FROM terms t JOIN academic_years y ON y.id = t.academic_year_id WHERE y.name = ? AND t.name = ?",
+		academicYear, name), &term)
+	if err == sql.ErrNoRows {
+		return models.Term{}, utils.ValidationError("Unknown term", utils.FieldError{Field: field, Message: "must be the name of a term of academic year " + academicYear})
+	} else if err != nil {
+		return models.Term{}, dbError(err, "Database error")
+	}
+	return term, nil
+}
+
+// checkAcademicYear reports an academic year that hasn't been added yet
+func checkAcademicYear(ctx context.Context, q queryer, name string) error {
+	var exists bool
+	err := q.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM academic_years WHERE name = ?)", name).Scan(&exists)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+	if !exists {
+		return utils.ValidationError("Unknown academic year", utils.FieldError{Field: "academic_year", Message: "academic year " + name + " does not exist, create it under /academicyears first"})
+	}
+	return nil
+}
+
+// GetAcademicYearsDBHandler lists the academic years, latest first, with their terms
+func GetAcademicYearsDBHandler(ctx context.Context) ([]models.AcademicYear, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT id, name, start_date, end_date FROM academic_years ORDER BY start_date DESC")
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	years := []models.AcademicYear{}
+	for rows.Next() {
+		var year models.AcademicYear
+		err := rows.Scan(&year.ID, &year.Name, &year.StartDate, &year.EndDate)
+		if err != nil {
+			return nil, dbError(err, "Database error")
+		}
+		years = append(years, year)
+	}
+	rows.Close()
+
+	terms, err := getTerms(ctx, db, "")
+	if err != nil {
+		return nil, err
+	}
+	for i := range years {
+		for _, term := range terms {
+			if term.AcademicYearID == years[i].ID {
+				years[i].Terms = append(years[i].Terms, term)
+			}
+		}
+	}
+	return years, nil
+}
+
+// GetOneAcademicYearDBHandler returns an academic year with its terms
+func GetOneAcademicYearDBHandler(ctx context.Context, id int) (models.AcademicYear, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return models.AcademicYear{}, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	var year models.AcademicYear
+	err = db.QueryRowContext(ctx, "SELECT id, name, start_date, end_date FROM academic_years WHERE id = ?", id).Scan(&year.ID, &year.Name, &year.StartDate, &year.EndDate)
+	if err == sql.ErrNoRows {
+		return models.AcademicYear{}, utils.NotFoundError(err, "Academic year not found")
+	} else if err != nil {
+		return models.AcademicYear{}, dbError(err, "Database error")
+	}
+
+	year.Terms, err = getTerms(ctx, db, year.Name)
+	if err != nil {
+		return models.AcademicYear{}, err
+	}
+	return year, nil
+}
+
+// checkYearDates rejects an academic year that overlaps another one
+func checkYearDates(ctx context.Context, q queryer, year models.AcademicYear) error {
+	var overlaps bool
+	err := q.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM academic_years WHERE id != ? AND start_date <= ? AND end_date >= ?)",
+		year.ID, year.EndDate, year.StartDate).Scan(&overlaps)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+	if overlaps {
+		return utils.ValidationError("Overlapping academic years", utils.FieldError{Field: "start_date", Message: "must not overlap another academic year"})
+	}
+	return nil
+}
+
+// AddAcademicYearsDBHandler adds academic years, the batch is all or nothing
+func AddAcademicYearsDBHandler(ctx context.Context, newYears []models.AcademicYear) ([]models.AcademicYear, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+
+	added := make([]models.AcademicYear, len(newYears))
+	for i, year := range newYears {
+		year.ID, year.Terms = 0, nil
+		err = checkYearDates(ctx, tx, year)
+		if err != nil {
+			tx.Rollback()
+			return nil, atIndex(err, i)
+		}
+
+		res, err := tx.ExecContext(ctx, "INSERT INTO academic_years (name, start_date, end_date) VALUES (?, ?, ?)", year.Name, year.StartDate, year.EndDate)
+		if isDuplicateEntry(err) {
+			tx.Rollback()
+			return nil, utils.ConflictError(err, "Academic year "+year.Name+" already exists")
+		} else if err != nil {
+			tx.Rollback()
+			return nil, dbError(err, "Database error")
+		}
+		lastID, err := res.LastInsertId()
+		if err != nil {
+			tx.Rollback()
+			return nil, dbError(err, "Database error")
+		}
+		year.ID = int(lastID)
+		added[i] = year
+
+		err = recordAudit(ctx, tx, changeEntry(models.AuditCreate, "academic_years", year.ID, nil, year))
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, dbError(err, "Error committing transaction")
+	}
+	return added, nil
+}
+
+// UpdateAcademicYearDBHandler moves the dates of an academic year, its terms must stay inside them.
+// The name can't be changed since classes refer to the year by it.
+func UpdateAcademicYearDBHandler(ctx context.Context, id int, updatedYear models.AcademicYear) (models.AcademicYear, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return models.AcademicYear{}, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.AcademicYear{}, dbError(err, "Database error")
+	}
+
+	var existing models.AcademicYear
+	err = tx.QueryRowContext(ctx, "SELECT id, name, start_date, end_date FROM academic_years WHERE id = ? FOR UPDATE", id).Scan(
+		&existing.ID, &existing.Name, &existing.StartDate, &existing.EndDate)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return models.AcademicYear{}, utils.NotFoundError(err, "Academic year not found")
+	} else if err != nil {
+		tx.Rollback()
+		return models.AcademicYear{}, dbError(err, "Database error")
+	}
+	if updatedYear.Name != existing.Name {
+		tx.Rollback()
+		return models.AcademicYear{}, utils.ValidationError("Academic years can't be renamed", utils.FieldError{Field: "name", Message: "must stay " + existing.Name})
+	}
+
+	updatedYear.ID, updatedYear.Terms = id, nil
+	err = checkYearDates(ctx, tx, updatedYear)
+	if err != nil {
+		tx.Rollback()
+		return models.AcademicYear{}, err
+	}
+	var outside bool
+	err = tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM terms WHERE academic_year_id = ? AND (start_date < ? OR end_date > ?))",
+		id, updatedYear.StartDate, updatedYear.EndDate).Scan(&outside)
+	if err != nil {
+		tx.Rollback()
+		return models.AcademicYear{}, dbError(err, "Database error")
+	}
+	if outside {
+		tx.Rollback()
+		return models.AcademicYear{}, utils.ValidationError("Terms outside of the academic year", utils.FieldError{Field: "start_date", Message: "must not leave any term of the year outside of it"})
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE academic_years SET start_date = ?, end_date = ? WHERE id = ?", updatedYear.StartDate, updatedYear.EndDate, id)
+	if err != nil {
+		tx.Rollback()
+		return models.AcademicYear{}, dbError(err, "Database error")
+	}
+	err = recordAudit(ctx, tx, changeEntry(models.AuditUpdate, "academic_years", id, existing, updatedYear))
+	if err != nil {
+		tx.Rollback()
+		return models.AcademicYear{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.AcademicYear{}, dbError(err, "Error committing transaction")
+	}
+	return GetOneAcademicYearDBHandler(ctx, id)
+}
+
+// DeleteAcademicYearDBHandler removes an academic year with its terms, it fails while classes belong to it
+func DeleteAcademicYearDBHandler(ctx context.Context, id int) error {
+	db, err := ConnectDB()
+	if err != nil {
+		return utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+
+	var existing models.AcademicYear
+	err = tx.QueryRowContext(ctx, "SELECT id, name, start_date, end_date FROM academic_years WHERE id = ? FOR UPDATE", id).Scan(
+		&existing.ID, &existing.Name, &existing.StartDate, &existing.EndDate)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return utils.NotFoundError(err, "Academic year not found")
+	} else if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+
+	var classes int
+	err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM classes WHERE academic_year = ?", existing.Name).Scan(&classes)
+	if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+	if classes > 0 {
+		tx.Rollback()
+		return utils.ConflictError(nil, "Academic year "+existing.Name+" still has "+strconv.Itoa(classes)+" classes")
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM academic_years WHERE id = ?", id)
+	if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+	err = recordAudit(ctx, tx, changeEntry(models.AuditDelete, "academic_years", id, existing, nil))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return dbError(err, "Error committing transaction")
+	}
+	return nil
+}
+
+// GetTermsDBHandler lists the terms by start date, optionally only those of an academic year
+func GetTermsDBHandler(ctx context.Context, academicYear string) ([]models.Term, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, utils.ErrorHandler(err, "Database connection error")
+	}
+	return getTerms(ctx, db, academicYear)
+}
+
+func getTerms(ctx context.Context, db *sql.DB, academicYear string) ([]models.Term, error) {
+	query := "SELECT " + termColumns + " FROM terms t JOIN academic_years y ON y.id = t.academic_year_id"
+	var args []any
+	if academicYear != "" {
+		query += " WHERE y.name = ?"
+		args = append(args, academicYear)
+	}
+	rows, err := db.QueryContext(ctx, query+" ORDER BY t.start_date", args...)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	terms := []models.Term{}
+	for rows.Next() {
+		var term models.Term
+		err := scanTerm(rows, &term)
+		if err != nil {
+			return nil, dbError(err, "Database error")
+		}
+		terms = append(terms, term)
+	}
+	rows.Close()
+
+	currentID, err := currentTermID(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	for i := range terms {
+		terms[i].Current = terms[i].ID == currentID
+	}
+	return terms, nil
+}
+
+// GetOneTermDBHandler returns a term
+func GetOneTermDBHandler(ctx context.Context, id int) (models.Term, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return models.Term{}, utils.ErrorHandler(err, "Database connection error")
+	}
+	return termByID(ctx, db, id)
+}
+
+// termByID returns a term, reporting a missing one as not found
+func termByID(ctx context.Context, q queryer, id int) (models.Term, error) {
+	term, found, err := getTerm(ctx, q, id)
+	if err != nil {
+		return models.Term{}, err
+	}
+	if !found {
+		return models.Term{}, utils.NotFoundError(nil, "Term not found")
+	}
+	return term, nil
+}
+
+// checkTermDates rejects a term outside of its academic year or overlapping another term of the year,
+// and fills in the name of its year
+func checkTermDates(ctx context.Context, q queryer, term *models.Term) error {
+	var start, end string
+	err := q.QueryRowContext(ctx, "SELECT name, start_date, end_date FROM academic_years WHERE id = ?", term.AcademicYearID).Scan(&term.AcademicYear, &start, &end)
+	if err == sql.ErrNoRows {
+		return utils.ValidationError("Unknown academic year", utils.FieldError{Field: "academic_year_id", Message: "must be the ID of an existing academic year"})
+	} else if err != nil {
+		return dbError(err, "Database error")
+	}
+	if term.StartDate < start || term.EndDate > end {
+		return utils.ValidationError("Term outside of its academic year", utils.FieldError{Field: "start_date", Message: "must be within " + start + " and " + end})
+	}
+
+	var overlaps bool
+	err = q.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM terms WHERE academic_year_id = ? AND id != ? AND start_date <= ? AND end_date >= ?)",
+		term.AcademicYearID, term.ID, term.EndDate, term.StartDate).Scan(&overlaps)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+	if overlaps {
+		return utils.ValidationError("Overlapping terms", utils.FieldError{Field: "start_date", Message: "must not overlap another term of the academic year"})
+	}
+	return nil
+}
+
+// AddTermsDBHandler adds terms to academic years, the batch is all or nothing
+func AddTermsDBHandler(ctx context.Context, newTerms []models.Term) ([]models.Term, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+
+	added := make([]models.Term, len(newTerms))
+	for i, term := range newTerms {
+		term.ID, term.Current = 0, false
+		err = checkTermDates(ctx, tx, &term)
+		if err != nil {
+			tx.Rollback()
+			return nil, atIndex(err, i)
+		}
+
+		res, err := tx.ExecContext(ctx, "INSERT INTO terms (academic_year_id, name, start_date, end_date) VALUES (?, ?, ?, ?)",
+			term.AcademicYearID, term.Name, term.StartDate, term.EndDate)
+		if isDuplicateEntry(err) {
+			tx.Rollback()
+			return nil, utils.ConflictError(err, "Academic year "+term.AcademicYear+" already has a term called "+term.Name)
+		} else if err != nil {
+			tx.Rollback()
+			return nil, dbError(err, "Database error")
+		}
+		lastID, err := res.LastInsertId()
+		if err != nil {
+			tx.Rollback()
+			return nil, dbError(err, "Database error")
+		}
+		term.ID = int(lastID)
+		added[i] = term
+
+		err = recordAudit(ctx, tx, changeEntry(models.AuditCreate, "terms", term.ID, nil, term))
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, dbError(err, "Error committing transaction")
+	}
+	return added, nil
+}
+
+// UpdateTermDBHandler moves the dates of a term. Its name and year can't be changed since assignments,
+// assessments and report cards refer to the term by them.
+func UpdateTermDBHandler(ctx context.Context, id int, updatedTerm models.Term) (models.Term, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return models.Term{}, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Term{}, dbError(err, "Database error")
+	}
+
+	existing, err := termByID(ctx, tx, id)
+	if err != nil {
+		tx.Rollback()
+		return models.Term{}, err
+	}
+	var fieldErrs []utils.FieldError
+	if updatedTerm.Name != existing.Name {
+		fieldErrs = append(fieldErrs, utils.FieldError{Field: "name", Message: "must stay " + existing.Name})
+	}
+	if updatedTerm.AcademicYearID != existing.AcademicYearID {
+		fieldErrs = append(fieldErrs, utils.FieldError{Field: "academic_year_id", Message: "must stay " + strconv.Itoa(existing.AcademicYearID)})
+	}
+	if len(fieldErrs) > 0 {
+		tx.Rollback()
+		return models.Term{}, utils.ValidationError("Terms can't be renamed or moved to another year", fieldErrs...)
+	}
+
+	updatedTerm.ID, updatedTerm.Current = id, existing.Current
+	err = checkTermDates(ctx, tx, &updatedTerm)
+	if err != nil {
+		tx.Rollback()
+		return models.Term{}, err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE terms SET start_date = ?, end_date = ? WHERE id = ?", updatedTerm.StartDate, updatedTerm.EndDate, id)
+	if err != nil {
+		tx.Rollback()
+		return models.Term{}, dbError(err, "Database error")
+	}
+	err = recordAudit(ctx, tx, changeEntry(models.AuditUpdate, "terms", id, existing, updatedTerm))
+	if err != nil {
+		tx.Rollback()
+		return models.Term{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.Term{}, dbError(err, "Error committing transaction")
+	}
+	return GetOneTermDBHandler(ctx, id)
+}
+
+// DeleteTermDBHandler removes a term nothing refers to yet
+func DeleteTermDBHandler(ctx context.Context, id int) error {
+	db, err := ConnectDB()
+	if err != nil {
+		return utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+
+	existing, err := termByID(ctx, tx, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var used bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM teaching_assignments a JOIN classes c ON c.id = a.class_id WHERE c.academic_year = ? AND a.term = ?)
+		OR EXISTS(SELECT 1 FROM assessments a JOIN classes c ON c.id = a.class_id WHERE c.academic_year = ? AND a.term = ?)`,
+		existing.AcademicYear, existing.Name, existing.AcademicYear, existing.Name).Scan(&used)
+	if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+	if used {
+		tx.Rollback()
+		return utils.ConflictError(nil, "Term "+existing.Name+" of "+existing.AcademicYear+" still has teaching assignments or assessments")
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM terms WHERE id = ?", id)
+	if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+	_, err = tx.ExecContext(ctx, "DELETE FROM settings WHERE name = 'current_term_id' AND value = ?", strconv.Itoa(id))
+	if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+	err = recordAudit(ctx, tx, changeEntry(models.AuditDelete, "terms", id, existing, nil))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return dbError(err, "Error committing transaction")
+	}
+	return nil
+}
+
+// GetCurrentTermDBHandler returns the current term, see currentTermID
+func GetCurrentTermDBHandler(ctx context.Context) (models.Term, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return models.Term{}, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	id, err := currentTermID(ctx, db)
+	if err != nil {
+		return models.Term{}, err
+	}
+	if id == 0 {
+		return models.Term{}, utils.NotFoundError(nil, "There is no current term")
+	}
+	return termByID(ctx, db, id)
+}
+
+// TermOrCurrentDBHandler returns the term name given in a request or, when it is empty, the name of
+// the current term. Without either the term is reported missing.
+func TermOrCurrentDBHandler(ctx context.Context, name string) (string, error) {
+	if name != "" {
+		return name, nil
+	}
+	db, err := ConnectDB()
+	if err != nil {
+		return "", utils.ErrorHandler(err, "Database connection error")
+	}
+
+	id, err := currentTermID(ctx, db)
+	if err != nil {
+		return "", err
+	}
+	term, found, err := getTerm(ctx, db, id)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", utils.ValidationError("Missing term", utils.FieldError{Field: "term", Message: "is required when there is no current term"})
+	}
+	return term.Name, nil
+}
+
+// SetCurrentTermDBHandler makes a term the current one, whatever the date
+func SetCurrentTermDBHandler(ctx context.Context, id int) (models.Term, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return models.Term{}, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Term{}, dbError(err, "Database error")
+	}
+
+	previousID, err := currentTermID(ctx, tx)
+	if err != nil {
+		tx.Rollback()
+		return models.Term{}, err
+	}
+	_, found, err := getTerm(ctx, tx, id)
+	if err != nil {
+		tx.Rollback()
+		return models.Term{}, err
+	}
+	if !found {
+		tx.Rollback()
+		return models.Term{}, utils.ValidationError("Unknown term", utils.FieldError{Field: "term_id", Message: "must be the ID of an existing term"})
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO settings (name, value) VALUES ('current_term_id', ?) ON DUPLICATE KEY UPDATE value = VALUES(value)", strconv.Itoa(id))
+	if err != nil {
+		tx.Rollback()
+		return models.Term{}, dbError(err, "Database error")
+	}
+	err = recordAudit(ctx, tx, models.AuditEntry{
+		Action:     models.AuditUpdate,
+		Resource:   "terms",
+		ResourceID: &id,
+		Changes:    map[string]models.AuditChange{"current_term_id": {From: previousID, To: id}},
+	})
+	if err != nil {
+		tx.Rollback()
+		return models.Term{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.Term{}, dbError(err, "Error committing transaction")
+	}
+	return GetOneTermDBHandler(ctx, id)
+}
+
+// recordEnrollment keeps the enrollments of a student in step with their class: moving to another class
+// ends the open enrollment today and starts one in the new class
+func recordEnrollment(ctx context.Context, exec execer, studentID, classID int) error {
+	_, err := exec.ExecContext(ctx, "UPDATE enrollments SET end_date = CURDATE() WHERE student_id = ? AND end_date IS NULL AND class_id != ?", studentID, classID)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+	_, err = exec.ExecContext(ctx, `INSERT INTO enrollments (student_id, class_id, start_date)
+		SELECT ?, ?, CURDATE() FROM DUAL WHERE NOT EXISTS (SELECT 1 FROM enrollments WHERE student_id = ? AND end_date IS NULL)`,
+		studentID, classID, studentID)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+	return nil
+}
+
+// GetStudentEnrollmentsDBHandler lists the classes a student has been in, latest first
+func GetStudentEnrollmentsDBHandler(ctx context.Context, studentID int) ([]models.Enrollment, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	var exists bool
+	err = db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM students WHERE id = ? AND deleted_at IS NULL)", studentID).Scan(&exists)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	if !exists {
+		return nil, utils.NotFoundError(nil, "Student not found")
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT e.id, e.student_id, e.class_id, c.name, c.academic_year, e.start_date, e.end_date, e.outcome
+		FROM enrollments e JOIN classes c ON c.id = e.class_id WHERE e.student_id = ? ORDER BY e.start_date DESC, e.id DESC`, studentID)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	enrollments := []models.Enrollment{}
+	for rows.Next() {
+		var e models.Enrollment
+		err := rows.Scan(&e.ID, &e.StudentID, &e.ClassID, &e.Class, &e.AcademicYear, &e.StartDate, &e.EndDate, &e.Outcome)
+		if err != nil {
+			return nil, dbError(err, "Database error")
+		}
+		enrollments = append(enrollments, e)
+	}
+	return enrollments, nil
+}
+
+// studentTermFilter narrows students down to those enrolled in a class of the term's academic year
+// at some point during the term
+func studentTermFilter(term *models.Term) (string, []any) {
+	if term == nil {
+		return "", nil
+	}
+	return ` AND id IN (SELECT e.student_id FROM enrollments e JOIN classes c ON c.id = e.class_id
+		WHERE c.academic_year = ? AND e.start_date <= ? AND (e.end_date IS NULL OR e.end_date >= ?))`,
+		[]any{term.AcademicYear, term.EndDate, term.StartDate}
+}
+
+// teacherTermFilter narrows teachers down to those assigned to a class of the term's academic year in the term
+func teacherTermFilter(term *models.Term) (string, []any) {
+	if term == nil {
+		return "", nil
+	}
+	return ` AND id IN (SELECT a.teacher_id FROM teaching_assignments a JOIN classes c ON c.id = a.class_id
+		WHERE c.academic_year = ? AND a.term = ?)`, []any{term.AcademicYear, term.Name}
+}
@@ -0,0 +1,522 @@
+package sqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/internal/models"
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+const slotQuery = `SELECT s.id, s.term_id, t.name, s.weekday, s.period, TIME_FORMAT(p.start_time, '%H:%i'), TIME_FORMAT(p.end_time, '%H:%i'),
+	s.class_id, c.name, s.subject, s.teacher_id, CONCAT(te.first_name, ' ', te.last_name), s.room_id, COALESCE(r.name, '')
+	FROM timetable_slots s JOIN terms t ON t.id = s.term_id JOIN periods p ON p.number = s.period JOIN classes c ON c.id = s.class_id
+	JOIN teachers te ON te.id = s.teacher_id LEFT JOIN rooms r ON r.id = s.room_id`
+
+func scanSlot(row interface{ Scan(...any) error }, slot *models.Slot) error {
+	return row.Scan(&slot.ID, &slot.TermID, &slot.Term, &slot.Weekday, &slot.Period, &slot.StartTime, &slot.EndTime,
+		&slot.ClassID, &slot.Class, &slot.Subject, &slot.TeacherID, &slot.TeacherName, &slot.RoomID, &slot.Room)
+}
+
+// GetPeriodsDBHandler lists the periods of the school day in order
+func GetPeriodsDBHandler(ctx context.Context) ([]models.Period, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, utils.ErrorHandler(err, "Database connection error")
+	}
+	return periods(ctx, db)
+}
+
+func periods(ctx context.Context, db *sql.DB) ([]models.Period, error) {
+	rows, err := db.QueryContext(ctx, "SELECT number, name, TIME_FORMAT(start_time, '%H:%i'), TIME_FORMAT(end_time, '%H:%i') FROM periods ORDER BY number")
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	periods := []models.Period{}
+	for rows.Next() {
+		var period models.Period
+		err := rows.Scan(&period.Number, &period.Name, &period.StartTime, &period.EndTime)
+		if err != nil {
+			return nil, dbError(err, "Database error")
+		}
+		periods = append(periods, period)
+	}
+	return periods, nil
+}
+
+// ReplacePeriodsDBHandler replaces the periods of the school day, a period the timetable still uses
+// can't be left out
+func ReplacePeriodsDBHandler(ctx context.Context, newPeriods []models.Period) error {
+	db, err := ConnectDB()
+	if err != nil {
+		return utils.ErrorHandler(err, "Database connection error")
+	}
+
+	before, err := periods(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+
+	kept := []any{0}
+	for _, period := range newPeriods {
+		kept = append(kept, period.Number)
+	}
+	var used sql.NullInt64
+	err = tx.QueryRowContext(ctx, "SELECT MIN(period) FROM timetable_slots WHERE period NOT IN (?"+strings.Repeat(", ?", len(kept)-1)+")", kept...).Scan(&used)
+	if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+	if used.Valid {
+		tx.Rollback()
+		return utils.ConflictError(nil, fmt.Sprintf("Period %d is used by the timetable, move or delete its slots first", used.Int64))
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM periods")
+	if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+	for _, period := range newPeriods {
+		_, err = tx.ExecContext(ctx, "INSERT INTO periods (number, name, start_time, end_time) VALUES (?, ?, ?, ?)",
+			period.Number, period.Name, period.StartTime, period.EndTime)
+		if err != nil {
+			tx.Rollback()
+			return dbError(err, "Database error")
+		}
+	}
+
+	err = recordAudit(ctx, tx, models.AuditEntry{
+		Action:   models.AuditUpdate,
+		Resource: "periods",
+		Changes:  map[string]models.AuditChange{"periods": {From: before, To: newPeriods}},
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return dbError(err, "Error committing transaction")
+	}
+	return nil
+}
+
+// GetRoomsDBHandler lists the rooms by name
+func GetRoomsDBHandler(ctx context.Context) ([]models.Room, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT id, name, capacity FROM rooms ORDER BY name")
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	rooms := []models.Room{}
+	for rows.Next() {
+		var room models.Room
+		err := rows.Scan(&room.ID, &room.Name, &room.Capacity)
+		if err != nil {
+			return nil, dbError(err, "Database error")
+		}
+		rooms = append(rooms, room)
+	}
+	return rooms, nil
+}
+
+// AddRoomsDBHandler adds rooms, the batch is all or nothing
+func AddRoomsDBHandler(ctx context.Context, newRooms []models.Room) ([]models.Room, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+
+	added := make([]models.Room, len(newRooms))
+	for i, room := range newRooms {
+		res, err := tx.ExecContext(ctx, "INSERT INTO rooms (name, capacity) VALUES (?, ?)", room.Name, room.Capacity)
+		if isDuplicateEntry(err) {
+			tx.Rollback()
+			return nil, utils.ConflictError(err, "Room "+room.Name+" already exists")
+		} else if err != nil {
+			tx.Rollback()
+			return nil, dbError(err, "Database error")
+		}
+		lastID, err := res.LastInsertId()
+		if err != nil {
+			tx.Rollback()
+			return nil, dbError(err, "Database error")
+		}
+		room.ID = int(lastID)
+		added[i] = room
+
+		err = recordAudit(ctx, tx, changeEntry(models.AuditCreate, "rooms", room.ID, nil, room))
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, dbError(err, "Error committing transaction")
+	}
+	return added, nil
+}
+
+// DeleteRoomDBHandler removes a room the timetable doesn't use
+func DeleteRoomDBHandler(ctx context.Context, id int) error {
+	db, err := ConnectDB()
+	if err != nil {
+		return utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+
+	var room models.Room
+	err = tx.QueryRowContext(ctx, "SELECT id, name, capacity FROM rooms WHERE id = ? FOR UPDATE", id).Scan(&room.ID, &room.Name, &room.Capacity)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return utils.NotFoundError(err, "Room not found")
+	} else if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+
+	var used bool
+	err = tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM timetable_slots WHERE room_id = ?)", id).Scan(&used)
+	if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+	if used {
+		tx.Rollback()
+		return utils.ConflictError(nil, "Room "+room.Name+" is used by the timetable, move or delete its slots first")
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM rooms WHERE id = ?", id)
+	if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+	err = recordAudit(ctx, tx, changeEntry(models.AuditDelete, "rooms", id, room, nil))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return dbError(err, "Error committing transaction")
+	}
+	return nil
+}
+
+// GetTimetableDBHandler lists the slots of a term, the current one unless termValue is the ID of another,
+// by weekday and period. A class, teacher or room other than 0 narrows them down to theirs.
+func GetTimetableDBHandler(ctx context.Context, termValue string, classID, teacherID, roomID int) (models.Term, []models.Slot, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return models.Term{}, nil, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	if termValue == "" {
+		termValue = "current"
+	}
+	term, err := termParam(ctx, db, termValue)
+	if err != nil {
+		return models.Term{}, nil, err
+	}
+
+	query := slotQuery + " WHERE s.term_id = ?"
+	args := []any{term.ID}
+	for column, id := range map[string]int{"s.class_id": classID, "s.teacher_id": teacherID, "s.room_id": roomID} {
+		if id != 0 {
+			query += " AND " + column + " = ?"
+			args = append(args, id)
+		}
+	}
+	query += " ORDER BY s.weekday, s.period, c.grade, c.section"
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return models.Term{}, nil, dbError(err, "Database error")
+	}
+	defer rows.Close()
+
+	slots := []models.Slot{}
+	for rows.Next() {
+		var slot models.Slot
+		err := scanSlot(rows, &slot)
+		if err != nil {
+			return models.Term{}, nil, dbError(err, "Database error")
+		}
+		slots = append(slots, slot)
+	}
+	return *term, slots, nil
+}
+
+// GetClassTimetableDBHandler is the weekly timetable of a class in a term, see GetTimetableDBHandler
+func GetClassTimetableDBHandler(ctx context.Context, classID int, termValue string) (models.Term, []models.Slot, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return models.Term{}, nil, utils.ErrorHandler(err, "Database connection error")
+	}
+	err = checkClassExists(ctx, db, classID)
+	if err != nil {
+		return models.Term{}, nil, err
+	}
+	return GetTimetableDBHandler(ctx, termValue, classID, 0, 0)
+}
+
+// GetTeacherTimetableDBHandler is the weekly timetable of a teacher in a term, see GetTimetableDBHandler
+func GetTeacherTimetableDBHandler(ctx context.Context, teacherID int, termValue string) (models.Term, []models.Slot, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return models.Term{}, nil, utils.ErrorHandler(err, "Database connection error")
+	}
+	err = checkTeacherExists(ctx, db, teacherID)
+	if err != nil {
+		return models.Term{}, nil, err
+	}
+	return GetTimetableDBHandler(ctx, termValue, 0, teacherID, 0)
+}
+
+// GetOneSlotDBHandler returns a slot of the timetable
+func GetOneSlotDBHandler(ctx context.Context, id int) (models.Slot, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return models.Slot{}, utils.ErrorHandler(err, "Database connection error")
+	}
+	return slotByID(ctx, db, id)
+}
+
+func slotByID(ctx context.Context, q queryer, id int) (models.Slot, error) {
+	var slot models.Slot
+	err := scanSlot(q.QueryRowContext(ctx, slotQuery+" WHERE s.id = ?", id), &slot)
+	if err == sql.ErrNoRows {
+		return models.Slot{}, utils.NotFoundError(err, "Slot not found")
+	} else if err != nil {
+		return models.Slot{}, dbError(err, "Database error")
+	}
+	return slot, nil
+}
+
+// checkSlot validates what a slot refers to: a term of its class's academic year, a period, a room
+// and a teacher assigned to the subject in the class for the term. It rejects a slot that double-books
+// its teacher, class or room and fills in the names of what the slot refers to.
+func checkSlot(ctx context.Context, q queryer, slot *models.Slot) error {
+	term, found, err := getTerm(ctx, q, slot.TermID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return utils.ValidationError("Unknown term", utils.FieldError{Field: "term_id", Message: "must be the ID of an existing term"})
+	}
+	slot.Term = term.Name
+
+	var academicYear string
+	err = q.QueryRowContext(ctx, "SELECT name, academic_year FROM classes WHERE id = ?", slot.ClassID).Scan(&slot.Class, &academicYear)
+	if err == sql.ErrNoRows {
+		return utils.ValidationError("Unknown class", utils.FieldError{Field: "class_id", Message: "must be the ID of an existing class"})
+	} else if err != nil {
+		return dbError(err, "Database error")
+	}
+	if academicYear != term.AcademicYear {
+		return utils.ValidationError("Term of another academic year", utils.FieldError{Field: "term_id", Message: fmt.Sprintf("must be a term of %s, the academic year of class %s", academicYear, slot.Class)})
+	}
+
+	err = q.QueryRowContext(ctx, "SELECT TIME_FORMAT(start_time, '%H:%i'), TIME_FORMAT(end_time, '%H:%i') FROM periods WHERE number = ?", slot.Period).Scan(&slot.StartTime, &slot.EndTime)
+	if err == sql.ErrNoRows {
+		return utils.ValidationError("Unknown period", utils.FieldError{Field: "period", Message: "must be a period of the school day, see /timetable/periods"})
+	} else if err != nil {
+		return dbError(err, "Database error")
+	}
+
+	slot.Room = ""
+	if slot.RoomID != nil {
+		err = q.QueryRowContext(ctx, "SELECT name FROM rooms WHERE id = ?", *slot.RoomID).Scan(&slot.Room)
+		if err == sql.ErrNoRows {
+			return utils.ValidationError("Unknown room", utils.FieldError{Field: "room_id", Message: "must be the ID of an existing room"})
+		} else if err != nil {
+			return dbError(err, "Database error")
+		}
+	}
+
+	err = q.QueryRowContext(ctx, `SELECT CONCAT(t.first_name, ' ', t.last_name) FROM teaching_assignments a JOIN teachers t ON t.id = a.teacher_id
+		WHERE a.teacher_id = ? AND a.class_id = ? AND a.subject = ? AND a.term = ? AND t.deleted_at IS NULL`,
+		slot.TeacherID, slot.ClassID, slot.Subject, slot.Term).Scan(&slot.TeacherName)
+	if err == sql.ErrNoRows {
+		return utils.ValidationError("Teacher not assigned", utils.FieldError{Field: "teacher_id",
+			Message: fmt.Sprintf("must be assigned to teach %s to %s in %s, see /teachers/{id}/assignments", slot.Subject, slot.Class, slot.Term)})
+	} else if err != nil {
+		return dbError(err, "Database error")
+	}
+
+	var other models.Slot
+	err = q.QueryRowContext(ctx, `SELECT id, teacher_id, class_id, room_id FROM timetable_slots
+		WHERE term_id = ? AND weekday = ? AND period = ? AND id != ? AND (teacher_id = ? OR class_id = ? OR room_id = ?) LIMIT 1`,
+		slot.TermID, slot.Weekday, slot.Period, slot.ID, slot.TeacherID, slot.ClassID, slot.RoomID).Scan(&other.ID, &other.TeacherID, &other.ClassID, &other.RoomID)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return dbError(err, "Database error")
+	}
+	when := fmt.Sprintf("on %s in period %d", models.WeekdayName(slot.Weekday), slot.Period)
+	switch {
+	case other.TeacherID == slot.TeacherID:
+		return utils.ConflictError(nil, fmt.Sprintf("%s already teaches %s, see slot %d", slot.TeacherName, when, other.ID))
+	case other.ClassID == slot.ClassID:
+		return utils.ConflictError(nil, fmt.Sprintf("Class %s already has a lesson %s, see slot %d", slot.Class, when, other.ID))
+	default:
+		return utils.ConflictError(nil, fmt.Sprintf("Room %s is already taken %s, see slot %d", slot.Room, when, other.ID))
+	}
+}
+
+// AddSlotsDBHandler adds lessons to the timetable, the batch is all or nothing so it can't double-book
+// within itself either
+func AddSlotsDBHandler(ctx context.Context, newSlots []models.Slot) ([]models.Slot, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return nil, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, dbError(err, "Database error")
+	}
+
+	added := make([]models.Slot, len(newSlots))
+	for i, slot := range newSlots {
+		slot.ID = 0
+		err = checkSlot(ctx, tx, &slot)
+		if err != nil {
+			tx.Rollback()
+			return nil, atIndex(err, i)
+		}
+
+		res, err := tx.ExecContext(ctx, "INSERT INTO timetable_slots (term_id, weekday, period, class_id, subject, teacher_id, room_id) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			slot.TermID, slot.Weekday, slot.Period, slot.ClassID, slot.Subject, slot.TeacherID, slot.RoomID)
+		if err != nil {
+			tx.Rollback()
+			return nil, dbError(err, "Database error")
+		}
+		lastID, err := res.LastInsertId()
+		if err != nil {
+			tx.Rollback()
+			return nil, dbError(err, "Database error")
+		}
+		slot.ID = int(lastID)
+		added[i] = slot
+
+		err = recordAudit(ctx, tx, changeEntry(models.AuditCreate, "timetable_slots", slot.ID, nil, slot))
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, dbError(err, "Error committing transaction")
+	}
+	return added, nil
+}
+
+// UpdateSlotDBHandler replaces a slot of the timetable, e.g. to move a lesson, with the checks of a new one
+func UpdateSlotDBHandler(ctx context.Context, id int, updatedSlot models.Slot) (models.Slot, error) {
+	db, err := ConnectDB()
+	if err != nil {
+		return models.Slot{}, utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Slot{}, dbError(err, "Database error")
+	}
+
+	existing, err := slotByID(ctx, tx, id)
+	if err != nil {
+		tx.Rollback()
+		return models.Slot{}, err
+	}
+	updatedSlot.ID = id
+	err = checkSlot(ctx, tx, &updatedSlot)
+	if err != nil {
+		tx.Rollback()
+		return models.Slot{}, err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE timetable_slots SET term_id = ?, weekday = ?, period = ?, class_id = ?, subject = ?, teacher_id = ?, room_id = ? WHERE id = ?",
+		updatedSlot.TermID, updatedSlot.Weekday, updatedSlot.Period, updatedSlot.ClassID, updatedSlot.Subject, updatedSlot.TeacherID, updatedSlot.RoomID, id)
+	if err != nil {
+		tx.Rollback()
+		return models.Slot{}, dbError(err, "Database error")
+	}
+	err = recordAudit(ctx, tx, changeEntry(models.AuditUpdate, "timetable_slots", id, existing, updatedSlot))
+	if err != nil {
+		tx.Rollback()
+		return models.Slot{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.Slot{}, dbError(err, "Error committing transaction")
+	}
+	return updatedSlot, nil
+}
+
+// DeleteSlotDBHandler removes a lesson from the timetable
+func DeleteSlotDBHandler(ctx context.Context, id int) error {
+	db, err := ConnectDB()
+	if err != nil {
+		return utils.ErrorHandler(err, "Database connection error")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+
+	existing, err := slotByID(ctx, tx, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "DELETE FROM timetable_slots WHERE id = ?", id)
+	if err != nil {
+		tx.Rollback()
+		return dbError(err, "Database error")
+	}
+	err = recordAudit(ctx, tx, changeEntry(models.AuditDelete, "timetable_slots", id, existing, nil))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return dbError(err, "Error committing transaction")
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package sqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+// uniqueColumns are the columns of each table with a unique index. The indexes cover deleted
+// rows too, so a deleted record still holds its values until it is purged.
+var uniqueColumns = map[string][]string{
+	"students": {"email", "student_number"},
+	"teachers": {"email"},
+}
+
+// naturalKeyFilter matches the live row that has the same values as record in the key columns,
+// which must have been checked with utils.IsModelColumn
+func naturalKeyFilter(keys []string, record interface{}) (string, []any) {
+	conditions, args := keyConditions(keys, record)
+	return " WHERE deleted_at IS NULL AND " + conditions, args
+}
+
+func keyConditions(keys []string, record interface{}) (string, []any) {
+	conditions := make([]string, len(keys))
+	var args []any
+	for i, key := range keys {
+		conditions[i] = key + " = ?"
+		args = append(args, utils.GetColumnValue(record, key))
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// checkDeletedMatch looks for a deleted row of table that record would be inserted over, one with
+// the same values in the key columns or in a unique column. Inserting it would fail on the unique
+// index, so the caller is told which record to restore or purge instead.
+func checkDeletedMatch(ctx context.Context, q queryer, table, resource string, keys []string, record interface{}) error {
+	conditions, args := keyConditions(keys, record)
+	for _, column := range uniqueColumns[table] {
+		conditions += " OR " + column + " = ?"
+		args = append(args, utils.GetColumnValue(record, column))
+	}
+
+	var id int
+	err := q.QueryRowContext(ctx, "SELECT id FROM "+table+" WHERE deleted_at IS NOT NULL AND ("+conditions+") LIMIT 1", args...).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return dbError(err, "Database error")
+	}
+	return utils.ConflictError(nil, fmt.Sprintf("%s %d was deleted but still holds these values, restore it with POST /%s/%d/restore or purge it first", resource, id, table, id))
+}
@@ -0,0 +1,48 @@
+package sqlconnect
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/aayushxrj/go-rest-api-school-mgmt/pkg/utils"
+)
+
+// checkVersion rejects a write when the client's version is not the current version of the row
+func checkVersion(resource string, id, expected, current int) error {
+	if expected == utils.AnyVersion || expected == current {
+		return nil
+	}
+	return utils.PreconditionFailedError(fmt.Sprintf("%s with ID %d has been modified, current version is %d", resource, id, current))
+}
+
+// checkVersionedWrite reports a lost race when an UPDATE or DELETE guarded by
+// "AND version = ?" matched no rows because another request changed the row first
+func checkVersionedWrite(res sql.Result, resource string, id int) error {
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return dbError(err, "Database error")
+	}
+	if rowsAffected == 0 {
+		return utils.PreconditionFailedError(fmt.Sprintf("%s with ID %d was modified by another request", resource, id))
+	}
+	return nil
+}
+
+// versionFromUpdate reads the version an item of a bulk patch was based on
+func versionFromUpdate(update map[string]interface{}) (int, error) {
+	var version int
+	switch v := update["version"].(type) {
+	case float64:
+		version = int(v)
+		if float64(version) != v {
+			version = 0
+		}
+	case string:
+		version, _ = strconv.Atoi(v)
+	}
+	if version < 1 {
+		return 0, utils.ValidationError("Invalid or missing version in update object", utils.FieldError{Field: "version", Message: "must be the version of the record being updated"})
+	}
+	return version, nil
+}
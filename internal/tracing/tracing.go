@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+const defaultServiceName = "school-mgmt-api"
+
+// Init configures the global tracer provider and W3C trace context propagation.
+// Spans are exported over OTLP/HTTP to OTEL_EXPORTER_OTLP_ENDPOINT (e.g. http://localhost:4318).
+// When the endpoint is unset or TRACING_ENABLED=false, spans are not exported but
+// incoming traceparent headers are still propagated.
+// The returned function flushes pending spans and must be called on shutdown.
+func Init(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if os.Getenv("TRACING_ENABLED") == "false" || os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" {
+		fmt.Println("Tracing exporter disabled")
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	fmt.Println("Tracing exporter enabled for", serviceName)
+	return provider.Shutdown, nil
+}
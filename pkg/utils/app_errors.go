@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrorKind classifies domain errors so they can be mapped to HTTP status codes in one place
+type ErrorKind int
+
+const (
+	KindInternal ErrorKind = iota
+	KindValidation
+	KindNotFound
+	KindConflict
+	KindUnauthorized
+	KindForbidden
+	KindBadRequest
+	KindPreconditionFailed
+	KindPreconditionRequired
+	KindUnsupportedMediaType
+)
+
+// FieldError describes why a single field of a request was rejected.
+// Index is set for errors of items in bulk payloads, Row for errors of rows in imported files.
+type FieldError struct {
+	Index   *int   `json:"index,omitempty"`
+	Row     *int   `json:"row,omitempty"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// AppError is a typed error returned from the repository layer and handlers.
+// Message is safe to show to clients, Err keeps the underlying cause for logging.
+type AppError struct {
+	Kind    ErrorKind
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+func (e *AppError) Error() string {
+	return e.Message
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// Status returns the HTTP status code for the kind of error
+func (e *AppError) Status() int {
+	switch e.Kind {
+	case KindValidation:
+		return http.StatusUnprocessableEntity
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindBadRequest:
+		return http.StatusBadRequest
+	case KindPreconditionFailed:
+		return http.StatusPreconditionFailed
+	case KindPreconditionRequired:
+		return http.StatusPreconditionRequired
+	case KindUnsupportedMediaType:
+		return http.StatusUnsupportedMediaType
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func newAppError(kind ErrorKind, err error, message string, fields []FieldError) error {
+	if err == nil {
+		err = errors.New(message)
+	}
+	ErrorHandler(err, message)
+	return &AppError{Kind: kind, Message: message, Fields: fields, Err: err}
+}
+
+// NotFoundError reports that the requested resource does not exist
+func NotFoundError(err error, message string) error {
+	return newAppError(KindNotFound, err, message, nil)
+}
+
+// ConflictError reports that the request conflicts with the current state, e.g. a duplicate email
+func ConflictError(err error, message string) error {
+	return newAppError(KindConflict, err, message, nil)
+}
+
+// ValidationError reports invalid input, optionally with the offending fields
+func ValidationError(message string, fields ...FieldError) error {
+	return newAppError(KindValidation, nil, message, fields)
+}
+
+// BadRequestError reports a request that is malformed rather than invalid, e.g. an unknown field
+func BadRequestError(message string) error {
+	return newAppError(KindBadRequest, nil, message, nil)
+}
+
+// UnauthorizedError reports missing or invalid credentials
+func UnauthorizedError(message string) error {
+	return newAppError(KindUnauthorized, nil, message, nil)
+}
+
+// ForbiddenError reports that the authenticated user may not perform the action
+func ForbiddenError(message string) error {
+	return newAppError(KindForbidden, nil, message, nil)
+}
+
+// PreconditionFailedError reports that the resource changed since the client last read it
+func PreconditionFailedError(message string) error {
+	return newAppError(KindPreconditionFailed, nil, message, nil)
+}
+
+// PreconditionRequiredError reports that a conditional request header like If-Match is missing
+func PreconditionRequiredError(message string) error {
+	return newAppError(KindPreconditionRequired, nil, message, nil)
+}
+
+// UnsupportedMediaTypeError reports a request body in a format the endpoint doesn't accept
+func UnsupportedMediaTypeError(message string) error {
+	return newAppError(KindUnsupportedMediaType, nil, message, nil)
+}
@@ -1,7 +1,5 @@
 package utils
 
-import "errors"
-
 type ContextKey string
 
 func AuthorizeUser(userRole string, allowedRoles ...string) (bool, error) {
@@ -11,5 +9,5 @@ func AuthorizeUser(userRole string, allowedRoles ...string) (bool, error) {
 		}
 	}
 
-	return false, errors.New("user not authorized")
-}
\ No newline at end of file
+	return false, ForbiddenError("user not authorized")
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Bulk request modes, chosen with ?mode=
+const (
+	// BulkModeAtomic applies every item or none of them
+	BulkModeAtomic = "atomic"
+	// BulkModePartial applies each item on its own and reports a result per item
+	BulkModePartial = "partial"
+)
+
+// BulkItemResult is the outcome of one item of a bulk request in partial mode
+type BulkItemResult struct {
+	Index  int      `json:"index"`
+	ID     int      `json:"id,omitempty"`
+	Status int      `json:"status"`
+	Error  *Problem `json:"error,omitempty"`
+}
+
+// BulkMode reads the ?mode= of a bulk request, atomic unless partial is asked for
+func BulkMode(r *http.Request) (string, error) {
+	switch mode := r.URL.Query().Get("mode"); mode {
+	case "", BulkModeAtomic:
+		return BulkModeAtomic, nil
+	case BulkModePartial:
+		return BulkModePartial, nil
+	default:
+		return "", ValidationError("Invalid mode", FieldError{Field: "mode", Message: "must be one of atomic, partial"})
+	}
+}
+
+// BulkItemSuccess is the result of an item that was applied
+func BulkItemSuccess(index, id, status int) BulkItemResult {
+	return BulkItemResult{Index: index, ID: id, Status: status}
+}
+
+// BulkItemFailure is the result of an item that was rejected with err
+func BulkItemFailure(index, id int, err error) BulkItemResult {
+	problem := errorProblem(err)
+	return BulkItemResult{Index: index, ID: id, Status: problem.Status, Error: &problem}
+}
+
+// WriteBulkResults writes the per-item results of a partial bulk request as a 207 Multi-Status
+func WriteBulkResults(w http.ResponseWriter, results []BulkItemResult) {
+	succeeded := 0
+	for _, result := range results {
+		if result.Error == nil {
+			succeeded++
+		}
+	}
+
+	response := struct {
+		Status    string           `json:"status"`
+		Succeeded int              `json:"succeeded"`
+		Failed    int              `json:"failed"`
+		Results   []BulkItemResult `json:"results"`
+	}{
+		Status:    "partial",
+		Succeeded: succeeded,
+		Failed:    len(results) - succeeded,
+		Results:   results,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusMultiStatus)
+	json.NewEncoder(w).Encode(response)
+}
@@ -27,6 +27,40 @@ func GenerateInsertQuery(tableName string, model interface{}) string {
 	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", tableName, columns, placeholders)
 }
 
+// GenerateUpdateQuery builds an UPDATE of every column of model by id, its values are
+// GetStructValues(model) followed by the id
+func GenerateUpdateQuery(tableName string, model interface{}) string {
+	modelType := reflect.TypeOf(model)
+	var assignments string
+	for i := 0; i < modelType.NumField(); i++ {
+		dbTag := strings.TrimSuffix(modelType.Field(i).Tag.Get("db"), ",omitempty")
+		if dbTag != "" && dbTag != "id" {
+			if assignments != "" {
+				assignments += ", "
+			}
+			assignments += dbTag + " = ?"
+		}
+	}
+	return fmt.Sprintf("UPDATE %s SET %s WHERE id = ?", tableName, assignments)
+}
+
+// IsModelColumn reports whether column is a database column of model
+func IsModelColumn(column string, model interface{}) bool {
+	return isValidSortField(column, model)
+}
+
+// GetColumnValue returns the value of the field of model stored in column
+func GetColumnValue(model interface{}, column string) interface{} {
+	modelValue := reflect.ValueOf(model)
+	modelType := modelValue.Type()
+	for i := 0; i < modelType.NumField(); i++ {
+		if strings.TrimSuffix(modelType.Field(i).Tag.Get("db"), ",omitempty") == column {
+			return modelValue.Field(i).Interface()
+		}
+	}
+	return nil
+}
+
 func GetStructValues(model interface{}) []interface{} {
 	modelValue := reflect.ValueOf(model)
 	modelType := reflect.TypeOf(model)
@@ -57,12 +91,14 @@ func isValidSortOrder(order string) bool {
 // 	return validFields[field]
 // }
 
+// isValidSortField reports whether field is a column of model. Encrypted columns are left out,
+// their stored values can't be compared.
 func isValidSortField(field string, model interface{}) bool {
 	modelType := reflect.TypeOf(model)
 	for i := 0; i < modelType.NumField(); i++ {
 		dbTag := modelType.Field(i).Tag.Get("db")
 		dbTag = strings.TrimSuffix(dbTag, ",omitempty")
-		if dbTag == field {
+		if dbTag == field && modelType.Field(i).Tag.Get("encrypted") != "true" {
 			return true
 		}
 	}
@@ -120,7 +156,7 @@ func AddFilters(r *http.Request, query string, args []any, model interface{}) (s
 	for i := 0; i < modelType.NumField(); i++ {
 		dbTag := modelType.Field(i).Tag.Get("db")
 		dbTag = strings.TrimSuffix(dbTag, ",omitempty")
-		if dbTag != "" && dbTag != "id" {
+		if dbTag != "" && dbTag != "id" && modelType.Field(i).Tag.Get("encrypted") != "true" {
 			value := r.URL.Query().Get(dbTag)
 			if value != "" {
 				query += " AND " + dbTag + " = ?"
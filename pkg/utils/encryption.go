@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+)
+
+// encryptedPrefix marks values sealed by EncryptField, the version allows the scheme or key to
+// change later while old values can still be read
+const encryptedPrefix = "v1:"
+
+var (
+	fieldAEAD     cipher.AEAD
+	fieldAEADErr  error
+	fieldAEADOnce sync.Once
+)
+
+// InitEncryption reads DATA_ENCRYPTION_KEY, 32 bytes encoded in base64, and prepares the cipher
+// of EncryptField and DecryptField. The server calls it at start so a missing or invalid key
+// stops it there instead of failing requests later.
+func InitEncryption() error {
+	fieldAEADOnce.Do(func() {
+		fieldAEAD, fieldAEADErr = newFieldCipher(os.Getenv("DATA_ENCRYPTION_KEY"))
+	})
+	return fieldAEADErr
+}
+
+// newFieldCipher is AES-256-GCM keyed with encoded
+func newFieldCipher(encoded string) (cipher.AEAD, error) {
+	if encoded == "" {
+		return nil, errors.New("DATA_ENCRYPTION_KEY is not set")
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != 32 {
+		return nil, errors.New("DATA_ENCRYPTION_KEY must be 32 bytes encoded in base64")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptField seals a sensitive value for storage with the application key. aad names where the
+// value is stored, e.g. its column and row, and must be given again to DecryptField, so a sealed
+// value copied elsewhere can't be read. Empty values are stored as they are.
+func EncryptField(value, aad string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	err := InitEncryption()
+	if err != nil {
+		return "", ErrorHandler(err, "Error encrypting data")
+	}
+	sealed, err := sealField(fieldAEAD, value, aad)
+	if err != nil {
+		return "", ErrorHandler(err, "Error encrypting data")
+	}
+	return sealed, nil
+}
+
+// DecryptField opens a value sealed by EncryptField with the same aad
+func DecryptField(stored, aad string) (string, error) {
+	if stored == "" {
+		return "", nil
+	}
+	err := InitEncryption()
+	if err != nil {
+		return "", ErrorHandler(err, "Error decrypting data")
+	}
+	value, err := openField(fieldAEAD, stored, aad)
+	if err != nil {
+		return "", ErrorHandler(err, "Error decrypting data")
+	}
+	return value, nil
+}
+
+func sealField(aead cipher.AEAD, value, aad string) (string, error) {
+	nonce := make([]byte, aead.NonceSize())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(aad))
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func openField(aead cipher.AEAD, stored, aad string) (string, error) {
+	encoded, ok := strings.CutPrefix(stored, encryptedPrefix)
+	if !ok {
+		return "", errors.New("unknown encryption scheme")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("encrypted value is too short")
+	}
+	value, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(aad))
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
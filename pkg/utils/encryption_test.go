@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"encoding/base64"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var (
+	testKey  = base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	otherKey = base64.StdEncoding.EncodeToString([]byte("fedcba9876543210fedcba9876543210"))
+)
+
+// useKey sets DATA_ENCRYPTION_KEY for one test, dropping the cipher InitEncryption keeps
+func useKey(t *testing.T, key string) {
+	t.Helper()
+	t.Setenv("DATA_ENCRYPTION_KEY", key)
+	reset := func() {
+		fieldAEAD, fieldAEADErr, fieldAEADOnce = nil, nil, sync.Once{}
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+func TestInitEncryption(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{"valid", testKey, false},
+		{"missing", "", true},
+		{"not base64", "not a key!", true},
+		{"too short", base64.StdEncoding.EncodeToString([]byte("0123456789abcdef")), true},
+		{"too long", base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 33))), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useKey(t, tt.key)
+			err := InitEncryption()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("InitEncryption() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEncryptFieldRoundTrip(t *testing.T) {
+	useKey(t, testKey)
+	for _, value := range []string{"Calle Mayor 5, Madrid", "Asthma, inhaler in bag", "ü€😀", strings.Repeat("x", 1000)} {
+		sealed, err := EncryptField(value, "students.address.1")
+		if err != nil {
+			t.Fatalf("EncryptField(%q) error = %v", value, err)
+		}
+		if !strings.HasPrefix(sealed, encryptedPrefix) || strings.Contains(sealed, value) {
+			t.Errorf("EncryptField(%q) = %q, want a sealed value", value, sealed)
+		}
+		opened, err := DecryptField(sealed, "students.address.1")
+		if err != nil || opened != value {
+			t.Errorf("DecryptField() = %q, %v, want %q", opened, err, value)
+		}
+	}
+}
+
+func TestEncryptFieldUsesFreshNonces(t *testing.T) {
+	useKey(t, testKey)
+	a, _ := EncryptField("same", "aad")
+	b, _ := EncryptField("same", "aad")
+	if a == b {
+		t.Errorf("EncryptField sealed the same value twice as %q", a)
+	}
+}
+
+func TestEncryptFieldEmpty(t *testing.T) {
+	useKey(t, "")
+	sealed, err := EncryptField("", "aad")
+	if err != nil || sealed != "" {
+		t.Errorf(`EncryptField("") = %q, %v, want "" without a key`, sealed, err)
+	}
+	opened, err := DecryptField("", "aad")
+	if err != nil || opened != "" {
+		t.Errorf(`DecryptField("") = %q, %v, want "" without a key`, opened, err)
+	}
+	if _, err := EncryptField("value", "aad"); err == nil {
+		t.Error("EncryptField without a key succeeded")
+	}
+}
+
+func TestDecryptFieldRejects(t *testing.T) {
+	aead, err := newFieldCipher(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := newFieldCipher(otherKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := sealField(aead, "Peanut allergy", "students.medical_notes.7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(sealed, encryptedPrefix))
+	flipped := append([]byte(nil), raw...)
+	flipped[len(flipped)-1] ^= 1
+
+	tests := []struct {
+		name   string
+		stored string
+		aad    string
+		open   func(string, string) (string, error)
+	}{
+		{"wrong key", sealed, "students.medical_notes.7", func(s, aad string) (string, error) { return openField(other, s, aad) }},
+		{"other student", sealed, "students.medical_notes.8", nil},
+		{"other column", sealed, "students.address.7", nil},
+		{"tampered", encryptedPrefix + base64.StdEncoding.EncodeToString(flipped), "students.medical_notes.7", nil},
+		{"truncated", encryptedPrefix + base64.StdEncoding.EncodeToString(raw[:8]), "students.medical_notes.7", nil},
+		{"not base64", encryptedPrefix + "***", "students.medical_notes.7", nil},
+		{"unknown scheme", "v9:" + strings.TrimPrefix(sealed, encryptedPrefix), "students.medical_notes.7", nil},
+		{"plaintext", "Peanut allergy", "students.medical_notes.7", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			open := tt.open
+			if open == nil {
+				open = func(s, aad string) (string, error) { return openField(aead, s, aad) }
+			}
+			if value, err := open(tt.stored, tt.aad); err == nil {
+				t.Errorf("openField() = %q, want an error", value)
+			}
+		})
+	}
+
+	if value, err := openField(aead, sealed, "students.medical_notes.7"); err != nil || value != "Peanut allergy" {
+		t.Errorf("openField() = %q, %v, want the sealed value", value, err)
+	}
+}
+
+func TestDecryptFieldWrongKey(t *testing.T) {
+	useKey(t, testKey)
+	sealed, err := EncryptField("Calle Mayor 5", "students.address.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	useKey(t, otherKey)
+	if value, err := DecryptField(sealed, "students.address.1"); err == nil {
+		t.Errorf("DecryptField() with another key = %q, want an error", value)
+	}
+}
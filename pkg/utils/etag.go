@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AnyVersion is returned by IfMatchVersion for "If-Match: *", it matches any current version
+const AnyVersion = 0
+
+// VersionETag returns the strong entity tag for a row version, e.g. "3"
+func VersionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// SetVersionETag sets the ETag header of the response to the row version
+func SetVersionETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", VersionETag(version))
+}
+
+// IfMatchVersion returns the row version the client expects from the If-Match header.
+// A missing header is a 428, weak or malformed tags can never match and are a 412.
+func IfMatchVersion(r *http.Request) (int, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		return 0, PreconditionRequiredError("If-Match header with the resource ETag is required")
+	}
+	if header == "*" {
+		return AnyVersion, nil
+	}
+
+	if len(header) < 2 || !strings.HasPrefix(header, `"`) || !strings.HasSuffix(header, `"`) {
+		return 0, PreconditionFailedError("If-Match does not match the current ETag")
+	}
+	version, err := strconv.Atoi(header[1 : len(header)-1])
+	if err != nil || version < 1 {
+		return 0, PreconditionFailedError("If-Match does not match the current ETag")
+	}
+	return version, nil
+}
+
+// ContentETag returns a weak entity tag from a hash of body, for responses
+// that have no row version to derive one from, like lists. It is weak because
+// the hash is of the body before compression, which differs per encoding.
+func ContentETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// SetLastModified sets the Last-Modified header of the response, zero times are skipped
+func SetLastModified(w http.ResponseWriter, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	w.Header().Set("Last-Modified", t.UTC().Format(http.TimeFormat))
+}
+
+// NotModified reports whether a GET can be answered with 304 Not Modified given
+// the ETag and Last-Modified of the response. As in RFC 9110, If-None-Match takes
+// precedence and If-Modified-Since is only looked at when it is absent.
+func NotModified(r *http.Request, etag, lastModified string) bool {
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		return etag != "" && etagListMatches(header, etag)
+	}
+
+	header := r.Header.Get("If-Modified-Since")
+	if header == "" || lastModified == "" {
+		return false
+	}
+	since, err := http.ParseTime(header)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.After(since)
+}
+
+// etagListMatches uses the weak comparison If-None-Match calls for
+func etagListMatches(header, etag string) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
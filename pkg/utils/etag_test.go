@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVersionETag(t *testing.T) {
+	if got := VersionETag(3); got != `"3"` {
+		t.Errorf(`VersionETag(3) = %s, want "3"`, got)
+	}
+}
+
+func TestIfMatchVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    int
+		wantErr int
+	}{
+		{"missing", "", 0, http.StatusPreconditionRequired},
+		{"blank", "   ", 0, http.StatusPreconditionRequired},
+		{"any", "*", AnyVersion, 0},
+		{"strong tag", `"4"`, 4, 0},
+		{"surrounding space", ` "12" `, 12, 0},
+		{"weak tag", `W/"4"`, 0, http.StatusPreconditionFailed},
+		{"unquoted", "4", 0, http.StatusPreconditionFailed},
+		{"lone quote", `"`, 0, http.StatusPreconditionFailed},
+		{"not a number", `"abc"`, 0, http.StatusPreconditionFailed},
+		{"zero", `"0"`, 0, http.StatusPreconditionFailed},
+		{"negative", `"-1"`, 0, http.StatusPreconditionFailed},
+		{"list", `"1", "2"`, 0, http.StatusPreconditionFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/students/1", nil)
+			if tt.header != "" {
+				r.Header.Set("If-Match", tt.header)
+			}
+			got, err := IfMatchVersion(r)
+			if tt.wantErr == 0 {
+				if err != nil || got != tt.want {
+					t.Errorf("IfMatchVersion() = %d, %v, want %d", got, err, tt.want)
+				}
+				return
+			}
+			var appErr *AppError
+			if !errors.As(err, &appErr) || appErr.Status() != tt.wantErr {
+				t.Errorf("IfMatchVersion() error = %v, want status %d", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestContentETag(t *testing.T) {
+	a, b := ContentETag([]byte(`[1]`)), ContentETag([]byte(`[2]`))
+	if a == b {
+		t.Errorf("ContentETag gave %s for different bodies", a)
+	}
+	if a != ContentETag([]byte(`[1]`)) {
+		t.Errorf("ContentETag is not stable")
+	}
+	if len(a) != 36 || a[:3] != `W/"` || a[len(a)-1] != '"' {
+		t.Errorf("ContentETag() = %s, want a weak tag of a 32 digit hash", a)
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	const lastModified = "Wed, 01 Oct 2025 10:00:00 GMT"
+	tests := []struct {
+		name         string
+		ifNoneMatch  string
+		ifModSince   string
+		etag         string
+		lastModified string
+		want         bool
+	}{
+		{"no conditions", "", "", `"3"`, lastModified, false},
+		{"etag matches", `"3"`, "", `"3"`, lastModified, true},
+		{"etag differs", `"2"`, "", `"3"`, lastModified, false},
+		{"weak comparison", `W/"3"`, "", `"3"`, lastModified, true},
+		{"weak etag", `"3"`, "", `W/"3"`, lastModified, true},
+		{"in a list", `"1", "3"`, "", `"3"`, lastModified, true},
+		{"star", "*", "", `"3"`, lastModified, true},
+		{"no etag to match", `"3"`, "", "", lastModified, false},
+		{"if-none-match wins over date", `"2"`, lastModified, `"3"`, lastModified, false},
+		{"not modified since", "", lastModified, `"3"`, lastModified, true},
+		{"modified after", "", "Wed, 01 Oct 2025 09:59:59 GMT", `"3"`, lastModified, false},
+		{"later date", "", "Thu, 02 Oct 2025 00:00:00 GMT", `"3"`, lastModified, true},
+		{"bad date", "", "yesterday", `"3"`, lastModified, false},
+		{"no last modified", "", lastModified, `"3"`, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/students", nil)
+			if tt.ifNoneMatch != "" {
+				r.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			if tt.ifModSince != "" {
+				r.Header.Set("If-Modified-Since", tt.ifModSince)
+			}
+			if got := NotModified(r, tt.etag, tt.lastModified); got != tt.want {
+				t.Errorf("NotModified() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
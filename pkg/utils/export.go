@@ -0,0 +1,220 @@
+package utils
+
+import (
+	"encoding/csv"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Export formats of listings
+const (
+	ExportCSV  = "csv"
+	ExportXLSX = "xlsx"
+	ExportPDF  = "pdf"
+)
+
+var exportContentTypes = map[string]string{
+	ExportCSV:  "text/csv",
+	ExportXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	ExportPDF:  "application/pdf",
+}
+
+// ExportFormat returns the format a listing was asked for, with ?format= or else the Accept header.
+// It returns "" for JSON. Accept is read in order, quality values are not weighed.
+func ExportFormat(r *http.Request) (string, error) {
+	if format := r.URL.Query().Get("format"); format != "" {
+		if format == "json" {
+			return "", nil
+		}
+		if _, ok := exportContentTypes[format]; !ok {
+			return "", ValidationError("Invalid format", FieldError{Field: "format", Message: "must be one of json, csv, xlsx or pdf"})
+		}
+		return format, nil
+	}
+
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if mediaType == "application/json" || mediaType == "*/*" {
+			return "", nil
+		}
+		for format, contentType := range exportContentTypes {
+			if mediaType == contentType {
+				return format, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// RowWriter writes a listing to the response one row at a time
+type RowWriter interface {
+	WriteRow(values []string) error
+	// Close finishes the file, it must be called once every row has been written
+	Close() error
+}
+
+// NewRowWriter starts an export of a listing as an attachment called name, e.g. students.csv.
+// title heads the PDF pages and names the Excel sheet.
+func NewRowWriter(w http.ResponseWriter, format, name, title string, header []string) (RowWriter, error) {
+	w.Header().Set("Content-Type", exportContentTypes[format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"."+format))
+
+	switch format {
+	case ExportCSV:
+		return newCSVRowWriter(w, header)
+	case ExportXLSX:
+		return newXLSXRowWriter(w, title, header)
+	case ExportPDF:
+		return newPDFRowWriter(w, title, header), nil
+	}
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+// ExportHeader names the columns of model that are exported, "first_name" becomes "First Name"
+func ExportHeader(model interface{}) []string {
+	var header []string
+	for _, column := range exportColumns(reflect.TypeOf(model)) {
+		words := strings.Split(modelColumnName(reflect.TypeOf(model).Field(column)), "_")
+		for i, word := range words {
+			if word == "id" {
+				words[i] = "ID"
+			} else {
+				words[i] = strings.ToUpper(word[:1]) + word[1:]
+			}
+		}
+		header = append(header, strings.Join(words, " "))
+	}
+	return header
+}
+
+// ExportRow returns the exported columns of record, in the order of ExportHeader
+func ExportRow(record interface{}) []string {
+	value := reflect.ValueOf(record)
+	var row []string
+	for _, column := range exportColumns(value.Type()) {
+		field := value.Field(column)
+		// optional values are exported as what they point at, or left empty
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				row = append(row, "")
+				continue
+			}
+			field = field.Elem()
+		}
+		row = append(row, fmt.Sprint(field.Interface()))
+	}
+	return row
+}
+
+// exportColumns are the indexes of the stored fields of a model, the row version is left out
+func exportColumns(modelType reflect.Type) []int {
+	var columns []int
+	for i := 0; i < modelType.NumField(); i++ {
+		name := modelColumnName(modelType.Field(i))
+		if name != "" && name != "version" {
+			columns = append(columns, i)
+		}
+	}
+	return columns
+}
+
+func modelColumnName(field reflect.StructField) string {
+	return strings.TrimSuffix(field.Tag.Get("db"), ",omitempty")
+}
+
+type csvRowWriter struct {
+	writer *csv.Writer
+}
+
+func newCSVRowWriter(w http.ResponseWriter, header []string) (*csvRowWriter, error) {
+	rw := &csvRowWriter{writer: csv.NewWriter(w)}
+	return rw, rw.WriteRow(header)
+}
+
+func (rw *csvRowWriter) WriteRow(values []string) error {
+	for i, value := range values {
+		// spreadsheets run cells starting with these as formulas
+		if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+			values[i] = "'" + value
+		}
+	}
+	return rw.writer.Write(values)
+}
+
+func (rw *csvRowWriter) Close() error {
+	rw.writer.Flush()
+	return rw.writer.Error()
+}
+
+// xlsxRowWriter builds the sheet with excelize's stream writer, which moves rows to a temporary file
+// once they take up too much memory. The workbook can only be written out once it is complete.
+type xlsxRowWriter struct {
+	w      http.ResponseWriter
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func newXLSXRowWriter(w http.ResponseWriter, title string, header []string) (*xlsxRowWriter, error) {
+	file := excelize.NewFile()
+	// sheet names are limited to 31 characters
+	if len(title) > 31 {
+		title = title[:31]
+	}
+	err := file.SetSheetName("Sheet1", title)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := file.NewStreamWriter(title)
+	if err != nil {
+		return nil, err
+	}
+	err = stream.SetColWidth(1, len(header), 20)
+	if err != nil {
+		return nil, err
+	}
+
+	bold, err := file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return nil, err
+	}
+	cells := make([]interface{}, len(header))
+	for i, name := range header {
+		cells[i] = excelize.Cell{StyleID: bold, Value: name}
+	}
+	err = stream.SetRow("A1", cells, excelize.RowOpts{Height: 18})
+	if err != nil {
+		return nil, err
+	}
+	return &xlsxRowWriter{w: w, file: file, stream: stream, row: 1}, nil
+}
+
+func (rw *xlsxRowWriter) WriteRow(values []string) error {
+	rw.row++
+	cells := make([]interface{}, len(values))
+	for i, value := range values {
+		cells[i] = value
+	}
+	cell, err := excelize.CoordinatesToCellName(1, rw.row)
+	if err != nil {
+		return err
+	}
+	return rw.stream.SetRow(cell, cells)
+}
+
+func (rw *xlsxRowWriter) Close() error {
+	defer rw.file.Close()
+	err := rw.stream.Flush()
+	if err != nil {
+		return err
+	}
+	return rw.file.Write(rw.w)
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"encoding/csv"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCSVRowWriterEscapesFormulas(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Ana", "Ana"},
+		{"", ""},
+		{"=SUM(A1:A9)", "'=SUM(A1:A9)"},
+		{"+34 600 000 000", "'+34 600 000 000"},
+		{"-1", "'-1"},
+		{"@cmd", "'@cmd"},
+		{"\tTab", "'\tTab"},
+		{"\rReturn", "'\rReturn"},
+		{"a=b", "a=b"},
+		{"'quoted", "'quoted"},
+	}
+
+	rec := httptest.NewRecorder()
+	rw, err := newCSVRowWriter(rec, []string{"Value", "Row"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		if err := rw.WriteRow([]string{tt.in, "-"}); err != nil {
+			t.Fatalf("WriteRow(%q) error = %v", tt.in, err)
+		}
+	}
+	if err := rw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(rec.Body.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("export is not valid CSV: %v\n%s", err, rec.Body.String())
+	}
+	if len(rows) != len(tests)+1 || rows[0][0] != "Value" {
+		t.Fatalf("export = %q, want the header and %d rows", rows, len(tests))
+	}
+	for i, tt := range tests {
+		if got := rows[i+1]; got[0] != tt.want || got[1] != "'-" {
+			t.Errorf("WriteRow(%q) wrote %q, want %q", tt.in, got, []string{tt.want, "'-"})
+		}
+	}
+}
+
+type exportRecord struct {
+	ID        int     `db:"id"`
+	FirstName string  `db:"first_name,omitempty"`
+	Score     float64 `db:"score"`
+	Nickname  *string `db:"nickname,omitempty"`
+	Birthday  *string `db:"birthday,omitempty"`
+	Version   int     `db:"version"`
+	Note      string
+}
+
+func TestExportRow(t *testing.T) {
+	nickname := "Annie"
+	record := exportRecord{ID: 7, FirstName: "Ana", Score: 9.5, Nickname: &nickname, Version: 3, Note: "not stored"}
+
+	if got, want := ExportHeader(record), []string{"ID", "First Name", "Score", "Nickname", "Birthday"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ExportHeader() = %q, want %q", got, want)
+	}
+	if got, want := ExportRow(record), []string{"7", "Ana", "9.5", "Annie", ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ExportRow() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ICalContentType is the media type of iCalendar files
+const ICalContentType = "text/calendar"
+
+// icalTime is the layout of local times in iCalendar, without a time zone they are floating and
+// happen at the same wall clock time wherever the calendar is opened
+const icalTime = "20060102T150405"
+
+// ICalEvent is an event of a calendar, repeated every week until Until when it is set
+type ICalEvent struct {
+	UID         string
+	Summary     string
+	Location    string
+	Description string
+	Start       time.Time
+	End         time.Time
+	Until       time.Time
+}
+
+// WriteICal writes events as an iCalendar file called name
+func WriteICal(w io.Writer, name string, events []ICalEvent) error {
+	out := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeICalLine(out, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//School Management API//Timetable//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("X-WR-CALNAME", icalEscape(name))
+	stamp := time.Now().UTC().Format(icalTime) + "Z"
+	for _, event := range events {
+		line("BEGIN", "VEVENT")
+		line("UID", event.UID)
+		line("DTSTAMP", stamp)
+		line("DTSTART", event.Start.Format(icalTime))
+		line("DTEND", event.End.Format(icalTime))
+		if !event.Until.IsZero() {
+			line("RRULE", "FREQ=WEEKLY;UNTIL="+event.Until.Format(icalTime))
+		}
+		line("SUMMARY", icalEscape(event.Summary))
+		if event.Location != "" {
+			line("LOCATION", icalEscape(event.Location))
+		}
+		if event.Description != "" {
+			line("DESCRIPTION", icalEscape(event.Description))
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return out.Flush()
+}
+
+// writeICalLine ends a content line with CRLF, folding it so no line is longer than 75 bytes
+// without splitting a UTF-8 character
+func writeICalLine(out *bufio.Writer, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		fmt.Fprintf(out, "%s\r\n ", s[:cut])
+		s = s[cut:]
+		// continuation lines start with a space that counts towards their length
+		limit = 74
+	}
+	fmt.Fprintf(out, "%s\r\n", s)
+}
+
+// icalEscape escapes the characters that have a meaning in iCalendar text values
+func icalEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
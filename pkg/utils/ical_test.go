@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestICalEscape(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Math", "Math"},
+		{"Room 1, floor 2", `Room 1\, floor 2`},
+		{"a;b", `a\;b`},
+		{`back\slash`, `back\\slash`},
+		{"two\nlines", `two\nlines`},
+		{"two\r\nlines", `two\nlines`},
+		{`\,`, `\\\,`},
+	}
+	for _, tt := range tests {
+		if got := icalEscape(tt.in); got != tt.want {
+			t.Errorf("icalEscape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWriteICalLineFolds(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"short", "SUMMARY:Math"},
+		{"exactly 75 bytes", "DESCRIPTION:" + strings.Repeat("x", 63)},
+		{"76 bytes", "DESCRIPTION:" + strings.Repeat("x", 64)},
+		{"several folds", "DESCRIPTION:" + strings.Repeat("abcdefghij", 30)},
+		{"multibyte characters", "SUMMARY:" + strings.Repeat("Ünterricht für Schüler – ", 8)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			out := bufio.NewWriter(&buf)
+			writeICalLine(out, tt.line)
+			out.Flush()
+
+			written := buf.String()
+			if !strings.HasSuffix(written, "\r\n") {
+				t.Fatalf("line %q doesn't end with CRLF", written)
+			}
+			lines := strings.Split(strings.TrimSuffix(written, "\r\n"), "\r\n")
+			for i, line := range lines {
+				if len(line) > 75 {
+					t.Errorf("line %d is %d bytes long", i, len(line))
+				}
+				if i > 0 && !strings.HasPrefix(line, " ") {
+					t.Errorf("continuation line %d doesn't start with a space: %q", i, line)
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a UTF-8 character: %q", i, line)
+				}
+			}
+			if unfolded := strings.ReplaceAll(strings.TrimSuffix(written, "\r\n"), "\r\n ", ""); unfolded != tt.line {
+				t.Errorf("unfolded line = %q, want %q", unfolded, tt.line)
+			}
+			if len(tt.line) <= 75 && len(lines) != 1 {
+				t.Errorf("line of %d bytes was folded", len(tt.line))
+			}
+		})
+	}
+}
+
+func TestWriteICal(t *testing.T) {
+	start := time.Date(2025, 9, 1, 8, 30, 0, 0, time.UTC)
+	events := []ICalEvent{
+		{
+			UID:         "slot-1@school",
+			Summary:     "Math, 10A",
+			Location:    "Room 101",
+			Description: "Teacher: Ana Lopez",
+			Start:       start,
+			End:         start.Add(45 * time.Minute),
+			Until:       time.Date(2025, 12, 19, 23, 59, 59, 0, time.UTC),
+		},
+		{UID: "slot-2@school", Summary: "Art", Start: start.Add(time.Hour), End: start.Add(105 * time.Minute)},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteICal(&buf, "Timetable; 10A", events); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+
+	want := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//School Management API//Timetable//EN",
+		"CALSCALE:GREGORIAN",
+		`X-WR-CALNAME:Timetable\; 10A`,
+		"BEGIN:VEVENT",
+		"UID:slot-1@school",
+		"DTSTAMP:",
+		"DTSTART:20250901T083000",
+		"DTEND:20250901T091500",
+		"RRULE:FREQ=WEEKLY;UNTIL=20251219T235959",
+		`SUMMARY:Math\, 10A`,
+		"LOCATION:Room 101",
+		"DESCRIPTION:Teacher: Ana Lopez",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:slot-2@school",
+		"DTSTAMP:",
+		"DTSTART:20250901T093000",
+		"DTEND:20250901T101500",
+		"SUMMARY:Art",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("WriteICal() wrote %d lines, want %d:\n%s", len(lines), len(want), buf.String())
+	}
+	for i := range want {
+		if want[i] == "DTSTAMP:" {
+			if !strings.HasPrefix(lines[i], "DTSTAMP:") || !strings.HasSuffix(lines[i], "Z") || len(lines[i]) != len("DTSTAMP:20060102T150405Z") {
+				t.Errorf("line %d = %q, want a UTC DTSTAMP", i, lines[i])
+			}
+			continue
+		}
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, lines[i], want[i])
+		}
+	}
+}
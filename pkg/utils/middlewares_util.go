@@ -5,9 +5,12 @@ import "net/http"
 // Middleware is a function that wraps an http.Handler with additional functionality
 type Middleware func(http.Handler) http.Handler
 
+// ApplyMiddlewares wraps handler with middlewares, the first one being the innermost.
+// The handler and every middleware get their own tracing span.
 func ApplyMiddlewares(handler http.Handler, middlewares ...Middleware) http.Handler {
+	handler = TraceHandler(handler)
 	for _, middleware := range middlewares {
-		handler = middleware(handler)
+		handler = TraceMiddleware(middlewareName(middleware), middleware(handler))
 	}
 	return handler
 }
@@ -0,0 +1,263 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// A4 landscape, in points
+const (
+	pdfPageWidth  = 842.0
+	pdfPageHeight = 595.0
+	pdfMargin     = 36.0
+	pdfFontSize   = 9.0
+	pdfLineHeight = 14.0
+)
+
+// Objects written before the first page, the page tree is written last because it lists every page
+const (
+	pdfCatalogObject  = 1
+	pdfPagesObject    = 2
+	pdfFontObject     = 3
+	pdfBoldFontObject = 4
+)
+
+// pdfRowWriter lays rows out as a table with the standard Helvetica font and writes every page
+// to the response as soon as it is full, so only one page is held in memory
+type pdfRowWriter struct {
+	out     *countingWriter
+	title   string
+	header  []string
+	widths  []float64
+	offsets []int64 // byte offset of every object, for the cross-reference table
+	pages   []int
+	content bytes.Buffer // drawing operations of the current page
+	y       float64
+	err     error
+	winAnsi *encoding.Encoder
+}
+
+func newPDFRowWriter(w io.Writer, title string, header []string) *pdfRowWriter {
+	rw := &pdfRowWriter{
+		out:     &countingWriter{w: w},
+		title:   title,
+		header:  header,
+		offsets: make([]int64, pdfBoldFontObject+1),
+		// the standard fonts are Windows-1252 encoded
+		winAnsi: encoding.ReplaceUnsupported(charmap.Windows1252.NewEncoder()),
+	}
+	rw.widths = pdfColumnWidths(len(header))
+
+	rw.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+	rw.object(pdfCatalogObject, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pdfPagesObject))
+	rw.object(pdfFontObject, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	rw.object(pdfBoldFontObject, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	return rw
+}
+
+func (rw *pdfRowWriter) WriteRow(values []string) error {
+	if rw.content.Len() == 0 || rw.y < pdfMargin+pdfLineHeight {
+		rw.finishPage()
+		rw.startPage()
+	}
+	rw.drawRow("/F1", values)
+	return rw.err
+}
+
+func (rw *pdfRowWriter) Close() error {
+	if rw.content.Len() == 0 {
+		// an empty listing still gets a page with its header
+		rw.startPage()
+	}
+	rw.finishPage()
+
+	kids := make([]string, len(rw.pages))
+	for i, page := range rw.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", page)
+	}
+	rw.object(pdfPagesObject, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(rw.pages)))
+
+	xref := rw.out.n
+	rw.printf("xref\n0 %d\n0000000000 65535 f \n", len(rw.offsets))
+	for _, offset := range rw.offsets[1:] {
+		rw.printf("%010d 00000 n \n", offset)
+	}
+	rw.printf("trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(rw.offsets), pdfCatalogObject, xref)
+	return rw.err
+}
+
+func (rw *pdfRowWriter) startPage() {
+	rw.content.Reset()
+	rw.y = pdfPageHeight - pdfMargin - 12
+	rw.text("/F2", 12, pdfMargin, rw.y, rw.title)
+	footer := fmt.Sprintf("Page %d, generated %s", len(rw.pages)+1, time.Now().UTC().Format("2006-01-02 15:04 MST"))
+	rw.text("/F1", 8, pdfMargin, pdfMargin/2, footer)
+	rw.y -= 2 * pdfLineHeight
+	if len(rw.header) > 0 {
+		rw.drawRow("/F2", rw.header)
+	}
+}
+
+// finishPage writes the current page, if there is one, as a content stream and a page object
+func (rw *pdfRowWriter) finishPage() {
+	if rw.content.Len() == 0 {
+		return
+	}
+	contents := rw.nextObject()
+	rw.object(contents, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", rw.content.Len(), rw.content.Bytes()))
+	page := rw.nextObject()
+	rw.object(page, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %g %g] /Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> >> /Contents %d 0 R >>",
+		pdfPagesObject, pdfPageWidth, pdfPageHeight, pdfFontObject, pdfBoldFontObject, contents))
+	rw.pages = append(rw.pages, page)
+	rw.content.Reset()
+}
+
+func (rw *pdfRowWriter) drawRow(font string, values []string) {
+	x := pdfMargin
+	for i, value := range values {
+		if i >= len(rw.widths) {
+			break
+		}
+		rw.text(font, pdfFontSize, x, rw.y, fitText(value, rw.widths[i]-4))
+		x += rw.widths[i]
+	}
+	rw.y -= pdfLineHeight
+}
+
+func (rw *pdfRowWriter) text(font string, size, x, y float64, s string) {
+	encoded, err := rw.winAnsi.String(s)
+	if err != nil {
+		encoded = "?"
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`, "\r", " ", "\n", " ").Replace(encoded)
+	fmt.Fprintf(&rw.content, "BT %s %g Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, escaped)
+}
+
+func (rw *pdfRowWriter) nextObject() int {
+	rw.offsets = append(rw.offsets, 0)
+	return len(rw.offsets) - 1
+}
+
+func (rw *pdfRowWriter) object(number int, body string) {
+	rw.offsets[number] = rw.out.n
+	rw.printf("%d 0 obj\n%s\nendobj\n", number, body)
+}
+
+func (rw *pdfRowWriter) printf(format string, args ...interface{}) {
+	if rw.err == nil {
+		_, rw.err = fmt.Fprintf(rw.out, format, args...)
+	}
+}
+
+// PDFSection is a part of a PDF report with a title, lines of text and a table
+type PDFSection struct {
+	Title  string
+	Lines  []string
+	Header []string
+	Rows   [][]string
+}
+
+// WritePDFReport writes a document, e.g. a report card, as a PDF of lines under the title followed
+// by its sections. Long lines are wrapped, tables that run over a page repeat their header.
+func WritePDFReport(w io.Writer, title string, lines []string, sections []PDFSection) error {
+	rw := newPDFRowWriter(w, title, nil)
+	rw.startPage()
+	for _, line := range lines {
+		rw.line("/F1", line)
+	}
+	for _, section := range sections {
+		// a section title isn't left alone at the bottom of a page
+		rw.header = nil
+		rw.y -= pdfLineHeight / 2
+		rw.ensureSpace(3)
+		rw.line("/F2", section.Title)
+		for _, line := range section.Lines {
+			rw.line("/F1", line)
+		}
+		if len(section.Header) > 0 {
+			rw.header = section.Header
+			rw.widths = pdfColumnWidths(len(section.Header))
+			rw.ensureSpace(2)
+			rw.drawRow("/F2", rw.header)
+			for _, row := range section.Rows {
+				rw.WriteRow(row)
+			}
+		}
+	}
+	return rw.Close()
+}
+
+// line writes s across the page, wrapped over as many lines as it takes
+func (rw *pdfRowWriter) line(font, s string) {
+	for _, part := range wrapText(s, pdfPageWidth-2*pdfMargin) {
+		rw.ensureSpace(1)
+		rw.text(font, pdfFontSize, pdfMargin, rw.y, part)
+		rw.y -= pdfLineHeight
+	}
+}
+
+// ensureSpace starts a new page unless lines more lines fit on the current one
+func (rw *pdfRowWriter) ensureSpace(lines int) {
+	if rw.y < pdfMargin+float64(lines)*pdfLineHeight {
+		rw.finishPage()
+		rw.startPage()
+	}
+}
+
+func pdfColumnWidths(columns int) []float64 {
+	widths := make([]float64, columns)
+	for i := range widths {
+		widths[i] = (pdfPageWidth - 2*pdfMargin) / float64(columns)
+	}
+	return widths
+}
+
+// wrapText breaks s into lines that fit in width at word boundaries, see fitText
+func wrapText(s string, width float64) []string {
+	maxChars := int(width / (pdfFontSize * 0.55))
+	var lines []string
+	var current []rune
+	for _, word := range strings.Fields(s) {
+		runes := []rune(word)
+		if len(current) > 0 && len(current)+1+len(runes) > maxChars {
+			lines = append(lines, fitText(string(current), width))
+			current = nil
+		}
+		if len(current) > 0 {
+			current = append(current, ' ')
+		}
+		current = append(current, runes...)
+	}
+	if len(current) > 0 || len(lines) == 0 {
+		lines = append(lines, fitText(string(current), width))
+	}
+	return lines
+}
+
+// fitText shortens s to fit in width, estimating Helvetica's average character width
+func fitText(s string, width float64) string {
+	runes := []rune(s)
+	maxChars := int(width / (pdfFontSize * 0.55))
+	if len(runes) <= maxChars || maxChars < 2 {
+		return s
+	}
+	return string(runes[:maxChars-1]) + "…"
+}
+
+// countingWriter keeps track of the offset in the output, which the cross-reference table needs
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// Problem is an RFC 7807 problem details response body
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// WriteProblem writes an application/problem+json response with the given status and detail
+func WriteProblem(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	writeProblem(w, Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: r.URL.Path,
+	})
+}
+
+// WriteError maps err to a status code and writes it as application/problem+json.
+// Errors that are not an *AppError are treated as internal server errors.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	problem := errorProblem(err)
+	problem.Instance = r.URL.Path
+	writeProblem(w, problem)
+}
+
+// errorProblem describes err as a problem, without the instance it occurred on
+func errorProblem(err error) Problem {
+	var appErr *AppError
+	if !errors.As(err, &appErr) {
+		return Problem{
+			Type:   "about:blank",
+			Title:  http.StatusText(http.StatusInternalServerError),
+			Status: http.StatusInternalServerError,
+			Detail: err.Error(),
+		}
+	}
+
+	status := appErr.Status()
+	return Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: appErr.Message,
+		Errors: appErr.Fields,
+	}
+}
+
+func writeProblem(w http.ResponseWriter, problem Problem) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	// errors must not be served from a cache in place of the resource
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/aayushxrj/go-rest-api-school-mgmt"
+
+// Tracer returns the tracer used for the API's own spans
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// TraceHandler wraps handler in a span named after the route it is registered under
+func TraceHandler(handler http.Handler) http.Handler {
+	mux, isMux := handler.(*http.ServeMux)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := "handler"
+		if isMux {
+			if _, pattern := mux.Handler(r); pattern != "" {
+				name += " " + pattern
+			}
+		}
+		ctx, span := Tracer().Start(r.Context(), name)
+		defer span.End()
+
+		handler.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// TraceMiddleware wraps the output of a middleware in a span, so it covers the middleware
+// itself and everything it calls further down the chain
+func TraceMiddleware(name string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := Tracer().Start(r.Context(), "middleware "+name)
+		defer span.End()
+
+		handler.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+var closureSuffix = regexp.MustCompile(`(\.func\d+)+$|-fm$`)
+
+// middlewareName derives a readable name like "middlewares.Compression" from a middleware func
+func middlewareName(middleware Middleware) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(middleware).Pointer())
+	if fn == nil {
+		return "unknown"
+	}
+	name := fn.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return closureSuffix.ReplaceAllString(name, "")
+}
@@ -0,0 +1,282 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Validation rules are declared on model fields with the `validate` tag, e.g.
+//
+//	Email string `json:"email" validate:"required,email,maxlen=100"`
+//
+// Supported rules:
+//   - required       value must not be blank
+//   - email          value must be a plain email address
+//   - maxlen=N       value must be at most N characters
+//   - enum=a|b|c     value must be one of the listed values
+//   - pattern=REGEX  value must match REGEX, must be the last rule as it may contain commas
+//   - min=N          number must be at least N
+//   - max=N          number must be at most N
+//   - readonly       value is maintained by the server, partial updates may not set it
+//
+// String and number fields are validated. A number is blank when it is zero, or nil for pointers.
+// Rules other than required are skipped for blank values.
+
+var patternCache sync.Map // map[string]*regexp.Regexp
+
+// ValidateStruct checks every tagged field of model and returns all failures
+func ValidateStruct(model interface{}) []FieldError {
+	val := reflect.ValueOf(model)
+	modelType := val.Type()
+
+	var errs []FieldError
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		tag := field.Tag.Get("validate")
+		if tag == "" {
+			continue
+		}
+		value := val.Field(i)
+		// a set pointer is never blank, even when it points at a zero
+		pointer := value.Kind() == reflect.Ptr
+		if pointer {
+			if value.IsNil() {
+				if isNumber(field.Type.Elem()) {
+					errs = append(errs, validateNumber(jsonFieldName(field), 0, true, tag)...)
+				}
+				continue
+			}
+			value = value.Elem()
+		}
+		switch {
+		case value.Kind() == reflect.String:
+			errs = append(errs, validateValue(jsonFieldName(field), value.String(), tag)...)
+		case isInteger(value.Type()):
+			errs = append(errs, validateNumber(jsonFieldName(field), float64(value.Int()), !pointer && value.IsZero(), tag)...)
+		case isNumber(value.Type()):
+			errs = append(errs, validateNumber(jsonFieldName(field), value.Float(), !pointer && value.IsZero(), tag)...)
+		}
+	}
+	return errs
+}
+
+// ValidateFields checks the fields present in a partial update against the rules on model.
+// Keys that don't belong to model are reported, keys of untagged fields like id are skipped.
+func ValidateFields(model interface{}, updates map[string]interface{}) []FieldError {
+	modelType := reflect.TypeOf(model)
+
+	fields := make(map[string]reflect.StructField, modelType.NumField())
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		fields[jsonFieldName(field)] = field
+	}
+
+	// sorted so errors come back in a stable order
+	keys := make([]string, 0, len(updates))
+	for key := range updates {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []FieldError
+	for _, key := range keys {
+		v := updates[key]
+		field, ok := fields[key]
+		if !ok {
+			errs = append(errs, FieldError{Field: key, Message: "is not an allowed field"})
+			continue
+		}
+		tag := field.Tag.Get("validate")
+		if tag == "" {
+			continue
+		}
+		if contains(splitRules(tag), "readonly") {
+			errs = append(errs, FieldError{Field: key, Message: "is read-only"})
+			continue
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+			// null clears an optional value
+			if v == nil {
+				errs = append(errs, validateNumber(key, 0, true, tag)...)
+				continue
+			}
+		}
+		if isNumber(fieldType) {
+			number, ok := v.(float64)
+			if !ok {
+				errs = append(errs, FieldError{Field: key, Message: "must be a number"})
+				continue
+			}
+			if isInteger(fieldType) && number != math.Trunc(number) {
+				errs = append(errs, FieldError{Field: key, Message: "must be a whole number"})
+				continue
+			}
+			errs = append(errs, validateNumber(key, number, number == 0 && field.Type.Kind() != reflect.Ptr, tag)...)
+			continue
+		}
+
+		value, ok := v.(string)
+		if !ok {
+			errs = append(errs, FieldError{Field: key, Message: "must be a string"})
+			continue
+		}
+		errs = append(errs, validateValue(key, value, tag)...)
+	}
+	return errs
+}
+
+// WithIndex tags errs with the position of the item they belong to in a bulk payload
+func WithIndex(errs []FieldError, index int) []FieldError {
+	for i := range errs {
+		idx := index
+		errs[i].Index = &idx
+	}
+	return errs
+}
+
+// WithRow tags errs with the row of an imported file they were found on
+func WithRow(errs []FieldError, row int) []FieldError {
+	for i := range errs {
+		r := row
+		errs[i].Row = &r
+	}
+	return errs
+}
+
+func validateValue(name, value, tag string) []FieldError {
+	var errs []FieldError
+	blank := strings.TrimSpace(value) == ""
+
+	for _, rule := range splitRules(tag) {
+		ruleName, arg, _ := strings.Cut(rule, "=")
+
+		if ruleName == "required" {
+			if blank {
+				errs = append(errs, FieldError{Field: name, Message: "is required"})
+			}
+			continue
+		}
+		if blank {
+			continue
+		}
+
+		switch ruleName {
+		case "email":
+			addr, err := mail.ParseAddress(value)
+			if err != nil || addr.Address != value {
+				errs = append(errs, FieldError{Field: name, Message: "must be a valid email address"})
+			}
+		case "maxlen":
+			max, err := strconv.Atoi(arg)
+			if err == nil && utf8.RuneCountInString(value) > max {
+				errs = append(errs, FieldError{Field: name, Message: fmt.Sprintf("must be at most %d characters", max)})
+			}
+		case "enum":
+			allowed := strings.Split(arg, "|")
+			if !contains(allowed, value) {
+				errs = append(errs, FieldError{Field: name, Message: "must be one of " + strings.Join(allowed, ", ")})
+			}
+		case "pattern":
+			re, err := compilePattern(arg)
+			if err == nil && !re.MatchString(value) {
+				errs = append(errs, FieldError{Field: name, Message: "has an invalid format"})
+			}
+		}
+	}
+	return errs
+}
+
+func validateNumber(name string, value float64, blank bool, tag string) []FieldError {
+	var errs []FieldError
+	for _, rule := range splitRules(tag) {
+		ruleName, arg, _ := strings.Cut(rule, "=")
+
+		if ruleName == "required" {
+			if blank {
+				errs = append(errs, FieldError{Field: name, Message: "is required"})
+			}
+			continue
+		}
+		if blank {
+			continue
+		}
+
+		limit, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			continue
+		}
+		switch ruleName {
+		case "min":
+			if value < limit {
+				errs = append(errs, FieldError{Field: name, Message: fmt.Sprintf("must be at least %g", limit)})
+			}
+		case "max":
+			if value > limit {
+				errs = append(errs, FieldError{Field: name, Message: fmt.Sprintf("must be at most %g", limit)})
+			}
+		}
+	}
+	return errs
+}
+
+func isInteger(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func isNumber(t reflect.Type) bool {
+	return isInteger(t) || t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64
+}
+
+// splitRules splits a validate tag on commas, keeping a trailing pattern rule intact
+func splitRules(tag string) []string {
+	var rules []string
+	for tag != "" {
+		if strings.HasPrefix(tag, "pattern=") {
+			return append(rules, tag)
+		}
+		rule, rest, _ := strings.Cut(tag, ",")
+		rules = append(rules, rule)
+		tag = rest
+	}
+	return rules
+}
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patternCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, ErrorHandler(err, "invalid validation pattern "+pattern)
+	}
+	patternCache.Store(pattern, re)
+	return re, nil
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	return strings.TrimSuffix(field.Tag.Get("json"), ",omitempty")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+type validatedRecord struct {
+	ID       int      `json:"id,omitempty"`
+	Name     string   `json:"name,omitempty" validate:"required,maxlen=5"`
+	Email    string   `json:"email,omitempty" validate:"email"`
+	Role     string   `json:"role,omitempty" validate:"enum=admin|exec"`
+	Code     string   `json:"code,omitempty" validate:"maxlen=10,pattern=^[A-Z]{2,3}$"`
+	Grade    int      `json:"grade,omitempty" validate:"required,min=1,max=12"`
+	Capacity int      `json:"capacity,omitempty" validate:"min=0,max=200"`
+	Score    *float64 `json:"score,omitempty" validate:"min=0,max=100"`
+	Weight   *float64 `json:"weight,omitempty" validate:"required,min=0"`
+	Stamp    string   `json:"stamp,omitempty" validate:"readonly"`
+}
+
+func floatPtr(f float64) *float64 { return &f }
+
+func validRecord() validatedRecord {
+	return validatedRecord{Name: "Ana", Email: "ana@school.com", Role: "admin", Code: "AB", Grade: 10, Weight: floatPtr(1)}
+}
+
+func TestValidateStruct(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*validatedRecord)
+		want   []FieldError
+	}{
+		{"valid", func(r *validatedRecord) {}, nil},
+		{"required string blank", func(r *validatedRecord) { r.Name = "  " }, []FieldError{{Field: "name", Message: "is required"}}},
+		{"maxlen counts runes", func(r *validatedRecord) { r.Name = "Zoë" }, nil},
+		{"maxlen exceeded", func(r *validatedRecord) { r.Name = "Alexandra" }, []FieldError{{Field: "name", Message: "must be at most 5 characters"}}},
+		{"email with display name", func(r *validatedRecord) { r.Email = "Ana <ana@school.com>" }, []FieldError{{Field: "email", Message: "must be a valid email address"}}},
+		{"optional email blank", func(r *validatedRecord) { r.Email = "" }, nil},
+		{"enum", func(r *validatedRecord) { r.Role = "root" }, []FieldError{{Field: "role", Message: "must be one of admin, exec"}}},
+		{"pattern", func(r *validatedRecord) { r.Code = "ab" }, []FieldError{{Field: "code", Message: "has an invalid format"}}},
+		{"pattern with comma quantifier", func(r *validatedRecord) { r.Code = "ABC" }, nil},
+		{"required number zero", func(r *validatedRecord) { r.Grade = 0 }, []FieldError{{Field: "grade", Message: "is required"}}},
+		{"below min", func(r *validatedRecord) { r.Grade = -1 }, []FieldError{{Field: "grade", Message: "must be at least 1"}}},
+		{"above max", func(r *validatedRecord) { r.Grade = 13 }, []FieldError{{Field: "grade", Message: "must be at most 12"}}},
+		{"max boundary", func(r *validatedRecord) { r.Grade, r.Capacity = 12, 200 }, nil},
+		{"optional number zero", func(r *validatedRecord) { r.Capacity = 0 }, nil},
+		{"pointer to zero is set", func(r *validatedRecord) { r.Score = floatPtr(0) }, nil},
+		{"pointer above max", func(r *validatedRecord) { r.Score = floatPtr(100.5) }, []FieldError{{Field: "score", Message: "must be at most 100"}}},
+		{"required pointer nil", func(r *validatedRecord) { r.Weight = nil }, []FieldError{{Field: "weight", Message: "is required"}}},
+		{"required pointer to zero", func(r *validatedRecord) { r.Weight = floatPtr(0) }, nil},
+		{"all failures reported", func(r *validatedRecord) { r.Name, r.Role = "", "x" }, []FieldError{
+			{Field: "name", Message: "is required"},
+			{Field: "role", Message: "must be one of admin, exec"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := validRecord()
+			tt.modify(&record)
+			got := ValidateStruct(record)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateStruct() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		updates map[string]interface{}
+		want    []FieldError
+	}{
+		{"valid", map[string]interface{}{"name": "Bo", "grade": float64(3)}, nil},
+		{"untagged field skipped", map[string]interface{}{"id": "7"}, nil},
+		{"unknown field", map[string]interface{}{"nickname": "Bo"}, []FieldError{{Field: "nickname", Message: "is not an allowed field"}}},
+		{"readonly", map[string]interface{}{"stamp": "x"}, []FieldError{{Field: "stamp", Message: "is read-only"}}},
+		{"required cleared", map[string]interface{}{"name": ""}, []FieldError{{Field: "name", Message: "is required"}}},
+		{"string expected", map[string]interface{}{"name": float64(1)}, []FieldError{{Field: "name", Message: "must be a string"}}},
+		{"number expected", map[string]interface{}{"grade": "10"}, []FieldError{{Field: "grade", Message: "must be a number"}}},
+		{"whole number expected", map[string]interface{}{"grade": 2.5}, []FieldError{{Field: "grade", Message: "must be a whole number"}}},
+		{"number out of range", map[string]interface{}{"capacity": float64(201)}, []FieldError{{Field: "capacity", Message: "must be at most 200"}}},
+		{"null clears optional pointer", map[string]interface{}{"score": nil}, nil},
+		{"null on required pointer", map[string]interface{}{"weight": nil}, []FieldError{{Field: "weight", Message: "is required"}}},
+		{"sorted by field", map[string]interface{}{"role": "x", "code": "x"}, []FieldError{
+			{Field: "code", Message: "has an invalid format"},
+			{Field: "role", Message: "must be one of admin, exec"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidateFields(validatedRecord{}, tt.updates)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateFields() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitRules(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want []string
+	}{
+		{"", nil},
+		{"required", []string{"required"}},
+		{"required,maxlen=10", []string{"required", "maxlen=10"}},
+		{"maxlen=10,pattern=^[a-z]{1,3}$", []string{"maxlen=10", "pattern=^[a-z]{1,3}$"}},
+	}
+	for _, tt := range tests {
+		got := splitRules(tt.tag)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitRules(%q) = %q, want %q", tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestWithIndex(t *testing.T) {
+	errs := WithIndex([]FieldError{{Field: "a"}, {Field: "b"}}, 3)
+	for _, err := range errs {
+		if err.Index == nil || *err.Index != 3 {
+			t.Errorf("WithIndex() left %+v without index 3", err)
+		}
+	}
+}